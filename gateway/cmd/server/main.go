@@ -2,18 +2,29 @@
 package main
 
 import (
-	"context"
+	"fmt"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
+	"github.com/0xsj/fn-go/gateway/internal/cache"
+	"github.com/0xsj/fn-go/gateway/internal/config"
 	"github.com/0xsj/fn-go/gateway/internal/handlers"
+	"github.com/0xsj/fn-go/gateway/internal/idempotency"
 	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/gateway/internal/proxy"
+	"github.com/0xsj/fn-go/gateway/internal/ratelimit"
+	"github.com/0xsj/fn-go/gateway/internal/versioning"
+	"github.com/0xsj/fn-go/gateway/internal/websocket"
+	"github.com/0xsj/fn-go/gateway/pkg/health"
+	"github.com/0xsj/fn-go/pkg/common/db"
+	"github.com/0xsj/fn-go/pkg/common/jwks"
+	"github.com/0xsj/fn-go/pkg/common/lifecycle"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
 	"github.com/0xsj/fn-go/pkg/common/response"
+	"github.com/0xsj/fn-go/pkg/common/storage"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -24,14 +35,23 @@ func main() {
 	logger = logger.WithLayer("api-gateway")
 	logger.Info("Initializing API gateway")
 
+	// Load configuration
+	logger.Info("Loading configuration")
+	cfg, err := config.Load(logger)
+	if err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to load configuration")
+	}
+
+	// Readiness fails as soon as shutdown begins
+	shutdown := lifecycle.NewManager(cfg.Server, logger)
+
 	// Initialize NATS client
 	logger.Info("Connecting to NATS server")
-	config := nats.DefaultConfig()
-	client, err := nats.NewClient(logger, config)
+	natsConfig := nats.DefaultConfig()
+	client, err := nats.NewClient(logger, natsConfig)
 	if err != nil {
 		logger.With("error", err.Error()).Fatal("Failed to connect to NATS")
 	}
-	defer client.Close()
 	logger.Info("Successfully connected to NATS server")
 
 	// Initialize HTTP response handler
@@ -40,14 +60,72 @@ func main() {
 
 	// Create and configure middleware
 	logger.Info("Configuring middleware")
-	rateLimiter := middleware.NewRateLimiter(100, 1*time.Minute, respHandler)
+	redisClient := newRedisClient(cfg, logger)
+	proxies, err := middleware.NewTrustedProxies(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		logger.With("error", err.Error()).Fatal("Invalid trusted proxy configuration")
+	}
+	apiVersions := newVersioning(cfg, respHandler, logger)
+	rateLimiter := newRateLimiter(cfg, redisClient, proxies, respHandler, logger)
+	idempotent := newIdempotency(cfg, redisClient, proxies, respHandler, logger)
+	
+	// Route policies are declared by the handlers below; permissions are cached
+	// per user and flushed when auth-service reports a role permission change
+	policies := middleware.NewPolicySet()
+	permissionCache := middleware.NewPermissionCache(client.Conn(), 5*time.Minute, logger)
+	subscriber := patterns.NewSubscriber(client.Conn(), "api-gateway", logger)
+	if _, err := permissionCache.SubscribeInvalidations(subscriber); err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to subscribe to permission changes")
+	}
+	authorizer := middleware.NewAuthorizer(policies, permissionCache, respHandler, logger)
+	
+	// Access tokens are verified against auth-service's published signing
+	// keys, refetched on rotation announcements and on unknown key IDs
+	verifier := newVerifier(cfg, client.Conn(), subscriber, logger)
+	var tokenVerifier *jwks.Verifier
+	if cfg.JWKS.Enabled {
+		tokenVerifier = verifier
+	}
+
+	// Cacheable reads are declared by the handlers below and evicted when the
+	// owning service publishes a domain event
+	cachePolicies := middleware.NewCachePolicies()
+	responseCache := newResponseCache(cfg, redisClient, cachePolicies, logger)
+	if err := responseCache.SubscribeInvalidations(subscriber, "incident", "location", "entity"); err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to subscribe to cache invalidation events")
+	}
+	
+	// Routes are declared by the handlers below for the admin API, which reports
+	// their error rates and can switch them into maintenance
+	routeTable := middleware.NewRouteTable()
+	routeStats := middleware.NewRouteStats(routeTable, cfg.Admin.StatsWindow)
+	maintenance := middleware.NewMaintenance(respHandler, logger)
+	breakers := newBreakers(cfg, logger)
+	hub := websocket.NewHub(logger)
+	
+	// Mutating requests are audited to monitoring-service, named by the route table
+	auditor := newAuditor(cfg, routeTable, client.Conn(), proxies, respHandler, logger)
 	
 	middlewareChain := middleware.NewChain(
 		middleware.Logger(logger),
+		// Outside Recovery, so panics count as server errors
+		routeStats.Middleware,
 		middleware.Recovery(logger),
 		middleware.CORS([]string{"*"}),
+		// Transcodes JSON responses, so it wraps everything that rewrites them
+		respHandler.FormatMiddleware,
+		breakers.Middleware,
+		apiVersions.Version,
+		// After versioning, so routes match without their version prefix
+		maintenance.Middleware,
+		rateLimiter.ClientLimit,
+		middleware.Authentication(client.Conn(), tokenVerifier, respHandler, logger),
+		middleware.Impersonation(logger),
 		rateLimiter.RateLimit,
-		middleware.Authentication(client.Conn(), respHandler, logger),
+		auditor.Audit,
+		authorizer.Authorize,
+		idempotent.Idempotent,
+		responseCache.Cache,
 	)
 
 	// Create server and handler
@@ -55,15 +133,12 @@ func main() {
 	
 	// Register metrics endpoint
 	mux.Handle("/metrics", promhttp.Handler())
+	routeTable.Add(middleware.Route{Method: http.MethodGet, Pattern: "/metrics"})
 	
-	// Register health endpoint
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		respHandler.Success(w, map[string]any{
-			"status":  "ok",
-			"time":    time.Now().Format(time.RFC3339),
-			"version": "1.0.0",
-		}, "Gateway is healthy")
-	})
+	// Register health endpoints
+	healthHandler := handlers.NewHealthHandler(newHealthChecker(cfg, client.Conn(), redisClient, shutdown, logger), respHandler, logger)
+	healthHandler.RegisterRoutes(mux)
+	healthHandler.RegisterRouteTable(routeTable)
 
 	// Register service handlers
 	logger.Info("Registering service handlers")
@@ -71,25 +146,104 @@ func main() {
 	// User handler
 	userHandler := handlers.NewUserHandler(client.Conn(), respHandler, logger)
 	userHandler.RegisterRoutes(mux)
+	userHandler.RegisterPolicies(policies)
+	userHandler.RegisterRouteTable(routeTable)
 	
 	// Auth handler
-	authHandler := handlers.NewAuthHandler(client.Conn(), respHandler, logger)
+	authHandler := handlers.NewAuthHandler(client.Conn(), respHandler, proxies, logger)
 	authHandler.RegisterRoutes(mux)
-	
-	// Incident handler
-	incidentHandler := handlers.NewIncidentHandler(client.Conn(), respHandler, logger)
+	authHandler.RegisterPolicies(policies)
+	authHandler.RegisterRouteTable(routeTable)
+
+	// Signing keys for verifying access tokens, served from the verifier's cache
+	jwksHandler := handlers.NewJWKSHandler(verifier, cfg.JWKS.RefreshInterval, respHandler, logger)
+	jwksHandler.RegisterRoutes(mux)
+	jwksHandler.RegisterRouteTable(routeTable)
+
+	// OAuth2 authorization server and OpenID Connect provider for partner apps
+	oauthHandler := handlers.NewOAuthHandler(client.Conn(), respHandler, logger)
+	oauthHandler.RegisterRoutes(mux)
+	oauthHandler.RegisterPolicies(policies)
+	oauthHandler.RegisterRouteTable(routeTable)
+
+	// Incident handler; files are streamed to storage rather than over NATS
+	fileStore, err := storage.NewLocalStore(cfg.Upload.StoragePath)
+	if err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to initialize file storage")
+	}
+	incidentHandler := handlers.NewIncidentHandler(client.Conn(), respHandler, logger).WithFiles(fileStore, cfg.Upload)
 	incidentHandler.RegisterRoutes(mux)
+	incidentHandler.RegisterPolicies(policies)
+	incidentHandler.RegisterRouteTable(routeTable)
+	incidentHandler.RegisterCachePolicies(cachePolicies)
+	
+	// Location handler
+	locationHandler := handlers.NewLocationHandler(client.Conn(), respHandler, logger)
+	locationHandler.RegisterRoutes(mux)
+	locationHandler.RegisterPolicies(policies)
+	locationHandler.RegisterRouteTable(routeTable)
+	locationHandler.RegisterCachePolicies(cachePolicies)
+	
+	// Entity handler
+	entityHandler := handlers.NewEntityHandler(client.Conn(), respHandler, logger)
+	entityHandler.RegisterRoutes(mux)
+	entityHandler.RegisterPolicies(policies)
+	entityHandler.RegisterRouteTable(routeTable)
+	entityHandler.RegisterCachePolicies(cachePolicies)
+	
+	// GraphQL handler, authorizing each field against the REST route policies
+	if cfg.GraphQL.Enabled {
+		graphqlHandler, err := handlers.NewGraphQLHandler(client.Conn(), authorizer, cfg.GraphQL, respHandler, logger)
+		if err != nil {
+			logger.With("error", err.Error()).Fatal("Failed to build GraphQL schema")
+		}
+		graphqlHandler.RegisterRoutes(mux)
+		graphqlHandler.RegisterPolicies(policies)
+		graphqlHandler.RegisterRouteTable(routeTable)
+	}
+	
+	// Audit log, recorded by the audit middleware
+	auditHandler := handlers.NewAuditHandler(client.Conn(), respHandler, logger)
+	auditHandler.RegisterRoutes(mux)
+	auditHandler.RegisterPolicies(policies)
+	auditHandler.RegisterRouteTable(routeTable)
+	
+	// Admin API; maintenance changes and connection drops reach every replica
+	if cfg.Admin.Enabled {
+		adminHandler := handlers.NewAdminHandler(routeTable, rateLimiter, hub, routeStats, maintenance, respHandler, logger).
+			WithBreakers(breakers).
+			WithPublisher(patterns.NewPublisher(client.Conn(), "api-gateway", logger))
+		adminHandler.RegisterRoutes(mux)
+		adminHandler.RegisterPolicies(policies)
+		adminHandler.RegisterRouteTable(routeTable)
+		if err := adminHandler.SubscribeControls(subscriber); err != nil {
+			logger.With("error", err.Error()).Fatal("Failed to subscribe to admin controls")
+		}
+	}
 	
 	// Apply middleware to all handlers
 	wrappedHandler := middlewareChain.Then(mux)
-	
+
+	// Batch handler, dispatching each sub-request through the full middleware chain
+	if cfg.Batch.Enabled {
+		batchHandler := handlers.NewBatchHandler(wrappedHandler, cfg.Batch, respHandler, logger)
+		batchHandler.RegisterRoutes(mux)
+		batchHandler.RegisterPolicies(policies)
+		batchHandler.RegisterRouteTable(routeTable)
+	}
+
+	// Requests matching no policy are denied, so every route needs one
+	for _, route := range policies.Uncovered(routeTable.Routes()) {
+		logger.With("method", route.Method).With("pattern", route.Pattern).Fatal("Route has no authorization policy")
+	}
+
 	// Create server
 	server := &http.Server{
-		Addr:         ":8080",
+		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
 		Handler:      wrappedHandler,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  30 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	// Start server in a goroutine
@@ -101,21 +255,158 @@ func main() {
 	}()
 	logger.Info("Server started in background")
 
-	// Wait for termination signal
-	logger.Info("Waiting for termination signal")
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
-	<-signalCh
+	// HTTP requests in flight still need NATS, so the connection is drained
+	// only after the server has stopped
+	shutdown.OnShutdown("http-server", server.Shutdown)
+	shutdown.OnShutdown("websocket-hub", hub.Shutdown)
+	shutdown.OnShutdown("audit", auditor.Close)
+	shutdown.OnShutdown("jwks", verifier.Close)
+	shutdown.OnShutdown("nats", client.Drain)
+	if redisClient != nil {
+		shutdown.OnShutdown("redis", lifecycle.CloseFunc(redisClient.Close))
+	}
 
-	logger.Info("Received termination signal, shutting down")
-	
-	// Create a timeout context for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-	
-	if err := server.Shutdown(ctx); err != nil {
-		logger.With("error", err.Error()).Error("Server shutdown failed")
+	if err := shutdown.Run(); err != nil {
+		logger.With("error", err.Error()).Error("Graceful shutdown incomplete")
+		os.Exit(1)
 	}
-	
 	logger.Info("Server shutdown complete")
-}
\ No newline at end of file
+}
+
+// newRedisClient connects to Redis when the rate limiter, response cache or
+// idempotency store uses it. It returns nil if Redis isn't needed or can't be reached.
+func newRedisClient(cfg *config.Config, logger log.Logger) *db.RedisClient {
+	if cfg.RateLimit.Backend != config.RateLimitBackendRedis &&
+		cfg.Cache.Backend != config.CacheBackendRedis &&
+		cfg.Idempotency.Backend != config.IdempotencyBackendRedis {
+		return nil
+	}
+
+	redisClient, err := db.NewRedisClient(logger, cfg.Redis)
+	if err != nil {
+		logger.With("error", err.Error()).Warn("Redis unavailable, falling back to per-replica memory")
+		return nil
+	}
+	return redisClient
+}
+
+// newVersioning builds the API versioning middleware, applying the configured
+// deprecation and sunset dates to the declared versions
+func newVersioning(cfg *config.Config, respHandler *response.HTTPHandler, logger log.Logger) *middleware.Versioning {
+	versions := versioning.Versions()
+	for _, version := range versions {
+		if date, ok := cfg.Versioning.Deprecations[version.Name]; ok {
+			version.Deprecated = date
+		}
+		if date, ok := cfg.Versioning.Sunsets[version.Name]; ok {
+			version.Sunset = date
+		}
+		if version.DocsURL == "" {
+			version.DocsURL = cfg.Versioning.DocsURL
+		}
+	}
+
+	registry, err := versioning.NewRegistry(cfg.Versioning.DefaultVersion, versions...)
+	if err != nil {
+		logger.With("error", err.Error()).Fatal("Invalid API version configuration")
+	}
+	return middleware.NewVersioning(registry, respHandler, logger)
+}
+
+// newRateLimiter builds the rate limiter, sharing state across replicas through
+// Redis when configured and falling back to per-replica memory otherwise
+func newRateLimiter(cfg *config.Config, redisClient *db.RedisClient, proxies *middleware.TrustedProxies, respHandler *response.HTTPHandler, logger log.Logger) *middleware.RateLimiter {
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Backend == config.RateLimitBackendRedis && redisClient != nil {
+		store = ratelimit.NewFallbackStore(ratelimit.NewRedisStore(redisClient, "ratelimit:"), store, logger)
+	}
+
+	return middleware.NewRateLimiter(store, cfg.RateLimit, proxies, respHandler, logger)
+}
+
+// newBreakers builds the per-service circuit breakers of the NATS proxy, or
+// returns nil when they're disabled
+func newBreakers(cfg *config.Config, logger log.Logger) *proxy.Breakers {
+	if !cfg.Breaker.Enabled {
+		return nil
+	}
+	return proxy.NewBreakers(cfg.Breaker.FailureThreshold, cfg.Breaker.OpenTimeout, logger)
+}
+
+// newVerifier builds the verifier checking access tokens against the
+// published signing keys, refreshed in the background and on rotation
+func newVerifier(cfg *config.Config, conn *nats.Conn, subscriber *patterns.Subscriber, logger log.Logger) *jwks.Verifier {
+	verifier := jwks.NewVerifier(jwks.NATSFetcher(conn, 5*time.Second, logger), cfg.JWKS.MinRefreshInterval, logger)
+	if err := verifier.Start(cfg.JWKS.RefreshInterval); err != nil {
+		logger.With("error", err.Error()).Warn("Failed to fetch signing keys, retrying in the background")
+	}
+	if _, err := verifier.SubscribeRotations(subscriber); err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to subscribe to signing key rotations")
+	}
+	return verifier
+}
+
+// newAuditor builds the audit middleware publishing to monitoring-service, or
+// returns nil when auditing is disabled
+func newAuditor(cfg *config.Config, routes *middleware.RouteTable, conn *nats.Conn, proxies *middleware.TrustedProxies, respHandler *response.HTTPHandler, logger log.Logger) *middleware.Auditor {
+	if !cfg.Audit.Enabled {
+		return nil
+	}
+	return middleware.NewAuditor(routes, patterns.NewPublisher(conn, "api-gateway", logger), proxies, cfg.Audit, respHandler, logger)
+}
+
+// newResponseCache builds the response cache, backed by Redis when configured
+// and by a bounded in-memory LRU otherwise
+func newResponseCache(cfg *config.Config, redisClient *db.RedisClient, policies *middleware.CachePolicies, logger log.Logger) *middleware.ResponseCache {
+	if !cfg.Cache.Enabled {
+		// An empty policy set never matches, so every request passes through
+		return middleware.NewResponseCache(cache.NewLRUStore(1), middleware.NewCachePolicies(), logger)
+	}
+
+	var store cache.Store = cache.NewLRUStore(cfg.Cache.MaxEntries)
+	if cfg.Cache.Backend == config.CacheBackendRedis && redisClient != nil {
+		store = cache.NewRedisStore(redisClient, "cache:")
+	}
+
+	return middleware.NewResponseCache(store, policies, logger)
+}
+
+// newIdempotency builds the idempotency middleware. Retries may reach any
+// replica, so records live in Redis when configured; memory only suits a
+// single replica.
+func newIdempotency(cfg *config.Config, redisClient *db.RedisClient, proxies *middleware.TrustedProxies, respHandler *response.HTTPHandler, logger log.Logger) *middleware.Idempotency {
+	var store idempotency.Store = idempotency.NewMemoryStore()
+	if cfg.Idempotency.Backend == config.IdempotencyBackendRedis && redisClient != nil {
+		store = idempotency.NewRedisStore(redisClient, "idempotency:")
+	}
+
+	return middleware.NewIdempotency(store, cfg.Idempotency, proxies, respHandler, logger)
+}
+
+// newHealthChecker registers the shutdown state and the gateway's dependencies:
+// NATS, the configured services and Redis when it backs the limiter, cache or
+// idempotency store
+func newHealthChecker(cfg *config.Config, conn *nats.Conn, redisClient *db.RedisClient, shutdown *lifecycle.Manager, logger log.Logger) *health.Checker {
+	checker := health.NewChecker(cfg.Service.Version, cfg.Health.Timeout, logger)
+	checker.Register(health.Dependency{Name: "lifecycle", Critical: true, Check: shutdown.ReadyCheck})
+	checker.Register(health.Dependency{Name: "nats", Critical: true, Check: health.NATSCheck(conn)})
+
+	critical := make(map[string]bool, len(cfg.Health.CriticalServices))
+	for _, name := range cfg.Health.CriticalServices {
+		critical[name] = true
+	}
+	for _, name := range cfg.Health.Services {
+		checker.Register(health.Dependency{
+			Name:     name + "-service",
+			Critical: critical[name],
+			Check:    health.ServiceCheck(conn, name, logger),
+		})
+	}
+
+	// Redis failures fall back to per-replica memory, so they only degrade the gateway
+	if redisClient != nil {
+		checker.Register(health.Dependency{Name: "redis", Check: redisClient.Ping})
+	}
+
+	return checker
+}
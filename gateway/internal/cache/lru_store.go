@@ -0,0 +1,123 @@
+// gateway/internal/cache/lru_store.go
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// lruItem is an entry tracked by the LRU list
+type lruItem struct {
+	key   string
+	entry *Entry
+	tags  []string
+}
+
+// LRUStore is an in-memory store bounded by entry count
+type LRUStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+	tags     map[string]map[string]struct{}
+}
+
+// NewLRUStore creates an in-memory store holding at most capacity entries
+func NewLRUStore(capacity int) *LRUStore {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &LRUStore{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		tags:     make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements Store
+func (s *LRUStore) Get(ctx context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	item := elem.Value.(*lruItem)
+	if !item.entry.Fresh(time.Now()) {
+		s.remove(elem)
+		return nil, nil
+	}
+
+	s.order.MoveToFront(elem)
+	return item.entry, nil
+}
+
+// Set implements Store
+func (s *LRUStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.remove(elem)
+	}
+
+	elem := s.order.PushFront(&lruItem{key: key, entry: entry, tags: tags})
+	s.items[key] = elem
+	for _, tag := range tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// InvalidateTags implements Store
+func (s *LRUStore) InvalidateTags(ctx context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			if elem, ok := s.items[key]; ok {
+				s.remove(elem)
+			}
+		}
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+// Len returns the number of cached entries
+func (s *LRUStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// remove drops an element and its tag references; callers hold the lock
+func (s *LRUStore) remove(elem *list.Element) {
+	item := elem.Value.(*lruItem)
+	s.order.Remove(elem)
+	delete(s.items, item.key)
+
+	for _, tag := range item.tags {
+		if keys, ok := s.tags[tag]; ok {
+			delete(keys, item.key)
+			if len(keys) == 0 {
+				delete(s.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// gateway/internal/cache/redis_store.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/db"
+)
+
+// RedisStore shares cached responses between gateway replicas through Redis.
+// Tags are Redis sets holding the keys of the entries they reference.
+type RedisStore struct {
+	client *db.RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a new Redis-backed store
+func NewRedisStore(client *db.RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get implements Store
+func (s *RedisStore) Get(ctx context.Context, key string) (*Entry, error) {
+	raw, err := s.client.Get(ctx, s.entryKey(key))
+	if stderrors.Is(err, db.ErrRedisNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		// A corrupt entry is treated as a miss and overwritten on the next store
+		return nil, nil
+	}
+	if !entry.Fresh(time.Now()) {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Set implements Store
+func (s *RedisStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	entryKey := s.entryKey(key)
+	if err := s.client.Set(ctx, entryKey, string(data), ttl); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		tagKey := s.tagKey(tag)
+		if _, err := s.client.Do(ctx, "SADD", tagKey, entryKey); err != nil {
+			return err
+		}
+		// Keep the tag set around at least as long as the newest member
+		if _, err := s.client.Do(ctx, "PEXPIRE", tagKey, ttl.Milliseconds(), "GT"); err != nil {
+			if _, err := s.client.Do(ctx, "PEXPIRE", tagKey, ttl.Milliseconds()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// InvalidateTags implements Store
+func (s *RedisStore) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tagKey := s.tagKey(tag)
+
+		reply, err := s.client.Do(ctx, "SMEMBERS", tagKey)
+		if err != nil {
+			return err
+		}
+
+		keys := []string{tagKey}
+		if members, ok := reply.([]any); ok {
+			for _, member := range members {
+				if key, ok := member.(string); ok {
+					keys = append(keys, key)
+				}
+			}
+		}
+
+		if err := s.client.Del(ctx, keys...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RedisStore) entryKey(key string) string {
+	return s.prefix + "entry:" + key
+}
+
+func (s *RedisStore) tagKey(tag string) string {
+	return s.prefix + "tag:" + tag
+}
@@ -0,0 +1,41 @@
+// gateway/internal/cache/store.go
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// Entry is a cached HTTP response
+type Entry struct {
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body"`
+	ETag      string      `json:"etag"`
+	StoredAt  time.Time   `json:"stored_at"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// Fresh reports whether the entry is still within its TTL
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// Store persists cached responses and indexes them by tag for invalidation
+type Store interface {
+	// Get returns the entry for key, or nil if it is missing or expired
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Set stores an entry under key for ttl and associates it with tags
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error
+	// InvalidateTags removes every entry associated with any of the tags
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
+// StrongETag computes a strong ETag from the response payload
+func StrongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -1 +1,208 @@
-package config
\ No newline at end of file
+// gateway/internal/config/config.go
+package config
+
+import (
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/ratelimit"
+	"github.com/0xsj/fn-go/pkg/common/config"
+	"github.com/0xsj/fn-go/pkg/common/db"
+)
+
+// Rate limit tiers. Authenticated users, and the API keys and OAuth clients
+// acting for them, are limited by the user's role; service accounts share the
+// service tier.
+const (
+	TierAnonymous  = "anonymous"
+	TierCustomer   = "customer"
+	TierDispatcher = "dispatcher"
+	TierAdmin      = "admin"
+	TierService    = "service"
+	// TierClientIP is a coarse per-IP ceiling applied before authentication
+	TierClientIP = "ip"
+)
+
+// Rate limit backends
+const (
+	RateLimitBackendMemory = "memory"
+	RateLimitBackendRedis  = "redis"
+)
+
+// Response cache backends
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+)
+
+// Idempotency store backends
+const (
+	IdempotencyBackendMemory = "memory"
+	IdempotencyBackendRedis  = "redis"
+)
+
+type Config struct {
+	Service     ServiceConfig
+	Server      config.ServerConfig
+	Redis       db.RedisConfig
+	RateLimit   RateLimitConfig
+	Cache       CacheConfig
+	Health      HealthConfig
+	GraphQL     GraphQLConfig
+	Batch       BatchConfig
+	Idempotency IdempotencyConfig
+	Upload      UploadConfig
+	Versioning  VersioningConfig
+	Breaker     BreakerConfig
+	Admin       AdminConfig
+	Audit       AuditConfig
+	JWKS        JWKSConfig
+}
+
+type ServiceConfig struct {
+	Name    string
+	Version string
+}
+
+// RateLimitConfig configures the gateway rate limiter
+type RateLimitConfig struct {
+	Enabled bool
+	Backend string
+	// TrustedProxies lists the IPs or CIDRs allowed to set X-Forwarded-For
+	TrustedProxies []string
+	// Tiers maps a tier name to its limit
+	Tiers map[string]ratelimit.Limit
+	// Routes are extra per-route limits applied on top of the tier limit
+	Routes []RouteLimit
+	// APIKeys overrides the tier for individual API keys or service accounts
+	APIKeys map[string]ratelimit.Limit
+}
+
+// RouteLimit limits requests to paths starting with Prefix
+type RouteLimit struct {
+	Method string
+	Prefix string
+	Limit  ratelimit.Limit
+}
+
+// CacheConfig configures the gateway response cache
+type CacheConfig struct {
+	Enabled bool
+	Backend string
+	// MaxEntries bounds the in-memory cache
+	MaxEntries int
+}
+
+// HealthConfig configures the dependency checks behind /readyz and /health
+type HealthConfig struct {
+	// Services are checked through their service.<name>.health subject
+	Services []string
+	// CriticalServices make the gateway not ready when unreachable
+	CriticalServices []string
+	Timeout          time.Duration
+}
+
+// GraphQLConfig configures the /graphql endpoint
+type GraphQLConfig struct {
+	Enabled bool
+	// MaxDepth and MaxComplexity reject expensive queries before execution
+	MaxDepth      int
+	MaxComplexity int
+	// ListSize is the assumed length of unbounded lists when scoring complexity
+	ListSize int
+	// BatchWait is how long loaders collect keys before calling the services
+	BatchWait time.Duration
+	Timeout   time.Duration
+}
+
+// BatchConfig configures the /batch endpoint
+type BatchConfig struct {
+	Enabled bool
+	// MaxRequests bounds the sub-requests in one batch
+	MaxRequests int
+	// MaxConcurrency bounds the sub-requests of one batch running at once
+	MaxConcurrency int
+}
+
+// IdempotencyConfig configures Idempotency-Key handling for POST, PUT and PATCH
+type IdempotencyConfig struct {
+	Enabled bool
+	Backend string
+	// TTL is how long a completed response is replayed for its key
+	TTL time.Duration
+	// LockTimeout bounds how long a key stays reserved by a request in flight,
+	// so a replica dying mid-request doesn't block retries forever
+	LockTimeout time.Duration
+	// MaxBodyBytes bounds the request bodies fingerprinted and responses stored
+	MaxBodyBytes int64
+}
+
+// UploadConfig configures file uploads, which are streamed to storage rather
+// than proxied over NATS
+type UploadConfig struct {
+	// StoragePath is the directory files are stored under
+	StoragePath string
+	// MaxFileSize bounds the size of one uploaded file in bytes
+	MaxFileSize int64
+	// AllowedTypes lists the accepted MIME types, detected from the file
+	// contents; entries ending in /* accept a whole family such as image/*
+	AllowedTypes []string
+	// Timeout replaces the server read and write timeouts for transfers
+	Timeout time.Duration
+}
+
+// VersioningConfig configures API versioning
+type VersioningConfig struct {
+	// DefaultVersion serves requests naming no version
+	DefaultVersion string
+	// Deprecations and Sunsets schedule the retirement of versions by name
+	Deprecations map[string]time.Time
+	Sunsets      map[string]time.Time
+	// DocsURL documents migrating off deprecated versions
+	DocsURL string
+}
+
+// BreakerConfig configures the per-service circuit breakers of the NATS proxy
+type BreakerConfig struct {
+	Enabled bool
+	// FailureThreshold is the number of consecutive unanswered requests that
+	// opens a service's breaker
+	FailureThreshold int
+	// OpenTimeout is how long an open breaker fails requests before probing the service
+	OpenTimeout time.Duration
+}
+
+// AdminConfig configures the /admin introspection and control API
+type AdminConfig struct {
+	Enabled bool
+	// StatsWindow is the period route error rates are computed over
+	StatsWindow time.Duration
+}
+
+// AuditConfig configures the audit records emitted for mutating requests
+type AuditConfig struct {
+	Enabled bool
+	// RedactFields are matched against param names, ignoring case, "_" and
+	// "-", so "taxid" redacts tax_id and TaxID alike; a name containing any
+	// entry has its value redacted
+	RedactFields []string
+	// MaxBodyBytes bounds the request bodies read into a record's params
+	MaxBodyBytes int64
+	// BufferSize bounds the records waiting to be published
+	BufferSize int
+	// EnqueueTimeout is how long a request waits for room in a full buffer.
+	// Impersonated requests are refused if none frees up; other records are
+	// dropped and logged.
+	EnqueueTimeout time.Duration
+}
+
+// JWKSConfig configures local verification of access tokens against the
+// auth service's published signing keys
+type JWKSConfig struct {
+	// Enabled verifies tokens in the gateway instead of asking the auth
+	// service; a deactivated user keeps access until their token expires
+	Enabled bool
+	// RefreshInterval is how often the key set is refetched
+	RefreshInterval time.Duration
+	// MinRefreshInterval limits refetches triggered by unknown key IDs
+	MinRefreshInterval time.Duration
+}
@@ -1 +1,269 @@
-package config
\ No newline at end of file
+// gateway/internal/config/loader.go
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/ratelimit"
+	"github.com/0xsj/fn-go/pkg/common/config"
+	"github.com/0xsj/fn-go/pkg/common/db"
+	"github.com/0xsj/fn-go/pkg/common/log"
+)
+
+// Default tier limits, written as rate/period/burst
+var defaultTiers = map[string]string{
+	TierAnonymous:  "30/1m/10",
+	TierCustomer:   "60/1m/20",
+	TierDispatcher: "300/1m/60",
+	TierAdmin:      "600/1m/100",
+	TierService:    "1200/1m/200",
+	TierClientIP:   "1200/1m/300",
+}
+
+// Default per-route limits protecting credential endpoints
+const defaultRouteLimits = "POST /auth/login=10/1m/5;" +
+	"POST /auth/register=5/1m/3;" +
+	"POST /auth/forgot-password=5/15m/2;" +
+	"POST /auth/reset-password=5/15m/2;" +
+	"POST /auth/unlock=5/15m/2;" +
+	"POST /auth/magic-link=5/15m/2;" +
+	"POST /auth/magic-link/verify=10/1m/5;" +
+	"POST /auth/mfa/verify=10/1m/5;" +
+	"POST /auth/mfa/setup=5/1m/3;" +
+	"POST /oauth/token=30/1m/10;" +
+	"POST /oauth/introspect=60/1m/20"
+
+// Services checked by the health endpoints. The gateway can't authenticate
+// or serve incidents without the critical ones.
+var (
+	defaultHealthServices   = []string{"auth", "user", "incident", "location", "entity", "notification", "chat", "monitoring"}
+	defaultCriticalServices = []string{"auth", "user", "incident"}
+)
+
+// File types accepted for upload by default: photos, videos and documents
+// such as floor plans
+var defaultUploadTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "video/mp4", "application/pdf"}
+
+// Param names whose values are redacted from audit records by default:
+// credentials, tax identifiers and phone numbers
+var defaultRedactFields = []string{"password", "secret", "token", "apikey", "authorization", "taxid", "phone", "mobile", "recoverycode"}
+
+// Load loads the gateway configuration from GATEWAY_* environment variables
+func Load(logger log.Logger) (*Config, error) {
+	provider := config.NewEnvProvider("GATEWAY")
+
+	if err := provider.Validate(); err != nil {
+		logger.With("error", err.Error()).
+			With("missing_vars", provider.MissingVars()).
+			Warn("Some environment variables are missing, using defaults")
+	}
+
+	cfg := &Config{
+		Service: ServiceConfig{
+			Name:    provider.GetDefault("NAME", "api-gateway"),
+			Version: provider.GetDefault("VERSION", "1.0.0"),
+		},
+		Server: config.ServerConfig{
+			Port:            provider.GetIntDefault("PORT", 8080),
+			Host:            provider.GetDefault("HOST", ""),
+			ReadTimeout:     provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:    provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:     provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+			ShutdownTimeout: provider.GetDurationDefault("SHUTDOWN_TIMEOUT", 30*time.Second),
+			ShutdownDelay:   provider.GetDurationDefault("SHUTDOWN_DELAY", 5*time.Second),
+		},
+		Redis: db.RedisConfig{
+			Host:     provider.GetDefault("REDIS_HOST", "localhost"),
+			Port:     provider.GetIntDefault("REDIS_PORT", 6379),
+			Password: provider.GetDefault("REDIS_PASSWORD", ""),
+			DB:       provider.GetIntDefault("REDIS_DB", 0),
+			PoolSize: provider.GetIntDefault("REDIS_POOL_SIZE", 10),
+			Timeout:  provider.GetDurationDefault("REDIS_TIMEOUT", 2*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:        provider.GetBoolDefault("RATE_LIMIT_ENABLED", true),
+			Backend:        provider.GetDefault("RATE_LIMIT_BACKEND", RateLimitBackendRedis),
+			TrustedProxies: provider.GetSlice("TRUSTED_PROXIES", ","),
+			Tiers:          make(map[string]ratelimit.Limit),
+			APIKeys:        make(map[string]ratelimit.Limit),
+		},
+		Cache: CacheConfig{
+			Enabled:    provider.GetBoolDefault("CACHE_ENABLED", true),
+			Backend:    provider.GetDefault("CACHE_BACKEND", CacheBackendMemory),
+			MaxEntries: provider.GetIntDefault("CACHE_MAX_ENTRIES", 10000),
+		},
+		Health: HealthConfig{
+			Services:         provider.GetSlice("HEALTH_SERVICES", ","),
+			CriticalServices: provider.GetSlice("HEALTH_CRITICAL_SERVICES", ","),
+			Timeout:          provider.GetDurationDefault("HEALTH_TIMEOUT", 2*time.Second),
+		},
+		GraphQL: GraphQLConfig{
+			Enabled:       provider.GetBoolDefault("GRAPHQL_ENABLED", true),
+			MaxDepth:      provider.GetIntDefault("GRAPHQL_MAX_DEPTH", 8),
+			MaxComplexity: provider.GetIntDefault("GRAPHQL_MAX_COMPLEXITY", 500),
+			ListSize:      provider.GetIntDefault("GRAPHQL_LIST_SIZE", 20),
+			BatchWait:     provider.GetDurationDefault("GRAPHQL_BATCH_WAIT", 2*time.Millisecond),
+			Timeout:       provider.GetDurationDefault("GRAPHQL_TIMEOUT", 10*time.Second),
+		},
+		Batch: BatchConfig{
+			Enabled:        provider.GetBoolDefault("BATCH_ENABLED", true),
+			MaxRequests:    provider.GetIntDefault("BATCH_MAX_REQUESTS", 20),
+			MaxConcurrency: provider.GetIntDefault("BATCH_MAX_CONCURRENCY", 6),
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled:      provider.GetBoolDefault("IDEMPOTENCY_ENABLED", true),
+			Backend:      provider.GetDefault("IDEMPOTENCY_BACKEND", IdempotencyBackendRedis),
+			TTL:          provider.GetDurationDefault("IDEMPOTENCY_TTL", 24*time.Hour),
+			LockTimeout:  provider.GetDurationDefault("IDEMPOTENCY_LOCK_TIMEOUT", time.Minute),
+			MaxBodyBytes: int64(provider.GetIntDefault("IDEMPOTENCY_MAX_BODY_BYTES", 1<<20)),
+		},
+		Upload: UploadConfig{
+			StoragePath:  provider.GetDefault("UPLOAD_STORAGE_PATH", "/tmp/attachments"),
+			MaxFileSize:  int64(provider.GetIntDefault("UPLOAD_MAX_FILE_SIZE", 100<<20)),
+			AllowedTypes: provider.GetSlice("UPLOAD_ALLOWED_TYPES", ","),
+			Timeout:      provider.GetDurationDefault("UPLOAD_TIMEOUT", 10*time.Minute),
+		},
+		Versioning: VersioningConfig{
+			DefaultVersion: provider.GetDefault("API_DEFAULT_VERSION", "v1"),
+			DocsURL:        provider.GetDefault("API_DEPRECATION_DOCS_URL", ""),
+		},
+		Breaker: BreakerConfig{
+			Enabled:          provider.GetBoolDefault("BREAKER_ENABLED", true),
+			FailureThreshold: provider.GetIntDefault("BREAKER_FAILURE_THRESHOLD", 5),
+			OpenTimeout:      provider.GetDurationDefault("BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		},
+		Admin: AdminConfig{
+			Enabled:     provider.GetBoolDefault("ADMIN_ENABLED", true),
+			StatsWindow: provider.GetDurationDefault("ADMIN_STATS_WINDOW", 5*time.Minute),
+		},
+		Audit: AuditConfig{
+			Enabled:        provider.GetBoolDefault("AUDIT_ENABLED", true),
+			RedactFields:   provider.GetSlice("AUDIT_REDACT_FIELDS", ","),
+			MaxBodyBytes:   int64(provider.GetIntDefault("AUDIT_MAX_BODY_BYTES", 64<<10)),
+			BufferSize:     provider.GetIntDefault("AUDIT_BUFFER_SIZE", 1024),
+			EnqueueTimeout: provider.GetDurationDefault("AUDIT_ENQUEUE_TIMEOUT", 100*time.Millisecond),
+		},
+		JWKS: JWKSConfig{
+			Enabled:            provider.GetBoolDefault("JWKS_ENABLED", true),
+			RefreshInterval:    provider.GetDurationDefault("JWKS_REFRESH_INTERVAL", 5*time.Minute),
+			MinRefreshInterval: provider.GetDurationDefault("JWKS_MIN_REFRESH_INTERVAL", 10*time.Second),
+		},
+	}
+
+	if len(cfg.Upload.AllowedTypes) == 0 {
+		cfg.Upload.AllowedTypes = defaultUploadTypes
+	}
+	if len(cfg.Audit.RedactFields) == 0 {
+		cfg.Audit.RedactFields = defaultRedactFields
+	}
+	if len(cfg.Health.Services) == 0 {
+		cfg.Health.Services = defaultHealthServices
+	}
+	if len(cfg.Health.CriticalServices) == 0 {
+		cfg.Health.CriticalServices = defaultCriticalServices
+	}
+
+	for tier, def := range defaultTiers {
+		key := "RATE_LIMIT_TIER_" + strings.ToUpper(tier)
+		limit, err := ratelimit.ParseLimit(provider.GetDefault(key, def))
+		if err != nil {
+			return nil, fmt.Errorf("GATEWAY_%s: %w", key, err)
+		}
+		cfg.RateLimit.Tiers[tier] = limit
+	}
+
+	routes, err := parseLimitList(provider.GetDefault("RATE_LIMIT_ROUTES", defaultRouteLimits))
+	if err != nil {
+		return nil, fmt.Errorf("GATEWAY_RATE_LIMIT_ROUTES: %w", err)
+	}
+	for target, limit := range routes {
+		method, prefix, ok := strings.Cut(target, " ")
+		if !ok {
+			method, prefix = "", target
+		}
+		cfg.RateLimit.Routes = append(cfg.RateLimit.Routes, RouteLimit{
+			Method: strings.ToUpper(method),
+			Prefix: prefix,
+			Limit:  limit,
+		})
+	}
+
+	// Most specific prefix first, so the first matching route wins
+	sort.Slice(cfg.RateLimit.Routes, func(i, j int) bool {
+		return len(cfg.RateLimit.Routes[i].Prefix) > len(cfg.RateLimit.Routes[j].Prefix)
+	})
+
+	apiKeys, err := parseLimitList(provider.Get("RATE_LIMIT_API_KEYS"))
+	if err != nil {
+		return nil, fmt.Errorf("GATEWAY_RATE_LIMIT_API_KEYS: %w", err)
+	}
+	cfg.RateLimit.APIKeys = apiKeys
+
+	if cfg.Versioning.Deprecations, err = parseDateList(provider.Get("API_DEPRECATIONS")); err != nil {
+		return nil, fmt.Errorf("GATEWAY_API_DEPRECATIONS: %w", err)
+	}
+	if cfg.Versioning.Sunsets, err = parseDateList(provider.Get("API_SUNSETS")); err != nil {
+		return nil, fmt.Errorf("GATEWAY_API_SUNSETS: %w", err)
+	}
+
+	logger.With("rate_limit_backend", cfg.RateLimit.Backend).
+		With("route_limits", len(cfg.RateLimit.Routes)).
+		With("api_key_limits", len(cfg.RateLimit.APIKeys)).
+		With("cache_backend", cfg.Cache.Backend).
+		With("idempotency_backend", cfg.Idempotency.Backend).
+		Debug("Gateway configuration loaded")
+
+	return cfg, nil
+}
+
+// parseLimitList parses "target=limit;target=limit" into a map
+func parseLimitList(s string) (map[string]ratelimit.Limit, error) {
+	limits := make(map[string]ratelimit.Limit)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		target, raw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q, expected target=limit", entry)
+		}
+
+		limit, err := ratelimit.ParseLimit(raw)
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.TrimSpace(target)] = limit
+	}
+	return limits, nil
+}
+
+// parseDateList parses "name=2006-01-02;name=2006-01-02T15:04:05Z" into a map
+func parseDateList(s string) (map[string]time.Time, error) {
+	dates := make(map[string]time.Time)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, raw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q, expected name=date", entry)
+		}
+
+		raw = strings.TrimSpace(raw)
+		date, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if date, err = time.Parse(time.DateOnly, raw); err != nil {
+				return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", raw)
+			}
+		}
+		dates[strings.TrimSpace(name)] = date
+	}
+	return dates, nil
+}
@@ -0,0 +1,338 @@
+// gateway/internal/handlers/admin_handler.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/gateway/internal/proxy"
+	"github.com/0xsj/fn-go/gateway/internal/websocket"
+	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// defaultMaintenanceMessage is returned for routes put into maintenance without a message
+const defaultMaintenanceMessage = "This endpoint is temporarily down for maintenance"
+
+// maintenanceChange is broadcast to every replica when a route enters or leaves maintenance
+type maintenanceChange struct {
+	Enabled bool                        `json:"enabled"`
+	Route   middleware.MaintenanceRoute `json:"route"`
+}
+
+// AdminHandler serves the /admin API for operators: live introspection of the
+// gateway and controls over rate limits, connections and maintenance mode.
+// Listings describe the replica answering the request, named by instance.
+// Maintenance changes and connection drops are broadcast over NATS, so they
+// apply on every replica.
+type AdminHandler struct {
+	routes      *middleware.RouteTable
+	rateLimiter *middleware.RateLimiter
+	hub         *websocket.Hub
+	stats       *middleware.RouteStats
+	maintenance *middleware.Maintenance
+	breakers    *proxy.Breakers
+	publisher   *patterns.Publisher
+	instance    string
+	resp        *response.HTTPHandler
+	logger      log.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(routes *middleware.RouteTable, rateLimiter *middleware.RateLimiter, hub *websocket.Hub,
+	stats *middleware.RouteStats, maintenance *middleware.Maintenance, respHandler *response.HTTPHandler, logger log.Logger) *AdminHandler {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+
+	return &AdminHandler{
+		routes:      routes,
+		rateLimiter: rateLimiter,
+		hub:         hub,
+		stats:       stats,
+		maintenance: maintenance,
+		instance:    instance,
+		resp:        respHandler,
+		logger:      logger.WithLayer("admin-handler"),
+	}
+}
+
+// WithBreakers reports the circuit breakers of the NATS proxy
+func (h *AdminHandler) WithBreakers(breakers *proxy.Breakers) *AdminHandler {
+	h.breakers = breakers
+	return h
+}
+
+// WithPublisher broadcasts controls to the other replicas
+func (h *AdminHandler) WithPublisher(publisher *patterns.Publisher) *AdminHandler {
+	h.publisher = publisher
+	return h
+}
+
+// RegisterRoutes registers the admin routes
+func (h *AdminHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/routes", h.handleRoutes)
+	mux.HandleFunc("/admin/ratelimits", h.handleRateLimits)
+	mux.HandleFunc("/admin/ratelimits/", h.handleRateLimit)
+	mux.HandleFunc("/admin/connections", h.handleConnections)
+	mux.HandleFunc("/admin/connections/", h.handleConnection)
+	mux.HandleFunc("/admin/breakers", h.handleBreakers)
+	mux.HandleFunc("/admin/stats", h.handleStats)
+	mux.HandleFunc("/admin/maintenance", h.handleMaintenance)
+}
+
+// RegisterPolicies restricts the admin API to holders of the system admin permission
+func (h *AdminHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	policies.Add(
+		middleware.RoutePolicy{Pattern: "/admin/*", Resource: "system", Action: "admin"},
+	)
+}
+
+// RegisterRouteTable declares the admin routes, which the gateway serves itself
+func (h *AdminHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Pattern: "/admin/*"},
+	)
+}
+
+// SubscribeControls applies the maintenance changes and connection drops
+// broadcast by the admin API of any replica, this one included
+func (h *AdminHandler) SubscribeControls(subscriber *patterns.Subscriber) error {
+	_, err := subscriber.Subscribe(nats.SubjectGatewayMaintenance, func(ctx context.Context, msg *patterns.MessageEnvelope) error {
+		var change maintenanceChange
+		if err := msg.Unmarshal(&change); err != nil {
+			return err
+		}
+		if change.Enabled {
+			h.maintenance.Set(change.Route)
+		} else {
+			h.maintenance.Clear(change.Route.Method, change.Route.Pattern)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = subscriber.Subscribe(nats.SubjectGatewayConnectionDrop, func(ctx context.Context, msg *patterns.MessageEnvelope) error {
+		var payload struct {
+			ID string `json:"id"`
+		}
+		if err := msg.Unmarshal(&payload); err != nil {
+			return err
+		}
+		h.hub.Drop(payload.ID)
+		return nil
+	})
+	return err
+}
+
+// handleRoutes handles GET /admin/routes
+func (h *AdminHandler) handleRoutes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.respondMethodNotAllowed(w)
+		return
+	}
+	h.resp.Success(w, map[string]any{
+		"instance": h.instance,
+		"routes":   h.routes.Routes(),
+	}, "")
+}
+
+// handleRateLimits handles GET /admin/ratelimits
+func (h *AdminHandler) handleRateLimits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.respondMethodNotAllowed(w)
+		return
+	}
+	h.resp.Success(w, map[string]any{
+		"instance": h.instance,
+		"keys":     h.rateLimiter.Keys(),
+	}, "")
+}
+
+// handleRateLimit handles DELETE /admin/ratelimits/{identity}, resetting every
+// limit of a client such as user:<id> or ip:<address>
+func (h *AdminHandler) handleRateLimit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.respondMethodNotAllowed(w)
+		return
+	}
+
+	identity := strings.TrimPrefix(r.URL.Path, "/admin/ratelimits/")
+	if _, _, ok := strings.Cut(identity, ":"); !ok || strings.Contains(identity, "/") {
+		h.resp.HandleError(w, errors.NewBadRequestError("Identity must be of the form type:id, e.g. user:<id> or ip:<address>", nil))
+		return
+	}
+
+	keys, err := h.rateLimiter.ResetClient(r.Context(), identity)
+	if err != nil {
+		h.logger.With("identity", identity).With("error", err.Error()).Error("Failed to reset rate limits")
+		h.resp.HandleError(w, errors.NewInternalError("Failed to reset rate limits", err))
+		return
+	}
+	h.resp.Success(w, map[string]any{"identity": identity, "reset": keys}, "Rate limits reset")
+}
+
+// handleConnections handles GET /admin/connections
+func (h *AdminHandler) handleConnections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.respondMethodNotAllowed(w)
+		return
+	}
+	h.resp.Success(w, map[string]any{
+		"instance":    h.instance,
+		"connections": h.hub.Connections(),
+	}, "")
+}
+
+// handleConnection handles DELETE /admin/connections/{id}. A connection held by
+// another replica is dropped there once the broadcast arrives.
+func (h *AdminHandler) handleConnection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.respondMethodNotAllowed(w)
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/connections/"), "/")
+	if id == "" {
+		http.Redirect(w, r, "/admin/connections", http.StatusFound)
+		return
+	}
+
+	if h.hub.Drop(id) {
+		h.resp.NoContent(w)
+		return
+	}
+	if h.publisher == nil {
+		h.resp.HandleError(w, errors.NewNotFoundError("Connection not found", nil))
+		return
+	}
+	if err := h.publisher.Publish(r.Context(), nats.SubjectGatewayConnectionDrop, map[string]string{"id": id}); err != nil {
+		h.resp.HandleError(w, errors.NewInternalError("Failed to forward the drop to other replicas", err))
+		return
+	}
+	h.resp.Accepted(w, map[string]string{"id": id}, "Connection is not on this replica, drop forwarded to the others")
+}
+
+// handleBreakers handles GET /admin/breakers
+func (h *AdminHandler) handleBreakers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.respondMethodNotAllowed(w)
+		return
+	}
+
+	breakers := []proxy.BreakerStatus{}
+	if h.breakers != nil {
+		breakers = h.breakers.Status()
+	}
+	h.resp.Success(w, map[string]any{
+		"instance": h.instance,
+		"enabled":  h.breakers != nil,
+		"breakers": breakers,
+	}, "")
+}
+
+// handleStats handles GET /admin/stats
+func (h *AdminHandler) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.respondMethodNotAllowed(w)
+		return
+	}
+	h.resp.Success(w, map[string]any{
+		"instance": h.instance,
+		"window":   h.stats.Window().String(),
+		"routes":   h.stats.Stats(),
+	}, "")
+}
+
+// handleMaintenance handles /admin/maintenance: GET lists the routes in
+// maintenance, PUT switches a route in and DELETE takes it out again
+func (h *AdminHandler) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.resp.Success(w, map[string]any{
+			"instance": h.instance,
+			"routes":   h.maintenance.Routes(),
+		}, "")
+	case http.MethodPut:
+		h.handleSetMaintenance(w, r)
+	case http.MethodDelete:
+		h.handleClearMaintenance(w, r)
+	default:
+		h.respondMethodNotAllowed(w)
+	}
+}
+
+// handleSetMaintenance handles PUT /admin/maintenance
+func (h *AdminHandler) handleSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var route middleware.MaintenanceRoute
+	if err := json.NewDecoder(r.Body).Decode(&route); err != nil {
+		h.resp.HandleError(w, errors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+	if !strings.HasPrefix(route.Pattern, "/") {
+		h.resp.HandleError(w, errors.NewBadRequestError("Pattern must be a route pattern such as /incidents/*", nil))
+		return
+	}
+	if route.RetryAfter < 0 {
+		h.resp.HandleError(w, errors.NewBadRequestError("Retry after must not be negative", nil))
+		return
+	}
+	if route.Message == "" {
+		route.Message = defaultMaintenanceMessage
+	}
+	route.Method = strings.ToUpper(route.Method)
+	route.Since = time.Now().UTC()
+
+	h.maintenance.Set(route)
+	h.broadcastMaintenance(r.Context(), maintenanceChange{Enabled: true, Route: route})
+	h.resp.Success(w, route, "Route switched into maintenance")
+}
+
+// handleClearMaintenance handles DELETE /admin/maintenance?pattern=...&method=...
+func (h *AdminHandler) handleClearMaintenance(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	route := middleware.MaintenanceRoute{Method: query.Get("method"), Pattern: query.Get("pattern")}
+	if route.Pattern == "" {
+		h.resp.HandleError(w, errors.NewBadRequestError("Missing pattern query parameter", nil))
+		return
+	}
+
+	if !h.maintenance.Clear(route.Method, route.Pattern) {
+		h.resp.HandleError(w, errors.NewNotFoundError("Route is not in maintenance", nil))
+		return
+	}
+	h.broadcastMaintenance(r.Context(), maintenanceChange{Route: route})
+	h.resp.NoContent(w)
+}
+
+// broadcastMaintenance sends a maintenance change to the other replicas. It
+// has already been applied here, so failing to send only leaves the others behind.
+func (h *AdminHandler) broadcastMaintenance(ctx context.Context, change maintenanceChange) {
+	if h.publisher == nil {
+		return
+	}
+	if err := h.publisher.Publish(ctx, nats.SubjectGatewayMaintenance, change); err != nil {
+		h.logger.With("pattern", change.Route.Pattern).
+			With("error", err.Error()).
+			Error("Failed to broadcast maintenance change")
+	}
+}
+
+// respondMethodNotAllowed sends a method not allowed response
+func (h *AdminHandler) respondMethodNotAllowed(w http.ResponseWriter) {
+	h.resp.Error(w, response.ErrorResponse{
+		Code:    "METHOD_NOT_ALLOWED",
+		Message: "Method not allowed",
+	})
+}
@@ -0,0 +1,53 @@
+// gateway/internal/handlers/audit_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// AuditHandler serves the audit log stored by monitoring-service
+type AuditHandler struct {
+	*BaseHandler
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(conn *nats.Conn, respHandler *response.HTTPHandler, logger log.Logger) *AuditHandler {
+	return &AuditHandler{
+		BaseHandler: NewBaseHandler(conn, respHandler, logger.WithLayer("audit-handler"), "audit"),
+	}
+}
+
+// RegisterRoutes registers the audit routes
+func (h *AuditHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/audit", h.handleAudit)
+}
+
+// RegisterPolicies restricts the audit log to holders of the system admin permission
+func (h *AuditHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	policies.Add(
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/audit", Resource: "system", Action: "admin"},
+	)
+}
+
+// RegisterRouteTable declares the audit routes and the subjects serving them
+func (h *AuditHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodGet, Pattern: "/audit", Subject: nats.SubjectAuditList},
+	)
+}
+
+// handleAudit handles GET /audit, filtered by user_id, actor_id, method,
+// route, status, correlation_id and a from/to time range in RFC 3339
+func (h *AuditHandler) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	h.logger.Info("Handling list audit records request")
+	h.HandleRequest(w, r, nats.SubjectAuditList)
+}
@@ -2,8 +2,12 @@
 package handlers
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/response"
@@ -12,24 +16,106 @@ import (
 // AuthHandler handles authentication-related requests
 type AuthHandler struct {
 	*BaseHandler
+	proxies *middleware.TrustedProxies
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(conn *nats.Conn, respHandler *response.HTTPHandler, logger log.Logger) *AuthHandler {
+// NewAuthHandler creates a new auth handler. Logins are forwarded with the
+// client IP resolved through proxies, which auth-service throttles them by.
+func NewAuthHandler(conn *nats.Conn, respHandler *response.HTTPHandler, proxies *middleware.TrustedProxies, logger log.Logger) *AuthHandler {
 	return &AuthHandler{
 		BaseHandler: NewBaseHandler(conn, respHandler, logger.WithLayer("auth-handler"), "auth"),
+		proxies:     proxies,
 	}
 }
 
-// RegisterRoutes registers the auth routes
+// RegisterRoutes registers the auth routes. Routes whose responses may carry
+// tokens, API keys, TOTP secrets or recovery codes are marked no-store.
 func (h *AuthHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/auth/login", h.handleLogin)
-	mux.HandleFunc("/auth/register", h.handleRegister)
-	mux.HandleFunc("/auth/refresh", h.handleRefresh)
+	mux.HandleFunc("/auth/login", noStore(h.handleLogin))
+	mux.HandleFunc("/auth/register", noStore(h.handleRegister))
+	mux.HandleFunc("/auth/refresh", noStore(h.handleRefresh))
 	mux.HandleFunc("/auth/logout", h.handleLogout)
 	mux.HandleFunc("/auth/verify-email", h.handleVerifyEmail)
 	mux.HandleFunc("/auth/forgot-password", h.handleForgotPassword)
 	mux.HandleFunc("/auth/reset-password", h.handleResetPassword)
+	mux.HandleFunc("/auth/unlock", h.handleUnlock)
+	mux.HandleFunc("/auth/magic-link", h.handleMagicLink)
+	mux.HandleFunc("/auth/magic-link/verify", noStore(h.handleMagicLinkVerify))
+	mux.HandleFunc("/auth/api-keys", noStore(h.handleAPIKeys))
+	mux.HandleFunc("/auth/api-keys/", noStore(h.handleAPIKey))
+	mux.HandleFunc("/auth/mfa", h.handleMFA)
+	mux.HandleFunc("/auth/mfa/", noStore(h.handleMFAAction))
+	mux.HandleFunc("/auth/keys", h.handleSigningKeys)
+	mux.HandleFunc("/auth/keys/rotate", h.handleRotateSigningKey)
+	mux.HandleFunc("/auth/roles", h.handleRoles)
+	mux.HandleFunc("/auth/roles/", h.handleRoleParent)
+	mux.HandleFunc("/auth/users/", noStore(h.handleUserAccess))
+	mux.HandleFunc("/auth/policy/explain", h.handleExplainPolicy)
+}
+
+// RegisterPolicies declares the access rules for the auth routes. Credentials
+// can only be managed from a user session, never with another API key, and
+// never by an administrator impersonating the user.
+func (h *AuthHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	credentials := []middleware.Condition{middleware.UsersOnly(), middleware.NotImpersonating()}
+
+	policies.Add(
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/auth/logout", Conditions: []middleware.Condition{middleware.UsersOnly()}},
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/auth/api-keys", Conditions: credentials},
+		middleware.RoutePolicy{Pattern: "/auth/api-keys", Conditions: []middleware.Condition{middleware.UsersOnly()}},
+		middleware.RoutePolicy{Pattern: "/auth/api-keys/*", Conditions: credentials},
+		middleware.RoutePolicy{Method: http.MethodDelete, Pattern: "/auth/mfa", Conditions: credentials},
+		middleware.RoutePolicy{Pattern: "/auth/mfa", Conditions: []middleware.Condition{middleware.UsersOnly()}},
+		middleware.RoutePolicy{Pattern: "/auth/mfa/enroll", Conditions: credentials},
+		middleware.RoutePolicy{Pattern: "/auth/mfa/confirm", Conditions: credentials},
+		middleware.RoutePolicy{Pattern: "/auth/mfa/recovery-codes", Conditions: credentials},
+		middleware.RoutePolicy{Pattern: "/auth/mfa/users/*", Resource: "system", Action: "admin"},
+		middleware.RoutePolicy{Pattern: "/auth/keys", Resource: "system", Action: "admin"},
+		middleware.RoutePolicy{Pattern: "/auth/keys/rotate", Resource: "system", Action: "admin"},
+		middleware.RoutePolicy{Pattern: "/auth/roles", Resource: "system", Action: "admin"},
+		middleware.RoutePolicy{Pattern: "/auth/roles/*", Resource: "system", Action: "admin"},
+		middleware.RoutePolicy{Pattern: "/auth/users/*", Resource: "system", Action: "admin"},
+		middleware.RoutePolicy{Pattern: "/auth/policy/explain", Resource: "system", Action: "admin"},
+	)
+}
+
+// RegisterRouteTable declares the auth routes and the subjects serving them
+func (h *AuthHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/login", Subject: "auth.login"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/register", Subject: "auth.register"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/refresh", Subject: "auth.refresh"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/logout", Subject: "auth.logout"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/auth/verify-email", Subject: "auth.verify-email"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/forgot-password", Subject: "auth.forgot-password"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/reset-password", Subject: "auth.reset-password"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/unlock", Subject: "auth.unlock"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/magic-link", Subject: "auth.magic-link.request"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/magic-link/verify", Subject: "auth.magic-link.exchange"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/auth/api-keys", Subject: "auth.apikeys.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/api-keys", Subject: "auth.apikeys.create"},
+		middleware.Route{Method: http.MethodDelete, Pattern: "/auth/api-keys/{id}", Subject: "auth.apikeys.revoke"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/api-keys/{id}/rotate", Subject: "auth.apikeys.rotate"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/mfa/verify", Subject: "auth.mfa.verify"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/mfa/setup", Subject: "auth.mfa.enroll"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/auth/mfa", Subject: "auth.mfa.status"},
+		middleware.Route{Method: http.MethodDelete, Pattern: "/auth/mfa", Subject: "auth.mfa.disable"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/mfa/enroll", Subject: "auth.mfa.enroll"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/mfa/confirm", Subject: "auth.mfa.confirm"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/mfa/recovery-codes", Subject: "auth.mfa.recovery-codes"},
+		middleware.Route{Method: http.MethodDelete, Pattern: "/auth/mfa/users/{id}", Subject: "auth.mfa.reset"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/auth/keys", Subject: "auth.keys.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/keys/rotate", Subject: "auth.keys.rotate"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/auth/roles", Subject: "auth.roles.list"},
+		middleware.Route{Method: http.MethodPut, Pattern: "/auth/roles/{id}/parent", Subject: "auth.roles.parent"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/auth/users/{id}/roles", Subject: "auth.users.roles.get"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/users/{id}/roles", Subject: "auth.users.roles.assign"},
+		middleware.Route{Method: http.MethodDelete, Pattern: "/auth/users/{id}/roles/{role}", Subject: "auth.users.roles.revoke"},
+		middleware.Route{Method: http.MethodPut, Pattern: "/auth/users/{id}/attributes", Subject: "auth.users.attributes.set"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/users/{id}/unlock", Subject: "auth.users.unlock"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/users/{id}/impersonate", Subject: "auth.users.impersonate"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/auth/policy/explain", Subject: "auth.policy.evaluate"},
+	)
 }
 
 // handleLogin handles POST /auth/login
@@ -40,7 +126,23 @@ func (h *AuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	h.logger.Info("Handling login request")
-	h.HandleRequest(w, r, "auth.login")
+	h.proxy.ProxyRequest(w, r, "auth.login", h.withClient)
+}
+
+// withClient forwards a JSON body with the client's IP and user agent. The
+// IP is resolved here, overriding any in the body, so a client can't dodge
+// per-IP login throttling by claiming another address.
+func (h *AuthHandler) withClient(r *http.Request) (any, error) {
+	var req map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		req = map[string]any{}
+	}
+	req["ipAddress"] = h.proxies.ClientIP(r)
+	req["userAgent"] = r.UserAgent()
+	return req, nil
 }
 
 // handleRegister handles POST /auth/register
@@ -65,15 +167,21 @@ func (h *AuthHandler) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	h.HandleRequest(w, r, "auth.refresh")
 }
 
-// handleLogout handles POST /auth/logout
+// handleLogout handles POST /auth/logout. The user logged out is always the
+// caller, so one user can't end another's sessions.
 func (h *AuthHandler) handleLogout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		h.RespondWithMethodNotAllowed(w)
 		return
 	}
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
 	
-	h.logger.Info("Handling logout request")
-	h.HandleRequest(w, r, "auth.logout")
+	h.logger.With("user_id", principal.UserID()).Info("Handling logout request")
+	h.proxy.ProxyRequest(w, r, "auth.logout", userBody(principal, nil))
 }
 
 // handleVerifyEmail handles GET /auth/verify-email
@@ -118,4 +226,401 @@ func (h *AuthHandler) handleResetPassword(w http.ResponseWriter, r *http.Request
 	
 	h.logger.Info("Handling password reset request")
 	h.HandleRequest(w, r, "auth.reset-password")
-}
\ No newline at end of file
+}
+
+// handleUnlock handles POST /auth/unlock, redeeming the token emailed to a
+// user whose account was locked out
+func (h *AuthHandler) handleUnlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	h.logger.Info("Handling unlock request")
+	h.HandleRequest(w, r, "auth.unlock")
+}
+
+// handleMagicLink handles POST /auth/magic-link, emailing a passwordless
+// login link
+func (h *AuthHandler) handleMagicLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	h.logger.Info("Handling magic link request")
+	h.proxy.ProxyRequest(w, r, "auth.magic-link.request", h.withClient)
+}
+
+// handleMagicLinkVerify handles POST /auth/magic-link/verify, logging in with
+// the token from a login link
+func (h *AuthHandler) handleMagicLinkVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	h.logger.Info("Handling magic link login request")
+	h.proxy.ProxyRequest(w, r, "auth.magic-link.exchange", h.withClient)
+}
+
+// handleAPIKeys handles requests to /auth/api-keys
+func (h *AuthHandler) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+	
+	switch r.Method {
+	case http.MethodGet:
+		h.logger.With("owner_id", principal.UserID()).Info("Handling list API keys request")
+		h.proxy.ProxyRequest(w, r, "auth.apikeys.list", func(r *http.Request) (any, error) {
+			return map[string]string{"ownerId": principal.UserID()}, nil
+		})
+	case http.MethodPost:
+		h.logger.With("owner_id", principal.UserID()).Info("Handling create API key request")
+		h.proxy.ProxyRequest(w, r, "auth.apikeys.create", ownedBody(principal, nil))
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
+
+// handleAPIKey handles requests to /auth/api-keys/{id} and /auth/api-keys/{id}/rotate
+func (h *AuthHandler) handleAPIKey(w http.ResponseWriter, r *http.Request) {
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+	
+	id, action, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/api-keys/"), "/"), "/")
+	if id == "" {
+		http.Redirect(w, r, "/auth/api-keys", http.StatusFound)
+		return
+	}
+	logger := h.logger.With("key_id", id).With("owner_id", principal.UserID())
+	
+	switch {
+	case action == "" && r.Method == http.MethodDelete:
+		logger.Info("Handling revoke API key request")
+		h.proxy.ProxyRequest(w, r, "auth.apikeys.revoke", func(r *http.Request) (any, error) {
+			return map[string]string{"id": id, "ownerId": principal.UserID()}, nil
+		})
+	case action == "rotate" && r.Method == http.MethodPost:
+		logger.Info("Handling rotate API key request")
+		h.proxy.ProxyRequest(w, r, "auth.apikeys.rotate", ownedBody(principal, map[string]any{"id": id}))
+	case action == "" || action == "rotate":
+		h.RespondWithMethodNotAllowed(w)
+	default:
+		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
+	}
+}
+
+// handleMFA handles requests to /auth/mfa
+func (h *AuthHandler) handleMFA(w http.ResponseWriter, r *http.Request) {
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+	logger := h.logger.With("user_id", principal.UserID())
+	
+	switch r.Method {
+	case http.MethodGet:
+		logger.Info("Handling MFA status request")
+		h.proxy.ProxyRequest(w, r, "auth.mfa.status", func(r *http.Request) (any, error) {
+			return map[string]string{"userId": principal.UserID()}, nil
+		})
+	case http.MethodDelete:
+		logger.Info("Handling disable MFA request")
+		h.proxy.ProxyRequest(w, r, "auth.mfa.disable", userBody(principal, nil))
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
+
+// handleMFAAction handles the second login step, enrollment and recovery
+// codes under /auth/mfa/, and the administrator reset at /auth/mfa/users/{id}
+func (h *AuthHandler) handleMFAAction(w http.ResponseWriter, r *http.Request) {
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/mfa/"), "/")
+	
+	// Logins awaiting a second factor have no session yet; the MFA token
+	// from the first step identifies them
+	switch action {
+	case "verify", "setup":
+		if r.Method != http.MethodPost {
+			h.RespondWithMethodNotAllowed(w)
+			return
+		}
+		if action == "verify" {
+			h.logger.Info("Handling MFA verification request")
+			h.proxy.ProxyRequest(w, r, "auth.mfa.verify", h.withClient)
+			return
+		}
+		h.logger.Info("Handling MFA setup request")
+		h.proxy.ProxyRequest(w, r, "auth.mfa.enroll", func(r *http.Request) (any, error) {
+			var req struct {
+				MFAToken string `json:"mfaToken"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return map[string]string{"mfaToken": req.MFAToken}, nil
+		})
+		return
+	}
+	
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+	logger := h.logger.With("user_id", principal.UserID())
+	
+	if id, ok := strings.CutPrefix(action, "users/"); ok && id != "" && !strings.Contains(id, "/") {
+		if r.Method != http.MethodDelete {
+			h.RespondWithMethodNotAllowed(w)
+			return
+		}
+		logger.With("target_user_id", id).Info("Handling reset MFA request")
+		h.proxy.ProxyRequest(w, r, "auth.mfa.reset", func(r *http.Request) (any, error) {
+			return map[string]string{"userId": id}, nil
+		})
+		return
+	}
+	
+	var subject string
+	switch action {
+	case "enroll":
+		subject = "auth.mfa.enroll"
+	case "confirm":
+		subject = "auth.mfa.confirm"
+	case "recovery-codes":
+		subject = "auth.mfa.recovery-codes"
+	default:
+		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	logger.With("subject", subject).Info("Handling MFA request")
+	h.proxy.ProxyRequest(w, r, subject, userBody(principal, nil))
+}
+
+// noStore marks a handler's responses as carrying credentials, which clients,
+// proxies and the idempotency store must not keep
+func noStore(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		next(w, r)
+	}
+}
+
+// ownedBody decodes an optional JSON body, merges in the given fields and sets
+// the owner to the caller, so users can only manage their own keys
+func ownedBody(principal *middleware.Principal, fields map[string]any) func(r *http.Request) (any, error) {
+	return callerBody(principal, "ownerId", fields)
+}
+
+// userBody is like ownedBody for requests naming the caller as the user they
+// act on, so users can only manage their own second factor
+func userBody(principal *middleware.Principal, fields map[string]any) func(r *http.Request) (any, error) {
+	return callerBody(principal, "userId", fields)
+}
+
+// callerBody decodes an optional JSON body, merges in the given fields and
+// sets key to the calling user
+func callerBody(principal *middleware.Principal, key string, fields map[string]any) func(r *http.Request) (any, error) {
+	return func(r *http.Request) (any, error) {
+		data := map[string]any{}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &data); err != nil {
+				return nil, err
+			}
+		}
+		for k, v := range fields {
+			data[k] = v
+		}
+		data[key] = principal.UserID()
+		return data, nil
+	}
+}
+
+// handleSigningKeys handles GET /auth/keys, listing the token signing keys
+func (h *AuthHandler) handleSigningKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	h.logger.Info("Handling list signing keys request")
+	h.HandleRequest(w, r, "auth.keys.list")
+}
+
+// handleRotateSigningKey handles POST /auth/keys/rotate, replacing the token
+// signing key ahead of schedule
+func (h *AuthHandler) handleRotateSigningKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	h.logger.Info("Handling rotate signing key request")
+	h.proxy.ProxyRequest(w, r, "auth.keys.rotate", func(r *http.Request) (any, error) {
+		return map[string]any{}, nil
+	})
+}
+
+// handleRoles handles GET /auth/roles, listing the roles and what each inherits
+func (h *AuthHandler) handleRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	h.logger.Info("Handling list roles request")
+	h.HandleRequest(w, r, "auth.roles.list")
+}
+
+// handleRoleParent handles PUT /auth/roles/{id}/parent, changing the role a
+// role inherits from
+func (h *AuthHandler) handleRoleParent(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/roles/"), "/"), "/")
+	if id == "" || action != "parent" {
+		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPut {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	h.logger.With("role_id", id).Info("Handling set role parent request")
+	h.proxy.ProxyRequest(w, r, "auth.roles.parent", func(r *http.Request) (any, error) {
+		var req struct {
+			ParentID string `json:"parentId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+		return map[string]string{"roleId": id, "parentId": req.ParentID}, nil
+	})
+}
+
+// handleUserAccess handles the roles and attributes of a user under
+// /auth/users/{id}/roles and /auth/users/{id}/attributes, lifting a login
+// lockout at /auth/users/{id}/unlock, and impersonating the user at
+// /auth/users/{id}/impersonate
+func (h *AuthHandler) handleUserAccess(w http.ResponseWriter, r *http.Request) {
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+	
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/users/"), "/"), "/")
+	if len(parts) < 2 || parts[0] == "" {
+		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
+		return
+	}
+	id := parts[0]
+	logger := h.logger.With("target_user_id", id).With("user_id", principal.UserID())
+	
+	switch {
+	case len(parts) == 2 && parts[1] == "roles" && r.Method == http.MethodGet:
+		logger.Info("Handling get user roles request")
+		h.proxy.ProxyRequest(w, r, "auth.users.roles.get", func(r *http.Request) (any, error) {
+			return map[string]string{"userId": id}, nil
+		})
+	case len(parts) == 2 && parts[1] == "roles" && r.Method == http.MethodPost:
+		logger.Info("Handling assign user role request")
+		h.proxy.ProxyRequest(w, r, "auth.users.roles.assign", func(r *http.Request) (any, error) {
+			var req struct {
+				Role string `json:"role"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return map[string]string{"userId": id, "role": req.Role, "assignedBy": principal.UserID()}, nil
+		})
+	case len(parts) == 3 && parts[1] == "roles" && r.Method == http.MethodDelete:
+		logger.With("role", parts[2]).Info("Handling revoke user role request")
+		h.proxy.ProxyRequest(w, r, "auth.users.roles.revoke", func(r *http.Request) (any, error) {
+			return map[string]string{"userId": id, "role": parts[2]}, nil
+		})
+	case len(parts) == 2 && parts[1] == "attributes" && r.Method == http.MethodPut:
+		logger.Info("Handling set user attributes request")
+		h.proxy.ProxyRequest(w, r, "auth.users.attributes.set", func(r *http.Request) (any, error) {
+			var req struct {
+				Attributes map[string]string `json:"attributes"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return map[string]any{"userId": id, "attributes": req.Attributes}, nil
+		})
+	case len(parts) == 2 && parts[1] == "unlock" && r.Method == http.MethodPost:
+		logger.Info("Handling unlock account request")
+		h.proxy.ProxyRequest(w, r, "auth.users.unlock", func(r *http.Request) (any, error) {
+			return map[string]string{"userId": id, "unlockedBy": principal.UserID()}, nil
+		})
+	case len(parts) == 2 && parts[1] == "impersonate" && r.Method == http.MethodPost:
+		logger.Info("Handling impersonation request")
+		h.proxy.ProxyRequest(w, r, "auth.users.impersonate", func(r *http.Request) (any, error) {
+			req, err := h.withClient(r)
+			if err != nil {
+				return nil, err
+			}
+			body := req.(map[string]any)
+			body["userId"] = id
+			body["actorId"] = principal.UserID()
+			return body, nil
+		})
+	case len(parts) == 2 && (parts[1] == "roles" || parts[1] == "attributes" || parts[1] == "unlock" || parts[1] == "impersonate"),
+		len(parts) == 3 && parts[1] == "roles":
+		h.RespondWithMethodNotAllowed(w)
+	default:
+		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
+	}
+}
+
+// handleExplainPolicy handles POST /auth/policy/explain, showing why a user
+// would be granted or denied an action on a resource with given attributes.
+// Without a userId the caller's own access is explained.
+func (h *AuthHandler) handleExplainPolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+	
+	h.logger.With("user_id", principal.UserID()).Info("Handling explain policy request")
+	h.proxy.ProxyRequest(w, r, "auth.policy.evaluate", func(r *http.Request) (any, error) {
+		var req struct {
+			UserID     string         `json:"userId"`
+			Resource   string         `json:"resource"`
+			Action     string         `json:"action"`
+			Attributes map[string]any `json:"attributes,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+		if req.UserID == "" {
+			req.UserID = principal.UserID()
+		}
+		return req, nil
+	})
+}
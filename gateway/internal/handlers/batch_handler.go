@@ -0,0 +1,564 @@
+// gateway/internal/handlers/batch_handler.go
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/0xsj/fn-go/gateway/internal/config"
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/gateway/internal/versioning"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+const (
+	// batchMaxBodyBytes bounds the size of a batch request body
+	batchMaxBodyBytes = 1 << 20
+	// ErrCodeFailedDependency is returned for sub-requests whose dependencies failed
+	ErrCodeFailedDependency = "FAILED_DEPENDENCY"
+)
+
+// batchReference matches {{id.body.path.to.value}} and {{id.status}}
+var batchReference = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)((?:\.[A-Za-z0-9_-]+)*)\s*\}\}`)
+
+// batchForwardedHeaders are copied from the batch request to every sub-request,
+// so each one authenticates and is rate limited as the caller. Accept is not
+// copied: sub-responses are embedded in the batch response as JSON.
+var batchForwardedHeaders = []string{
+	"Authorization",
+	middleware.APIKeyHeader,
+	middleware.APIVersionHeader,
+	"Accept-Language",
+	"User-Agent",
+	"X-Forwarded-For",
+}
+
+// batchReservedHeaders can't be set on a sub-request. They identify the caller
+// and the client address, which every sub-request takes from the batch request.
+var batchReservedHeaders = map[string]bool{
+	"Authorization":         true,
+	middleware.APIKeyHeader: true,
+	"X-Forwarded-For":       true,
+	"X-Forwarded-Host":      true,
+	"X-Forwarded-Proto":     true,
+	"X-Real-Ip":             true,
+	"Forwarded":             true,
+}
+
+// batchContextKey marks the context of a sub-request, so a sub-request that
+// reaches the batch endpoint by any path is refused
+type batchContextKey struct{}
+
+// BatchRequest is one sub-request of a batch. Path, header values and body may
+// reference earlier responses with {{id.body.field}} or {{id.status}}, which
+// makes the sub-request wait for them.
+type BatchRequest struct {
+	ID        string            `json:"id,omitempty"`
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      json.RawMessage   `json:"body,omitempty"`
+	DependsOn []string          `json:"dependsOn,omitempty"`
+}
+
+// BatchResponse is the outcome of one sub-request
+type BatchResponse struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchHandler runs several requests in one round trip. Sub-requests are
+// dispatched through the gateway's full middleware chain, so each one is
+// authenticated, authorized and rate limited on its own.
+type BatchHandler struct {
+	handler http.Handler
+	cfg     config.BatchConfig
+	resp    *response.HTTPHandler
+	logger  log.Logger
+}
+
+// NewBatchHandler creates a new batch handler dispatching to handler, which
+// should be the gateway's root handler including its middleware
+func NewBatchHandler(handler http.Handler, cfg config.BatchConfig, respHandler *response.HTTPHandler, logger log.Logger) *BatchHandler {
+	return &BatchHandler{
+		handler: handler,
+		cfg:     cfg,
+		resp:    respHandler,
+		logger:  logger.WithLayer("batch-handler"),
+	}
+}
+
+// RegisterRoutes registers the batch route
+func (h *BatchHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/batch", h.handleBatch)
+}
+
+// RegisterPolicies admits any authenticated caller to the batch route; each
+// sub-request is authorized against its own route's policy
+func (h *BatchHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	policies.Add(
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/batch", AnyScope: true},
+	)
+}
+
+// RegisterRouteTable declares the batch route; its sub-requests are counted
+// against their own routes
+func (h *BatchHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodPost, Pattern: "/batch"},
+	)
+}
+
+// handleBatch handles POST /batch
+func (h *BatchHandler) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.resp.Error(w, response.ErrorResponse{Code: "METHOD_NOT_ALLOWED", Message: "Method not allowed"})
+		return
+	}
+	if r.Context().Value(batchContextKey{}) != nil {
+		h.badRequest(w, "Invalid batch", "batches cannot be nested")
+		return
+	}
+
+	var requests []BatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, batchMaxBodyBytes)).Decode(&requests); err != nil {
+		h.badRequest(w, "Request body must be a JSON array of sub-requests", err.Error())
+		return
+	}
+
+	deps, err := h.plan(requests)
+	if err != nil {
+		h.badRequest(w, "Invalid batch", err.Error())
+		return
+	}
+
+	logger := h.logger.With("requests", len(requests))
+	logger.Info("Executing batch")
+
+	responses := h.execute(r, requests, deps)
+
+	failed := 0
+	for _, resp := range responses {
+		if resp.Status >= http.StatusBadRequest {
+			failed++
+		}
+	}
+	logger.With("failed", failed).Debug("Batch completed")
+
+	h.resp.JSON(w, http.StatusOK, responses)
+}
+
+func (h *BatchHandler) badRequest(w http.ResponseWriter, message, details string) {
+	h.resp.Error(w, response.ErrorResponse{Code: "BAD_REQUEST", Message: message, Details: details})
+}
+
+// plan validates the sub-requests, assigns missing IDs and returns the
+// dependencies of each one by index
+func (h *BatchHandler) plan(requests []BatchRequest) ([][]int, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("batch contains no requests")
+	}
+	if len(requests) > h.cfg.MaxRequests {
+		return nil, fmt.Errorf("batch contains %d requests, the maximum is %d", len(requests), h.cfg.MaxRequests)
+	}
+
+	index := make(map[string]int, len(requests))
+	for i := range requests {
+		req := &requests[i]
+		if req.ID == "" {
+			req.ID = strconv.Itoa(i)
+		}
+		if _, exists := index[req.ID]; exists {
+			return nil, fmt.Errorf("duplicate request id %q", req.ID)
+		}
+		index[req.ID] = i
+
+		req.Method = strings.ToUpper(req.Method)
+		if req.Method == "" {
+			req.Method = http.MethodGet
+		}
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return nil, fmt.Errorf("request %q: unsupported method %s", req.ID, req.Method)
+		}
+
+		if !strings.HasPrefix(req.Path, "/") {
+			return nil, fmt.Errorf("request %q: path must start with /", req.ID)
+		}
+		if u, err := url.Parse(req.Path); err == nil && isBatchPath(u.Path) {
+			return nil, fmt.Errorf("request %q: batches cannot be nested", req.ID)
+		}
+		for name := range req.Headers {
+			if batchReservedHeaders[http.CanonicalHeaderKey(name)] {
+				return nil, fmt.Errorf("request %q: header %s is taken from the batch request", req.ID, name)
+			}
+		}
+	}
+
+	deps := make([][]int, len(requests))
+	for i, req := range requests {
+		names := append([]string{}, req.DependsOn...)
+		for _, text := range append([]string{req.Path, string(req.Body)}, headerValues(req.Headers)...) {
+			for _, match := range batchReference.FindAllStringSubmatch(text, -1) {
+				names = append(names, match[1])
+			}
+		}
+
+		seen := make(map[int]bool)
+		for _, name := range names {
+			j, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("request %q depends on unknown request %q", req.ID, name)
+			}
+			if j == i {
+				return nil, fmt.Errorf("request %q depends on itself", req.ID)
+			}
+			if !seen[j] {
+				seen[j] = true
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	if cycle := findCycle(deps); cycle >= 0 {
+		return nil, fmt.Errorf("request %q is part of a dependency cycle", requests[cycle].ID)
+	}
+	return deps, nil
+}
+
+// findCycle returns the index of a request on a dependency cycle, or -1
+func findCycle(deps [][]int) int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(deps))
+
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		state[i] = visiting
+		for _, j := range deps[i] {
+			if state[j] == visiting || (state[j] == unvisited && visit(j)) {
+				return true
+			}
+		}
+		state[i] = visited
+		return false
+	}
+
+	for i := range deps {
+		if state[i] == unvisited && visit(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// batchResult is a completed sub-request, kept for later references
+type batchResult struct {
+	done     chan struct{}
+	response BatchResponse
+	body     any
+}
+
+// execute runs the sub-requests concurrently, each starting once its
+// dependencies have completed
+func (h *BatchHandler) execute(r *http.Request, requests []BatchRequest, deps [][]int) []BatchResponse {
+	results := make([]*batchResult, len(requests))
+	for i := range results {
+		results[i] = &batchResult{done: make(chan struct{})}
+	}
+
+	concurrency := h.cfg.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(results[i].done)
+
+			req := requests[i]
+			resolved := make(map[string]*batchResult, len(deps[i]))
+			for _, j := range deps[i] {
+				<-results[j].done
+				dep := results[j]
+				if dep.response.Status >= http.StatusBadRequest {
+					results[i].response = failedDependency(req.ID, fmt.Sprintf("Dependency %q failed with status %d", dep.response.ID, dep.response.Status))
+					return
+				}
+				resolved[dep.response.ID] = dep
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			h.run(r, req, resolved, results[i])
+		}(i)
+	}
+	wg.Wait()
+
+	responses := make([]BatchResponse, len(results))
+	for i, result := range results {
+		responses[i] = result.response
+	}
+	return responses
+}
+
+// run dispatches one sub-request through the gateway
+func (h *BatchHandler) run(parent *http.Request, req BatchRequest, deps map[string]*batchResult, result *batchResult) {
+	path, err := substitute(req.Path, deps, url.PathEscape)
+	if err != nil {
+		result.response = failedDependency(req.ID, err.Error())
+		return
+	}
+
+	var body []byte
+	if len(req.Body) > 0 {
+		if body, err = substituteJSON(req.Body, deps); err != nil {
+			result.response = failedDependency(req.ID, err.Error())
+			return
+		}
+	}
+
+	ctx := context.WithValue(parent.Context(), batchContextKey{}, req.ID)
+	sub, err := http.NewRequestWithContext(ctx, req.Method, path, bytes.NewReader(body))
+	if err != nil {
+		result.response = batchError(req.ID, http.StatusBadRequest, "BAD_REQUEST", "Invalid request: "+err.Error())
+		return
+	}
+	// Checked on the decoded path, as it's routed: escapes and referenced
+	// values can both spell out the batch endpoint
+	if isBatchPath(sub.URL.Path) {
+		result.response = batchError(req.ID, http.StatusBadRequest, "BAD_REQUEST", "Batches cannot be nested")
+		return
+	}
+	sub.RemoteAddr = parent.RemoteAddr
+	sub.Host = parent.Host
+	for _, name := range batchForwardedHeaders {
+		if value := parent.Header.Get(name); value != "" {
+			sub.Header.Set(name, value)
+		}
+	}
+	if len(body) > 0 {
+		sub.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range req.Headers {
+		if value, err = substitute(value, deps, nil); err != nil {
+			result.response = failedDependency(req.ID, err.Error())
+			return
+		}
+		sub.Header.Set(name, value)
+	}
+
+	rec := newBatchRecorder()
+	h.handler.ServeHTTP(rec, sub)
+
+	result.response = BatchResponse{
+		ID:      req.ID,
+		Status:  rec.status,
+		Headers: make(map[string]string, len(rec.header)),
+	}
+	for name := range rec.header {
+		result.response.Headers[name] = rec.header.Get(name)
+	}
+
+	raw := rec.body.Bytes()
+	if len(raw) == 0 {
+		return
+	}
+	if json.Valid(raw) {
+		result.response.Body = json.RawMessage(raw)
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		decoder.Decode(&result.body)
+	} else {
+		result.response.Body, _ = json.Marshal(string(raw))
+	}
+}
+
+// substitute replaces references in a string, escaping each value
+func substitute(text string, deps map[string]*batchResult, escape func(string) string) (string, error) {
+	var resolveErr error
+	replaced := batchReference.ReplaceAllStringFunc(text, func(match string) string {
+		value, err := resolveReference(match, deps)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		s := fmt.Sprint(value)
+		if escape != nil {
+			s = escape(s)
+		}
+		return s
+	})
+	return replaced, resolveErr
+}
+
+// substituteJSON replaces references in a JSON body. A string that is only a
+// reference takes the referenced value with its type, e.g. a number or object.
+func substituteJSON(raw json.RawMessage, deps map[string]*batchResult) ([]byte, error) {
+	if !batchReference.Match(raw) {
+		return raw, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var body any
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+
+	var walk func(v any) (any, error)
+	walk = func(v any) (any, error) {
+		switch v := v.(type) {
+		case string:
+			if loc := batchReference.FindStringIndex(v); loc != nil && loc[0] == 0 && loc[1] == len(v) {
+				return resolveReference(v, deps)
+			}
+			return substitute(v, deps, nil)
+		case map[string]any:
+			for key, item := range v {
+				replaced, err := walk(item)
+				if err != nil {
+					return nil, err
+				}
+				v[key] = replaced
+			}
+		case []any:
+			for i, item := range v {
+				replaced, err := walk(item)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = replaced
+			}
+		}
+		return v, nil
+	}
+
+	body, err := walk(body)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(body)
+}
+
+// resolveReference evaluates {{id.status}} or {{id.body.path}} against a completed dependency
+func resolveReference(ref string, deps map[string]*batchResult) (any, error) {
+	match := batchReference.FindStringSubmatch(ref)
+	dep, ok := deps[match[1]]
+	if !ok {
+		return nil, fmt.Errorf("reference %s names a request that hasn't completed", ref)
+	}
+
+	segments := strings.Split(strings.TrimPrefix(match[2], "."), ".")
+	switch segments[0] {
+	case "status":
+		if len(segments) == 1 {
+			return dep.response.Status, nil
+		}
+	case "body":
+		value := dep.body
+		for _, segment := range segments[1:] {
+			switch v := value.(type) {
+			case map[string]any:
+				value, ok = v[segment]
+			case []any:
+				n, err := strconv.Atoi(segment)
+				ok = err == nil && n >= 0 && n < len(v)
+				if ok {
+					value = v[n]
+				}
+			default:
+				ok = false
+			}
+			if !ok {
+				return nil, fmt.Errorf("reference %s not found in the response of %q", ref, match[1])
+			}
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("reference %s must use .status or .body", ref)
+}
+
+func failedDependency(id, message string) BatchResponse {
+	return batchError(id, http.StatusFailedDependency, ErrCodeFailedDependency, message)
+}
+
+func batchError(id string, status int, code, message string) BatchResponse {
+	body, _ := json.Marshal(response.ErrorResponse{Code: code, Message: message})
+	return BatchResponse{
+		ID:      id,
+		Status:  status,
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    body,
+	}
+}
+
+// isBatchPath reports whether a decoded URL path routes to the batch
+// endpoint, with or without a version prefix
+func isBatchPath(urlPath string) bool {
+	cleaned := path.Clean("/" + urlPath)
+	if segment, rest, ok := strings.Cut(strings.TrimPrefix(cleaned, "/"), "/"); ok && versioning.IsVersionName(segment) {
+		cleaned = "/" + rest
+	}
+	return cleaned == "/batch" || strings.HasPrefix(cleaned, "/batch/")
+}
+
+func headerValues(headers map[string]string) []string {
+	values := make([]string, 0, len(headers))
+	for _, value := range headers {
+		values = append(values, value)
+	}
+	return values
+}
+
+// batchRecorder captures a sub-request's response
+type batchRecorder struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func newBatchRecorder() *batchRecorder {
+	return &batchRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+// Header implements http.ResponseWriter
+func (rec *batchRecorder) Header() http.Header {
+	return rec.header
+}
+
+// Write implements http.ResponseWriter
+func (rec *batchRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.body.Write(b)
+}
+
+// WriteHeader implements http.ResponseWriter
+func (rec *batchRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.wroteHeader = true
+	rec.status = status
+}
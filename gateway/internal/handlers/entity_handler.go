@@ -1 +1,94 @@
-package handlers
\ No newline at end of file
+// gateway/internal/handlers/entity_handler.go
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// EntityHandler handles entity-related requests
+type EntityHandler struct {
+	*BaseHandler
+}
+
+// NewEntityHandler creates a new entity handler
+func NewEntityHandler(conn *nats.Conn, respHandler *response.HTTPHandler, logger log.Logger) *EntityHandler {
+	return &EntityHandler{
+		BaseHandler: NewBaseHandler(conn, respHandler, logger.WithLayer("entity-handler"), "entities"),
+	}
+}
+
+// RegisterRoutes registers the entity routes
+func (h *EntityHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/entities", h.handleEntities)
+	mux.HandleFunc("/entities/", h.handleEntity)
+}
+
+// RegisterPolicies declares the permissions required by the entity routes
+func (h *EntityHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	policies.Add(
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/entities", Resource: "entity", Action: "read"},
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/entities", Resource: "entity", Action: "create"},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/entities/{id}", Resource: "entity", Action: "read"},
+	)
+}
+
+// RegisterRouteTable declares the entity routes and the subjects serving them
+func (h *EntityHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodGet, Pattern: "/entities", Subject: "entity.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/entities", Subject: "entity.create"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/entities/{id}", Subject: "entity.get"},
+	)
+}
+
+// RegisterCachePolicies declares the cacheable entity reads, shared by all callers
+func (h *EntityHandler) RegisterCachePolicies(policies *middleware.CachePolicies) {
+	policies.Add(
+		middleware.CachePolicy{Pattern: "/entities", TTL: time.Minute, Shared: true,
+			Tags: []string{"entity", "entity:list"}},
+		middleware.CachePolicy{Pattern: "/entities/{id}", TTL: 5 * time.Minute, Shared: true,
+			Tags: []string{"entity", "entity:{id}"}},
+	)
+	policies.AddInvalidations(
+		middleware.CacheInvalidation{Method: http.MethodPost, Pattern: "/entities", Tags: []string{"entity:list"}},
+	)
+}
+
+// handleEntities handles requests to /entities
+func (h *EntityHandler) handleEntities(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.logger.Info("Handling list entities request")
+		h.HandleRequest(w, r, "entity.list")
+	case http.MethodPost:
+		h.logger.Info("Handling create entity request")
+		h.HandleRequest(w, r, "entity.create")
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
+
+// handleEntity handles requests to /entities/{id}
+func (h *EntityHandler) handleEntity(w http.ResponseWriter, r *http.Request) {
+	id := h.ExtractIDFromPath(r)
+	if id == "" {
+		http.Redirect(w, r, "/entities", http.StatusFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.logger.With("entity_id", id).Info("Handling get entity request")
+		h.proxy.ProxyRequest(w, r, "entity.get", func(r *http.Request) (any, error) {
+			return map[string]string{"id": id}, nil
+		})
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
@@ -0,0 +1,323 @@
+// gateway/internal/handlers/graphql_handler.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/config"
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/gateway/pkg/graphql"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+const (
+	// graphqlMaxBodyBytes bounds the size of a GraphQL request body
+	graphqlMaxBodyBytes = 1 << 20
+	// graphqlMaxConcurrentFetches bounds the NATS requests one batch runs at once
+	graphqlMaxConcurrentFetches = 8
+	// graphqlFetchTimeout matches the timeout of proxied REST requests
+	graphqlFetchTimeout = 5 * time.Second
+)
+
+// GraphQLHandler serves a read-only GraphQL API over the incident, location,
+// entity and user services. Every field is authorized against the policy of
+// the equivalent REST route, so both APIs expose exactly the same data.
+type GraphQLHandler struct {
+	*BaseHandler
+	authorizer *middleware.Authorizer
+	schema     *graphql.Schema
+	executor   *graphql.Executor
+	cfg        config.GraphQLConfig
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(conn *nats.Conn, authorizer *middleware.Authorizer, cfg config.GraphQLConfig, respHandler *response.HTTPHandler, logger log.Logger) (*GraphQLHandler, error) {
+	h := &GraphQLHandler{
+		BaseHandler: NewBaseHandler(conn, respHandler, logger.WithLayer("graphql-handler"), "graphql"),
+		authorizer:  authorizer,
+		cfg:         cfg,
+	}
+
+	schema, err := h.buildGraphQLSchema()
+	if err != nil {
+		return nil, err
+	}
+	h.schema = schema
+	h.executor = graphql.NewExecutor(schema, graphql.Limits{
+		MaxDepth:      cfg.MaxDepth,
+		MaxComplexity: cfg.MaxComplexity,
+		ListSize:      cfg.ListSize,
+	})
+	return h, nil
+}
+
+// RegisterRoutes registers the GraphQL routes
+func (h *GraphQLHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/graphql", h.handleGraphQL)
+	mux.HandleFunc("/graphql/schema", h.handleSchema)
+}
+
+// RegisterPolicies admits any authenticated caller to the GraphQL routes;
+// each field is authorized against the policy of the REST route it reads
+func (h *GraphQLHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	policies.Add(
+		middleware.RoutePolicy{Pattern: "/graphql", AnyScope: true},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/graphql/schema", AnyScope: true},
+	)
+}
+
+// RegisterRouteTable declares the GraphQL routes. Queries fan out to the list
+// and get subjects of every resource.
+func (h *GraphQLHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Pattern: "/graphql"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/graphql/schema"},
+	)
+}
+
+// handleGraphQL handles GET and POST /graphql
+func (h *GraphQLHandler) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				h.writeGraphQLError(w, "Variables must be a JSON object")
+				return
+			}
+		}
+	case http.MethodPost:
+		body := http.MaxBytesReader(w, r.Body, graphqlMaxBodyBytes)
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			h.writeGraphQLError(w, "Request body must be a JSON object with a query")
+			return
+		}
+	default:
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		h.writeGraphQLError(w, "Must provide a query")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, graphqlRequestKey{}, h.newGraphQLRequest(r))
+
+	logger := h.logger.With("operation", req.OperationName)
+	logger.Debug("Executing GraphQL query")
+
+	result := h.executor.Execute(ctx, req)
+
+	status := http.StatusOK
+	if result.Data == nil {
+		// The request was rejected before execution
+		status = http.StatusBadRequest
+		logger.With("errors", len(result.Errors)).Info("GraphQL request rejected")
+	} else if len(result.Errors) > 0 {
+		logger.With("errors", len(result.Errors)).Debug("GraphQL query completed with field errors")
+	}
+	h.resp.JSON(w, status, result)
+}
+
+// handleSchema handles GET /graphql/schema, returning the schema as SDL
+func (h *GraphQLHandler) handleSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	h.resp.Stream(w, []byte(h.schema.SDL()), "text/plain; charset=utf-8")
+}
+
+func (h *GraphQLHandler) writeGraphQLError(w http.ResponseWriter, message string) {
+	h.resp.JSON(w, http.StatusBadRequest, &graphql.Response{
+		Errors: []*graphql.Error{graphql.NewError(message, "BAD_REQUEST")},
+	})
+}
+
+// graphqlRequestKey carries the per-request state through resolver contexts
+type graphqlRequestKey struct{}
+
+// graphqlRequest is the state shared by the resolvers of one request: the
+// caller's HTTP request, its loaders and the authorization decisions taken
+type graphqlRequest struct {
+	request *http.Request
+	loaders map[string]*graphql.Loader
+
+	mu     sync.Mutex
+	checks map[string]graphqlCheck
+}
+
+type graphqlCheck struct {
+	request *http.Request
+	err     error
+}
+
+func (h *GraphQLHandler) newGraphQLRequest(r *http.Request) *graphqlRequest {
+	state := &graphqlRequest{
+		request: r,
+		loaders: make(map[string]*graphql.Loader, len(graphqlResources)),
+		checks:  make(map[string]graphqlCheck),
+	}
+	for _, resource := range graphqlResources {
+		state.loaders[resource.name] = graphql.NewLoader(h.batchGet(resource), h.cfg.BatchWait, 0)
+	}
+	return state
+}
+
+func graphqlRequestFrom(ctx context.Context) *graphqlRequest {
+	state, _ := ctx.Value(graphqlRequestKey{}).(*graphqlRequest)
+	return state
+}
+
+// authorize applies the policy of the REST route GET path for the caller and
+// returns the request as rewritten by it, e.g. with a forced owner filter.
+// Decisions are remembered for the rest of the request.
+func (h *GraphQLHandler) authorize(state *graphqlRequest, path string) (*http.Request, error) {
+	state.mu.Lock()
+	check, ok := state.checks[path]
+	state.mu.Unlock()
+	if ok {
+		return check.request, check.err
+	}
+
+	req, err := http.NewRequestWithContext(state.request.Context(), http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	authorized, problem := h.authorizer.Check(req)
+	if problem != nil {
+		gqlErr := graphql.NewError(problem.Detail, problem.Code)
+		if permission, ok := problem.Extensions["required_permission"]; ok {
+			gqlErr = gqlErr.WithExtension("required_permission", permission)
+		}
+		check = graphqlCheck{err: gqlErr}
+	} else {
+		check = graphqlCheck{request: authorized}
+	}
+
+	state.mu.Lock()
+	state.checks[path] = check
+	state.mu.Unlock()
+	return check.request, check.err
+}
+
+// load authorizes and loads one resource through the request's loader
+func (h *GraphQLHandler) load(ctx context.Context, resource graphqlResource, id string) (any, error) {
+	state := graphqlRequestFrom(ctx)
+	if _, err := h.authorize(state, resource.itemPath(id)); err != nil {
+		return nil, err
+	}
+	return state.loaders[resource.name].Load(ctx, id)
+}
+
+// loadList authorizes and lists a resource, priming the loader with the items
+func (h *GraphQLHandler) loadList(ctx context.Context, resource graphqlResource) (any, error) {
+	state := graphqlRequestFrom(ctx)
+	authorized, err := h.authorize(state, resource.path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Policy conditions express filters as query params, as for the REST route
+	filters := make(map[string]any)
+	for key, values := range authorized.URL.Query() {
+		if len(values) == 1 {
+			filters[key] = values[0]
+		} else {
+			filters[key] = values
+		}
+	}
+
+	data, err := h.request(resource.name+".list", filters)
+	if err != nil {
+		return nil, err
+	}
+	items, err := resource.decodeList(data)
+	if err != nil {
+		h.logger.With("resource", resource.name).With("error", err.Error()).Error("Failed to decode list response")
+		return nil, graphql.NewError("Invalid response from the "+resource.name+" service", graphql.ErrCodeInternal)
+	}
+
+	loader := state.loaders[resource.name]
+	for _, item := range items {
+		if id := resource.id(item); id != "" {
+			loader.Prime(id, item)
+		}
+	}
+	return items, nil
+}
+
+// batchGet loads a batch of IDs for a loader. The services only expose a
+// single-item get, so the batch fans out concurrently after the loader has
+// removed duplicates.
+func (h *GraphQLHandler) batchGet(resource graphqlResource) graphql.BatchFunc {
+	subject := resource.name + ".get"
+	return func(ctx context.Context, keys []string) ([]any, []error) {
+		h.logger.With("subject", subject).With("keys", len(keys)).Debug("Loading GraphQL batch")
+
+		values := make([]any, len(keys))
+		errs := make([]error, len(keys))
+		sem := make(chan struct{}, graphqlMaxConcurrentFetches)
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			wg.Add(1)
+			go func(i int, key string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				data, err := h.request(subject, map[string]string{"id": key})
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				if values[i], err = resource.decode(data); err != nil {
+					h.logger.With("subject", subject).With("error", err.Error()).Error("Failed to decode response")
+					errs[i] = graphql.NewError("Invalid response from the "+resource.name+" service", graphql.ErrCodeInternal)
+				}
+			}(i, key)
+		}
+		wg.Wait()
+		return values, errs
+	}
+}
+
+// request calls a service subject and returns the data of a successful reply
+func (h *GraphQLHandler) request(subject string, payload any) (json.RawMessage, error) {
+	var result struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data,omitempty"`
+		Error   struct {
+			Message string `json:"message"`
+		} `json:"error,omitempty"`
+	}
+
+	logger := h.logger.With("subject", subject)
+	if err := patterns.Request(h.conn, subject, payload, &result, graphqlFetchTimeout, logger); err != nil {
+		logger.With("error", err.Error()).Error("NATS request failed")
+		return nil, graphql.NewError("Service unavailable", "SERVICE_UNAVAILABLE")
+	}
+	if !result.Success {
+		code := "INTERNAL_SERVER_ERROR"
+		if strings.Contains(strings.ToLower(result.Error.Message), "not found") {
+			code = "NOT_FOUND"
+		}
+		return nil, graphql.NewError(result.Error.Message, code)
+	}
+	return result.Data, nil
+}
@@ -0,0 +1,164 @@
+// gateway/internal/handlers/graphql_schema.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"reflect"
+
+	"github.com/0xsj/fn-go/gateway/pkg/graphql"
+	"github.com/0xsj/fn-go/pkg/models"
+)
+
+// graphqlResource is a resource served by a NATS service through
+// <name>.get and <name>.list, and exposed over REST under path
+type graphqlResource struct {
+	name  string
+	path  string
+	model reflect.Type
+}
+
+var (
+	incidentResource = graphqlResource{name: "incident", path: "/incidents", model: reflect.TypeOf(models.Incident{})}
+	locationResource = graphqlResource{name: "location", path: "/locations", model: reflect.TypeOf(models.Location{})}
+	entityResource   = graphqlResource{name: "entity", path: "/entities", model: reflect.TypeOf(models.Entity{})}
+	// user-service doesn't serve user.list yet, so users are only reachable by ID
+	userResource = graphqlResource{name: "user", path: "/users", model: reflect.TypeOf(models.User{})}
+
+	graphqlResources = []graphqlResource{incidentResource, locationResource, entityResource, userResource}
+)
+
+// itemPath is the REST path of one item, whose policy guards the GraphQL field
+func (r graphqlResource) itemPath(id string) string {
+	return r.path + "/" + url.PathEscape(id)
+}
+
+// decode decodes one item into a pointer to the resource's model
+func (r graphqlResource) decode(data []byte) (any, error) {
+	item := reflect.New(r.model)
+	if err := json.Unmarshal(data, item.Interface()); err != nil {
+		return nil, err
+	}
+	return item.Interface(), nil
+}
+
+// decodeList decodes a list of items into pointers to the resource's model.
+// Paginated lists are unwrapped to the items of their page.
+func (r graphqlResource) decodeList(data []byte) ([]any, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var page struct {
+			Items json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(trimmed, &page); err != nil {
+			return nil, err
+		}
+		data = page.Items
+	}
+
+	list := reflect.New(reflect.SliceOf(reflect.PointerTo(r.model)))
+	if err := json.Unmarshal(data, list.Interface()); err != nil {
+		return nil, err
+	}
+
+	items := make([]any, list.Elem().Len())
+	for i := range items {
+		items[i] = list.Elem().Index(i).Interface()
+	}
+	return items, nil
+}
+
+// id reads the ID field every model carries
+func (r graphqlResource) id(item any) string {
+	return reflect.ValueOf(item).Elem().FieldByName("ID").String()
+}
+
+// buildGraphQLSchema derives the object types from pkg/models and links them
+// through the IDs they carry, e.g. Incident.locationId resolves to location
+func (h *GraphQLHandler) buildGraphQLSchema() (*graphql.Schema, error) {
+	b := graphql.NewBuilder()
+	user := b.Object("User", "A user account", models.User{})
+	entity := b.Object("Entity", "An organization such as a customer, vendor or partner", models.Entity{})
+	location := b.Object("Location", "A site, building or area", models.Location{})
+	incident := b.Object("Incident", "A reported incident", models.Incident{})
+
+	incident.
+		AddField(h.relation("location", "Where the incident happened", location, locationResource, func(source any) string {
+			return source.(*models.Incident).LocationID
+		})).
+		AddField(h.relation("entity", "The organization the incident concerns", entity, entityResource, func(source any) string {
+			return source.(*models.Incident).EntityID
+		})).
+		AddField(h.relation("reporter", "The user who reported the incident", user, userResource, func(source any) string {
+			return source.(*models.Incident).ReportedBy
+		})).
+		AddField(h.relation("assignee", "The user the incident is assigned to", user, userResource, func(source any) string {
+			return source.(*models.Incident).AssignedTo
+		}))
+
+	location.
+		AddField(h.relation("parent", "The enclosing location", location, locationResource, func(source any) string {
+			return source.(*models.Location).ParentID
+		})).
+		AddField(h.relation("entity", "The organization owning the location", entity, entityResource, func(source any) string {
+			return source.(*models.Location).EntityID
+		}))
+
+	entity.
+		AddField(h.relation("parent", "The parent organization", entity, entityResource, func(source any) string {
+			return source.(*models.Entity).ParentID
+		}))
+
+	query := graphql.NewObject("Query", "")
+	query.
+		AddField(h.item("incident", "Look up an incident by ID", incident, incidentResource)).
+		AddField(h.list("incidents", "List incidents visible to the caller", incident, incidentResource)).
+		AddField(h.item("location", "Look up a location by ID", location, locationResource)).
+		AddField(h.list("locations", "List locations", location, locationResource)).
+		AddField(h.item("entity", "Look up an entity by ID", entity, entityResource)).
+		AddField(h.list("entities", "List entities", entity, entityResource)).
+		AddField(h.item("user", "Look up a user by ID", user, userResource))
+
+	return graphql.NewSchema(query)
+}
+
+// item is a root field loading one resource by ID
+func (h *GraphQLHandler) item(name, description string, t *graphql.Object, resource graphqlResource) *graphql.FieldDefinition {
+	return &graphql.FieldDefinition{
+		Name:        name,
+		Description: description,
+		Type:        t,
+		Args:        []*graphql.Argument{{Name: "id", Type: graphql.NonNullOf(graphql.ID)}},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return h.load(p.Context, resource, p.Args["id"].(string))
+		},
+	}
+}
+
+// list is a root field listing a resource, filtered as the REST route would be
+func (h *GraphQLHandler) list(name, description string, t *graphql.Object, resource graphqlResource) *graphql.FieldDefinition {
+	return &graphql.FieldDefinition{
+		Name:        name,
+		Description: description,
+		Type:        graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(t))),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return h.loadList(p.Context, resource)
+		},
+	}
+}
+
+// relation is a field following an ID on the source to another resource
+func (h *GraphQLHandler) relation(name, description string, t *graphql.Object, resource graphqlResource, id func(source any) string) *graphql.FieldDefinition {
+	return &graphql.FieldDefinition{
+		Name:        name,
+		Description: description,
+		Type:        t,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			key := id(p.Source)
+			if key == "" {
+				return nil, nil
+			}
+			return h.load(p.Context, resource, key)
+		},
+	}
+}
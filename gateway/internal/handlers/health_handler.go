@@ -0,0 +1,83 @@
+// gateway/internal/handlers/health_handler.go
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/gateway/pkg/health"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// HealthHandler serves the liveness, readiness and detailed health endpoints
+type HealthHandler struct {
+	checker *health.Checker
+	resp    *response.HTTPHandler
+	logger  log.Logger
+	started time.Time
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(checker *health.Checker, respHandler *response.HTTPHandler, logger log.Logger) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+		resp:    respHandler,
+		logger:  logger.WithLayer("health-handler"),
+		started: time.Now(),
+	}
+}
+
+// RegisterRoutes registers the health routes
+func (h *HealthHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/livez", h.handleLive)
+	mux.HandleFunc("/readyz", h.handleReady)
+	mux.HandleFunc("/health", h.handleHealth)
+}
+
+// RegisterRouteTable declares the health routes, answered by the gateway itself
+func (h *HealthHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodGet, Pattern: "/livez"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/readyz"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/health"},
+	)
+}
+
+// handleLive handles GET /livez. It only reports that the process is serving,
+// so a failing dependency never gets the gateway restarted.
+func (h *HealthHandler) handleLive(w http.ResponseWriter, r *http.Request) {
+	h.resp.JSON(w, http.StatusOK, map[string]any{
+		"status": health.StatusUp,
+		"uptime": time.Since(h.started).Round(time.Second).String(),
+	})
+}
+
+// handleReady handles GET /readyz, failing while a critical dependency is down
+func (h *HealthHandler) handleReady(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Check(r.Context())
+
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+		h.logger.With("status", report.Status).Warn("Gateway not ready")
+	}
+
+	h.resp.JSON(w, status, map[string]any{
+		"status": report.Status,
+		"ready":  report.Ready,
+	})
+}
+
+// handleHealth handles GET /health with per-dependency status and latency
+func (h *HealthHandler) handleHealth(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Check(r.Context())
+
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	h.resp.JSON(w, status, report)
+}
@@ -0,0 +1,310 @@
+// gateway/internal/handlers/incident_files.go
+package handlers
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/config"
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/response"
+	"github.com/0xsj/fn-go/pkg/common/storage"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// uploadFormField is the multipart field carrying the file
+	uploadFormField = "file"
+	// multipartOverhead allows for part headers and form fields around the file
+	multipartOverhead = 1 << 20
+	// fileMetadataTimeout bounds the NATS requests carrying file metadata
+	fileMetadataTimeout = 5 * time.Second
+)
+
+// errFileTooLarge is returned while streaming a file past the size limit
+var errFileTooLarge = stderrors.New("file exceeds the maximum size")
+
+// WithFiles enables file uploads and downloads, streaming file contents to
+// and from store. incident-service only records the metadata.
+func (h *IncidentHandler) WithFiles(store storage.Store, cfg config.UploadConfig) *IncidentHandler {
+	h.files = store
+	h.uploads = cfg
+	return h
+}
+
+// handleIncidentFiles handles requests to /incidents/{id}/files
+func (h *IncidentHandler) handleIncidentFiles(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		// List files
+		h.proxy.ProxyRequest(w, r, "incident.files.list", func(r *http.Request) (any, error) {
+			return map[string]string{"incident_id": id}, nil
+		})
+	case http.MethodPost:
+		if h.files == nil {
+			h.RespondWithError(w, "NOT_IMPLEMENTED", "File upload is not enabled", http.StatusNotImplemented)
+			return
+		}
+		h.handleUploadFile(w, r, id)
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
+
+// handleIncidentFile handles requests to /incidents/{id}/files/{fileId}
+func (h *IncidentHandler) handleIncidentFile(w http.ResponseWriter, r *http.Request, id, fileID string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	if h.files == nil {
+		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
+		return
+	}
+	h.handleDownloadFile(w, r, id, fileID)
+}
+
+// handleUploadFile handles POST /incidents/{id}/files. The file part of the
+// multipart body is streamed to storage while its size, type and checksum are
+// checked, then incident-service is told about it through incident.files.attach.
+func (h *IncidentHandler) handleUploadFile(w http.ResponseWriter, r *http.Request, id string) {
+	logger := h.logger.With("incident_id", id)
+	h.extendDeadlines(w)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		h.resp.Problem(w, r, response.NewProblem(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE",
+			"Files must be uploaded as multipart/form-data"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.uploads.MaxFileSize+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		h.RespondWithError(w, "BAD_REQUEST", "Invalid multipart body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	part, err := nextFilePart(reader)
+	if err != nil {
+		h.RespondWithError(w, "BAD_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer part.Close()
+
+	// Trust the detected type rather than the one the client declared
+	body := bufio.NewReaderSize(part, 512)
+	head, _ := body.Peek(512)
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !h.allowedType(contentType) {
+		logger.With("content_type", contentType).Warn("Rejected upload with disallowed type")
+		h.resp.Problem(w, r, response.NewProblem(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE",
+			"Files of type "+contentType+" are not accepted"))
+		return
+	}
+
+	fileID := uuid.New().String()
+	key := "incidents/" + id + "/" + fileID
+	hash := sha256.New()
+	limited := &sizeLimitReader{r: body, remaining: h.uploads.MaxFileSize}
+
+	start := time.Now()
+	info, err := h.files.Put(r.Context(), key, io.TeeReader(limited, hash), contentType)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if stderrors.Is(err, errFileTooLarge) || stderrors.As(err, &maxBytesErr) {
+			h.resp.Problem(w, r, response.NewProblem(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE",
+				"Files must not exceed "+strconv.FormatInt(h.uploads.MaxFileSize, 10)+" bytes"))
+			return
+		}
+		logger.With("error", err.Error()).Error("Failed to store uploaded file")
+		h.RespondWithError(w, "INTERNAL_SERVER_ERROR", "Failed to store file", http.StatusInternalServerError)
+		return
+	}
+
+	attachment := &models.IncidentAttachment{
+		ID:          fileID,
+		IncidentID:  id,
+		FileName:    part.FileName(),
+		FileSize:    info.Size,
+		ContentType: contentType,
+		StoragePath: key,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		CreatedAt:   time.Now(),
+	}
+	if principal, ok := middleware.PrincipalFromContext(r.Context()); ok {
+		attachment.UploadedBy = principal.UserID()
+	}
+
+	logger = logger.With("file_id", fileID).With("size", info.Size)
+	logger.With("duration_ms", time.Since(start).Milliseconds()).Info("Stored uploaded file")
+
+	var recorded models.IncidentAttachment
+	if err := h.fileRequest("incident.files.attach", attachment, &recorded); err != nil {
+		// Don't leave behind files the service doesn't know about
+		if delErr := h.files.Delete(r.Context(), key); delErr != nil {
+			logger.With("error", delErr.Error()).Error("Failed to remove orphaned file")
+		}
+		h.respondFileError(w, err)
+		return
+	}
+
+	h.resp.Created(w, &recorded, "")
+}
+
+// handleDownloadFile handles GET /incidents/{id}/files/{fileId}, serving
+// byte ranges and conditional requests straight from storage
+func (h *IncidentHandler) handleDownloadFile(w http.ResponseWriter, r *http.Request, id, fileID string) {
+	logger := h.logger.With("incident_id", id).With("file_id", fileID)
+	h.extendDeadlines(w)
+
+	var attachment models.IncidentAttachment
+	if err := h.fileRequest("incident.files.get", map[string]string{"incident_id": id, "id": fileID}, &attachment); err != nil {
+		h.respondFileError(w, err)
+		return
+	}
+
+	object, err := h.files.Open(r.Context(), attachment.StoragePath)
+	if err != nil {
+		if stderrors.Is(err, storage.ErrNotFound) {
+			logger.Error("File metadata exists but the stored file is missing")
+			h.RespondWithError(w, "NOT_FOUND", "File not found", http.StatusNotFound)
+			return
+		}
+		logger.With("error", err.Error()).Error("Failed to open stored file")
+		h.RespondWithError(w, "INTERNAL_SERVER_ERROR", "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+	defer object.Close()
+
+	header := w.Header()
+	header.Set("Content-Type", attachment.ContentType)
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "private, max-age=0, must-revalidate")
+	if attachment.Checksum != "" {
+		header.Set("ETag", `"`+attachment.Checksum+`"`)
+	}
+
+	http.ServeContent(w, r, "", object.Info().ModTime, object)
+}
+
+// nextFilePart skips to the file part of a multipart body
+func nextFilePart(reader *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, stderrors.New("Missing " + uploadFormField + " part in multipart body")
+		}
+		if err != nil {
+			return nil, stderrors.New("Invalid multipart body: " + err.Error())
+		}
+		if part.FormName() == uploadFormField && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// allowedType reports whether uploads of a detected MIME type are accepted
+func (h *IncidentHandler) allowedType(contentType string) bool {
+	for _, allowed := range h.uploads.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+		if family, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(contentType, family+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// extendDeadlines lets a transfer outlive the server's read and write
+// timeouts, which are sized for JSON requests
+func (h *IncidentHandler) extendDeadlines(w http.ResponseWriter) {
+	deadline := time.Now().Add(h.uploads.Timeout)
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(deadline); err != nil {
+		h.logger.With("error", err.Error()).Debug("Failed to extend read deadline")
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil {
+		h.logger.With("error", err.Error()).Debug("Failed to extend write deadline")
+	}
+}
+
+// fileError is an error reported by incident-service for a file request
+type fileError struct {
+	code    string
+	message string
+}
+
+func (e *fileError) Error() string {
+	return e.message
+}
+
+// fileRequest sends file metadata to incident-service and decodes the reply data into out
+func (h *IncidentHandler) fileRequest(subject string, payload, out any) error {
+	var result struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data,omitempty"`
+		Error   struct {
+			Message string `json:"message"`
+		} `json:"error,omitempty"`
+	}
+
+	logger := h.logger.With("subject", subject)
+	if err := patterns.Request(h.conn, subject, payload, &result, fileMetadataTimeout, logger); err != nil {
+		logger.With("error", err.Error()).Error("NATS request failed")
+		return &fileError{code: "SERVICE_UNAVAILABLE", message: "Failed to process request"}
+	}
+	if !result.Success {
+		code := "INTERNAL_SERVER_ERROR"
+		if strings.Contains(strings.ToLower(result.Error.Message), "not found") {
+			code = "NOT_FOUND"
+		}
+		return &fileError{code: code, message: result.Error.Message}
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		logger.With("error", err.Error()).Error("Failed to decode response")
+		return &fileError{code: "INTERNAL_SERVER_ERROR", message: "Invalid response from the incident service"}
+	}
+	return nil
+}
+
+func (h *IncidentHandler) respondFileError(w http.ResponseWriter, err error) {
+	var fileErr *fileError
+	if !stderrors.As(err, &fileErr) {
+		fileErr = &fileError{code: "INTERNAL_SERVER_ERROR", message: err.Error()}
+	}
+	h.resp.Error(w, response.ErrorResponse{Code: fileErr.code, Message: fileErr.message})
+}
+
+// sizeLimitReader fails once more than remaining bytes have been read
+type sizeLimitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (lr *sizeLimitReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > lr.remaining+1 {
+		p = p[:lr.remaining+1]
+	}
+	n, err := lr.r.Read(p)
+	lr.remaining -= int64(n)
+	if lr.remaining < 0 {
+		return n, errFileTooLarge
+	}
+	return n, err
+}
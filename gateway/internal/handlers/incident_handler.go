@@ -5,17 +5,24 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/0xsj/fn-go/gateway/internal/config"
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
 	"github.com/0xsj/fn-go/pkg/common/response"
+	"github.com/0xsj/fn-go/pkg/common/storage"
+	"github.com/0xsj/fn-go/pkg/models"
 )
 
 // IncidentHandler handles incident-related requests
 type IncidentHandler struct {
 	*BaseHandler
+	files   storage.Store
+	uploads config.UploadConfig
 }
 
 // NewIncidentHandler creates a new incident handler
@@ -31,6 +38,77 @@ func (h *IncidentHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/incidents/", h.handleIncident)
 }
 
+// RegisterPolicies declares the permissions required by the incident routes.
+// Grants conditioned on the incident, such as customers reading and
+// commenting on those they reported or dispatchers updating those of their
+// entity, are evaluated against the incident loaded from incident.get.
+func (h *IncidentHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	const lookup = "incident.get"
+
+	policies.Add(
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/incidents", Resource: "incident", Action: "read",
+			Conditions: []middleware.Condition{middleware.ScopeToOwner("reported_by", string(models.RoleCustomer))}},
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/incidents", Resource: "incident", Action: "create"},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/incidents/{id}", Resource: "incident", Action: "read", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodPut, Pattern: "/incidents/{id}", Resource: "incident", Action: "update", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodDelete, Pattern: "/incidents/{id}", Resource: "incident", Action: "delete", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/incidents/{id}/comments", Resource: "incident", Action: "read", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/incidents/{id}/comments", Resource: "incident", Action: "comment", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodPut, Pattern: "/incidents/{id}/status", Resource: "incident", Action: "update", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodPut, Pattern: "/incidents/{id}/assign", Resource: "incident", Action: "assign", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/incidents/{id}/history", Resource: "incident", Action: "read", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/incidents/{id}/files", Resource: "incident", Action: "read", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/incidents/{id}/files", Resource: "incident", Action: "update", Lookup: lookup},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/incidents/{id}/files/{fileId}", Resource: "incident", Action: "read", Lookup: lookup},
+		// Related incidents span other reporters, so customers can't list them
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/incidents/{id}/related", Resource: "incident", Action: "read",
+			Conditions: []middleware.Condition{middleware.DenyRoles(string(models.RoleCustomer))}},
+	)
+}
+
+// RegisterRouteTable declares the incident routes and the subjects serving
+// them. File contents are streamed to storage, so only their metadata goes
+// over NATS, and related incidents are gathered from several subjects.
+func (h *IncidentHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodGet, Pattern: "/incidents", Subject: "incident.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/incidents", Subject: "incident.create"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/incidents/{id}", Subject: "incident.get"},
+		middleware.Route{Method: http.MethodPut, Pattern: "/incidents/{id}", Subject: "incident.update"},
+		middleware.Route{Method: http.MethodDelete, Pattern: "/incidents/{id}", Subject: "incident.delete"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/incidents/{id}/comments", Subject: "incident.comments.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/incidents/{id}/comments", Subject: "incident.comments.add"},
+		middleware.Route{Method: http.MethodPut, Pattern: "/incidents/{id}/status", Subject: "incident.status.update"},
+		middleware.Route{Method: http.MethodPut, Pattern: "/incidents/{id}/assign", Subject: "incident.assign"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/incidents/{id}/history", Subject: "incident.history"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/incidents/{id}/files", Subject: "incident.files.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/incidents/{id}/files", Subject: "incident.files.attach"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/incidents/{id}/files/{fileId}", Subject: "incident.files.get"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/incidents/{id}/related"},
+	)
+}
+
+// RegisterCachePolicies declares the cacheable incident reads. Incidents are
+// scoped per caller, so entries are private and kept briefly. File downloads
+// are streamed with their own validators and are never cached.
+func (h *IncidentHandler) RegisterCachePolicies(policies *middleware.CachePolicies) {
+	policies.Add(
+		middleware.CachePolicy{Pattern: "/incidents", TTL: 15 * time.Second,
+			Tags: []string{"incident", "incident:list"}},
+		middleware.CachePolicy{Pattern: "/incidents/{id}", TTL: 30 * time.Second,
+			Tags: []string{"incident", "incident:{id}"}},
+	)
+	for _, sub := range []string{"comments", "history", "files", "related"} {
+		policies.Add(middleware.CachePolicy{Pattern: "/incidents/{id}/" + sub, TTL: 30 * time.Second,
+			Tags: []string{"incident", "incident:{id}"}})
+	}
+	policies.AddInvalidations(
+		middleware.CacheInvalidation{Method: http.MethodPost, Pattern: "/incidents", Tags: []string{"incident:list"}},
+		middleware.CacheInvalidation{Pattern: "/incidents/{id}", Tags: []string{"incident:{id}", "incident:list"}},
+		middleware.CacheInvalidation{Pattern: "/incidents/{id}/*", Tags: []string{"incident:{id}", "incident:list"}},
+	)
+}
+
 // handleIncidents handles requests to /incidents
 func (h *IncidentHandler) handleIncidents(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -93,6 +171,10 @@ func (h *IncidentHandler) handleIncidentSubPath(w http.ResponseWriter, r *http.R
 		// This demonstrates service-to-service communication
 		h.handleRelatedIncidents(w, r, id)
 	default:
+		if fileID, ok := strings.CutPrefix(subPath, "files/"); ok && fileID != "" && !strings.Contains(fileID, "/") {
+			h.handleIncidentFile(w, r, id, fileID)
+			return
+		}
 		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
 	}
 }
@@ -287,23 +369,6 @@ func (h *IncidentHandler) handleIncidentHistory(w http.ResponseWriter, r *http.R
 	})
 }
 
-// handleIncidentFiles handles requests to /incidents/{id}/files
-func (h *IncidentHandler) handleIncidentFiles(w http.ResponseWriter, r *http.Request, id string) {
-	switch r.Method {
-	case http.MethodGet:
-		// List files
-		h.proxy.ProxyRequest(w, r, "incident.files.list", func(r *http.Request) (any, error) {
-			return map[string]string{"incident_id": id}, nil
-		})
-	case http.MethodPost:
-		// Upload file (multipart form)
-		h.logger.Warn("File upload not implemented yet")
-		h.RespondWithError(w, "NOT_IMPLEMENTED", "File upload not implemented yet", http.StatusNotImplemented)
-	default:
-		h.RespondWithMethodNotAllowed(w)
-	}
-}
-
 // handleRelatedIncidents demonstrates service-to-service communication
 // It will get incidents related to the current incident by location
 func (h *IncidentHandler) handleRelatedIncidents(w http.ResponseWriter, r *http.Request, id string) {
@@ -0,0 +1,65 @@
+// gateway/internal/handlers/jwks_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/pkg/common/jwks"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// JWKSHandler publishes the auth service's token signing keys, so clients
+// and other services can verify access tokens themselves
+type JWKSHandler struct {
+	verifier *jwks.Verifier
+	maxAge   time.Duration
+	resp     *response.HTTPHandler
+	logger   log.Logger
+}
+
+// NewJWKSHandler creates a new JWKS handler serving the verifier's cached key
+// set. Clients may cache it for maxAge; keys are published ahead of use, so
+// a cached copy still verifies fresh tokens.
+func NewJWKSHandler(verifier *jwks.Verifier, maxAge time.Duration, respHandler *response.HTTPHandler, logger log.Logger) *JWKSHandler {
+	return &JWKSHandler{
+		verifier: verifier,
+		maxAge:   maxAge,
+		resp:     respHandler,
+		logger:   logger.WithLayer("jwks-handler"),
+	}
+}
+
+// RegisterRoutes registers the JWKS route
+func (h *JWKSHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/.well-known/jwks.json", h.handleJWKS)
+}
+
+// RegisterRouteTable declares the JWKS route, answered by the gateway itself
+func (h *JWKSHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodGet, Pattern: "/.well-known/jwks.json"},
+	)
+}
+
+// handleJWKS handles GET /.well-known/jwks.json
+func (h *JWKSHandler) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.resp.Error(w, response.ErrorResponse{Code: "METHOD_NOT_ALLOWED", Message: "Method not allowed"})
+		return
+	}
+
+	set := h.verifier.Set()
+	if len(set.Keys) == 0 {
+		h.logger.Warn("JWKS requested before any signing keys were fetched")
+		w.Header().Set("Retry-After", "5")
+		h.resp.Error(w, response.ErrorResponse{Code: "SERVICE_UNAVAILABLE", Message: "Signing keys are not available yet"})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.maxAge.Seconds())))
+	h.resp.JSON(w, http.StatusOK, set)
+}
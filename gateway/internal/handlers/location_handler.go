@@ -1 +1,95 @@
-package handlers
\ No newline at end of file
+// gateway/internal/handlers/location_handler.go
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// LocationHandler handles location-related requests
+type LocationHandler struct {
+	*BaseHandler
+}
+
+// NewLocationHandler creates a new location handler
+func NewLocationHandler(conn *nats.Conn, respHandler *response.HTTPHandler, logger log.Logger) *LocationHandler {
+	return &LocationHandler{
+		BaseHandler: NewBaseHandler(conn, respHandler, logger.WithLayer("location-handler"), "locations"),
+	}
+}
+
+// RegisterRoutes registers the location routes
+func (h *LocationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/locations", h.handleLocations)
+	mux.HandleFunc("/locations/", h.handleLocation)
+}
+
+// RegisterPolicies declares the permissions required by the location routes
+func (h *LocationHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	policies.Add(
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/locations", Resource: "location", Action: "read"},
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/locations", Resource: "location", Action: "create"},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/locations/{id}", Resource: "location", Action: "read"},
+	)
+}
+
+// RegisterRouteTable declares the location routes and the subjects serving them
+func (h *LocationHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodGet, Pattern: "/locations", Subject: "location.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/locations", Subject: "location.create"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/locations/{id}", Subject: "location.get"},
+	)
+}
+
+// RegisterCachePolicies declares the cacheable location reads. Locations are
+// the same for every caller and change rarely, so dashboards share one entry.
+func (h *LocationHandler) RegisterCachePolicies(policies *middleware.CachePolicies) {
+	policies.Add(
+		middleware.CachePolicy{Pattern: "/locations", TTL: time.Minute, Shared: true,
+			Tags: []string{"location", "location:list"}},
+		middleware.CachePolicy{Pattern: "/locations/{id}", TTL: 5 * time.Minute, Shared: true,
+			Tags: []string{"location", "location:{id}"}},
+	)
+	policies.AddInvalidations(
+		middleware.CacheInvalidation{Method: http.MethodPost, Pattern: "/locations", Tags: []string{"location:list"}},
+	)
+}
+
+// handleLocations handles requests to /locations
+func (h *LocationHandler) handleLocations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.logger.Info("Handling list locations request")
+		h.HandleRequest(w, r, "location.list")
+	case http.MethodPost:
+		h.logger.Info("Handling create location request")
+		h.HandleRequest(w, r, "location.create")
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
+
+// handleLocation handles requests to /locations/{id}
+func (h *LocationHandler) handleLocation(w http.ResponseWriter, r *http.Request) {
+	id := h.ExtractIDFromPath(r)
+	if id == "" {
+		http.Redirect(w, r, "/locations", http.StatusFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.logger.With("location_id", id).Info("Handling get location request")
+		h.proxy.ProxyRequest(w, r, "location.get", func(r *http.Request) (any, error) {
+			return map[string]string{"id": id}, nil
+		})
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
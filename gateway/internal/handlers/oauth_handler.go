@@ -0,0 +1,358 @@
+// gateway/internal/handlers/oauth_handler.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// oauthTimeout bounds requests to the auth service's OAuth endpoints
+const oauthTimeout = 5 * time.Second
+
+// OAuthHandler serves the OAuth2 authorization server and OpenID Connect
+// provider endpoints. The protocol endpoints answer in the formats the RFCs
+// define rather than the gateway's response envelope, so off-the-shelf
+// client libraries can talk to them.
+type OAuthHandler struct {
+	*BaseHandler
+}
+
+// NewOAuthHandler creates a new OAuth handler
+func NewOAuthHandler(conn *nats.Conn, respHandler *response.HTTPHandler, logger log.Logger) *OAuthHandler {
+	return &OAuthHandler{
+		BaseHandler: NewBaseHandler(conn, respHandler, logger.WithLayer("oauth-handler"), "oauth"),
+	}
+}
+
+// RegisterRoutes registers the OAuth routes. Authorization codes and client
+// secrets are issued no-store, as tokens are by handleToken.
+func (h *OAuthHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/.well-known/openid-configuration", h.handleDiscovery)
+	mux.HandleFunc("/oauth/authorize", noStore(h.handleAuthorize))
+	mux.HandleFunc("/oauth/token", h.handleToken)
+	mux.HandleFunc("/oauth/userinfo", h.handleUserInfo)
+	mux.HandleFunc("/oauth/introspect", h.handleIntrospect)
+	mux.HandleFunc("/oauth/consents", h.handleConsents)
+	mux.HandleFunc("/oauth/consents/", h.handleConsent)
+	mux.HandleFunc("/oauth/clients", noStore(h.handleClients))
+	mux.HandleFunc("/oauth/clients/", h.handleClient)
+}
+
+// RegisterPolicies declares the access rules for the OAuth routes. Users
+// authorize clients and manage their consents from their own session;
+// registering clients is for administrators. An impersonating administrator
+// can't authorize clients, as the tokens a client gets carry no act claim.
+func (h *OAuthHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	ownSession := []middleware.Condition{middleware.UsersOnly(), middleware.NotImpersonating()}
+
+	policies.Add(
+		middleware.RoutePolicy{Pattern: "/oauth/authorize", Conditions: ownSession},
+		middleware.RoutePolicy{Pattern: "/oauth/consents", Conditions: ownSession},
+		middleware.RoutePolicy{Pattern: "/oauth/consents/*", Conditions: ownSession},
+		middleware.RoutePolicy{Pattern: "/oauth/clients", Resource: "system", Action: "admin"},
+		middleware.RoutePolicy{Pattern: "/oauth/clients/*", Resource: "system", Action: "admin"},
+	)
+}
+
+// RegisterRouteTable declares the OAuth routes
+func (h *OAuthHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodGet, Pattern: "/.well-known/openid-configuration", Subject: "auth.oauth.discovery"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/oauth/authorize", Subject: "auth.oauth.authorize"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/oauth/authorize", Subject: "auth.oauth.authorize"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/oauth/token", Subject: "auth.oauth.token"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/oauth/userinfo", Subject: "auth.oauth.userinfo"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/oauth/userinfo", Subject: "auth.oauth.userinfo"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/oauth/introspect", Subject: "auth.oauth.introspect"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/oauth/consents", Subject: "auth.oauth.consents.list"},
+		middleware.Route{Method: http.MethodDelete, Pattern: "/oauth/consents/{clientId}", Subject: "auth.oauth.consents.revoke"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/oauth/clients", Subject: "auth.oauth.clients.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/oauth/clients", Subject: "auth.oauth.clients.register"},
+		middleware.Route{Method: http.MethodDelete, Pattern: "/oauth/clients/{id}", Subject: "auth.oauth.clients.revoke"},
+	)
+}
+
+// handleDiscovery handles GET /.well-known/openid-configuration
+func (h *OAuthHandler) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+
+	h.oauthRequest(w, "auth.oauth.discovery", map[string]string{})
+}
+
+// handleAuthorize handles /oauth/authorize for the signed-in user. GET takes
+// the client's authorization request from the query string and reports
+// whether the user still has to consent; POST carries the user's decision.
+// Either way the reply names where to send the browser next.
+func (h *OAuthHandler) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+	logger := h.logger.With("user_id", principal.UserID())
+
+	switch r.Method {
+	case http.MethodGet:
+		logger.Info("Handling authorization request")
+		h.proxy.ProxyRequest(w, r, "auth.oauth.authorize", func(r *http.Request) (any, error) {
+			query := r.URL.Query()
+			data := map[string]string{"userId": principal.UserID(), "actorId": principal.ActorID}
+			for _, param := range []string{
+				"client_id", "redirect_uri", "response_type", "scope", "state",
+				"nonce", "code_challenge", "code_challenge_method",
+			} {
+				if value := query.Get(param); value != "" {
+					data[param] = value
+				}
+			}
+			return data, nil
+		})
+	case http.MethodPost:
+		logger.Info("Handling authorization decision")
+		h.proxy.ProxyRequest(w, r, "auth.oauth.authorize", userBody(principal, map[string]any{"actorId": principal.ActorID}))
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
+
+// handleToken handles POST /oauth/token
+func (h *OAuthHandler) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		h.oauthError(w, http.StatusBadRequest, "invalid_request", "The request body must be form encoded")
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(r)
+	payload := map[string]string{
+		"grant_type":    r.PostForm.Get("grant_type"),
+		"code":          r.PostForm.Get("code"),
+		"redirect_uri":  r.PostForm.Get("redirect_uri"),
+		"code_verifier": r.PostForm.Get("code_verifier"),
+		"refresh_token": r.PostForm.Get("refresh_token"),
+		"scope":         r.PostForm.Get("scope"),
+		"client_id":     clientID,
+		"client_secret": clientSecret,
+		"userAgent":     r.UserAgent(),
+	}
+
+	h.logger.With("client_id", clientID).With("grant_type", payload["grant_type"]).Info("Handling OAuth token request")
+
+	// Responses carrying tokens must not be cached (RFC 6749 section 5.1)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	h.oauthRequest(w, "auth.oauth.token", payload)
+}
+
+// handleUserInfo handles GET and POST /oauth/userinfo, authenticated with
+// the access token the client was issued
+func (h *OAuthHandler) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="userinfo"`)
+		h.oauthError(w, http.StatusUnauthorized, "invalid_token", "An access token is required")
+		return
+	}
+
+	h.logger.Debug("Handling userinfo request")
+	w.Header().Set("Cache-Control", "no-store")
+	h.oauthRequest(w, "auth.oauth.userinfo", map[string]string{"accessToken": token})
+}
+
+// handleIntrospect handles POST /oauth/introspect
+func (h *OAuthHandler) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		h.oauthError(w, http.StatusBadRequest, "invalid_request", "The request body must be form encoded")
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(r)
+	h.logger.With("client_id", clientID).Debug("Handling token introspection request")
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.oauthRequest(w, "auth.oauth.introspect", map[string]string{
+		"token":           r.PostForm.Get("token"),
+		"token_type_hint": r.PostForm.Get("token_type_hint"),
+		"client_id":       clientID,
+		"client_secret":   clientSecret,
+	})
+}
+
+// handleConsents handles GET /oauth/consents, listing the clients the caller
+// has granted access
+func (h *OAuthHandler) handleConsents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+
+	h.logger.With("user_id", principal.UserID()).Info("Handling list OAuth consents request")
+	h.proxy.ProxyRequest(w, r, "auth.oauth.consents.list", func(r *http.Request) (any, error) {
+		return map[string]string{"userId": principal.UserID()}, nil
+	})
+}
+
+// handleConsent handles DELETE /oauth/consents/{clientId}, withdrawing the
+// caller's consent so the client can no longer refresh its tokens
+func (h *OAuthHandler) handleConsent(w http.ResponseWriter, r *http.Request) {
+	principal, err := middleware.GetPrincipal(r)
+	if err != nil {
+		h.resp.HandleError(w, err)
+		return
+	}
+
+	clientID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/oauth/consents/"), "/")
+	if clientID == "" {
+		http.Redirect(w, r, "/oauth/consents", http.StatusFound)
+		return
+	}
+	if strings.Contains(clientID, "/") {
+		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+
+	h.logger.With("user_id", principal.UserID()).With("client_id", clientID).Info("Handling revoke OAuth consent request")
+	h.proxy.ProxyRequest(w, r, "auth.oauth.consents.revoke", func(r *http.Request) (any, error) {
+		return map[string]string{"userId": principal.UserID(), "clientId": clientID}, nil
+	})
+}
+
+// handleClients handles GET and POST /oauth/clients
+func (h *OAuthHandler) handleClients(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.logger.Info("Handling list OAuth clients request")
+		h.HandleRequest(w, r, "auth.oauth.clients.list")
+	case http.MethodPost:
+		h.logger.Info("Handling register OAuth client request")
+		h.HandleRequest(w, r, "auth.oauth.clients.register")
+	default:
+		h.RespondWithMethodNotAllowed(w)
+	}
+}
+
+// handleClient handles DELETE /oauth/clients/{id}
+func (h *OAuthHandler) handleClient(w http.ResponseWriter, r *http.Request) {
+	clientID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/oauth/clients/"), "/")
+	if clientID == "" {
+		http.Redirect(w, r, "/oauth/clients", http.StatusFound)
+		return
+	}
+	if strings.Contains(clientID, "/") {
+		h.RespondWithError(w, "NOT_FOUND", "Resource not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		h.RespondWithMethodNotAllowed(w)
+		return
+	}
+
+	h.logger.With("client_id", clientID).Info("Handling revoke OAuth client request")
+	h.proxy.ProxyRequest(w, r, "auth.oauth.clients.revoke", func(r *http.Request) (any, error) {
+		return map[string]string{"clientId": clientID}, nil
+	})
+}
+
+// oauthRequest sends a protocol request to the auth service and writes its
+// reply as is. Protocol errors come back as successful replies carrying an
+// "error" code, which decides the status (RFC 6749 section 5.2, RFC 6750
+// section 3.1).
+func (h *OAuthHandler) oauthRequest(w http.ResponseWriter, subject string, payload any) {
+	var result struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data,omitempty"`
+		Error   struct {
+			Message string `json:"message"`
+		} `json:"error,omitempty"`
+	}
+
+	logger := h.logger.With("subject", subject)
+	if err := patterns.Request(h.conn, subject, payload, &result, oauthTimeout, logger); err != nil {
+		logger.With("error", err.Error()).Error("NATS request failed")
+		h.oauthError(w, http.StatusServiceUnavailable, "temporarily_unavailable", "The authorization server is unavailable")
+		return
+	}
+	if !result.Success {
+		logger.With("error", result.Error.Message).Error("OAuth request failed")
+		h.oauthError(w, http.StatusInternalServerError, "server_error", result.Error.Message)
+		return
+	}
+
+	var reply struct {
+		Error string `json:"error"`
+	}
+	json.Unmarshal(result.Data, &reply)
+
+	status := http.StatusOK
+	switch reply.Error {
+	case "":
+	case "invalid_client":
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	case "invalid_token":
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	case "insufficient_scope":
+		status = http.StatusForbidden
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+	default:
+		status = http.StatusBadRequest
+	}
+
+	h.resp.JSON(w, status, result.Data)
+}
+
+// oauthError writes an error in the OAuth format
+func (h *OAuthHandler) oauthError(w http.ResponseWriter, status int, code, description string) {
+	h.resp.JSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+// clientCredentials returns the client's credentials from HTTP Basic
+// authentication, falling back to the request body (RFC 6749 section 2.3.1)
+func clientCredentials(r *http.Request) (string, string) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		// Credentials are form encoded before being put in the header
+		if decoded, err := url.QueryUnescape(id); err == nil {
+			id = decoded
+		}
+		if decoded, err := url.QueryUnescape(secret); err == nil {
+			secret = decoded
+		}
+		return id, secret
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+}
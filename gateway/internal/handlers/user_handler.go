@@ -6,9 +6,11 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/0xsj/fn-go/gateway/internal/middleware"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/response"
+	"github.com/0xsj/fn-go/pkg/models"
 )
 
 // UserHandler handles user-related requests
@@ -32,6 +34,43 @@ func (h *UserHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/users/", h.handleUser)
 }
 
+// RegisterPolicies declares the permissions required by the user routes.
+// Customers can only read their own account; only admins manage other users' profiles.
+func (h *UserHandler) RegisterPolicies(policies *middleware.PolicySet) {
+	customer := string(models.RoleCustomer)
+	dispatcher := string(models.RoleDispatcher)
+
+	policies.Add(
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/users", Resource: "user", Action: "read",
+			Conditions: []middleware.Condition{middleware.ScopeToOwner("id", customer)}},
+		middleware.RoutePolicy{Method: http.MethodPost, Pattern: "/users", Resource: "user", Action: "create"},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/users/{id}", Resource: "user", Action: "read",
+			Conditions: []middleware.Condition{middleware.SelfOnly("id", customer)}},
+		middleware.RoutePolicy{Method: http.MethodPut, Pattern: "/users/{id}", Resource: "user", Action: "update"},
+		middleware.RoutePolicy{Method: http.MethodDelete, Pattern: "/users/{id}", Resource: "user", Action: "delete"},
+		middleware.RoutePolicy{Method: http.MethodGet, Pattern: "/users/{id}/profile", Resource: "user", Action: "read",
+			Conditions: []middleware.Condition{middleware.SelfOnly("id", customer)}},
+		middleware.RoutePolicy{Method: http.MethodPut, Pattern: "/users/{id}/profile",
+			Conditions: []middleware.Condition{middleware.SelfOnly("id", customer, dispatcher)}},
+		middleware.RoutePolicy{Method: http.MethodPut, Pattern: "/users/{id}/password",
+			Conditions: []middleware.Condition{middleware.UsersOnly(), middleware.NotImpersonating(), middleware.SelfOnly("id", customer, dispatcher)}},
+	)
+}
+
+// RegisterRouteTable declares the user routes and the subjects serving them
+func (h *UserHandler) RegisterRouteTable(routes *middleware.RouteTable) {
+	routes.Add(
+		middleware.Route{Method: http.MethodGet, Pattern: "/users", Subject: "user.list"},
+		middleware.Route{Method: http.MethodPost, Pattern: "/users", Subject: "user.create"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/users/{id}", Subject: "user.get"},
+		middleware.Route{Method: http.MethodPut, Pattern: "/users/{id}", Subject: "user.update"},
+		middleware.Route{Method: http.MethodDelete, Pattern: "/users/{id}", Subject: "user.delete"},
+		middleware.Route{Method: http.MethodGet, Pattern: "/users/{id}/profile", Subject: "user.profile.get"},
+		middleware.Route{Method: http.MethodPut, Pattern: "/users/{id}/profile", Subject: "user.profile.update"},
+		middleware.Route{Method: http.MethodPut, Pattern: "/users/{id}/password", Subject: "user.password.update"},
+	)
+}
+
 // handleUsers handles requests to /users
 func (h *UserHandler) handleUsers(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
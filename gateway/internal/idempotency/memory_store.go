@@ -0,0 +1,84 @@
+// gateway/internal/idempotency/memory_store.go
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryItem is a record and its expiry
+type memoryItem struct {
+	record    *Record
+	expiresAt time.Time
+}
+
+// MemoryStore keeps records in process memory. Expired records are swept
+// periodically, so memory is bounded by the keys used within the TTL.
+type MemoryStore struct {
+	mu         sync.Mutex
+	items      map[string]memoryItem
+	lastSweep  time.Time
+	sweepEvery time.Duration
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items:      make(map[string]memoryItem),
+		lastSweep:  time.Now(),
+		sweepEvery: time.Minute,
+	}
+}
+
+// Reserve implements Store
+func (s *MemoryStore) Reserve(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if item, ok := s.items[key]; ok && now.Before(item.expiresAt) {
+		return item.record, nil
+	}
+	s.items[key] = memoryItem{record: record, expiresAt: now.Add(ttl)}
+	return nil, nil
+}
+
+// Complete implements Store
+func (s *MemoryStore) Complete(ctx context.Context, key, token string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item, ok := s.items[key]; ok && item.record.State == StateProcessing && item.record.Token == token {
+		s.items[key] = memoryItem{record: record, expiresAt: time.Now().Add(ttl)}
+	}
+	return nil
+}
+
+// Release implements Store
+func (s *MemoryStore) Release(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item, ok := s.items[key]; ok && item.record.State == StateProcessing && item.record.Token == token {
+		delete(s.items, key)
+	}
+	return nil
+}
+
+// sweep drops expired records; callers hold the lock
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepEvery {
+		return
+	}
+	s.lastSweep = now
+
+	for key, item := range s.items {
+		if !now.Before(item.expiresAt) {
+			delete(s.items, key)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// gateway/internal/idempotency/redis_store.go
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/db"
+)
+
+// reserveScript sets the record unless the key exists, returning the
+// existing record if it does
+const reserveScript = `
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+  return false
+end
+return redis.call('GET', KEYS[1])
+`
+
+// swapScript replaces or deletes a processing record, but only while it is
+// still held by the given token. An empty ARGV[2] deletes the key.
+const swapScript = `
+local stored = redis.call('GET', KEYS[1])
+if not stored then
+  return 0
+end
+local record = cjson.decode(stored)
+if record.state ~= 'processing' or record.token ~= ARGV[1] then
+  return 0
+end
+if ARGV[2] == '' then
+  redis.call('DEL', KEYS[1])
+else
+  redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+end
+return 1
+`
+
+// RedisStore shares idempotency records between gateway replicas, so a
+// retry is recognised whichever replica it reaches
+type RedisStore struct {
+	client *db.RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a new Redis-backed store
+func NewRedisStore(client *db.RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Reserve implements Store
+func (s *RedisStore) Reserve(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.client.Eval(ctx, reserveScript, []string{s.prefix + key}, string(data), ttl.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, nil
+	}
+
+	raw, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected idempotency script reply: %v", reply)
+	}
+	var existing Record
+	if err := json.Unmarshal([]byte(raw), &existing); err != nil {
+		return nil, fmt.Errorf("corrupt idempotency record: %w", err)
+	}
+	return &existing, nil
+}
+
+// Complete implements Store
+func (s *RedisStore) Complete(ctx context.Context, key, token string, record *Record, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Eval(ctx, swapScript, []string{s.prefix + key}, token, string(data), ttl.Milliseconds())
+	return err
+}
+
+// Release implements Store
+func (s *RedisStore) Release(ctx context.Context, key, token string) error {
+	_, err := s.client.Eval(ctx, swapScript, []string{s.prefix + key}, token, "", 0)
+	return err
+}
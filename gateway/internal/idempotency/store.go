@@ -0,0 +1,41 @@
+// gateway/internal/idempotency/store.go
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Record states
+const (
+	// StateProcessing marks a key whose first request is still in flight
+	StateProcessing = "processing"
+	// StateCompleted marks a key whose response has been stored for replay
+	StateCompleted = "completed"
+)
+
+// Record is the state of one idempotency key
+type Record struct {
+	State string `json:"state"`
+	// Fingerprint identifies the request the key was first used with
+	Fingerprint string `json:"fingerprint"`
+	// Token identifies the request holding a processing record, so a request
+	// whose lock expired can't overwrite the record of the one that took over
+	Token     string      `json:"token,omitempty"`
+	Status    int         `json:"status,omitempty"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Store persists idempotency records
+type Store interface {
+	// Reserve creates record under key for ttl unless the key exists. It
+	// returns the existing record, or nil if the reservation succeeded.
+	Reserve(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error)
+	// Complete replaces the processing record holding token with a completed one
+	Complete(ctx context.Context, key, token string, record *Record, ttl time.Duration) error
+	// Release drops the processing record holding token so the key can be retried
+	Release(ctx context.Context, key, token string) error
+}
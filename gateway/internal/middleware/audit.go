@@ -0,0 +1,372 @@
+// gateway/internal/middleware/audit.go
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/config"
+	"github.com/0xsj/fn-go/gateway/pkg/metrics"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/response"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/google/uuid"
+)
+
+// CorrelationIDHeader carries the ID tying a request to its audit record and
+// the messages it causes. A client-supplied ID is kept, else one is generated.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// Redacted replaces the values of redacted params
+const Redacted = "[REDACTED]"
+
+// Auditor emits an audit record for every mutating request, and for every
+// request made while impersonating a user. Records are published to
+// monitoring-service in the background, so a slow NATS connection only holds
+// up requests once the buffer is full. Impersonated requests reserve room for
+// their record before running and are refused when none is available.
+type Auditor struct {
+	routes      *RouteTable
+	publisher   *patterns.Publisher
+	proxies     *TrustedProxies
+	redact      []string
+	maxBody     int64
+	wait        time.Duration
+	records     chan models.AuditRecord
+	slots       chan struct{}
+	done        chan struct{}
+	mu          sync.RWMutex
+	closed      bool
+	respHandler *response.HTTPHandler
+	logger      log.Logger
+}
+
+// NewAuditor creates an auditor and starts publishing its records
+func NewAuditor(routes *RouteTable, publisher *patterns.Publisher, proxies *TrustedProxies, cfg config.AuditConfig, respHandler *response.HTTPHandler, logger log.Logger) *Auditor {
+	redact := make([]string, 0, len(cfg.RedactFields))
+	for _, field := range cfg.RedactFields {
+		if field = normalizeParamName(field); field != "" {
+			redact = append(redact, field)
+		}
+	}
+
+	size := max(cfg.BufferSize, 1)
+	a := &Auditor{
+		routes:      routes,
+		publisher:   publisher,
+		proxies:     proxies,
+		redact:      redact,
+		maxBody:     cfg.MaxBodyBytes,
+		wait:        cfg.EnqueueTimeout,
+		records:     make(chan models.AuditRecord, size),
+		slots:       make(chan struct{}, size),
+		done:        make(chan struct{}),
+		respHandler: respHandler,
+		logger:      logger.WithLayer("audit"),
+	}
+	go a.run()
+	return a
+}
+
+// Audit returns the audit middleware. It runs after authentication, so
+// records name the principal, and before authorization, so denied attempts
+// are recorded too. Nil auditors leave requests unaudited.
+func (a *Auditor) Audit(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, authenticated := PrincipalFromContext(r.Context())
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			// Everything an administrator sees as the user is recorded too
+			if !authenticated || !principal.Impersonated() {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		start := time.Now()
+		correlationID := r.Header.Get(CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = r.Header.Get("X-Request-ID")
+		}
+		if correlationID == "" || len(correlationID) > 128 {
+			correlationID = uuid.New().String()
+		}
+		w.Header().Set(CorrelationIDHeader, correlationID)
+		// Publishers pick the correlation ID up from this context key
+		r = r.WithContext(context.WithValue(r.Context(), "correlation_id", correlationID))
+
+		record := models.AuditRecord{
+			ID:            uuid.New().String(),
+			Time:          start.UTC(),
+			Method:        r.Method,
+			Path:          r.URL.Path,
+			CorrelationID: correlationID,
+			ClientIP:      a.proxies.ClientIP(r),
+			UserAgent:     r.UserAgent(),
+		}
+		if authenticated {
+			record.UserID = principal.UserID()
+			record.PrincipalID = principal.ID
+			record.PrincipalType = principal.Type
+			record.ActorID = principal.ActorID
+			if principal.Role != "" {
+				record.Roles = []string{principal.Role}
+			}
+		}
+		route, matched := a.routes.Match(r.Method, r.URL.Path)
+		if matched {
+			record.Route = route.Pattern
+			record.Subject = route.Subject
+		}
+		record.Params = a.params(r, route, matched)
+
+		// A request made as someone else never goes unrecorded
+		reserved := false
+		if record.ActorID != "" {
+			if reason, ok := a.reserve(); !ok {
+				a.logDropped(record, reason)
+				a.respHandler.Problem(w, r, response.Problem{
+					Type:   "/problems/audit-unavailable",
+					Title:  "Audit unavailable",
+					Status: http.StatusServiceUnavailable,
+					Detail: "Requests made while impersonating cannot be recorded right now, please retry",
+					Code:   "AUDIT_UNAVAILABLE",
+				})
+				return
+			}
+			reserved = true
+		}
+
+		rw := newResponseWriter(w)
+		next.ServeHTTP(rw, r)
+
+		record.Status = rw.status
+		record.LatencyMS = time.Since(start).Milliseconds()
+		a.emit(record, reserved)
+	})
+}
+
+// params collects the path, query and JSON body params of a request with
+// sensitive values redacted, leaving the body readable by the handler
+func (a *Auditor) params(r *http.Request, route Route, matched bool) map[string]any {
+	params := make(map[string]any)
+
+	if matched {
+		if pathParams, ok := matchPattern(route.Pattern, r.URL.Path); ok && len(pathParams) > 0 {
+			values := make(map[string]any, len(pathParams))
+			for name, value := range pathParams {
+				values[name] = value
+			}
+			params["path"] = a.redactValue(values)
+		}
+	}
+
+	if query := r.URL.Query(); len(query) > 0 {
+		values := make(map[string]any, len(query))
+		for name, v := range query {
+			if len(v) == 1 {
+				values[name] = v[0]
+			} else {
+				values[name] = v
+			}
+		}
+		params["query"] = a.redactValue(values)
+	}
+
+	if body, ok := a.body(r); ok {
+		params["body"] = a.redactValue(body)
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// body decodes a JSON request body no larger than the configured limit.
+// Whatever was read is put back in front of the rest of the body.
+func (a *Auditor) body(r *http.Request) (any, bool) {
+	if r.Body == nil || r.Body == http.NoBody || a.maxBody <= 0 {
+		return nil, false
+	}
+	// File uploads are streamed and never JSON
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "" && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, a.maxBody+1))
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), r.Body), Closer: r.Body}
+	if err != nil || int64(len(data)) > a.maxBody {
+		return nil, false
+	}
+
+	var body any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// readCloser restores a partly read body while still closing the original
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// redactValue replaces the values of sensitive params throughout a decoded
+// JSON value, including nested objects and arrays
+func (a *Auditor) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for name, inner := range v {
+			if a.sensitive(name) {
+				redacted[name] = Redacted
+			} else {
+				redacted[name] = a.redactValue(inner)
+			}
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, inner := range v {
+			redacted[i] = a.redactValue(inner)
+		}
+		return redacted
+	}
+	return value
+}
+
+// sensitive reports whether a param name contains a redacted field
+func (a *Auditor) sensitive(name string) bool {
+	name = normalizeParamName(name)
+	for _, field := range a.redact {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeParamName folds the spellings of a param name, so tax_id, taxId
+// and Tax-ID all read "taxid"
+func normalizeParamName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("_", "", "-", "").Replace(name)
+}
+
+// reserve claims room in the buffer for one record, waiting up to the
+// enqueue timeout for a slot to free up. It returns why it failed otherwise.
+func (a *Auditor) reserve() (string, bool) {
+	a.mu.RLock()
+	closed := a.closed
+	a.mu.RUnlock()
+	if closed {
+		return "closed", false
+	}
+
+	select {
+	case a.slots <- struct{}{}:
+		return "", true
+	default:
+	}
+	timer := time.NewTimer(a.wait)
+	defer timer.Stop()
+	select {
+	case a.slots <- struct{}{}:
+		return "", true
+	case <-timer.C:
+		return "buffer_full", false
+	}
+}
+
+// emit queues a record for publishing. Records without a reserved slot wait
+// briefly for one and are dropped, and logged in full, if none frees up.
+func (a *Auditor) emit(record models.AuditRecord, reserved bool) {
+	if !reserved {
+		if reason, ok := a.reserve(); !ok {
+			a.logDropped(record, reason)
+			return
+		}
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		<-a.slots
+		a.logDropped(record, "closed")
+		return
+	}
+	// Holding a slot guarantees the send never blocks
+	a.records <- record
+}
+
+// logDropped counts a record that will never be published and logs it, so
+// what it recorded can still be recovered from the logs
+func (a *Auditor) logDropped(record models.AuditRecord, reason string) {
+	metrics.AuditRecordsDroppedCounter.WithLabelValues(reason).Inc()
+	a.logger.With("reason", reason).
+		With("correlation_id", record.CorrelationID).
+		With("record_id", record.ID).
+		With("method", record.Method).
+		With("path", record.Path).
+		With("status", record.Status).
+		With("user_id", record.UserID).
+		With("principal_id", record.PrincipalID).
+		With("actor_id", record.ActorID).
+		With("client_ip", record.ClientIP).
+		Error("Audit record dropped")
+}
+
+// run publishes queued records until the auditor is closed
+func (a *Auditor) run() {
+	defer close(a.done)
+	for record := range a.records {
+		<-a.slots
+		ctx := context.WithValue(context.Background(), "correlation_id", record.CorrelationID)
+		if err := a.publisher.Publish(ctx, nats.SubjectAuditRequest, record); err != nil {
+			metrics.AuditRecordsDroppedCounter.WithLabelValues("publish_failed").Inc()
+			a.logger.With("error", err.Error()).
+				With("correlation_id", record.CorrelationID).
+				Error("Failed to publish audit record")
+		}
+	}
+}
+
+// Close stops accepting records and waits for the queued ones to be
+// published, or for ctx to be done. Register it to run before NATS drains.
+func (a *Auditor) Close(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.records)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		a.logger.With("pending", len(a.records)).Warn("Audit records not published before shutdown")
+		return ctx.Err()
+	}
+}
@@ -3,11 +3,14 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/common/jwks"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
@@ -18,6 +21,9 @@ type userContextKey string
 
 const UserKey userContextKey = "user"
 
+// APIKeyHeader carries API keys issued to machine clients
+const APIKeyHeader = "X-API-Key"
+
 // Define auth-specific error codes
 const (
 	ErrCodeMissingToken   = "MISSING_TOKEN"
@@ -54,8 +60,10 @@ func init() {
 	}
 }
 
-// Authentication middleware authenticates requests
-func Authentication(conn *nats.Conn, respHandler *response.HTTPHandler, logger log.Logger) func(http.Handler) http.Handler {
+// Authentication middleware authenticates requests. With a verifier, bearer
+// tokens are checked against the auth service's published signing keys
+// instead of a round trip per request.
+func Authentication(conn *nats.Conn, verifier *jwks.Verifier, respHandler *response.HTTPHandler, logger log.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip authentication for specific paths
@@ -64,6 +72,20 @@ func Authentication(conn *nats.Conn, respHandler *response.HTTPHandler, logger l
 				return
 			}
 			
+			// Machine clients authenticate with an API key instead of a bearer token
+			if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" && extractToken(r) == "" {
+				principal, userData, err := validateAPIKey(conn, apiKey, logger)
+				if err != nil {
+					respHandler.HandleError(w, err)
+					return
+				}
+				
+				ctx := context.WithValue(r.Context(), UserKey, userData)
+				ctx = ContextWithPrincipal(ctx, principal)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			
 			// Extract token from Authorization header
 			token := extractToken(r)
 			if token == "" {
@@ -73,11 +95,27 @@ func Authentication(conn *nats.Conn, respHandler *response.HTTPHandler, logger l
 				return
 			}
 			
+			// Tokens without a key ID predate asymmetric signing, so only the
+			// auth service can still check them
+			if verifier != nil {
+				principal, userData, err := verifyToken(verifier, token, logger)
+				if err == nil {
+					ctx := context.WithValue(r.Context(), UserKey, userData)
+					ctx = ContextWithPrincipal(ctx, principal)
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+				if !stderrors.Is(err, jwks.ErrNoKeyID) {
+					respHandler.HandleError(w, err)
+					return
+				}
+			}
+			
 			// Validate token with auth service
 			var result struct {
-				Success bool        `json:"success"`
-				Data    any `json:"data,omitempty"`
-				Error   any `json:"error,omitempty"`
+				Success bool            `json:"success"`
+				Data    json.RawMessage `json:"data,omitempty"`
+				Error   any             `json:"error,omitempty"`
 			}
 			
 			authLogger := logger.With("operation", "token_validation")
@@ -114,8 +152,38 @@ func Authentication(conn *nats.Conn, respHandler *response.HTTPHandler, logger l
 				return
 			}
 			
-			// Add user info to request context
-			ctx := context.WithValue(r.Context(), UserKey, result.Data)
+			// A successful reply can still report the token as invalid
+			var validation tokenValidation
+			var userData map[string]any
+			if err := json.Unmarshal(result.Data, &validation); err != nil {
+				validationErr := errors.ErrorFromCode(ErrCodeValidationFail, "Failed to validate authentication", err)
+				authLogger.With("error", err.Error()).Error("Failed to decode token validation response")
+				respHandler.HandleError(w, validationErr)
+				return
+			}
+			json.Unmarshal(result.Data, &userData)
+			
+			if !validation.Valid || validation.User.ID == "" {
+				authLogger.With("path", r.URL.Path).Warn("Token rejected by auth service")
+				respHandler.HandleError(w, errors.ErrorFromCode(ErrCodeInvalidToken, "Invalid or expired authentication", nil))
+				return
+			}
+			
+			principal := &Principal{
+				ID:       validation.User.ID,
+				Type:     PrincipalTypeUser,
+				Username: validation.User.Username,
+				Email:    validation.User.Email,
+				Role:     validation.User.Role,
+				ActorID:  validation.Claims.Actor,
+			}
+			if validation.Claims.Audience != "" {
+				principal = clientPrincipal(principal, validation.Claims.Audience, validation.Claims.Scopes)
+			}
+			
+			// Add user info and principal to request context
+			ctx := context.WithValue(r.Context(), UserKey, userData)
+			ctx = ContextWithPrincipal(ctx, principal)
 			
 			// Call the next handler with the authenticated context
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -123,6 +191,157 @@ func Authentication(conn *nats.Conn, respHandler *response.HTTPHandler, logger l
 	}
 }
 
+// verifyToken verifies an access token against the published signing keys,
+// returning its principal and user data built from the token's claims
+func verifyToken(verifier *jwks.Verifier, token string, logger log.Logger) (*Principal, map[string]any, error) {
+	authLogger := logger.With("operation", "token_verification")
+	
+	claims, err := verifier.VerifyAccessToken(token)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, jwks.ErrNoKeyID):
+			return nil, nil, err
+		case stderrors.Is(err, jwks.ErrTokenExpired):
+			authLogger.Debug("Access token expired")
+			return nil, nil, errors.ErrorFromCode(ErrCodeExpiredToken, "Token has expired", nil)
+		}
+		authLogger.With("error", err.Error()).Warn("Token verification failed")
+		return nil, nil, errors.ErrorFromCode(ErrCodeInvalidToken, "Invalid or expired authentication", nil)
+	}
+	
+	var role string
+	if len(claims.Roles) > 0 {
+		role = claims.Roles[0]
+	}
+	
+	principal := &Principal{
+		ID:       claims.UserID,
+		Type:     PrincipalTypeUser,
+		Username: claims.Username,
+		Email:    claims.Email,
+		Role:     role,
+		ActorID:  claims.Actor,
+	}
+	if claims.Audience != "" {
+		principal = clientPrincipal(principal, claims.Audience, claims.Scopes)
+	}
+	
+	userData := map[string]any{
+		"id":       claims.UserID,
+		"username": claims.Username,
+		"email":    claims.Email,
+		"role":     role,
+	}
+	if claims.Actor != "" {
+		userData["actor_id"] = claims.Actor
+	}
+	
+	authLogger.With("user_id", principal.ID).Debug("Access token verified")
+	return principal, userData, nil
+}
+
+// tokenValidation mirrors the auth.validate reply payload
+type tokenValidation struct {
+	Valid bool `json:"valid"`
+	User  struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Role     string `json:"role"`
+	} `json:"user"`
+	Claims struct {
+		Audience string   `json:"aud"`
+		Scopes   []string `json:"scopes"`
+		Actor    string   `json:"actor"`
+	} `json:"claims"`
+}
+
+// clientPrincipal turns the principal of a token issued to an OAuth client
+// into one acting for the user, limited to the scopes the user granted
+func clientPrincipal(user *Principal, clientID string, scopes []string) *Principal {
+	return &Principal{
+		ID:       clientID,
+		Type:     PrincipalTypeOAuthClient,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+		OwnerID:  user.ID,
+		Scopes:   scopes,
+	}
+}
+
+// apiKeyValidation mirrors the auth.apikeys.validate reply payload
+type apiKeyValidation struct {
+	Valid bool `json:"valid"`
+	Key   *struct {
+		ID     string   `json:"id"`
+		Name   string   `json:"name"`
+		Prefix string   `json:"prefix"`
+		Scopes []string `json:"scopes"`
+	} `json:"key"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Role     string `json:"role"`
+	} `json:"user"`
+}
+
+// validateAPIKey validates an API key with the auth service, returning the key's
+// principal and its owner's user data
+func validateAPIKey(conn *nats.Conn, apiKey string, logger log.Logger) (*Principal, map[string]any, error) {
+	authLogger := logger.With("operation", "api_key_validation")
+	authLogger.Debug("Validating API key with auth service")
+	
+	var result struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data,omitempty"`
+		Error   any             `json:"error,omitempty"`
+	}
+	
+	err := patterns.Request(conn, "auth.apikeys.validate", map[string]string{"key": apiKey}, &result, 5*time.Second, logger)
+	if err != nil {
+		authLogger.With("error", err.Error()).Error("API key validation request failed")
+		return nil, nil, errors.ErrorFromCode(ErrCodeValidationFail, "Failed to validate authentication", err)
+	}
+	if !result.Success {
+		authLogger.With("error", result.Error).Warn("API key validation failed")
+		return nil, nil, errors.ErrorFromCode(ErrCodeInvalidToken, "Invalid API key", nil)
+	}
+	
+	var validation apiKeyValidation
+	if err := json.Unmarshal(result.Data, &validation); err != nil {
+		authLogger.With("error", err.Error()).Error("Failed to decode API key validation response")
+		return nil, nil, errors.ErrorFromCode(ErrCodeValidationFail, "Failed to validate authentication", err)
+	}
+	
+	if !validation.Valid || validation.Key == nil || validation.User.ID == "" {
+		authLogger.Warn("API key rejected by auth service")
+		return nil, nil, errors.ErrorFromCode(ErrCodeInvalidToken, "Invalid, revoked or expired API key", nil)
+	}
+	
+	principal := &Principal{
+		ID:       validation.Key.ID,
+		Type:     PrincipalTypeAPIKey,
+		Username: validation.Key.Name,
+		Email:    validation.User.Email,
+		Role:     validation.User.Role,
+		OwnerID:  validation.User.ID,
+		Scopes:   validation.Key.Scopes,
+	}
+	
+	userData := map[string]any{
+		"id":       validation.User.ID,
+		"username": validation.User.Username,
+		"email":    validation.User.Email,
+		"role":     validation.User.Role,
+		"api_key":  validation.Key.Prefix,
+	}
+	
+	authLogger.With("key_id", principal.ID).With("owner_id", principal.OwnerID).Debug("API key authenticated")
+	return principal, userData, nil
+}
+
 // GetUserFromContext gets the user from the request context
 func GetUserFromContext(r *http.Request) (map[string]any, error) {
 	user, ok := r.Context().Value(UserKey).(map[string]any)
@@ -156,13 +375,23 @@ func extractToken(r *http.Request) string {
 func isPublicPath(path string) bool {
 	publicPaths := []string{
 		"/health",
+		"/livez",
+		"/readyz",
 		"/metrics",
 		"/auth/login",
 		"/auth/register",
 		"/auth/refresh",
 		"/auth/forgot-password",
 		"/auth/reset-password",
+		"/auth/unlock",
+		"/auth/magic-link",
 		"/auth/verify-email",
+		"/auth/mfa/verify",
+		"/auth/mfa/setup",
+		"/oauth/token",
+		"/oauth/userinfo",
+		"/oauth/introspect",
+		"/.well-known/",
 		"/docs",
 		"/swagger",
 	}
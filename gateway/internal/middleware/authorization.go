@@ -0,0 +1,391 @@
+// gateway/internal/middleware/authorization.go
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// Problem codes returned by the authorization middleware
+const (
+	ErrCodePermissionDenied   = "PERMISSION_DENIED"
+	ErrCodeAuthorizationFail  = "AUTHORIZATION_UNAVAILABLE"
+	problemTypePermission     = "/problems/permission-denied"
+	problemTypeAuthentication = "/problems/authentication-required"
+	problemTypeAuthorization  = "/problems/authorization-unavailable"
+)
+
+// permissionEntry is a cached permission set for one user. Each permission
+// maps to true when some grant of it has no conditions.
+type permissionEntry struct {
+	permissions map[string]bool
+	expiresAt   time.Time
+}
+
+// PermissionCache caches each user's permissions as fetched from auth-service
+type PermissionCache struct {
+	mu      sync.RWMutex
+	entries map[string]permissionEntry
+	conn    *nats.Conn
+	ttl     time.Duration
+	timeout time.Duration
+	logger  log.Logger
+}
+
+// NewPermissionCache creates a new permission cache
+func NewPermissionCache(conn *nats.Conn, ttl time.Duration, logger log.Logger) *PermissionCache {
+	return &PermissionCache{
+		entries: make(map[string]permissionEntry),
+		conn:    conn,
+		ttl:     ttl,
+		timeout: 5 * time.Second,
+		logger:  logger.WithLayer("permission-cache"),
+	}
+}
+
+// HasPermission reports whether the user holds resource:action, possibly only
+// under conditions on the resource acted on
+func (c *PermissionCache) HasPermission(userID, resource, action string) (bool, error) {
+	permissions, err := c.Permissions(userID)
+	if err != nil {
+		return false, err
+	}
+	_, ok := permissions[resource+":"+action]
+	return ok, nil
+}
+
+// IsConditional reports whether the user holds resource:action only under
+// conditions, which auth-service must evaluate against the resource
+func (c *PermissionCache) IsConditional(userID, resource, action string) (bool, error) {
+	permissions, err := c.Permissions(userID)
+	if err != nil {
+		return false, err
+	}
+	unconditional, ok := permissions[resource+":"+action]
+	return ok && !unconditional, nil
+}
+
+// Permissions returns the user's permission set, loading it from auth-service on a miss
+func (c *PermissionCache) Permissions(userID string) (map[string]bool, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[userID]
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.permissions, nil
+	}
+
+	permissions, err := c.load(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[userID] = permissionEntry{
+		permissions: permissions,
+		expiresAt:   time.Now().Add(c.ttl),
+	}
+	c.mu.Unlock()
+
+	return permissions, nil
+}
+
+// load fetches the user's permissions from auth-service
+func (c *PermissionCache) load(userID string) (map[string]bool, error) {
+	var result struct {
+		Success bool `json:"success"`
+		Data    []struct {
+			Resource   string            `json:"resource"`
+			Action     string            `json:"action"`
+			Conditions []json.RawMessage `json:"conditions,omitempty"`
+		} `json:"data,omitempty"`
+		Error any `json:"error,omitempty"`
+	}
+
+	logger := c.logger.With("user_id", userID)
+	logger.Debug("Loading user permissions from auth service")
+
+	err := patterns.Request(c.conn, "auth.permissions.get", map[string]string{"userId": userID}, &result, c.timeout, logger)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to load user permissions", err)
+	}
+	if !result.Success {
+		return nil, errors.NewInternalError(fmt.Sprintf("auth service rejected permission lookup: %v", result.Error), nil)
+	}
+
+	permissions := make(map[string]bool, len(result.Data))
+	for _, p := range result.Data {
+		key := p.Resource + ":" + p.Action
+		permissions[key] = permissions[key] || len(p.Conditions) == 0
+	}
+
+	logger.With("permission_count", len(permissions)).Debug("User permissions loaded")
+	return permissions, nil
+}
+
+// Invalidate drops the cached permissions of a single user
+func (c *PermissionCache) Invalidate(userID string) {
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+}
+
+// Flush drops every cached permission set
+func (c *PermissionCache) Flush() {
+	c.mu.Lock()
+	c.entries = make(map[string]permissionEntry)
+	c.mu.Unlock()
+}
+
+// SubscribeInvalidations drops cached permissions whenever auth-service reports a change.
+// A change to one user's roles drops that user's entry; role membership isn't known to
+// the gateway, so any other change drops the whole cache.
+func (c *PermissionCache) SubscribeInvalidations(subscriber *patterns.Subscriber) (*nats.Subscription, error) {
+	return subscriber.Subscribe(nats.SubjectAuthPermissionsChanged, func(ctx context.Context, msg *patterns.MessageEnvelope) error {
+		var event struct {
+			UserID string `json:"userId"`
+		}
+		if err := msg.Unmarshal(&event); err == nil && event.UserID != "" {
+			c.logger.With("message_id", msg.ID).With("user_id", event.UserID).Info("User roles changed, invalidating cached permissions")
+			c.Invalidate(event.UserID)
+			return nil
+		}
+
+		c.logger.With("message_id", msg.ID).Info("Role permissions changed, flushing permission cache")
+		c.Flush()
+		return nil
+	})
+}
+
+// policyDecision is the part of an auth-service policy decision the gateway acts on
+type policyDecision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// Evaluate asks auth-service whether the user may perform resource:action on a
+// resource with the given attributes. Decisions depend on the resource, so they
+// aren't cached.
+func (c *PermissionCache) Evaluate(userID, resource, action string, attributes map[string]any) (*policyDecision, error) {
+	var result struct {
+		Success bool           `json:"success"`
+		Data    policyDecision `json:"data,omitempty"`
+		Error   any            `json:"error,omitempty"`
+	}
+
+	req := map[string]any{
+		"userId":     userID,
+		"resource":   resource,
+		"action":     action,
+		"attributes": attributes,
+	}
+
+	logger := c.logger.With("user_id", userID).With("permission", resource+":"+action)
+	err := patterns.Request(c.conn, "auth.policy.evaluate", req, &result, c.timeout, logger)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to evaluate policy", err)
+	}
+	if !result.Success {
+		return nil, errors.NewInternalError(fmt.Sprintf("auth service rejected policy evaluation: %v", result.Error), nil)
+	}
+
+	return &result.Data, nil
+}
+
+// Authorizer enforces route policies against the caller's permissions
+type Authorizer struct {
+	policies    *PolicySet
+	permissions *PermissionCache
+	respHandler *response.HTTPHandler
+	logger      log.Logger
+}
+
+// NewAuthorizer creates a new authorizer
+func NewAuthorizer(policies *PolicySet, permissions *PermissionCache, respHandler *response.HTTPHandler, logger log.Logger) *Authorizer {
+	return &Authorizer{
+		policies:    policies,
+		permissions: permissions,
+		respHandler: respHandler,
+		logger:      logger.WithLayer("authorization"),
+	}
+}
+
+// Authorize returns a middleware enforcing the policy matching each request.
+// It must run after Authentication; requests matching no policy are denied.
+func (a *Authorizer) Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		authorized, problem := a.Check(r)
+		if problem != nil {
+			a.respHandler.Problem(w, r, *problem)
+			return
+		}
+
+		next.ServeHTTP(w, authorized)
+	})
+}
+
+// Check evaluates the policy matching the request against the principal in its
+// context. It returns the request as rewritten by the policy conditions, or the
+// problem explaining why the caller may not proceed. Other entry points, such
+// as GraphQL resolvers, use it to apply the same rules as the REST routes.
+func (a *Authorizer) Check(r *http.Request) (*http.Request, *response.Problem) {
+	policy, params, ok := a.policies.Match(r.Method, r.URL.Path)
+	if !ok {
+		// Routes declare who may call them; one that doesn't is closed
+		a.logger.With("method", r.Method).With("path", r.URL.Path).Warn("No policy for request, denying")
+		return nil, a.deny(&RoutePolicy{}, "You don't have permission to perform this action")
+	}
+
+	logger := a.logger.With("method", r.Method).With("path", r.URL.Path).With("policy", policy.Pattern)
+
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		logger.Warn("Authorization attempted without an authenticated principal")
+		return nil, &response.Problem{
+			Type:   problemTypeAuthentication,
+			Title:  "Authentication required",
+			Status: http.StatusUnauthorized,
+			Detail: "This endpoint requires an authenticated caller",
+			Code:   "UNAUTHORIZED",
+		}
+	}
+	logger = logger.With("principal_id", principal.ID).With("principal_type", principal.Type).With("role", principal.Role)
+
+	if policy.Resource != "" {
+		// API keys get the intersection of their scopes and their owner's permissions
+		if !principal.ScopeAllows(policy.Permission()) {
+			logger.With("required_permission", policy.Permission()).Warn("Permission outside API key scopes")
+			return nil, a.deny(policy, "Your API key's scopes don't allow this action")
+		}
+
+		allowed, err := a.permissions.HasPermission(principal.UserID(), policy.Resource, policy.Action)
+		if err != nil {
+			logger.With("error", err.Error()).Error("Failed to evaluate permissions")
+			return nil, &response.Problem{
+				Type:   problemTypeAuthorization,
+				Title:  "Authorization unavailable",
+				Status: http.StatusServiceUnavailable,
+				Detail: "Permissions could not be evaluated, please retry",
+				Code:   ErrCodeAuthorizationFail,
+			}
+		}
+		if !allowed {
+			logger.With("required_permission", policy.Permission()).Warn("Permission denied")
+			return nil, a.deny(policy, "You don't have permission to perform this action")
+		}
+
+		if policy.Lookup != "" {
+			if problem := a.checkConditions(policy, params, principal, logger); problem != nil {
+				return nil, problem
+			}
+		}
+	} else if !principal.Unrestricted() && !policy.AnyScope {
+		// Without a permission there is nothing to match scopes against, so
+		// only API keys and clients scoped to everything reach these routes
+		logger.Warn("Scoped credential on a route without a permission")
+		return nil, a.deny(policy, "Your API key's scopes don't allow this action")
+	}
+
+	for _, condition := range policy.Conditions {
+		updated, err := condition(r, principal, params)
+		if err != nil {
+			if errors.IsForbidden(err) {
+				logger.With("reason", err.Error()).Warn("Policy condition denied request")
+				return nil, a.deny(policy, errorMessage(err))
+			}
+			logger.With("error", err.Error()).Error("Failed to evaluate policy condition")
+			return nil, &response.Problem{
+				Type:   problemTypeAuthorization,
+				Title:  "Authorization unavailable",
+				Status: http.StatusServiceUnavailable,
+				Detail: "Access rules could not be evaluated, please retry",
+				Code:   ErrCodeAuthorizationFail,
+			}
+		}
+		r = updated
+	}
+
+	return r, nil
+}
+
+// checkConditions has auth-service evaluate the caller's conditional grants
+// against the resource the route acts on. Callers granted the permission
+// unconditionally skip the lookup.
+func (a *Authorizer) checkConditions(policy *RoutePolicy, params RouteParams, principal *Principal, logger log.Logger) *response.Problem {
+	unavailable := &response.Problem{
+		Type:   problemTypeAuthorization,
+		Title:  "Authorization unavailable",
+		Status: http.StatusServiceUnavailable,
+		Detail: "Access rules could not be evaluated, please retry",
+		Code:   ErrCodeAuthorizationFail,
+	}
+
+	conditional, err := a.permissions.IsConditional(principal.UserID(), policy.Resource, policy.Action)
+	if err != nil {
+		logger.With("error", err.Error()).Error("Failed to evaluate permissions")
+		return unavailable
+	}
+	if !conditional {
+		return nil
+	}
+
+	// A resource that can't be loaded has no attributes, so no condition on it
+	// holds; this doesn't reveal whether it exists
+	attributes, _, err := loadResource(a.permissions.conn, policy.Lookup, params["id"], logger)
+	if err != nil {
+		logger.With("error", err.Error()).Error("Failed to load resource for policy evaluation")
+		return unavailable
+	}
+
+	decision, err := a.permissions.Evaluate(principal.UserID(), policy.Resource, policy.Action, attributes)
+	if err != nil {
+		logger.With("error", err.Error()).Error("Failed to evaluate policy")
+		return unavailable
+	}
+	if !decision.Allowed {
+		logger.With("required_permission", policy.Permission()).With("reason", decision.Reason).Warn("Policy conditions denied request")
+		return a.deny(policy, "You don't have permission to perform this action on this resource")
+	}
+
+	return nil
+}
+
+// deny builds a 403 problem for the policy
+func (a *Authorizer) deny(policy *RoutePolicy, detail string) *response.Problem {
+	problem := response.Problem{
+		Type:   problemTypePermission,
+		Title:  "Permission denied",
+		Status: http.StatusForbidden,
+		Detail: detail,
+		Code:   ErrCodePermissionDenied,
+	}
+	if permission := policy.Permission(); permission != "" {
+		problem = problem.WithExtension("required_permission", permission)
+	}
+	return &problem
+}
+
+// errorMessage returns the client-facing message of an AppError
+func errorMessage(err error) string {
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return err.Error()
+}
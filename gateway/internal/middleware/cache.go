@@ -0,0 +1,362 @@
+// gateway/internal/middleware/cache.go
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/cache"
+	"github.com/0xsj/fn-go/gateway/internal/versioning"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+)
+
+// CachePolicy opts a GET route into response caching
+type CachePolicy struct {
+	// Pattern is the path pattern, using the same syntax as RoutePolicy
+	Pattern string
+	// TTL is how long a response is served from the cache
+	TTL time.Duration
+	// Shared caches one representation for all callers; otherwise entries are per principal
+	Shared bool
+	// Tags label cached entries for invalidation; {name} is replaced with the path param
+	Tags []string
+}
+
+// CacheInvalidation evicts tagged entries when a mutating route succeeds
+type CacheInvalidation struct {
+	Method  string
+	Pattern string
+	Tags    []string
+}
+
+// CachePolicies holds the cache policies and invalidations declared by the handlers
+type CachePolicies struct {
+	mu            sync.RWMutex
+	policies      []CachePolicy
+	invalidations []CacheInvalidation
+}
+
+// NewCachePolicies creates an empty set of cache policies
+func NewCachePolicies() *CachePolicies {
+	return &CachePolicies{}
+}
+
+// Add registers cache policies; the first matching policy wins
+func (c *CachePolicies) Add(policies ...CachePolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policies = append(c.policies, policies...)
+}
+
+// AddInvalidations registers the tags evicted by mutating routes
+func (c *CachePolicies) AddInvalidations(invalidations ...CacheInvalidation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidations = append(c.invalidations, invalidations...)
+}
+
+// match finds the cache policy for a read
+func (c *CachePolicies) match(path string) (*CachePolicy, RouteParams, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for i := range c.policies {
+		if params, ok := matchPattern(c.policies[i].Pattern, path); ok {
+			policy := c.policies[i]
+			return &policy, params, true
+		}
+	}
+	return nil, nil, false
+}
+
+// matchInvalidation collects the tags evicted by a mutation
+func (c *CachePolicies) matchInvalidation(method, path string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var tags []string
+	for _, inv := range c.invalidations {
+		if inv.Method != "" && inv.Method != method {
+			continue
+		}
+		if params, ok := matchPattern(inv.Pattern, path); ok {
+			tags = append(tags, expandTags(inv.Tags, params)...)
+		}
+	}
+	return tags
+}
+
+// expandTags substitutes path params into tag templates
+func expandTags(tags []string, params RouteParams) []string {
+	expanded := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		for name, value := range params {
+			tag = strings.ReplaceAll(tag, "{"+name+"}", value)
+		}
+		expanded = append(expanded, tag)
+	}
+	return expanded
+}
+
+// ResponseCache serves cacheable GET responses from a store and answers
+// conditional requests with 304 Not Modified
+type ResponseCache struct {
+	store    cache.Store
+	policies *CachePolicies
+	logger   log.Logger
+}
+
+// NewResponseCache creates a new response cache
+func NewResponseCache(store cache.Store, policies *CachePolicies, logger log.Logger) *ResponseCache {
+	return &ResponseCache{
+		store:    store,
+		policies: policies,
+		logger:   logger.WithLayer("response-cache"),
+	}
+}
+
+// Cache returns the caching middleware. It runs after authorization so a
+// cached representation is never served to a caller who couldn't fetch it.
+func (rc *ResponseCache) Cache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			rc.serveRead(w, r, next)
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			rc.serveMutation(w, r, next)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// serveRead answers a read from the cache or fills the cache from the handler
+func (rc *ResponseCache) serveRead(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	policy, params, ok := rc.policies.match(r.URL.Path)
+	if !ok {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	// A cached entry is a whole representation, so partial reads go through
+	directives := r.Header.Get("Cache-Control")
+	if strings.Contains(directives, "no-store") || r.Header.Get("Range") != "" {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	key := rc.key(r, policy)
+	logger := rc.logger.With("path", r.URL.Path)
+
+	if !strings.Contains(directives, "no-cache") {
+		entry, err := rc.store.Get(r.Context(), key)
+		if err != nil {
+			logger.With("error", err.Error()).Warn("Cache lookup failed")
+		}
+		if entry != nil {
+			rc.writeEntry(w, r, policy, entry, "HIT")
+			return
+		}
+	}
+
+	rec := newCacheRecorder()
+	next.ServeHTTP(rec, r)
+
+	// Only API responses are cached; anything else, such as a file, keeps the
+	// headers its handler set
+	mediaType, _, _ := mime.ParseMediaType(rec.header.Get("Content-Type"))
+	if rec.status != http.StatusOK || mediaType != "application/json" {
+		rec.flush(w)
+		return
+	}
+
+	now := time.Now()
+	entry := &cache.Entry{
+		Status:    rec.status,
+		Header:    rec.header.Clone(),
+		Body:      rec.body.Bytes(),
+		ETag:      cache.StrongETag(rec.body.Bytes()),
+		StoredAt:  now,
+		ExpiresAt: now.Add(policy.TTL),
+	}
+
+	tags := expandTags(policy.Tags, params)
+	if err := rc.store.Set(r.Context(), key, entry, policy.TTL, tags); err != nil {
+		logger.With("error", err.Error()).Warn("Failed to store cached response")
+	}
+
+	rc.writeEntry(w, r, policy, entry, "MISS")
+}
+
+// serveMutation runs a mutation and evicts the tags it invalidates on success
+func (rc *ResponseCache) serveMutation(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	tags := rc.policies.matchInvalidation(r.Method, r.URL.Path)
+	if len(tags) == 0 {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	rw := newResponseWriter(w)
+	next.ServeHTTP(rw, r)
+
+	if rw.status < http.StatusBadRequest {
+		rc.invalidate(r.Context(), tags...)
+	}
+}
+
+// writeEntry writes a cached entry, or 304 if the client already holds it
+func (rc *ResponseCache) writeEntry(w http.ResponseWriter, r *http.Request, policy *CachePolicy, entry *cache.Entry, status string) {
+	header := w.Header()
+	for name, values := range entry.Header {
+		header[name] = values
+	}
+
+	scope := "private"
+	if policy.Shared {
+		scope = "public"
+	}
+	header.Set("ETag", entry.ETag)
+	header.Set("Cache-Control", scope+", max-age="+strconv.Itoa(int(policy.TTL.Seconds())))
+	header.Set("Vary", "Authorization, X-API-Key, Accept, API-Version")
+	header.Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+	header.Set("X-Cache", status)
+
+	if etagMatches(r.Header.Get("If-None-Match"), entry.ETag) {
+		header.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+	w.WriteHeader(entry.Status)
+	if r.Method != http.MethodHead {
+		w.Write(entry.Body)
+	}
+}
+
+// key identifies a cached representation of the request
+func (rc *ResponseCache) key(r *http.Request, policy *CachePolicy) string {
+	query := r.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	h.Write([]byte(r.URL.Path))
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		h.Write([]byte("\x00" + name + "=" + strings.Join(values, ",")))
+	}
+	h.Write([]byte("\x00" + r.Header.Get("Accept")))
+	// Versions may shape the same path's response differently
+	if version, ok := versioning.FromContext(r.Context()); ok {
+		h.Write([]byte("\x00" + version.Name))
+	}
+
+	if !policy.Shared {
+		if principal, ok := PrincipalFromContext(r.Context()); ok {
+			h.Write([]byte("\x00" + principal.Type + ":" + principal.ID))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// invalidate evicts tagged entries, logging rather than failing on store errors
+func (rc *ResponseCache) invalidate(ctx context.Context, tags ...string) {
+	if err := rc.store.InvalidateTags(ctx, tags...); err != nil {
+		rc.logger.With("tags", tags).With("error", err.Error()).Error("Failed to invalidate cached responses")
+		return
+	}
+	rc.logger.With("tags", tags).Debug("Invalidated cached responses")
+}
+
+// SubscribeInvalidations evicts cached responses when services publish
+// domain events for the given resources, e.g. "incident" listens on
+// "incident.event.>". Events carrying an id evict that resource and its
+// listings; other events evict everything tagged with the resource name.
+func (rc *ResponseCache) SubscribeInvalidations(subscriber *patterns.Subscriber, resources ...string) error {
+	for _, resource := range resources {
+		resource := resource
+		_, err := subscriber.Subscribe(resource+".event.>", func(ctx context.Context, msg *patterns.MessageEnvelope) error {
+			var payload struct {
+				ID string `json:"id"`
+			}
+			if err := msg.Unmarshal(&payload); err != nil || payload.ID == "" {
+				rc.invalidate(ctx, resource)
+				return nil
+			}
+			rc.invalidate(ctx, resource+":"+payload.ID, resource+":list")
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// etagMatches evaluates an If-None-Match header against an ETag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheRecorder buffers a response so it can be stored before being written
+type cacheRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func newCacheRecorder() *cacheRecorder {
+	return &cacheRecorder{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+// Header implements http.ResponseWriter
+func (rec *cacheRecorder) Header() http.Header {
+	return rec.header
+}
+
+// Write implements http.ResponseWriter
+func (rec *cacheRecorder) Write(b []byte) (int, error) {
+	return rec.body.Write(b)
+}
+
+// WriteHeader implements http.ResponseWriter
+func (rec *cacheRecorder) WriteHeader(status int) {
+	rec.status = status
+}
+
+// flush writes the buffered response through unchanged
+func (rec *cacheRecorder) flush(w http.ResponseWriter) {
+	for name, values := range rec.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(rec.status)
+	w.Write(rec.body.Bytes())
+}
@@ -0,0 +1,102 @@
+// gateway/internal/middleware/client_ip.go
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies resolves the client IP of a request, honouring
+// X-Forwarded-For only when the request arrives through a trusted proxy
+type TrustedProxies struct {
+	nets []*net.IPNet
+}
+
+// NewTrustedProxies creates a resolver from a list of IPs or CIDRs
+func NewTrustedProxies(entries []string) (*TrustedProxies, error) {
+	tp := &TrustedProxies{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			entry = fmt.Sprintf("%s/%d", ip.String(), bits)
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		tp.nets = append(tp.nets, ipNet)
+	}
+	return tp, nil
+}
+
+// ClientIP returns the originating client IP for the request. X-Forwarded-For
+// is walked right to left, skipping trusted proxies, so a client can't spoof
+// its address by prepending entries.
+func (tp *TrustedProxies) ClientIP(r *http.Request) string {
+	remote := remoteIP(r)
+	if tp == nil || !tp.isTrusted(remote) {
+		return remote
+	}
+
+	forwarded := r.Header.Values("X-Forwarded-For")
+	hops := make([]string, 0, len(forwarded))
+	for _, header := range forwarded {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			// A malformed hop can't be trusted; stop at the last good address
+			break
+		}
+		if !tp.isTrusted(ip.String()) {
+			return ip.String()
+		}
+		remote = ip.String()
+	}
+
+	return remote
+}
+
+// isTrusted reports whether the IP belongs to a trusted proxy
+func (tp *TrustedProxies) isTrusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range tp.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the host part of RemoteAddr without the port
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,231 @@
+// gateway/internal/middleware/idempotency.go
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/config"
+	"github.com/0xsj/fn-go/gateway/internal/idempotency"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// IdempotencyKeyHeader carries the client-chosen key identifying a retried request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds the keys clients may send
+const maxIdempotencyKeyLength = 255
+
+// Idempotency makes POST, PUT and PATCH requests carrying an Idempotency-Key
+// safe to retry. The first response for a key is stored and replayed for
+// retries; reusing a key for a different request is rejected, as is a retry
+// arriving while the first request is still in flight.
+type Idempotency struct {
+	store       idempotency.Store
+	config      config.IdempotencyConfig
+	proxies     *TrustedProxies
+	respHandler *response.HTTPHandler
+	logger      log.Logger
+}
+
+// NewIdempotency creates a new idempotency middleware
+func NewIdempotency(store idempotency.Store, cfg config.IdempotencyConfig, proxies *TrustedProxies, respHandler *response.HTTPHandler, logger log.Logger) *Idempotency {
+	return &Idempotency{
+		store:       store,
+		config:      cfg,
+		proxies:     proxies,
+		respHandler: respHandler,
+		logger:      logger.WithLayer("idempotency"),
+	}
+}
+
+// Idempotent returns the idempotency middleware. It runs after authorization,
+// so keys are scoped to the principal and rejected requests are never stored.
+func (i *Idempotency) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if !i.config.Enabled || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		// File uploads are streamed and far larger than the bodies kept here
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			i.respHandler.Problem(w, r, response.NewProblem(http.StatusBadRequest, "BAD_REQUEST",
+				"Idempotency-Key must be at most "+strconv.Itoa(maxIdempotencyKeyLength)+" characters"))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, i.config.MaxBodyBytes+1))
+		if err != nil {
+			i.respHandler.Problem(w, r, response.NewProblem(http.StatusBadRequest, "BAD_REQUEST", "Failed to read request body"))
+			return
+		}
+		if int64(len(body)) > i.config.MaxBodyBytes {
+			i.respHandler.Problem(w, r, response.NewProblem(http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE",
+				"Requests sent with an Idempotency-Key must not exceed "+strconv.FormatInt(i.config.MaxBodyBytes, 10)+" bytes"))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		i.serve(w, r, next, i.storeKey(r, key), fingerprint(r, body))
+	})
+}
+
+// serve reserves the key, then runs the request or answers from the existing record
+func (i *Idempotency) serve(w http.ResponseWriter, r *http.Request, next http.Handler, key, fp string) {
+	logger := i.logger.With("path", r.URL.Path)
+
+	// Store writes must outlive a client that disconnects mid-request
+	ctx := context.WithoutCancel(r.Context())
+
+	token := newIdempotencyToken()
+	existing, err := i.store.Reserve(ctx, key, &idempotency.Record{
+		State:       idempotency.StateProcessing,
+		Fingerprint: fp,
+		Token:       token,
+		CreatedAt:   time.Now(),
+	}, i.config.LockTimeout)
+	if err != nil {
+		// Fail open; an unavailable store shouldn't take the API down
+		logger.With("error", err.Error()).Error("Idempotency key reservation failed")
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	if existing != nil {
+		i.answer(w, r, existing, fp)
+		return
+	}
+
+	completed := false
+	defer func() {
+		// Release the key if the handler panicked or failed, so it can be retried
+		if !completed {
+			if err := i.store.Release(ctx, key, token); err != nil {
+				logger.With("error", err.Error()).Warn("Failed to release idempotency key")
+			}
+		}
+	}()
+
+	rec := newCacheRecorder()
+	next.ServeHTTP(rec, r)
+
+	// Server errors are transient, so retries run again rather than replay
+	// them. Responses marked no-store carry credentials, such as tokens or a
+	// client secret, which must never be written to the store.
+	if rec.status < http.StatusInternalServerError && int64(rec.body.Len()) <= i.config.MaxBodyBytes && !noStore(rec.header) {
+		err := i.store.Complete(ctx, key, token, &idempotency.Record{
+			State:       idempotency.StateCompleted,
+			Fingerprint: fp,
+			Status:      rec.status,
+			Header:      rec.header.Clone(),
+			Body:        rec.body.Bytes(),
+			CreatedAt:   time.Now(),
+		}, i.config.TTL)
+		if err != nil {
+			logger.With("error", err.Error()).Warn("Failed to store idempotent response")
+		} else {
+			completed = true
+		}
+	}
+
+	rec.flush(w)
+}
+
+// noStore reports whether a response forbids storing it
+func noStore(header http.Header) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// answer responds to a request whose key is already in use
+func (i *Idempotency) answer(w http.ResponseWriter, r *http.Request, record *idempotency.Record, fp string) {
+	logger := i.logger.With("path", r.URL.Path)
+
+	if record.Fingerprint != fp {
+		logger.Warn("Idempotency key reused with a different request")
+		i.respHandler.Problem(w, r, response.Problem{
+			Type:   "/problems/idempotency-key-reused",
+			Title:  "Idempotency key reused",
+			Status: http.StatusUnprocessableEntity,
+			Detail: "This Idempotency-Key was already used with a different request",
+			Code:   "IDEMPOTENCY_KEY_REUSED",
+		})
+		return
+	}
+
+	if record.State != idempotency.StateCompleted {
+		logger.Debug("Idempotent request already in flight")
+		w.Header().Set("Retry-After", "1")
+		i.respHandler.Problem(w, r, response.Problem{
+			Type:   "/problems/idempotency-key-in-use",
+			Title:  "Request in progress",
+			Status: http.StatusConflict,
+			Detail: "A request with this Idempotency-Key is still being processed, retry after the period in the Retry-After header",
+			Code:   "IDEMPOTENCY_KEY_IN_USE",
+		})
+		return
+	}
+
+	logger.With("status", record.Status).Debug("Replaying idempotent response")
+	header := w.Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.Status)
+	w.Write(record.Body)
+}
+
+// storeKey scopes a client key to the caller, falling back to the client IP
+// for anonymous requests such as registration
+func (i *Idempotency) storeKey(r *http.Request, key string) string {
+	scope := "ip:" + i.proxies.ClientIP(r)
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		scope = principal.Type + ":" + principal.ID
+	}
+
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// fingerprint identifies the request a key is used with
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + "\x00" + r.URL.Path + "\x00" + r.URL.RawQuery + "\x00"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newIdempotencyToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,45 @@
+// gateway/internal/middleware/impersonation.go
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/common/log"
+)
+
+// ImpersonatedByHeader marks responses to requests made while impersonating
+// a user, naming the administrator acting as them
+const ImpersonatedByHeader = "X-Impersonated-By"
+
+// Impersonation marks every response to an impersonated request, so clients
+// can show that an administrator is acting as the user. It runs after
+// authentication, which names the actor.
+func Impersonation(logger log.Logger) func(http.Handler) http.Handler {
+	logger = logger.WithLayer("impersonation")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal, ok := PrincipalFromContext(r.Context()); ok && principal.Impersonated() {
+				w.Header().Set(ImpersonatedByHeader, principal.ActorID)
+				logger.With("user_id", principal.UserID()).
+					With("actor_id", principal.ActorID).
+					With("method", r.Method).
+					With("path", r.URL.Path).
+					Info("Impersonated request")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NotImpersonating rejects requests made while impersonating a user. It
+// guards actions only the user should take, like changing their password or
+// second factor, which would also outlast the impersonation.
+func NotImpersonating() Condition {
+	return func(r *http.Request, principal *Principal, params RouteParams) (*http.Request, error) {
+		if principal.Impersonated() {
+			return nil, errors.NewForbiddenError("This action isn't allowed while impersonating a user", nil)
+		}
+		return r, nil
+	}
+}
@@ -0,0 +1,145 @@
+// gateway/internal/middleware/maintenance.go
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// maintenanceExempt lists the paths never put into maintenance, so operators
+// can't lock themselves out of the admin API or fail the health checks
+var maintenanceExempt = []string{"/admin", "/livez", "/readyz", "/health", "/metrics"}
+
+// MaintenanceRoute is a route switched into maintenance mode
+type MaintenanceRoute struct {
+	// Method limits maintenance to one HTTP method; empty matches any method
+	Method string `json:"method,omitempty"`
+	// Pattern is a route pattern; a trailing "*" covers a whole path prefix
+	Pattern string `json:"pattern"`
+	// Message is returned to callers as the problem detail
+	Message string `json:"message"`
+	// RetryAfter is sent in seconds in the Retry-After header when set
+	RetryAfter int       `json:"retry_after,omitempty"`
+	Since      time.Time `json:"since"`
+}
+
+// Maintenance answers routes switched into maintenance mode with a 503
+// instead of forwarding them to their services
+type Maintenance struct {
+	mu          sync.RWMutex
+	routes      map[string]MaintenanceRoute
+	respHandler *response.HTTPHandler
+	logger      log.Logger
+}
+
+// NewMaintenance creates a maintenance mode switch with no routes in maintenance
+func NewMaintenance(respHandler *response.HTTPHandler, logger log.Logger) *Maintenance {
+	return &Maintenance{
+		routes:      make(map[string]MaintenanceRoute),
+		respHandler: respHandler,
+		logger:      logger.WithLayer("maintenance"),
+	}
+}
+
+// Middleware returns a middleware rejecting requests to routes in maintenance.
+// It must run after versioning, which strips the version prefix routes are
+// matched without.
+func (m *Maintenance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := m.match(r.Method, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if route.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(route.RetryAfter))
+		}
+		m.respHandler.Problem(w, r, response.Problem{
+			Type:   "/problems/maintenance",
+			Title:  "Service under maintenance",
+			Status: http.StatusServiceUnavailable,
+			Detail: route.Message,
+			Code:   "MAINTENANCE",
+		})
+	})
+}
+
+// Set switches a route into maintenance, replacing any earlier message
+func (m *Maintenance) Set(route MaintenanceRoute) {
+	if route.Since.IsZero() {
+		route.Since = time.Now().UTC()
+	}
+	route.Method = strings.ToUpper(route.Method)
+
+	m.mu.Lock()
+	m.routes[route.Method+" "+route.Pattern] = route
+	m.mu.Unlock()
+
+	m.logger.With("method", route.Method).With("pattern", route.Pattern).Warn("Route switched into maintenance")
+}
+
+// Clear takes a route out of maintenance, reporting whether it was in maintenance
+func (m *Maintenance) Clear(method, pattern string) bool {
+	key := strings.ToUpper(method) + " " + pattern
+
+	m.mu.Lock()
+	_, ok := m.routes[key]
+	delete(m.routes, key)
+	m.mu.Unlock()
+
+	if ok {
+		m.logger.With("method", method).With("pattern", pattern).Info("Route taken out of maintenance")
+	}
+	return ok
+}
+
+// Routes returns the routes in maintenance, sorted by pattern
+func (m *Maintenance) Routes() []MaintenanceRoute {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	routes := make([]MaintenanceRoute, 0, len(m.routes))
+	for _, route := range m.routes {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// match finds the maintenance entry covering a request
+func (m *Maintenance) match(method, path string) (MaintenanceRoute, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.routes) == 0 {
+		return MaintenanceRoute{}, false
+	}
+	for _, prefix := range maintenanceExempt {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return MaintenanceRoute{}, false
+		}
+	}
+
+	for _, route := range m.routes {
+		if route.Method != "" && route.Method != method {
+			continue
+		}
+		if _, ok := matchPattern(route.Pattern, path); ok {
+			return route, true
+		}
+	}
+	return MaintenanceRoute{}, false
+}
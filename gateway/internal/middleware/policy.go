@@ -0,0 +1,252 @@
+// gateway/internal/middleware/policy.go
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+)
+
+// RouteParams holds the values captured by {name} segments of a route pattern
+type RouteParams map[string]string
+
+// Condition is an extra rule evaluated once the caller holds the route's permission.
+// It may return a rewritten request, e.g. to scope a listing to the caller, or a
+// forbidden AppError to deny access.
+type Condition func(r *http.Request, principal *Principal, params RouteParams) (*http.Request, error)
+
+// RoutePolicy declares the permission a route requires
+type RoutePolicy struct {
+	// Method is the HTTP method the policy applies to; empty matches any method.
+	// HEAD requests read what GET does, so GET policies apply to them too.
+	Method string
+	// Pattern is the path pattern, e.g. "/incidents/{id}/comments"; a trailing "*" matches any suffix
+	Pattern string
+	// Resource and Action name the required permission; an empty Resource only
+	// requires authentication, and API keys and clients scoped to everything
+	Resource string
+	Action   string
+	// AnyScope admits credentials of any scope to a route without a permission.
+	// It's for routes such as /graphql that authorize each request they make
+	// on the caller's behalf.
+	AnyScope bool
+	// Conditions are evaluated in order after the permission check
+	Conditions []Condition
+	// Lookup is the subject loading the resource named by the "id" path param.
+	// Callers holding the permission only under conditions, e.g. customers
+	// reading incidents they reported, have auth-service evaluate them against
+	// it. Routes without a resource to load, such as listings, pass conditional
+	// grants and rely on Conditions like ScopeToOwner instead.
+	Lookup string
+}
+
+// Permission returns the "resource:action" form of the required permission
+func (p RoutePolicy) Permission() string {
+	if p.Resource == "" {
+		return ""
+	}
+	return p.Resource + ":" + p.Action
+}
+
+// match reports whether the policy applies to the method and path, returning captured params
+func (p RoutePolicy) match(method, path string) (RouteParams, bool) {
+	if p.Method != "" && p.Method != method && !(p.Method == http.MethodGet && method == http.MethodHead) {
+		return nil, false
+	}
+	return matchPattern(p.Pattern, path)
+}
+
+// matchPattern matches a path against a route pattern with {name} segments and
+// an optional trailing "*", returning the captured params
+func matchPattern(pattern, path string) (RouteParams, bool) {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	params := RouteParams{}
+	for i, part := range patternParts {
+		if part == "*" && i == len(patternParts)-1 {
+			return params, true
+		}
+		if i >= len(pathParts) {
+			return nil, false
+		}
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if pathParts[i] == "" {
+				return nil, false
+			}
+			params[part[1:len(part)-1]] = pathParts[i]
+			continue
+		}
+		if part != pathParts[i] {
+			return nil, false
+		}
+	}
+
+	if len(pathParts) != len(patternParts) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// PolicySet holds the route policies declared by the handlers
+type PolicySet struct {
+	mu       sync.RWMutex
+	policies []RoutePolicy
+}
+
+// NewPolicySet creates an empty policy set
+func NewPolicySet() *PolicySet {
+	return &PolicySet{}
+}
+
+// Add registers route policies; the first matching policy wins
+func (s *PolicySet) Add(policies ...RoutePolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies = append(s.policies, policies...)
+}
+
+// Match finds the policy for a request method and path
+func (s *PolicySet) Match(method, path string) (*RoutePolicy, RouteParams, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := range s.policies {
+		if params, ok := s.policies[i].match(method, path); ok {
+			policy := s.policies[i]
+			return &policy, params, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Uncovered returns the routes, other than public ones, that no policy
+// matches. Requests to them are always denied, so each is a route someone
+// forgot to declare a policy for.
+func (s *PolicySet) Uncovered(routes []Route) []Route {
+	var uncovered []Route
+	for _, route := range routes {
+		if isPublicPath(route.Pattern) {
+			continue
+		}
+		if _, _, ok := s.Match(route.Method, route.Pattern); !ok {
+			uncovered = append(uncovered, route)
+		}
+	}
+	return uncovered
+}
+
+// Policies returns a copy of the registered policies
+func (s *PolicySet) Policies() []RoutePolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policies := make([]RoutePolicy, len(s.policies))
+	copy(policies, s.policies)
+	return policies
+}
+
+// appliesTo reports whether a condition restricted to roles applies to the principal.
+// An empty role list applies the condition to everyone.
+func appliesTo(principal *Principal, roles []string) bool {
+	return len(roles) == 0 || principal.HasRole(roles...)
+}
+
+// SelfOnly restricts the route to the principal's own resource, identified by a path param
+func SelfOnly(param string, roles ...string) Condition {
+	return func(r *http.Request, principal *Principal, params RouteParams) (*http.Request, error) {
+		if !appliesTo(principal, roles) {
+			return r, nil
+		}
+		if params[param] != principal.UserID() {
+			return nil, errors.NewForbiddenError("You can only access your own resources", nil)
+		}
+		return r, nil
+	}
+}
+
+// ScopeToOwner forces a list query parameter to the principal's user ID, so the
+// downstream service only returns resources the caller owns
+func ScopeToOwner(queryParam string, roles ...string) Condition {
+	return func(r *http.Request, principal *Principal, params RouteParams) (*http.Request, error) {
+		if !appliesTo(principal, roles) {
+			return r, nil
+		}
+
+		scoped := r.Clone(r.Context())
+		query := scoped.URL.Query()
+		query.Set(queryParam, principal.UserID())
+		scoped.URL.RawQuery = query.Encode()
+		return scoped, nil
+	}
+}
+
+// OwnerOf loads the resource identified by the "id" path param from subject and
+// requires ownerField to match the principal's user ID
+func OwnerOf(conn *nats.Conn, logger log.Logger, subject, ownerField string, roles ...string) Condition {
+	return func(r *http.Request, principal *Principal, params RouteParams) (*http.Request, error) {
+		if !appliesTo(principal, roles) {
+			return r, nil
+		}
+
+		resource, found, err := loadResource(conn, subject, params["id"], logger)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			// Don't reveal whether the resource exists to callers who can't own it
+			return nil, errors.NewForbiddenError("You can only access your own resources", nil)
+		}
+
+		owner, _ := resource[ownerField].(string)
+		if owner == "" || owner != principal.UserID() {
+			return nil, errors.NewForbiddenError("You can only access your own resources", nil)
+		}
+		return r, nil
+	}
+}
+
+// loadResource fetches the resource with the given ID from subject, reporting
+// whether the service found it
+func loadResource(conn *nats.Conn, subject, id string, logger log.Logger) (map[string]any, bool, error) {
+	var result struct {
+		Success bool           `json:"success"`
+		Data    map[string]any `json:"data,omitempty"`
+		Error   any            `json:"error,omitempty"`
+	}
+
+	err := patterns.Request(conn, subject, map[string]string{"id": id}, &result, 5*time.Second, logger)
+	if err != nil {
+		return nil, false, errors.NewInternalError(fmt.Sprintf("failed to load resource from %s", subject), err)
+	}
+	return result.Data, result.Success, nil
+}
+
+// DenyRoles rejects principals with any of the given roles, even if they hold the permission
+func DenyRoles(roles ...string) Condition {
+	return func(r *http.Request, principal *Principal, params RouteParams) (*http.Request, error) {
+		if principal.HasRole(roles...) {
+			return nil, errors.NewForbiddenError("Your role is not allowed to perform this action", nil)
+		}
+		return r, nil
+	}
+}
+
+// UsersOnly rejects principals that aren't interactive users, e.g. API keys
+// must not be able to mint or manage other credentials
+func UsersOnly() Condition {
+	return func(r *http.Request, principal *Principal, params RouteParams) (*http.Request, error) {
+		if principal.Type != PrincipalTypeUser {
+			return nil, errors.NewForbiddenError("This action requires a user session", nil)
+		}
+		return r, nil
+	}
+}
@@ -0,0 +1,114 @@
+// gateway/internal/middleware/principal.go
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/0xsj/fn-go/pkg/common/errors"
+)
+
+type principalContextKey string
+
+// PrincipalKey is the context key holding the authenticated *Principal
+const PrincipalKey principalContextKey = "principal"
+
+// Principal types
+const (
+	PrincipalTypeUser        = "user"
+	PrincipalTypeAPIKey      = "api_key"
+	PrincipalTypeOAuthClient = "oauth_client"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Role     string `json:"role,omitempty"`
+	// OwnerID is the user an API key or OAuth client acts for
+	OwnerID string `json:"owner_id,omitempty"`
+	// Scopes restrict an API key or OAuth client to a subset of its owner's
+	// permissions
+	Scopes []string `json:"scopes,omitempty"`
+	// ActorID is the administrator acting as the user while impersonating them
+	ActorID string `json:"actor_id,omitempty"`
+}
+
+// UserID returns the user whose permissions and resources the principal uses
+func (p *Principal) UserID() string {
+	if p.OwnerID != "" {
+		return p.OwnerID
+	}
+	return p.ID
+}
+
+// Impersonated reports whether an administrator is acting as the principal
+func (p *Principal) Impersonated() bool {
+	return p.ActorID != ""
+}
+
+// ScopeAllows reports whether the principal's scopes cover a "resource:action"
+// permission. Principals without scopes, i.e. users, are not restricted.
+func (p *Principal) ScopeAllows(permission string) bool {
+	if p.Type == PrincipalTypeUser {
+		return true
+	}
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, scope := range p.Scopes {
+		if scope == "*" || scope == permission || scope == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Unrestricted reports whether the principal's scopes, if it has any, allow
+// everything its owner may do
+func (p *Principal) Unrestricted() bool {
+	if p.Type == PrincipalTypeUser {
+		return true
+	}
+	for _, scope := range p.Scopes {
+		if scope == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the principal has any of the given roles
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, PrincipalKey, principal)
+}
+
+// PrincipalFromContext returns the principal stored in the context, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(PrincipalKey).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// GetPrincipal gets the authenticated principal from the request context
+func GetPrincipal(r *http.Request) (*Principal, error) {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		return nil, errors.ErrorFromCode(
+			"UNAUTHORIZED",
+			"Principal not found in context",
+			errors.ErrUnauthorized,
+		)
+	}
+	return principal, nil
+}
@@ -1,79 +1,309 @@
+// gateway/internal/middleware/rate_limiter.go
 package middleware
 
 import (
+	"context"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/0xsj/fn-go/gateway/internal/config"
+	"github.com/0xsj/fn-go/gateway/internal/ratelimit"
+	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/response"
 )
 
-// RateLimiter implements a simple rate limiting middleware
+// maxTrackedKeys bounds the rate limit keys whose state is kept for the admin API
+const maxTrackedKeys = 10000
+
+// KeyState is the state of a rate limit key as last seen by this replica
+type KeyState struct {
+	Key       string    `json:"key"`
+	Limit     string    `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Limited   bool      `json:"limited"`
+	Rejected  int       `json:"rejected"`
+	LastSeen  time.Time `json:"last_seen"`
+	period    time.Duration
+}
+
+// RateLimiter enforces tiered GCRA limits per user, API key and route
 type RateLimiter struct {
-	mu        sync.Mutex
-	tokens    map[string][]time.Time
-	rate      int           // Requests per window
-	window    time.Duration // Time window
+	store       ratelimit.Store
+	config      config.RateLimitConfig
+	proxies     *TrustedProxies
 	respHandler *response.HTTPHandler
+	logger      log.Logger
+
+	mu        sync.Mutex
+	keys      map[string]*KeyState
+	lastSweep time.Time
 }
 
 // NewRateLimiter creates a new rate limiter
-func NewRateLimiter(rate int, window time.Duration, respHandler *response.HTTPHandler) *RateLimiter {
+func NewRateLimiter(store ratelimit.Store, cfg config.RateLimitConfig, proxies *TrustedProxies, respHandler *response.HTTPHandler, logger log.Logger) *RateLimiter {
 	return &RateLimiter{
-		tokens:    make(map[string][]time.Time),
-		rate:      rate,
-		window:    window,
+		store:       store,
+		config:      cfg,
+		proxies:     proxies,
 		respHandler: respHandler,
+		logger:      logger.WithLayer("rate-limiter"),
+		keys:        make(map[string]*KeyState),
+		lastSweep:   time.Now(),
 	}
 }
 
-// RateLimit returns a middleware that limits the number of requests per client
+// ClientLimit returns a middleware applying the coarse per-IP ceiling. It runs
+// before authentication so requests with bad credentials are limited too.
+func (rl *RateLimiter) ClientLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := rl.config.Tiers[config.TierClientIP]
+		if !rl.config.Enabled || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := "ip:" + rl.proxies.ClientIP(r)
+		if rl.enforce(w, r, []ratelimit.Bucket{{Key: key, Limit: limit}}, false) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// RateLimit returns a middleware applying the caller's tier limit and any
+// matching route limit. It runs after authentication so the principal is known.
 func (rl *RateLimiter) RateLimit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get client IP or API key as the token
-		token := r.RemoteAddr
-		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
-			token = apiKey
-		}
-		
-		// Check if the client has exceeded the rate limit
-		if rl.isLimited(token) {
-			rl.respHandler.Error(w, response.ErrorResponse{
-				Code:    "RATE_LIMITED",
-				Message: "Too many requests, please try again later",
-			})
+		if !rl.config.Enabled {
+			next.ServeHTTP(w, r)
 			return
 		}
-		
-		next.ServeHTTP(w, r)
+
+		identity, limit := rl.identify(r)
+		buckets := []ratelimit.Bucket{{Key: "tier:" + identity, Limit: limit}}
+
+		if route, ok := rl.matchRoute(r); ok {
+			buckets = append(buckets, ratelimit.Bucket{
+				Key:   "route:" + route.Method + route.Prefix + ":" + identity,
+				Limit: route.Limit,
+			})
+		}
+
+		if rl.enforce(w, r, buckets, true) {
+			next.ServeHTTP(w, r)
+		}
 	})
 }
 
-// isLimited checks if the client has exceeded the rate limit
-func (rl *RateLimiter) isLimited(token string) bool {
+// identify returns the rate limit identity and tier limit for the request
+func (rl *RateLimiter) identify(r *http.Request) (string, ratelimit.Limit) {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		return "ip:" + rl.proxies.ClientIP(r), rl.tier(config.TierAnonymous)
+	}
+
+	if principal.Type != PrincipalTypeUser {
+		identity := principal.Type + ":" + principal.ID
+		if limit, ok := rl.config.APIKeys[principal.ID]; ok {
+			return identity, limit
+		}
+		// Service accounts act for no one; keys and clients get their owner's tier
+		if principal.OwnerID == "" {
+			return identity, rl.tier(config.TierService)
+		}
+		return identity, rl.roleTier(principal.Role)
+	}
+
+	return "user:" + principal.ID, rl.roleTier(principal.Role)
+}
+
+// roleTier returns the tier limit for a user's role, falling back to the customer tier
+func (rl *RateLimiter) roleTier(role string) ratelimit.Limit {
+	if limit, ok := rl.config.Tiers[role]; ok {
+		return limit
+	}
+	return rl.tier(config.TierCustomer)
+}
+
+// tier returns a tier limit, falling back to the anonymous tier
+func (rl *RateLimiter) tier(name string) ratelimit.Limit {
+	if limit, ok := rl.config.Tiers[name]; ok {
+		return limit
+	}
+	return rl.config.Tiers[config.TierAnonymous]
+}
+
+// matchRoute finds the per-route limit for the request
+func (rl *RateLimiter) matchRoute(r *http.Request) (config.RouteLimit, bool) {
+	for _, route := range rl.config.Routes {
+		if route.Method != "" && route.Method != r.Method {
+			continue
+		}
+		if strings.HasPrefix(r.URL.Path, route.Prefix) {
+			return route, true
+		}
+	}
+	return config.RouteLimit{}, false
+}
+
+// enforce checks every bucket, writes rate limit headers for the most
+// restrictive one and rejects the request if any bucket is exhausted. A
+// rejected request isn't charged to any bucket. It reports whether the
+// request may proceed.
+func (rl *RateLimiter) enforce(w http.ResponseWriter, r *http.Request, buckets []ratelimit.Bucket, setHeaders bool) bool {
+	limited := make([]ratelimit.Bucket, 0, len(buckets))
+	for _, b := range buckets {
+		if !b.Limit.IsZero() {
+			limited = append(limited, b)
+		}
+	}
+	if len(limited) == 0 {
+		return true
+	}
+
+	results, err := rl.store.Allow(r.Context(), limited)
+	if err != nil {
+		// Fail open; an unavailable limiter shouldn't take the API down
+		rl.logger.With("path", r.URL.Path).With("error", err.Error()).Error("Rate limit check failed")
+		return true
+	}
+
+	var tightest, rejected *ratelimit.Result
+	for i, b := range limited {
+		result := results[i]
+		rl.track(b, result)
+
+		if !result.Allowed {
+			if rejected == nil || result.RetryAfter > rejected.RetryAfter {
+				rejected = &result
+			}
+			rl.logger.With("key", b.Key).
+				With("path", r.URL.Path).
+				With("retry_after_ms", result.RetryAfter.Milliseconds()).
+				Warn("Rate limit exceeded")
+			continue
+		}
+		if tightest == nil || result.Remaining < tightest.Remaining {
+			tightest = &result
+		}
+	}
+
+	if rejected != nil {
+		rl.writeHeaders(w, *rejected)
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(rejected.RetryAfter)))
+		rl.respHandler.Problem(w, r, response.Problem{
+			Type:   "/problems/rate-limited",
+			Title:  "Too many requests",
+			Status: http.StatusTooManyRequests,
+			Detail: "Rate limit exceeded, retry after the period in the Retry-After header",
+			Code:   "RATE_LIMITED",
+		})
+		return false
+	}
+
+	if setHeaders && tightest != nil {
+		rl.writeHeaders(w, *tightest)
+	}
+	return true
+}
+
+// track records the outcome of a check for the admin API
+func (rl *RateLimiter) track(b ratelimit.Bucket, result ratelimit.Result) {
+	now := time.Now()
+
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
-	
-	now := time.Now()
-	
-	// Clean up old timestamps
-	if timestamps, ok := rl.tokens[token]; ok {
-		var validTimestamps []time.Time
-		for _, ts := range timestamps {
-			if now.Sub(ts) < rl.window {
-				validTimestamps = append(validTimestamps, ts)
+
+	if now.Sub(rl.lastSweep) >= time.Minute {
+		rl.lastSweep = now
+		for key, state := range rl.keys {
+			// A bucket idle for a whole period has fully drained
+			if now.Sub(state.LastSeen) > state.period {
+				delete(rl.keys, key)
 			}
 		}
-		rl.tokens[token] = validTimestamps
 	}
-	
-	// Check if the client has exceeded the rate limit
-	if len(rl.tokens[token]) >= rl.rate {
-		return true
+
+	state, ok := rl.keys[b.Key]
+	if !ok {
+		if len(rl.keys) >= maxTrackedKeys {
+			return
+		}
+		state = &KeyState{Key: b.Key}
+		rl.keys[b.Key] = state
+	}
+
+	state.Limit = b.Limit.String()
+	state.Remaining = result.Remaining
+	state.Limited = !result.Allowed
+	state.LastSeen = now
+	state.period = b.Limit.Period
+	if !result.Allowed {
+		state.Rejected++
 	}
-	
-	// Add the current timestamp
-	rl.tokens[token] = append(rl.tokens[token], now)
-	
-	return false
-}
\ No newline at end of file
+}
+
+// Keys returns the rate limit keys checked by this replica within their limit
+// period, most recently seen first. Buckets are shared through the store, so
+// Remaining only reflects the last check made here.
+func (rl *RateLimiter) Keys() []KeyState {
+	now := time.Now()
+
+	rl.mu.Lock()
+	states := make([]KeyState, 0, len(rl.keys))
+	for _, state := range rl.keys {
+		if now.Sub(state.LastSeen) <= state.period {
+			states = append(states, *state)
+		}
+	}
+	rl.mu.Unlock()
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].LastSeen.After(states[j].LastSeen)
+	})
+	return states
+}
+
+// ResetClient clears every bucket of a rate limit identity, as named in the
+// keys: "ip:<address>", "user:<id>" or "<principal type>:<id>". It returns the
+// keys reset.
+func (rl *RateLimiter) ResetClient(ctx context.Context, identity string) ([]string, error) {
+	keys := []string{"tier:" + identity}
+	if strings.HasPrefix(identity, "ip:") {
+		keys = append(keys, identity)
+	}
+	for _, route := range rl.config.Routes {
+		keys = append(keys, "route:"+route.Method+route.Prefix+":"+identity)
+	}
+
+	for _, key := range keys {
+		if err := rl.store.Reset(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	rl.mu.Lock()
+	for _, key := range keys {
+		delete(rl.keys, key)
+	}
+	rl.mu.Unlock()
+
+	rl.logger.With("identity", identity).Info("Rate limits reset")
+	return keys, nil
+}
+
+// writeHeaders sets the X-RateLimit-* headers
+func (rl *RateLimiter) writeHeaders(w http.ResponseWriter, result ratelimit.Result) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
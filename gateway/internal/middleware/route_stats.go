@@ -0,0 +1,146 @@
+// gateway/internal/middleware/route_stats.go
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsSlots is the number of slots the stats window is divided into; counts
+// age out one slot at a time
+const statsSlots = 12
+
+// unmatchedPattern groups requests to undeclared paths, so scans for random
+// paths can't grow the stats without bound
+const unmatchedPattern = "(unmatched)"
+
+// RouteStat summarizes the traffic of a route over the stats window
+type RouteStat struct {
+	Method       string `json:"method,omitempty"`
+	Pattern      string `json:"pattern"`
+	Requests     int    `json:"requests"`
+	ClientErrors int    `json:"client_errors"`
+	ServerErrors int    `json:"server_errors"`
+	// ErrorRate is the fraction of requests that failed with a 5xx status
+	ErrorRate float64 `json:"error_rate"`
+}
+
+type statsSlot struct {
+	start        time.Time
+	requests     int
+	clientErrors int
+	serverErrors int
+}
+
+type routeCounter struct {
+	method  string
+	pattern string
+	slots   [statsSlots]statsSlot
+}
+
+// RouteStats counts requests and errors per declared route over a sliding window
+type RouteStats struct {
+	routes   *RouteTable
+	window   time.Duration
+	slot     time.Duration
+	mu       sync.Mutex
+	counters map[string]*routeCounter
+}
+
+// NewRouteStats creates route statistics over the given window
+func NewRouteStats(routes *RouteTable, window time.Duration) *RouteStats {
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	return &RouteStats{
+		routes:   routes,
+		window:   window,
+		slot:     window / statsSlots,
+		counters: make(map[string]*routeCounter),
+	}
+}
+
+// Window returns the period the statistics cover
+func (s *RouteStats) Window() time.Duration {
+	return s.window
+}
+
+// Middleware returns a middleware recording the status of every response. It
+// runs outside Recovery so panics are counted as server errors.
+func (s *RouteStats) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := newResponseWriter(w)
+		next.ServeHTTP(rw, r)
+
+		method, pattern := "", unmatchedPattern
+		if route, ok := s.routes.Match(r.Method, r.URL.Path); ok {
+			method, pattern = r.Method, route.Pattern
+		}
+		s.record(method, pattern, rw.status, time.Now())
+	})
+}
+
+// record counts one response in the current slot of its route
+func (s *RouteStats) record(method, pattern string, status int, now time.Time) {
+	key := method + " " + pattern
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &routeCounter{method: method, pattern: pattern}
+		s.counters[key] = counter
+	}
+
+	start := now.Truncate(s.slot)
+	slot := &counter.slots[(start.UnixNano()/int64(s.slot))%statsSlots]
+	if !slot.start.Equal(start) {
+		*slot = statsSlot{start: start}
+	}
+
+	slot.requests++
+	switch {
+	case status >= http.StatusInternalServerError:
+		slot.serverErrors++
+	case status >= http.StatusBadRequest:
+		slot.clientErrors++
+	}
+}
+
+// Stats returns the routes that served requests within the window, sorted by pattern
+func (s *RouteStats) Stats() []RouteStat {
+	since := time.Now().Add(-s.window)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]RouteStat, 0, len(s.counters))
+	for key, counter := range s.counters {
+		stat := RouteStat{Method: counter.method, Pattern: counter.pattern}
+		for _, slot := range counter.slots {
+			if slot.start.After(since) {
+				stat.Requests += slot.requests
+				stat.ClientErrors += slot.clientErrors
+				stat.ServerErrors += slot.serverErrors
+			}
+		}
+		if stat.Requests == 0 {
+			// Idle routes are dropped and start over when requested again
+			delete(s.counters, key)
+			continue
+		}
+		stat.ErrorRate = float64(stat.ServerErrors) / float64(stat.Requests)
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Pattern != stats[j].Pattern {
+			return stats[i].Pattern < stats[j].Pattern
+		}
+		return stats[i].Method < stats[j].Method
+	})
+	return stats
+}
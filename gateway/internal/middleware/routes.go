@@ -0,0 +1,62 @@
+// gateway/internal/middleware/routes.go
+package middleware
+
+import (
+	"sync"
+)
+
+// Route describes a gateway route and the NATS subject serving it
+type Route struct {
+	// Method is the HTTP method of the route; empty matches any method
+	Method string `json:"method,omitempty"`
+	// Pattern is the path pattern, in the form used by route policies
+	Pattern string `json:"pattern"`
+	// Subject is the NATS subject the route is proxied to; empty for routes
+	// served by the gateway itself or fanning out to several subjects
+	Subject string `json:"subject,omitempty"`
+}
+
+// RouteTable holds the routes declared by the handlers. It names the routes
+// in the admin API, error rate statistics and maintenance mode.
+type RouteTable struct {
+	mu     sync.RWMutex
+	routes []Route
+}
+
+// NewRouteTable creates an empty route table
+func NewRouteTable() *RouteTable {
+	return &RouteTable{}
+}
+
+// Add registers routes; the first matching route wins
+func (t *RouteTable) Add(routes ...Route) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = append(t.routes, routes...)
+}
+
+// Match finds the route for a request method and path
+func (t *RouteTable) Match(method, path string) (Route, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, route := range t.routes {
+		if route.Method != "" && route.Method != method {
+			continue
+		}
+		if _, ok := matchPattern(route.Pattern, path); ok {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// Routes returns a copy of the registered routes
+func (t *RouteTable) Routes() []Route {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	routes := make([]Route, len(t.routes))
+	copy(routes, t.routes)
+	return routes
+}
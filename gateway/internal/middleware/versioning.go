@@ -0,0 +1,188 @@
+// gateway/internal/middleware/versioning.go
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/gateway/internal/versioning"
+	"github.com/0xsj/fn-go/gateway/pkg/metrics"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/response"
+)
+
+// APIVersionHeader selects the API version of an unprefixed request and
+// reports the version that served a response
+const APIVersionHeader = "API-Version"
+
+// maxTransformBodyBytes bounds the request bodies rewritten for older versions
+const maxTransformBodyBytes = 1 << 20
+
+// Versioning resolves the API version of each request from a /v1 style path
+// prefix or the API-Version header, then strips the prefix so routing and
+// policies only see unversioned paths. Bodies on routes with version rules
+// are transformed between the client's shape and the current one.
+type Versioning struct {
+	registry    *versioning.Registry
+	respHandler *response.HTTPHandler
+	logger      log.Logger
+}
+
+// NewVersioning creates a new versioning middleware
+func NewVersioning(registry *versioning.Registry, respHandler *response.HTTPHandler, logger log.Logger) *Versioning {
+	return &Versioning{
+		registry:    registry,
+		respHandler: respHandler,
+		logger:      logger.WithLayer("versioning"),
+	}
+}
+
+// Version returns the versioning middleware. It runs before maintenance mode,
+// rate limiting and authorization, which match routes by their unversioned path.
+func (v *Versioning) Version(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, ok := v.resolve(w, r)
+		if !ok {
+			return
+		}
+
+		if version.IsSunset(time.Now()) {
+			v.writeHeaders(w, version)
+			v.respHandler.Problem(w, r, response.Problem{
+				Type:   "/problems/api-version-sunset",
+				Title:  "API version retired",
+				Status: http.StatusGone,
+				Detail: "API version " + version.Name + " is no longer served",
+				Code:   "API_VERSION_SUNSET",
+			}.WithExtension("supported_versions", v.registry.Names()))
+			return
+		}
+
+		v.writeHeaders(w, version)
+		r = r.WithContext(versioning.WithVersion(r.Context(), version))
+
+		rw := newResponseWriter(w)
+		defer func() {
+			metrics.APIVersionRequestCounter.WithLabelValues(version.Name,
+				strconv.FormatBool(version.IsDeprecated()), strconv.Itoa(rw.status/100)+"xx").Inc()
+			metrics.APIVersionLastRequestGauge.WithLabelValues(version.Name).SetToCurrentTime()
+		}()
+
+		requestTransforms, responseTransforms := version.Transforms(r.Method, r.URL.Path)
+		if len(requestTransforms) > 0 && isJSON(r.Header.Get("Content-Type")) && !v.transformRequest(rw, r, requestTransforms) {
+			return
+		}
+
+		if len(responseTransforms) == 0 {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		rec := newCacheRecorder()
+		next.ServeHTTP(rec, r)
+		v.transformResponse(rec, responseTransforms, r)
+		rec.header.Del("Content-Length")
+		rec.flush(rw)
+	})
+}
+
+// resolve finds the version of a request, stripping a version path prefix. It
+// writes an error and returns false for unknown versions.
+func (v *Versioning) resolve(w http.ResponseWriter, r *http.Request) (*versioning.Version, bool) {
+	name := r.Header.Get(APIVersionHeader)
+
+	segment, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if versioning.IsVersionName(segment) {
+		name = segment
+		r.URL.Path = "/" + rest
+		if r.URL.RawPath != "" {
+			_, rawRest, _ := strings.Cut(strings.TrimPrefix(r.URL.RawPath, "/"), "/")
+			r.URL.RawPath = "/" + rawRest
+		}
+	}
+
+	if name == "" {
+		return v.registry.Default(), true
+	}
+
+	version, ok := v.registry.Get(name)
+	if !ok {
+		v.logger.With("version", name).With("path", r.URL.Path).Debug("Unknown API version requested")
+		v.respHandler.Problem(w, r, response.Problem{
+			Type:   "/problems/unsupported-api-version",
+			Title:  "Unsupported API version",
+			Status: http.StatusBadRequest,
+			Detail: "API version " + name + " is not supported",
+			Code:   "UNSUPPORTED_API_VERSION",
+		}.WithExtension("supported_versions", v.registry.Names()))
+		return nil, false
+	}
+	return version, true
+}
+
+// writeHeaders reports the version and its retirement schedule (RFC 9745, RFC 8594)
+func (v *Versioning) writeHeaders(w http.ResponseWriter, version *versioning.Version) {
+	header := w.Header()
+	header.Set(APIVersionHeader, version.Name)
+	header.Add("Vary", APIVersionHeader)
+
+	if version.IsDeprecated() {
+		header.Set("Deprecation", "@"+strconv.FormatInt(version.Deprecated.Unix(), 10))
+		if version.DocsURL != "" {
+			header.Add("Link", "<"+version.DocsURL+`>; rel="deprecation"`)
+		}
+	}
+	if !version.Sunset.IsZero() {
+		header.Set("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// transformRequest upgrades a JSON request body to the current shape. It
+// writes an error and returns false if the body can't be transformed.
+func (v *Versioning) transformRequest(w http.ResponseWriter, r *http.Request, transforms []versioning.Transform) bool {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTransformBodyBytes+1))
+	if err != nil || len(body) > maxTransformBodyBytes {
+		v.respHandler.Problem(w, r, response.NewProblem(http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE", "Request body is too large"))
+		return false
+	}
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		transformed, err := versioning.TransformRequest(body, transforms)
+		if err != nil {
+			v.respHandler.Problem(w, r, response.NewProblem(http.StatusBadRequest, "BAD_REQUEST", "Request body must be valid JSON"))
+			return false
+		}
+		body = transformed
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return true
+}
+
+// transformResponse downgrades a buffered JSON response to the client's shape
+func (v *Versioning) transformResponse(rec *cacheRecorder, transforms []versioning.Transform, r *http.Request) {
+	// Errors keep one shape across versions
+	if rec.status >= http.StatusMultipleChoices || !isJSON(rec.header.Get("Content-Type")) || rec.body.Len() == 0 {
+		return
+	}
+
+	transformed, err := versioning.TransformResponse(rec.body.Bytes(), transforms)
+	if err != nil {
+		v.logger.With("path", r.URL.Path).With("error", err.Error()).Warn("Failed to transform response for API version")
+		return
+	}
+	rec.body.Reset()
+	rec.body.Write(transformed)
+}
+
+// isJSON reports whether a Content-Type is JSON, including +json suffixes
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,196 @@
+// gateway/internal/proxy/breaker.go
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+)
+
+// BreakerState is the state of a service's circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed lets requests through
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen fails requests fast until the open timeout has passed
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets one probe request through to test the service
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerStatus describes a circuit breaker for the admin API
+type BreakerStatus struct {
+	Service   string       `json:"service"`
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"consecutive_failures"`
+	LastError string       `json:"last_error,omitempty"`
+	OpenedAt  *time.Time   `json:"opened_at,omitempty"`
+}
+
+type breaker struct {
+	state     BreakerState
+	failures  int
+	lastError string
+	openedAt  time.Time
+	probing   bool
+}
+
+// Breakers holds a circuit breaker per service. Consecutive transport failures,
+// such as timeouts or no responders, open a service's breaker so requests fail
+// fast instead of each waiting out the NATS timeout. Errors reported by the
+// service itself don't count.
+type Breakers struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	breakers    map[string]*breaker
+	logger      log.Logger
+}
+
+// NewBreakers creates breakers opening after threshold consecutive failures
+// and probing the service again after openTimeout
+func NewBreakers(threshold int, openTimeout time.Duration, logger log.Logger) *Breakers {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breakers{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		breakers:    make(map[string]*breaker),
+		logger:      logger.WithLayer("circuit-breaker"),
+	}
+}
+
+type breakersContextKey struct{}
+
+// Middleware makes the breakers available to the proxies serving a request.
+// Nil breakers leave requests unguarded.
+func (b *Breakers) Middleware(next http.Handler) http.Handler {
+	if b == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), breakersContextKey{}, b)))
+	})
+}
+
+// BreakersFromContext returns the breakers guarding a request, if any
+func BreakersFromContext(ctx context.Context) (*Breakers, bool) {
+	b, ok := ctx.Value(breakersContextKey{}).(*Breakers)
+	return b, ok
+}
+
+// ServiceOf returns the service a subject belongs to, its first token
+func ServiceOf(subject string) string {
+	service, _, _ := strings.Cut(subject, ".")
+	return service
+}
+
+// Allow reports whether a request to the service may be sent, and if not, how
+// long until the breaker lets a probe through
+func (b *Breakers) Allow(service string) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br, ok := b.breakers[service]
+	if !ok {
+		return 0, true
+	}
+
+	switch br.state {
+	case BreakerOpen:
+		wait := b.openTimeout - time.Since(br.openedAt)
+		if wait > 0 {
+			return wait, false
+		}
+		br.state = BreakerHalfOpen
+		br.probing = true
+		b.logger.With("service", service).Info("Circuit half-open, probing service")
+		return 0, true
+	case BreakerHalfOpen:
+		// One probe at a time; the rest fail fast until it completes
+		if br.probing {
+			return b.openTimeout, false
+		}
+		br.probing = true
+		return 0, true
+	}
+	return 0, true
+}
+
+// Success records a request the service answered, closing its breaker
+func (b *Breakers) Success(service string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br, ok := b.breakers[service]
+	if !ok {
+		return
+	}
+	if br.state != BreakerClosed {
+		b.logger.With("service", service).Info("Circuit closed, service recovered")
+	}
+	br.state = BreakerClosed
+	br.failures = 0
+	br.probing = false
+}
+
+// Failure records a request the service didn't answer, opening its breaker
+// once the threshold is reached or a probe fails
+func (b *Breakers) Failure(service string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br, ok := b.breakers[service]
+	if !ok {
+		br = &breaker{state: BreakerClosed}
+		b.breakers[service] = br
+	}
+	br.failures++
+	br.lastError = err.Error()
+	br.probing = false
+
+	if br.state == BreakerHalfOpen || (br.state == BreakerClosed && br.failures >= b.threshold) {
+		br.state = BreakerOpen
+		br.openedAt = time.Now()
+		b.logger.With("service", service).
+			With("failures", br.failures).
+			With("error", br.lastError).
+			Warn("Circuit opened, failing requests fast")
+	}
+}
+
+// Status returns the state of every breaker that has seen a failure, sorted by service
+func (b *Breakers) Status() []BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statuses := make([]BreakerStatus, 0, len(b.breakers))
+	for service, br := range b.breakers {
+		status := BreakerStatus{
+			Service:   service,
+			State:     br.state,
+			Failures:  br.failures,
+			LastError: br.lastError,
+		}
+		if br.state == BreakerOpen && time.Since(br.openedAt) >= b.openTimeout {
+			// Reported as it will be treated by the next request
+			status.State = BreakerHalfOpen
+		}
+		if br.state != BreakerClosed {
+			openedAt := br.openedAt.UTC()
+			status.OpenedAt = &openedAt
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Service < statuses[j].Service
+	})
+	return statuses
+}
@@ -3,11 +3,15 @@ package proxy
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/0xsj/fn-go/gateway/internal/versioning"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
@@ -40,6 +44,8 @@ func (p *NATSProxy) WithTimeout(timeout time.Duration) *NATSProxy {
 
 // ProxyRequest proxies an HTTP request to a NATS subject
 func (p *NATSProxy) ProxyRequest(w http.ResponseWriter, r *http.Request, subject string, transformRequest func(r *http.Request) (any, error)) {
+	// Older API versions may be served by their own subjects
+	subject = versioning.SubjectFor(r.Context(), subject)
 	logger := p.logger.With("subject", subject).With("method", r.Method).With("path", r.URL.Path)
 	logger.Info("Proxying request to NATS subject")
 	
@@ -107,7 +113,32 @@ func (p *NATSProxy) ProxyRequest(w http.ResponseWriter, r *http.Request, subject
 	
 	logger.With("request_data", requestData).Debug("Sending NATS request")
 	
+	// An open circuit fails fast instead of waiting out the timeout
+	service := ServiceOf(subject)
+	breakers, guarded := BreakersFromContext(r.Context())
+	if guarded {
+		if wait, ok := breakers.Allow(service); !ok {
+			logger.Warn("Circuit open, rejecting request")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			p.respHandler.Problem(w, r, response.Problem{
+				Type:   "/problems/service-unavailable",
+				Title:  "Service unavailable",
+				Status: http.StatusServiceUnavailable,
+				Detail: "The " + service + " service is unavailable, retry after the period in the Retry-After header",
+				Code:   "SERVICE_UNAVAILABLE",
+			})
+			return
+		}
+	}
+	
 	err = patterns.Request(p.conn, subject, requestData, &result, p.timeout, logger)
+	if guarded {
+		if unanswered(err) {
+			breakers.Failure(service, err)
+		} else {
+			breakers.Success(service)
+		}
+	}
 	
 	// Log the request duration
 	duration := time.Since(start)
@@ -138,7 +169,9 @@ func (p *NATSProxy) ProxyRequest(w http.ResponseWriter, r *http.Request, subject
 		
 		// Try to determine the appropriate error code
 		errorCode := "INTERNAL_SERVER_ERROR"
-		if contains(errorMsg, "not found") {
+		if contains(errorMsg, "too many") {
+			errorCode = "RATE_LIMITED"
+		} else if contains(errorMsg, "not found") {
 			errorCode = "NOT_FOUND"
 		} else if contains(errorMsg, "unauthorized") {
 			errorCode = "UNAUTHORIZED"
@@ -158,10 +191,43 @@ func (p *NATSProxy) ProxyRequest(w http.ResponseWriter, r *http.Request, subject
 		return
 	}
 	
+	// Paginated lists carry their cursors to the client in the meta and Link headers
+	if page, ok := cursorPage(result.Data); ok {
+		p.respHandler.WithCursor(w, r, page)
+		return
+	}
+	
 	// Success response
 	p.respHandler.Success(w, result.Data, "")
 }
 
+// cursorPage recognizes the result of a cursor-paginated list subject
+func cursorPage(data any) (response.CursorPage, bool) {
+	m, ok := data.(map[string]any)
+	if !ok {
+		return response.CursorPage{}, false
+	}
+	items, hasItems := m["items"]
+	cursors, hasCursors := m["cursors"].(map[string]any)
+	if !hasItems || !hasCursors {
+		return response.CursorPage{}, false
+	}
+
+	page := response.CursorPage{Items: items}
+	page.Cursors.Next, _ = cursors["next"].(string)
+	page.Cursors.Prev, _ = cursors["prev"].(string)
+	if limit, ok := cursors["limit"].(float64); ok {
+		page.Cursors.Limit = int(limit)
+	}
+	return page, true
+}
+
+// unanswered reports whether a request failed without reaching the service
+func unanswered(err error) bool {
+	return errors.Is(err, nats.ErrTimeout) || errors.Is(err, nats.ErrNoResponders) ||
+		errors.Is(err, nats.ErrConnectionClosed)
+}
+
 // Helper functions
 func contains(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
@@ -0,0 +1,125 @@
+// gateway/internal/ratelimit/limiter.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit describes a GCRA limit: Rate requests per Period sustained, with up
+// to Burst requests allowed back to back
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// ParseLimit parses a limit written as "rate/period[/burst]", e.g. "100/1m/20".
+// Burst defaults to the rate.
+func ParseLimit(s string) (Limit, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Limit{}, fmt.Errorf("invalid limit %q, expected rate/period[/burst]", s)
+	}
+
+	rate, err := strconv.Atoi(parts[0])
+	if err != nil || rate <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate in limit %q", s)
+	}
+
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("invalid period in limit %q", s)
+	}
+
+	burst := rate
+	if len(parts) == 3 {
+		burst, err = strconv.Atoi(parts[2])
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid burst in limit %q", s)
+		}
+	}
+
+	return Limit{Rate: rate, Period: period, Burst: burst}, nil
+}
+
+// String formats the limit in the form accepted by ParseLimit
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s/%d", l.Rate, l.Period, l.Burst)
+}
+
+// IsZero reports whether the limit is unset
+func (l Limit) IsZero() bool {
+	return l.Rate <= 0 || l.Period <= 0
+}
+
+// emissionInterval is the time between requests at the sustained rate
+func (l Limit) emissionInterval() time.Duration {
+	return l.Period / time.Duration(l.Rate)
+}
+
+// burstOffset is how far ahead of now the theoretical arrival time may run
+func (l Limit) burstOffset() time.Duration {
+	burst := l.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	return l.emissionInterval() * time.Duration(burst)
+}
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// Bucket is one limit a request is checked against, e.g. the caller's tier
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Store applies GCRA to a request's buckets and persists their theoretical
+// arrival times. The request is only charged to its buckets if every one of
+// them allows it, so a rejection doesn't drain the others. Results are in
+// the order of the buckets.
+type Store interface {
+	Allow(ctx context.Context, buckets []Bucket) ([]Result, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// gcra evaluates one request against the stored theoretical arrival time.
+// It returns the result and the new TAT to persist (zero if unchanged).
+func gcra(now, tat time.Time, limit Limit) (Result, time.Time) {
+	interval := limit.emissionInterval()
+	offset := limit.burstOffset()
+
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-offset)
+
+	result := Result{Limit: limit.Burst}
+	if now.Before(allowAt) {
+		result.Allowed = false
+		result.Remaining = 0
+		result.RetryAfter = allowAt.Sub(now)
+		result.ResetAfter = tat.Sub(now)
+		return result, time.Time{}
+	}
+
+	result.Allowed = true
+	result.Remaining = int((offset - newTAT.Sub(now)) / interval)
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	result.ResetAfter = newTAT.Sub(now)
+	return result, newTAT
+}
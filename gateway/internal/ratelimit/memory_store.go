@@ -0,0 +1,79 @@
+// gateway/internal/ratelimit/memory_store.go
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps GCRA state in process memory. Idle keys are evicted once
+// their theoretical arrival time has passed, so memory is bounded by active clients.
+type MemoryStore struct {
+	mu         sync.Mutex
+	tats       map[string]time.Time
+	lastSweep  time.Time
+	sweepEvery time.Duration
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tats:       make(map[string]time.Time),
+		lastSweep:  time.Now(),
+		sweepEvery: time.Minute,
+	}
+}
+
+// Allow implements Store
+func (s *MemoryStore) Allow(ctx context.Context, buckets []Bucket) ([]Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	results := make([]Result, len(buckets))
+	newTATs := make([]time.Time, len(buckets))
+	allowed := true
+	for i, b := range buckets {
+		results[i], newTATs[i] = gcra(now, s.tats[b.Key], b.Limit)
+		allowed = allowed && results[i].Allowed
+	}
+
+	if allowed {
+		for i, b := range buckets {
+			s.tats[b.Key] = newTATs[i]
+		}
+	}
+	return results, nil
+}
+
+// Reset implements Store
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.tats, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of tracked keys
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tats)
+}
+
+// sweep drops keys whose bucket has fully drained; callers hold the lock
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepEvery {
+		return
+	}
+	for key, tat := range s.tats {
+		if !tat.After(now) {
+			delete(s.tats, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,150 @@
+// gateway/internal/ratelimit/redis_store.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/db"
+	"github.com/0xsj/fn-go/pkg/common/log"
+)
+
+// gcraScript runs GCRA atomically in Redis using the server clock, so all
+// gateway replicas share one view of each bucket. Each key takes its emission
+// interval and burst offset from the arguments, in microseconds, and the new
+// arrival times are only written if every bucket allows the request.
+const gcraScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local results = {}
+local new_tats = {}
+local allowed = true
+for i, key in ipairs(KEYS) do
+  local interval = tonumber(ARGV[2 * i - 1])
+  local offset = tonumber(ARGV[2 * i])
+  local tat = now
+  local stored = redis.call('GET', key)
+  if stored then
+    tat = math.max(tonumber(stored), now)
+  end
+  local new_tat = tat + interval
+  local allow_at = new_tat - offset
+  if now < allow_at then
+    allowed = false
+    results[i] = {0, 0, allow_at - now, tat - now}
+  else
+    new_tats[i] = new_tat
+    results[i] = {1, math.floor((offset - (new_tat - now)) / interval), 0, new_tat - now}
+  end
+end
+if allowed then
+  for i, key in ipairs(KEYS) do
+    redis.call('SET', key, new_tats[i], 'PX', math.ceil((new_tats[i] - now) / 1000))
+  end
+end
+return results
+`
+
+// RedisStore keeps GCRA state in Redis
+type RedisStore struct {
+	client *db.RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a new Redis-backed store
+func NewRedisStore(client *db.RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Allow implements Store
+func (s *RedisStore) Allow(ctx context.Context, buckets []Bucket) ([]Result, error) {
+	keys := make([]string, len(buckets))
+	args := make([]any, 0, 2*len(buckets))
+	for i, b := range buckets {
+		keys[i] = s.prefix + b.Key
+		args = append(args, b.Limit.emissionInterval().Microseconds(), b.Limit.burstOffset().Microseconds())
+	}
+
+	reply, err := s.client.Eval(ctx, gcraScript, keys, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, ok := reply.([]any)
+	if !ok || len(replies) != len(buckets) {
+		return nil, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+
+	results := make([]Result, len(buckets))
+	for i, r := range replies {
+		values, ok := r.([]any)
+		if !ok || len(values) != 4 {
+			return nil, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+		}
+
+		ints := make([]int64, len(values))
+		for j, v := range values {
+			n, ok := v.(int64)
+			if !ok {
+				return nil, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+			}
+			ints[j] = n
+		}
+
+		results[i] = Result{
+			Allowed:    ints[0] == 1,
+			Limit:      buckets[i].Limit.Burst,
+			Remaining:  int(ints[1]),
+			RetryAfter: time.Duration(ints[2]) * time.Microsecond,
+			ResetAfter: time.Duration(ints[3]) * time.Microsecond,
+		}
+	}
+	return results, nil
+}
+
+// Reset implements Store
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key)
+}
+
+// FallbackStore uses a primary store and falls back to a secondary one when
+// the primary errors, so a Redis outage degrades to per-replica limiting
+// instead of failing every request
+type FallbackStore struct {
+	primary   Store
+	secondary Store
+	logger    log.Logger
+}
+
+// NewFallbackStore creates a new fallback store
+func NewFallbackStore(primary, secondary Store, logger log.Logger) *FallbackStore {
+	return &FallbackStore{
+		primary:   primary,
+		secondary: secondary,
+		logger:    logger,
+	}
+}
+
+// Allow implements Store
+func (s *FallbackStore) Allow(ctx context.Context, buckets []Bucket) ([]Result, error) {
+	results, err := s.primary.Allow(ctx, buckets)
+	if err == nil {
+		return results, nil
+	}
+
+	s.logger.With("error", err.Error()).Warn("Primary rate limit store failed, using fallback")
+	return s.secondary.Allow(ctx, buckets)
+}
+
+// Reset implements Store
+func (s *FallbackStore) Reset(ctx context.Context, key string) error {
+	primaryErr := s.primary.Reset(ctx, key)
+	if err := s.secondary.Reset(ctx, key); err != nil {
+		return err
+	}
+	return primaryErr
+}
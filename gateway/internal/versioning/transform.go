@@ -0,0 +1,96 @@
+// gateway/internal/versioning/transform.go
+package versioning
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Transform rewrites one JSON object in place
+type Transform func(obj map[string]any)
+
+// Rename moves a field to a new name
+func Rename(from, to string) Transform {
+	return func(obj map[string]any) {
+		if value, ok := obj[from]; ok {
+			delete(obj, from)
+			obj[to] = value
+		}
+	}
+}
+
+// Remove drops fields
+func Remove(fields ...string) Transform {
+	return func(obj map[string]any) {
+		for _, field := range fields {
+			delete(obj, field)
+		}
+	}
+}
+
+// Default sets a field that is missing or null
+func Default(field string, value any) Transform {
+	return func(obj map[string]any) {
+		if current, ok := obj[field]; !ok || current == nil {
+			obj[field] = value
+		}
+	}
+}
+
+// Apply runs transforms over every object in value: value itself if it is an
+// object, or each object element if it is an array
+func Apply(value any, transforms []Transform) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, transform := range transforms {
+			transform(v)
+		}
+	case []any:
+		for _, item := range v {
+			if obj, ok := item.(map[string]any); ok {
+				for _, transform := range transforms {
+					transform(obj)
+				}
+			}
+		}
+	}
+}
+
+// TransformRequest applies transforms to a JSON request body
+func TransformRequest(body []byte, transforms []Transform) ([]byte, error) {
+	value, err := decode(body)
+	if err != nil {
+		return nil, err
+	}
+	Apply(value, transforms)
+	return json.Marshal(value)
+}
+
+// TransformResponse applies transforms to a JSON response body. Success
+// envelopes are unwrapped so the transforms see the data they carry.
+func TransformResponse(body []byte, transforms []Transform) ([]byte, error) {
+	value, err := decode(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if envelope, ok := value.(map[string]any); ok {
+		if _, isEnvelope := envelope["success"]; isEnvelope {
+			Apply(envelope["data"], transforms)
+			return json.Marshal(envelope)
+		}
+	}
+	Apply(value, transforms)
+	return json.Marshal(value)
+}
+
+// decode parses JSON keeping numbers exact, so IDs and amounts survive the round trip
+func decode(body []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,171 @@
+// gateway/internal/versioning/version.go
+package versioning
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// namePattern matches version names such as v1 and v2
+var namePattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// Version is one version of the public API. Handlers always speak the current
+// shape of pkg/models; a version describes how its clients differ from it.
+type Version struct {
+	// Name is the path prefix and header value selecting the version, e.g. v1
+	Name string
+	// Deprecated is when the version was deprecated; zero if it isn't
+	Deprecated time.Time
+	// Sunset is when the version stops being served; zero if not scheduled
+	Sunset time.Time
+	// DocsURL documents the migration away from a deprecated version
+	DocsURL string
+	// Subjects maps a NATS subject to the one serving this version, for
+	// changes a transform can't express
+	Subjects map[string]string
+	// Rules transform requests and responses on matching routes
+	Rules []Rule
+}
+
+// Rule transforms the bodies of requests to a route
+type Rule struct {
+	// Method restricts the rule to one method; empty matches any
+	Method string
+	// Pattern is the route path, e.g. /incidents/{id}, with a trailing * matching any suffix
+	Pattern string
+	// Request upgrades a client's request body to the current shape
+	Request []Transform
+	// Response downgrades the current response data to the client's shape
+	Response []Transform
+}
+
+// IsDeprecated reports whether the version has been deprecated
+func (v *Version) IsDeprecated() bool {
+	return !v.Deprecated.IsZero()
+}
+
+// IsSunset reports whether the version is no longer served at t
+func (v *Version) IsSunset(t time.Time) bool {
+	return !v.Sunset.IsZero() && !t.Before(v.Sunset)
+}
+
+// Subject returns the subject serving this version in place of subject
+func (v *Version) Subject(subject string) string {
+	if mapped, ok := v.Subjects[subject]; ok {
+		return mapped
+	}
+	return subject
+}
+
+// Transforms returns the request and response transforms for a route
+func (v *Version) Transforms(method, path string) (request, response []Transform) {
+	for _, rule := range v.Rules {
+		if rule.Method != "" && rule.Method != method {
+			continue
+		}
+		if matchPath(rule.Pattern, path) {
+			request = append(request, rule.Request...)
+			response = append(response, rule.Response...)
+		}
+	}
+	return request, response
+}
+
+// Registry holds the versions served by the gateway
+type Registry struct {
+	versions map[string]*Version
+	names    []string
+	def      *Version
+}
+
+// NewRegistry creates a registry serving unversioned requests with defaultVersion
+func NewRegistry(defaultVersion string, versions ...*Version) (*Registry, error) {
+	r := &Registry{versions: make(map[string]*Version, len(versions))}
+	for _, v := range versions {
+		if !namePattern.MatchString(v.Name) {
+			return nil, fmt.Errorf("invalid API version name %q", v.Name)
+		}
+		if _, exists := r.versions[v.Name]; exists {
+			return nil, fmt.Errorf("duplicate API version %q", v.Name)
+		}
+		r.versions[v.Name] = v
+		r.names = append(r.names, v.Name)
+	}
+
+	def, ok := r.versions[defaultVersion]
+	if !ok {
+		return nil, fmt.Errorf("default API version %q is not registered", defaultVersion)
+	}
+	r.def = def
+	return r, nil
+}
+
+// Get returns a version by name
+func (r *Registry) Get(name string) (*Version, bool) {
+	v, ok := r.versions[name]
+	return v, ok
+}
+
+// Default returns the version serving unversioned requests
+func (r *Registry) Default() *Version {
+	return r.def
+}
+
+// Names returns the registered version names in registration order
+func (r *Registry) Names() []string {
+	return append([]string{}, r.names...)
+}
+
+// IsVersionName reports whether s has the form of a version name
+func IsVersionName(s string) bool {
+	return namePattern.MatchString(s)
+}
+
+type versionContextKey struct{}
+
+// WithVersion returns a context carrying the request's API version
+func WithVersion(ctx context.Context, v *Version) context.Context {
+	return context.WithValue(ctx, versionContextKey{}, v)
+}
+
+// FromContext returns the API version of a request
+func FromContext(ctx context.Context) (*Version, bool) {
+	v, ok := ctx.Value(versionContextKey{}).(*Version)
+	return v, ok
+}
+
+// SubjectFor maps subject to the one serving the request's API version
+func SubjectFor(ctx context.Context, subject string) string {
+	if v, ok := FromContext(ctx); ok {
+		return v.Subject(subject)
+	}
+	return subject
+}
+
+// matchPath matches a path against a route pattern
+func matchPath(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, part := range patternParts {
+		if part == "*" && i == len(patternParts)-1 {
+			return true
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if pathParts[i] == "" {
+				return false
+			}
+			continue
+		}
+		if part != pathParts[i] {
+			return false
+		}
+	}
+	return len(pathParts) == len(patternParts)
+}
@@ -0,0 +1,20 @@
+// gateway/internal/versioning/versions.go
+package versioning
+
+// Versions declares the API versions served by the gateway. v1 is the current
+// shape of pkg/models. When a model changes incompatibly, add the next version
+// and give the older ones the rules and subjects keeping their clients working,
+// for example:
+//
+//	{Name: "v1", Rules: []Rule{{
+//		Pattern:  "/incidents/*",
+//		Request:  []Transform{Rename("reporter_id", "reported_by")},
+//		Response: []Transform{Rename("reported_by", "reporter_id")},
+//	}}}
+//
+// Deprecation and sunset dates are configured per deployment.
+func Versions() []*Version {
+	return []*Version{
+		{Name: "v1"},
+	}
+}
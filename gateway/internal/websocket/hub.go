@@ -1 +1,176 @@
-package websocket
\ No newline at end of file
+// gateway/internal/websocket/hub.go
+package websocket
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/google/uuid"
+)
+
+// CloseGoingAway is the close code sent when the gateway shuts down (RFC 6455 7.4.1)
+const CloseGoingAway = 1001
+
+// ClosePolicyViolation is the close code sent to a connection dropped by an operator
+const ClosePolicyViolation = 1008
+
+// closeWriteTimeout bounds writing a close frame to one connection
+const closeWriteTimeout = time.Second
+
+// Hub tracks the upgraded WebSocket connections of the gateway. Hijacked
+// connections are invisible to http.Server, whose Shutdown neither waits for
+// nor closes them, so the hub closes them itself on shutdown.
+type Hub struct {
+	mu       sync.Mutex
+	conns    map[net.Conn]*Connection
+	shutdown bool
+	logger   log.Logger
+}
+
+// Connection describes an open WebSocket connection
+type Connection struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id,omitempty"`
+	RemoteAddr  string    `json:"remote_addr"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// NewHub creates a new WebSocket hub
+func NewHub(logger log.Logger) *Hub {
+	return &Hub{
+		conns:  make(map[net.Conn]*Connection),
+		logger: logger.WithLayer("websocket-hub"),
+	}
+}
+
+// Add tracks an upgraded connection of a user and returns its ID. It returns
+// false once the hub is shutting down, in which case the caller should close
+// the connection.
+func (h *Hub) Add(conn net.Conn, userID string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.shutdown {
+		return "", false
+	}
+	info := &Connection{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		RemoteAddr:  conn.RemoteAddr().String(),
+		ConnectedAt: time.Now().UTC(),
+	}
+	h.conns[conn] = info
+	return info.ID, true
+}
+
+// Remove stops tracking a connection closed by its handler
+func (h *Hub) Remove(conn net.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, conn)
+}
+
+// Len returns the number of open connections
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+// Connections returns the open connections, oldest first
+func (h *Hub) Connections() []Connection {
+	h.mu.Lock()
+	conns := make([]Connection, 0, len(h.conns))
+	for _, info := range h.conns {
+		conns = append(conns, *info)
+	}
+	h.mu.Unlock()
+
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].ConnectedAt.Before(conns[j].ConnectedAt)
+	})
+	return conns
+}
+
+// Drop closes a connection by ID, sending it a policy violation close frame.
+// It reports whether the connection was open on this hub.
+func (h *Hub) Drop(id string) bool {
+	h.mu.Lock()
+	var target net.Conn
+	for conn, info := range h.conns {
+		if info.ID == id {
+			target = conn
+			delete(h.conns, conn)
+			break
+		}
+	}
+	h.mu.Unlock()
+
+	if target == nil {
+		return false
+	}
+	h.logger.With("connection_id", id).Info("Dropping WebSocket connection")
+	h.close(target, closeFrame(ClosePolicyViolation, "closed by administrator"), time.Now().Add(closeWriteTimeout))
+	return true
+}
+
+// Shutdown sends every connection a Going Away close frame, so clients
+// reconnect to another instance, then closes it
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.shutdown = true
+	conns := make([]net.Conn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.conns = make(map[net.Conn]*Connection)
+	h.mu.Unlock()
+
+	if len(conns) == 0 {
+		return nil
+	}
+	h.logger.With("connections", len(conns)).Info("Closing WebSocket connections")
+
+	deadline := time.Now().Add(closeWriteTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	frame := closeFrame(CloseGoingAway, "server shutting down")
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn net.Conn) {
+			defer wg.Done()
+			h.close(conn, frame, deadline)
+		}(conn)
+	}
+	wg.Wait()
+	return nil
+}
+
+// close sends a close frame, then closes the connection
+func (h *Hub) close(conn net.Conn, frame []byte, deadline time.Time) {
+	conn.SetWriteDeadline(deadline)
+	if _, err := conn.Write(frame); err != nil {
+		h.logger.With("remote_addr", conn.RemoteAddr().String()).
+			With("error", err.Error()).
+			Debug("Failed to send WebSocket close frame")
+	}
+	conn.Close()
+}
+
+// closeFrame builds an unmasked server close frame. The reason must keep the
+// payload within the 125 bytes allowed for control frames.
+func closeFrame(code uint16, reason string) []byte {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, code)
+	payload = append(payload, reason...)
+
+	// FIN set, opcode 0x8 (close)
+	return append([]byte{0x88, byte(len(payload))}, payload...)
+}
@@ -0,0 +1,104 @@
+// gateway/pkg/graphql/ast.go
+package graphql
+
+// Document is a parsed GraphQL request document
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is a query, mutation or subscription definition
+type Operation struct {
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+}
+
+// VariableDefinition declares an operation variable
+type VariableDefinition struct {
+	Name    string
+	Type    TypeRef
+	Default Value
+}
+
+// TypeRef is a type as written in a variable definition, e.g. [ID!]!
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+// String renders the type reference as written
+func (t TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is a field, fragment spread or inline fragment
+type Selection interface {
+	directives() []*Directive
+}
+
+// Field selects a field of the parent object
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    map[string]Value
+	Directives   []*Directive
+	SelectionSet []Selection
+}
+
+// ResponseKey is the alias if given, otherwise the field name
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread includes a named fragment
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+// InlineFragment includes a selection set, optionally for one type only
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+// Fragment is a named fragment definition
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+// Directive annotates a selection, e.g. @include(if: $flag)
+type Directive struct {
+	Name      string
+	Arguments map[string]Value
+}
+
+func (f *Field) directives() []*Directive          { return f.Directives }
+func (f *FragmentSpread) directives() []*Directive { return f.Directives }
+func (f *InlineFragment) directives() []*Directive { return f.Directives }
+
+// Value is an argument literal: nil, bool, int64, float64, string, EnumValue,
+// Variable, []Value or map[string]Value
+type Value any
+
+// Variable references an operation variable
+type Variable string
+
+// EnumValue is an unquoted enum literal
+type EnumValue string
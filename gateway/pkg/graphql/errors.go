@@ -0,0 +1,43 @@
+// gateway/pkg/graphql/errors.go
+package graphql
+
+// Error is a GraphQL error as returned in the "errors" list of a response
+type Error struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// NewError creates an error carrying a machine-readable code in its extensions
+func NewError(message, code string) *Error {
+	return &Error{
+		Message:    message,
+		Extensions: map[string]any{"code": code},
+	}
+}
+
+// WithExtension returns a copy of the error with an extra extension member
+func (e *Error) WithExtension(key string, value any) *Error {
+	extensions := make(map[string]any, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		extensions[k] = v
+	}
+	extensions[key] = value
+
+	err := *e
+	err.Extensions = extensions
+	return &err
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Error codes used in extensions for request errors
+const (
+	ErrCodeParseFailed      = "GRAPHQL_PARSE_FAILED"
+	ErrCodeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	ErrCodeQueryTooComplex  = "QUERY_TOO_COMPLEX"
+	ErrCodeInternal         = "INTERNAL_SERVER_ERROR"
+)
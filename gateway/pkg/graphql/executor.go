@@ -0,0 +1,358 @@
+// gateway/pkg/graphql/executor.go
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response. Data is absent when the request failed
+// before execution, e.g. on a syntax or validation error.
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Executor runs queries against a schema within fixed limits
+type Executor struct {
+	schema *Schema
+	limits Limits
+}
+
+// NewExecutor creates a new executor
+func NewExecutor(schema *Schema, limits Limits) *Executor {
+	return &Executor{schema: schema, limits: limits}
+}
+
+// Execute parses, validates and executes a request. Sibling fields and list
+// items are resolved concurrently so loaders can batch their keys.
+func (e *Executor) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return requestError(err, ErrCodeParseFailed)
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return requestError(err, ErrCodeValidationFailed)
+	}
+	if op.Type != "query" {
+		return requestError(fmt.Errorf("%s operations are not supported", op.Type), ErrCodeValidationFailed)
+	}
+
+	vars, err := e.schema.coerceVariables(op, req.Variables)
+	if err != nil {
+		return requestError(err, ErrCodeValidationFailed)
+	}
+
+	if errs := e.schema.validate(doc, op, vars, e.limits); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	ex := &execution{doc: doc, vars: vars}
+	data, _ := ex.selectionSet(ctx, e.schema.Query, nil, op.SelectionSet, nil)
+
+	sort.SliceStable(ex.errors, func(i, j int) bool {
+		return fmt.Sprint(ex.errors[i].Path) < fmt.Sprint(ex.errors[j].Path)
+	})
+	return &Response{Data: data, Errors: ex.errors}
+}
+
+func requestError(err error, code string) *Response {
+	if gqlErr, ok := err.(*Error); ok {
+		if gqlErr.Extensions == nil {
+			gqlErr = gqlErr.WithExtension("code", code)
+		}
+		return &Response{Errors: []*Error{gqlErr}}
+	}
+	return &Response{Errors: []*Error{NewError(err.Error(), code)}}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, fmt.Errorf("must provide operation name if query contains multiple operations")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation named %q", name)
+}
+
+// execution holds the state of one request
+type execution struct {
+	doc    *Document
+	vars   map[string]any
+	mu     sync.Mutex
+	errors []*Error
+}
+
+func (ex *execution) addError(err error, path []any) {
+	gqlErr, ok := err.(*Error)
+	if !ok {
+		gqlErr = &Error{Message: err.Error()}
+	}
+	located := *gqlErr
+	located.Path = path
+
+	ex.mu.Lock()
+	ex.errors = append(ex.errors, &located)
+	ex.mu.Unlock()
+}
+
+// selectionSet executes the selections on obj. Like completeValue, the second
+// result is false when the value is null because of an error.
+func (ex *execution) selectionSet(ctx context.Context, obj *Object, source any, selections []Selection, path []any) (any, bool) {
+	keys, grouped := ex.collectFields(obj, selections, make(map[string]bool))
+
+	values := make([]any, len(keys))
+	oks := make([]bool, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, fields []*Field) {
+			defer wg.Done()
+			values[i], oks[i] = ex.field(ctx, obj, source, fields, appendPath(path, fields[0].ResponseKey()))
+		}(i, grouped[key])
+	}
+	wg.Wait()
+
+	for i, key := range keys {
+		if !oks[i] && isNonNull(fieldType(obj, grouped[key][0])) {
+			return nil, false
+		}
+	}
+	return &orderedMap{keys: keys, values: values}, true
+}
+
+// collectFields groups the selected fields by response key, in query order
+func (ex *execution) collectFields(obj *Object, selections []Selection, visited map[string]bool) ([]string, map[string][]*Field) {
+	var keys []string
+	grouped := make(map[string][]*Field)
+
+	var collect func(selections []Selection)
+	collect = func(selections []Selection) {
+		for _, selection := range selections {
+			if !shouldInclude(selection.directives(), ex.vars) {
+				continue
+			}
+			switch sel := selection.(type) {
+			case *Field:
+				key := sel.ResponseKey()
+				if _, seen := grouped[key]; !seen {
+					keys = append(keys, key)
+				}
+				grouped[key] = append(grouped[key], sel)
+			case *FragmentSpread:
+				fragment, ok := ex.doc.Fragments[sel.Name]
+				if !ok || visited[sel.Name] || fragment.TypeCondition != obj.Name {
+					continue
+				}
+				visited[sel.Name] = true
+				collect(fragment.SelectionSet)
+			case *InlineFragment:
+				if sel.TypeCondition != "" && sel.TypeCondition != obj.Name {
+					continue
+				}
+				collect(sel.SelectionSet)
+			}
+		}
+	}
+	collect(selections)
+	return keys, grouped
+}
+
+func fieldType(obj *Object, field *Field) Type {
+	if field.Name == "__typename" {
+		return NonNullOf(String)
+	}
+	def, _ := obj.Field(field.Name)
+	return def.Type
+}
+
+// field resolves and completes one field of obj
+func (ex *execution) field(ctx context.Context, obj *Object, source any, fields []*Field, path []any) (any, bool) {
+	field := fields[0]
+	if field.Name == "__typename" {
+		return obj.Name, true
+	}
+	def, _ := obj.Field(field.Name)
+
+	args, err := coerceArguments(def, field.Arguments, ex.vars)
+	if err != nil {
+		ex.addError(err, path)
+		return nil, false
+	}
+
+	result, err := ex.resolve(ctx, def, source, args, path)
+	if err != nil {
+		ex.addError(err, path)
+		return nil, false
+	}
+	return ex.completeValue(ctx, def.Type, fields, result, path)
+}
+
+// resolve calls the resolver, turning a panic into a field error
+func (ex *execution) resolve(ctx context.Context, def *FieldDefinition, source any, args map[string]any, path []any) (result any, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if def.Resolve == nil {
+		if m, ok := source.(map[string]any); ok {
+			return m[def.Name], nil
+		}
+		return nil, nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, NewError(fmt.Sprintf("Internal error resolving field %q", def.Name), ErrCodeInternal)
+		}
+	}()
+	return def.Resolve(ResolveParams{Context: ctx, Source: source, Args: args, Path: path})
+}
+
+// completeValue shapes a resolved value according to its type. The second
+// result is false when the value is null because of an error, which callers
+// propagate to the nearest nullable parent.
+func (ex *execution) completeValue(ctx context.Context, t Type, fields []*Field, result any, path []any) (any, bool) {
+	if nonNull, ok := t.(*NonNull); ok {
+		value, ok := ex.completeValue(ctx, nonNull.OfType, fields, result, path)
+		if !ok {
+			return nil, false
+		}
+		if value == nil {
+			ex.addError(fmt.Errorf("Cannot return null for non-nullable field"), path)
+			return nil, false
+		}
+		return value, true
+	}
+
+	if isNil(result) {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		value, err := t.Serialize(result)
+		if err != nil {
+			ex.addError(err, path)
+			return nil, false
+		}
+		return value, true
+	case *Object:
+		var selections []Selection
+		for _, field := range fields {
+			selections = append(selections, field.SelectionSet...)
+		}
+		return ex.selectionSet(ctx, t, result, selections, path)
+	case *List:
+		return ex.completeList(ctx, t, fields, result, path)
+	}
+
+	ex.addError(fmt.Errorf("unsupported type %s", t), path)
+	return nil, false
+}
+
+func (ex *execution) completeList(ctx context.Context, t *List, fields []*Field, result any, path []any) (any, bool) {
+	rv := reflect.ValueOf(result)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		ex.addError(fmt.Errorf("expected a list for type %s", t), path)
+		return nil, false
+	}
+
+	items := make([]any, rv.Len())
+	oks := make([]bool, rv.Len())
+	complete := func(i int) {
+		items[i], oks[i] = ex.completeValue(ctx, t.OfType, fields, rv.Index(i).Interface(), appendPath(path, i))
+	}
+
+	if _, isObject := namedType(t.OfType).(*Object); isObject {
+		var wg sync.WaitGroup
+		for i := range items {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				complete(i)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range items {
+			complete(i)
+		}
+	}
+
+	for _, ok := range oks {
+		if !ok && isNonNull(t.OfType) {
+			return nil, false
+		}
+	}
+	return items, true
+}
+
+func appendPath(path []any, segment any) []any {
+	next := make([]any, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, segment)
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(value); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// orderedMap is a response object that keeps fields in query order
+type orderedMap struct {
+	keys   []string
+	values []any
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,148 @@
+// gateway/pkg/graphql/executor_test.go
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// executeJSON runs a query and returns the response as JSON
+func executeJSON(t *testing.T, executor *Executor, req Request) string {
+	t.Helper()
+
+	body, err := json.Marshal(executor.Execute(context.Background(), req))
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	return string(body)
+}
+
+func TestExecute(t *testing.T) {
+	executor := NewExecutor(testSchema(t), Limits{MaxDepth: 5, MaxComplexity: 1000, ListSize: 10})
+
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{
+			name: "fields in query order",
+			req:  Request{Query: `{ incident(id: "1") { title id } }`},
+			want: `{"data":{"incident":{"title":"Fire","id":"1"}}}`,
+		},
+		{
+			name: "aliases and typename",
+			req:  Request{Query: `{ fire: incident(id: "1") { __typename name: title } flood: incident(id: "2") { name: title } }`},
+			want: `{"data":{"fire":{"__typename":"Incident","name":"Fire"},"flood":{"name":"Flood"}}}`,
+		},
+		{
+			name: "variables",
+			req:  Request{Query: `query($id: ID!) { incident(id: $id) { id } }`, Variables: map[string]any{"id": "2"}},
+			want: `{"data":{"incident":{"id":"2"}}}`,
+		},
+		{
+			name: "nested objects and nulls",
+			req:  Request{Query: `{ incidents { id reporter { name } } }`},
+			want: `{"data":{"incidents":[{"id":"1","reporter":{"name":"Ada"}},{"id":"2","reporter":null}]}}`,
+		},
+		{
+			name: "fragments",
+			req: Request{Query: `{ incident(id: "1") { ...Basic ... on Incident { reporter { id } } } }
+				fragment Basic on Incident { id title }`},
+			want: `{"data":{"incident":{"id":"1","title":"Fire","reporter":{"id":"u1"}}}}`,
+		},
+		{
+			name: "skip and include",
+			req: Request{
+				Query:     `query($full: Boolean!) { incident(id: "1") { id title @include(if: $full) reporter @skip(if: true) { id } } }`,
+				Variables: map[string]any{"full": false},
+			},
+			want: `{"data":{"incident":{"id":"1"}}}`,
+		},
+		{
+			name: "missing object is null",
+			req:  Request{Query: `{ incident(id: "9") { id } }`},
+			want: `{"data":{"incident":null}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := executeJSON(t, executor, tt.req); got != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteNullPropagation(t *testing.T) {
+	executor := NewExecutor(testSchema(t), Limits{})
+
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{
+			// summary is non-null, so its error nulls the nullable incident
+			name: "to the nearest nullable field",
+			req:  Request{Query: `{ incident(id: "1") { id summary } }`},
+			want: `{"data":{"incident":null},"errors":[{"message":"summary unavailable","path":["incident","summary"]}]}`,
+		},
+		{
+			// incidents and its items are non-null, so the error reaches the root
+			name: "through non-null lists",
+			req:  Request{Query: `{ incidents { summary } }`},
+			want: `{"errors":[{"message":"summary unavailable","path":["incidents",0,"summary"]},{"message":"summary unavailable","path":["incidents",1,"summary"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := executeJSON(t, executor, tt.req); got != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRequestErrors(t *testing.T) {
+	executor := NewExecutor(testSchema(t), Limits{})
+
+	tests := []struct {
+		name string
+		req  Request
+		code string
+	}{
+		{"syntax error", Request{Query: `{ incident(id: "1") { id }`}, ErrCodeParseFailed},
+		{"mutation", Request{Query: `mutation { incident(id: "1") { id } }`}, ErrCodeValidationFailed},
+		{"missing variable", Request{Query: `query($id: ID!) { incident(id: $id) { id } }`}, ErrCodeValidationFailed},
+		{"ambiguous operation", Request{Query: `query A { incidents { id } } query B { incidents { id } }`}, ErrCodeValidationFailed},
+		{"unknown operation", Request{Query: `query A { incidents { id } }`, OperationName: "B"}, ErrCodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := executor.Execute(context.Background(), tt.req)
+			if resp.Data != nil {
+				t.Errorf("got data %v, want none", resp.Data)
+			}
+			if code := errorCode(resp); code != tt.code {
+				t.Errorf("got errors %v, want code %s", resp.Errors, tt.code)
+			}
+		})
+	}
+}
+
+func TestExecuteSelectsNamedOperation(t *testing.T) {
+	executor := NewExecutor(testSchema(t), Limits{})
+
+	got := executeJSON(t, executor, Request{
+		Query:         `query A { incident(id: "1") { id } } query B { incident(id: "2") { id } }`,
+		OperationName: "B",
+	})
+	if want := `{"data":{"incident":{"id":"2"}}}`; got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+}
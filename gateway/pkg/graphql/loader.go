@@ -0,0 +1,145 @@
+// gateway/pkg/graphql/loader.go
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchFunc loads values for a batch of distinct keys. It returns one value
+// and one error per key, in key order; a nil errs slice means no errors.
+type BatchFunc func(ctx context.Context, keys []string) (values []any, errs []error)
+
+// Loader coalesces the loads issued while a query resolves. Keys requested
+// within the wait window are fetched in one batch, and every key is fetched
+// at most once per loader. Create one loader per request, since results are
+// cached for the loader's lifetime and may depend on the caller.
+type Loader struct {
+	fetch    BatchFunc
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[string]*loadResult
+	pending *loadBatch
+}
+
+type loadResult struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+type loadBatch struct {
+	ctx     context.Context
+	keys    []string
+	results []*loadResult
+}
+
+// NewLoader creates a loader; maxBatch of zero leaves batches unbounded
+func NewLoader(fetch BatchFunc, wait time.Duration, maxBatch int) *Loader {
+	return &Loader{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		results:  make(map[string]*loadResult),
+	}
+}
+
+// Load returns the value for key, joining the pending batch
+func (l *Loader) Load(ctx context.Context, key string) (any, error) {
+	l.mu.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loadResult{done: make(chan struct{})}
+		l.results[key] = result
+		l.enqueue(ctx, key, result)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-result.done:
+		return result.value, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Prime caches a value already known, e.g. an item returned by a list, so a
+// later Load for its key doesn't trigger a fetch
+func (l *Loader) Prime(key string, value any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.results[key]; ok {
+		return
+	}
+	result := &loadResult{done: make(chan struct{}), value: value}
+	close(result.done)
+	l.results[key] = result
+}
+
+// enqueue adds a key to the pending batch; l.mu must be held
+func (l *Loader) enqueue(ctx context.Context, key string, result *loadResult) {
+	if l.pending == nil {
+		batch := &loadBatch{ctx: ctx}
+		l.pending = batch
+		time.AfterFunc(l.wait, func() {
+			l.mu.Lock()
+			if l.pending == batch {
+				l.pending = nil
+			}
+			l.mu.Unlock()
+			l.dispatch(batch)
+		})
+	}
+
+	batch := l.pending
+	batch.keys = append(batch.keys, key)
+	batch.results = append(batch.results, result)
+
+	if l.maxBatch > 0 && len(batch.keys) >= l.maxBatch {
+		l.pending = nil
+		go l.dispatch(batch)
+	}
+}
+
+// dispatch runs a batch once, whichever of the timer or size limit fires first
+func (l *Loader) dispatch(batch *loadBatch) {
+	l.mu.Lock()
+	keys := batch.keys
+	results := batch.results
+	batch.keys, batch.results = nil, nil
+	l.mu.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+
+	values, errs := l.safeFetch(batch.ctx, keys)
+	for i, result := range results {
+		switch {
+		case len(errs) > i && errs[i] != nil:
+			result.err = errs[i]
+		case len(values) > i:
+			result.value = values[i]
+		default:
+			result.err = fmt.Errorf("loader returned no value for key %q", keys[i])
+		}
+		close(result.done)
+	}
+}
+
+func (l *Loader) safeFetch(ctx context.Context, keys []string) (values []any, errs []error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("loader panicked: %v", r)
+			values, errs = nil, make([]error, len(keys))
+			for i := range errs {
+				errs[i] = err
+			}
+		}
+	}()
+	return l.fetch(ctx, keys)
+}
@@ -0,0 +1,95 @@
+// gateway/pkg/graphql/parser_test.go
+package graphql
+
+import (
+	"testing"
+)
+
+func TestParseOperation(t *testing.T) {
+	doc, err := Parse(`query Incident($id: ID!, $tags: [String!] = ["a"]) {
+		incident(id: $id) { id title: name ...Reporter }
+	}
+	fragment Reporter on Incident { reporter { id } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(doc.Operations) != 1 {
+		t.Fatalf("got %d operations, want 1", len(doc.Operations))
+	}
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "Incident" {
+		t.Errorf("got operation %s %q, want query %q", op.Type, op.Name, "Incident")
+	}
+
+	if len(op.Variables) != 2 {
+		t.Fatalf("got %d variables, want 2", len(op.Variables))
+	}
+	if got := op.Variables[0].Type.String(); got != "ID!" {
+		t.Errorf("got $id of type %s, want ID!", got)
+	}
+	if got := op.Variables[1].Type.String(); got != "[String!]" {
+		t.Errorf("got $tags of type %s, want [String!]", got)
+	}
+	if op.Variables[1].Default == nil {
+		t.Error("$tags lost its default value")
+	}
+
+	incident, ok := op.SelectionSet[0].(*Field)
+	if !ok || incident.Name != "incident" {
+		t.Fatalf("got selection %#v, want field incident", op.SelectionSet[0])
+	}
+	if got, ok := incident.Arguments["id"].(Variable); !ok || got != "id" {
+		t.Errorf("got argument id %#v, want variable $id", incident.Arguments["id"])
+	}
+	if len(incident.SelectionSet) != 3 {
+		t.Fatalf("got %d selections on incident, want 3", len(incident.SelectionSet))
+	}
+	if title := incident.SelectionSet[1].(*Field); title.ResponseKey() != "title" || title.Name != "name" {
+		t.Errorf("got aliased field %q: %q, want title: name", title.ResponseKey(), title.Name)
+	}
+	if spread, ok := incident.SelectionSet[2].(*FragmentSpread); !ok || spread.Name != "Reporter" {
+		t.Errorf("got selection %#v, want spread of Reporter", incident.SelectionSet[2])
+	}
+
+	fragment, ok := doc.Fragments["Reporter"]
+	if !ok {
+		t.Fatal("fragment Reporter not parsed")
+	}
+	if fragment.TypeCondition != "Incident" {
+		t.Errorf("got type condition %q, want Incident", fragment.TypeCondition)
+	}
+}
+
+func TestParseShorthandQuery(t *testing.T) {
+	doc, err := Parse(`{ me { id } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if op := doc.Operations[0]; op.Type != "query" || op.Name != "" {
+		t.Errorf("got operation %s %q, want anonymous query", op.Type, op.Name)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"empty document", ``},
+		{"fragments only", `fragment F on Query { id }`},
+		{"unclosed selection set", `{ me { id }`},
+		{"missing argument value", `{ incident(id: ) { id } }`},
+		{"unterminated string", `{ incident(id: "1) { id } }`},
+		{"duplicate fragment", `{ ...F } fragment F on Query { id } fragment F on Query { id }`},
+		{"type definition", `type Query { id: ID }`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.query); err == nil {
+				t.Errorf("Parse(%q) succeeded, want an error", tt.query)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// gateway/pkg/graphql/reflect.go
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Builder derives object types from Go structs, following their JSON tags so
+// the GraphQL schema mirrors the REST payloads. Nested structs become their
+// own object types, named after the Go type and shared between parents.
+type Builder struct {
+	objects map[reflect.Type]*Object
+}
+
+// NewBuilder creates a new struct builder
+func NewBuilder() *Builder {
+	return &Builder{objects: make(map[reflect.Type]*Object)}
+}
+
+// Object returns the object type for the struct type of sample. Each JSON field
+// becomes a camelCase GraphQL field, e.g. reported_by becomes reportedBy.
+func (b *Builder) Object(name, description string, sample any) *Object {
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if obj, ok := b.objects[t]; ok {
+		return obj
+	}
+
+	obj := NewObject(name, description)
+	b.objects[t] = obj
+	b.addFields(obj, t, nil)
+	return obj
+}
+
+func (b *Builder) addFields(obj *Object, t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			b.addFields(obj, sf.Type, fieldIndex)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		jsonName := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = sf.Name
+		}
+
+		fieldType := b.typeOf(sf.Type, jsonName)
+		if fieldType == nil {
+			continue
+		}
+		obj.AddField(&FieldDefinition{
+			Name:    camelCase(jsonName),
+			Type:    fieldType,
+			Resolve: structFieldResolver(fieldIndex),
+		})
+	}
+}
+
+// typeOf maps a Go type to a GraphQL type; unsupported kinds return nil
+func (b *Builder) typeOf(t reflect.Type, jsonName string) Type {
+	if t == timeType {
+		return Time
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.typeOf(t.Elem(), jsonName)
+	case reflect.String:
+		if jsonName == "id" {
+			return NonNullOf(ID)
+		}
+		if strings.HasSuffix(jsonName, "_id") {
+			return ID
+		}
+		return String
+	case reflect.Bool:
+		return Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Int
+	case reflect.Float32, reflect.Float64:
+		return Float
+	case reflect.Map, reflect.Interface:
+		return JSON
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return String
+		}
+		if elem := b.typeOf(t.Elem(), ""); elem != nil {
+			return ListOf(elem)
+		}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return nil
+		}
+		return b.Object(t.Name(), "", reflect.New(t).Interface())
+	}
+	return nil
+}
+
+// structFieldResolver reads a struct field from the source, following pointers
+func structFieldResolver(index []int) ResolveFunc {
+	return func(p ResolveParams) (any, error) {
+		v := reflect.ValueOf(p.Source)
+		for _, i := range index {
+			for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+				if v.IsNil() {
+					return nil, nil
+				}
+				v = v.Elem()
+			}
+			if v.Kind() != reflect.Struct {
+				return nil, nil
+			}
+			v = v.Field(i)
+		}
+		if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil() {
+			return nil, nil
+		}
+		return v.Interface(), nil
+	}
+}
+
+// camelCase converts a snake_case JSON name to camelCase
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
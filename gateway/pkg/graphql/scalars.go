@@ -0,0 +1,189 @@
+// gateway/pkg/graphql/scalars.go
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Scalar is a leaf type
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved value to its JSON representation
+	Serialize func(value any) (any, error)
+	// ParseValue converts an input value (literal or variable) to its Go form.
+	// It must accept that form too, as variables are coerced when the request
+	// arrives and again as the arguments they're passed in.
+	ParseValue func(value any) (any, error)
+	builtin    bool
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Built-in scalars
+var (
+	ID = &Scalar{
+		Name:    "ID",
+		builtin: true,
+		Serialize: func(value any) (any, error) {
+			return serializeString(value)
+		},
+		ParseValue: func(value any) (any, error) {
+			switch v := value.(type) {
+			case string:
+				return v, nil
+			case int64:
+				return strconv.FormatInt(v, 10), nil
+			case float64:
+				if v == math.Trunc(v) {
+					return strconv.FormatInt(int64(v), 10), nil
+				}
+			}
+			return nil, fmt.Errorf("ID cannot represent value: %v", value)
+		},
+	}
+
+	String = &Scalar{
+		Name:    "String",
+		builtin: true,
+		Serialize: func(value any) (any, error) {
+			return serializeString(value)
+		},
+		ParseValue: func(value any) (any, error) {
+			if v, ok := value.(string); ok {
+				return v, nil
+			}
+			return nil, fmt.Errorf("String cannot represent a non string value: %v", value)
+		},
+	}
+
+	Int = &Scalar{
+		Name:    "Int",
+		builtin: true,
+		Serialize: func(value any) (any, error) {
+			rv := reflect.ValueOf(value)
+			switch rv.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return rv.Int(), nil
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return rv.Uint(), nil
+			case reflect.Float32, reflect.Float64:
+				if f := rv.Float(); f == math.Trunc(f) {
+					return int64(f), nil
+				}
+			}
+			return nil, fmt.Errorf("Int cannot represent value: %v", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			switch v := value.(type) {
+			case int:
+				return v, nil
+			case int64:
+				if v >= math.MinInt32 && v <= math.MaxInt32 {
+					return int(v), nil
+				}
+			case float64:
+				if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+					return int(v), nil
+				}
+			}
+			return nil, fmt.Errorf("Int cannot represent value: %v", value)
+		},
+	}
+
+	Float = &Scalar{
+		Name:    "Float",
+		builtin: true,
+		Serialize: func(value any) (any, error) {
+			rv := reflect.ValueOf(value)
+			switch rv.Kind() {
+			case reflect.Float32, reflect.Float64:
+				return rv.Float(), nil
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return float64(rv.Int()), nil
+			}
+			return nil, fmt.Errorf("Float cannot represent value: %v", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			switch v := value.(type) {
+			case int64:
+				return float64(v), nil
+			case float64:
+				return v, nil
+			}
+			return nil, fmt.Errorf("Float cannot represent value: %v", value)
+		},
+	}
+
+	Boolean = &Scalar{
+		Name:    "Boolean",
+		builtin: true,
+		Serialize: func(value any) (any, error) {
+			if rv := reflect.ValueOf(value); rv.Kind() == reflect.Bool {
+				return rv.Bool(), nil
+			}
+			return nil, fmt.Errorf("Boolean cannot represent value: %v", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			if v, ok := value.(bool); ok {
+				return v, nil
+			}
+			return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %v", value)
+		},
+	}
+
+	Time = &Scalar{
+		Name:        "Time",
+		Description: "An RFC 3339 timestamp",
+		Serialize: func(value any) (any, error) {
+			if t, ok := value.(time.Time); ok {
+				return t.Format(time.RFC3339Nano), nil
+			}
+			return nil, fmt.Errorf("Time cannot represent value: %v", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			if t, ok := value.(time.Time); ok {
+				return t, nil
+			}
+			if s, ok := value.(string); ok {
+				if t, err := time.Parse(time.RFC3339, s); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("Time cannot represent value: %v", value)
+		},
+	}
+
+	JSON = &Scalar{
+		Name:        "JSON",
+		Description: "Arbitrary JSON",
+		Serialize: func(value any) (any, error) {
+			if _, err := json.Marshal(value); err != nil {
+				return nil, fmt.Errorf("JSON cannot represent value: %v", err)
+			}
+			return value, nil
+		},
+		ParseValue: func(value any) (any, error) {
+			return value, nil
+		},
+	}
+)
+
+var builtinScalars = []*Scalar{ID, String, Int, Float, Boolean, Time, JSON}
+
+// serializeString accepts strings and named string types such as enums in pkg/models
+func serializeString(value any) (any, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	}
+	return nil, fmt.Errorf("cannot represent value as a string: %v", value)
+}
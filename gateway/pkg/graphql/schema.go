@@ -0,0 +1,240 @@
+// gateway/pkg/graphql/schema.go
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Type is a GraphQL output or input type
+type Type interface {
+	String() string
+}
+
+// Object is an object type with an ordered set of fields
+type Object struct {
+	Name        string
+	Description string
+	fields      map[string]*FieldDefinition
+	order       []string
+}
+
+// NewObject creates an empty object type
+func NewObject(name, description string) *Object {
+	return &Object{
+		Name:        name,
+		Description: description,
+		fields:      make(map[string]*FieldDefinition),
+	}
+}
+
+// AddField adds a field, replacing any existing field with the same name
+func (o *Object) AddField(field *FieldDefinition) *Object {
+	if _, exists := o.fields[field.Name]; !exists {
+		o.order = append(o.order, field.Name)
+	}
+	o.fields[field.Name] = field
+	return o
+}
+
+// Field returns a field by name
+func (o *Object) Field(name string) (*FieldDefinition, bool) {
+	field, ok := o.fields[name]
+	return field, ok
+}
+
+// Fields returns the fields in declaration order
+func (o *Object) Fields() []*FieldDefinition {
+	fields := make([]*FieldDefinition, 0, len(o.order))
+	for _, name := range o.order {
+		fields = append(fields, o.fields[name])
+	}
+	return fields
+}
+
+func (o *Object) String() string { return o.Name }
+
+// List wraps a type in a list
+type List struct {
+	OfType Type
+}
+
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+// NonNull marks a type as never null
+type NonNull struct {
+	OfType Type
+}
+
+func (n *NonNull) String() string { return n.OfType.String() + "!" }
+
+// ListOf is shorthand for &List{OfType: t}
+func ListOf(t Type) *List { return &List{OfType: t} }
+
+// NonNullOf is shorthand for &NonNull{OfType: t}
+func NonNullOf(t Type) *NonNull { return &NonNull{OfType: t} }
+
+// ResolveFunc produces the value of a field
+type ResolveFunc func(p ResolveParams) (any, error)
+
+// ResolveParams is passed to resolvers
+type ResolveParams struct {
+	Context context.Context
+	// Source is the value of the parent object
+	Source any
+	// Args holds the coerced field arguments, with defaults applied
+	Args map[string]any
+	// Path is the response path of the field
+	Path []any
+}
+
+// FieldDefinition declares a field of an object type
+type FieldDefinition struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	Resolve     ResolveFunc
+	// Cost is the field's weight in the query complexity; zero counts as one
+	Cost int
+}
+
+// Argument declares a field argument
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     any
+}
+
+// Schema is an executable schema with a query root
+type Schema struct {
+	Query *Object
+	types map[string]Type
+}
+
+// NewSchema creates a schema, checking that type names are unique
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{Query: query, types: make(map[string]Type)}
+	for _, scalar := range builtinScalars {
+		s.types[scalar.Name] = scalar
+	}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// collect registers a named type and everything reachable from it
+func (s *Schema) collect(t Type) error {
+	switch t := t.(type) {
+	case *List:
+		return s.collect(t.OfType)
+	case *NonNull:
+		return s.collect(t.OfType)
+	case *Scalar:
+		if existing, ok := s.types[t.Name]; ok && existing != t {
+			return fmt.Errorf("graphql: duplicate type %q", t.Name)
+		}
+		s.types[t.Name] = t
+	case *Object:
+		if existing, ok := s.types[t.Name]; ok {
+			if existing != t {
+				return fmt.Errorf("graphql: duplicate type %q", t.Name)
+			}
+			return nil
+		}
+		s.types[t.Name] = t
+		for _, field := range t.Fields() {
+			if err := s.collect(field.Type); err != nil {
+				return err
+			}
+			for _, arg := range field.Args {
+				if err := s.collect(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Type looks up a named type
+func (s *Schema) Type(name string) (Type, bool) {
+	t, ok := s.types[name]
+	return t, ok
+}
+
+// SDL renders the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	var sb strings.Builder
+	sb.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n")
+
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			if t.builtin {
+				continue
+			}
+			sb.WriteString("\n")
+			writeDescription(&sb, "", t.Description)
+			sb.WriteString("scalar " + t.Name + "\n")
+		case *Object:
+			sb.WriteString("\n")
+			writeDescription(&sb, "", t.Description)
+			sb.WriteString("type " + t.Name + " {\n")
+			for _, field := range t.Fields() {
+				writeDescription(&sb, "  ", field.Description)
+				sb.WriteString("  " + field.Name)
+				if len(field.Args) > 0 {
+					args := make([]string, 0, len(field.Args))
+					for _, arg := range field.Args {
+						def := arg.Name + ": " + arg.Type.String()
+						if arg.Default != nil {
+							def += " = " + fmt.Sprintf("%v", arg.Default)
+						}
+						args = append(args, def)
+					}
+					sb.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				sb.WriteString(": " + field.Type.String() + "\n")
+			}
+			sb.WriteString("}\n")
+		}
+	}
+	return sb.String()
+}
+
+func writeDescription(sb *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	sb.WriteString(indent + `"""` + description + `"""` + "\n")
+}
+
+// namedType strips list and non-null wrappers
+func namedType(t Type) Type {
+	for {
+		switch wrapped := t.(type) {
+		case *List:
+			t = wrapped.OfType
+		case *NonNull:
+			t = wrapped.OfType
+		default:
+			return t
+		}
+	}
+}
+
+func isNonNull(t Type) bool {
+	_, ok := t.(*NonNull)
+	return ok
+}
@@ -0,0 +1,240 @@
+// gateway/pkg/graphql/validate.go
+package graphql
+
+import (
+	"fmt"
+)
+
+// Limits bounds the cost of a query before it's executed
+type Limits struct {
+	// MaxDepth is the deepest allowed field nesting; zero disables the check
+	MaxDepth int
+	// MaxComplexity is the highest allowed complexity score; zero disables the check
+	MaxComplexity int
+	// ListSize is the assumed length of a list field without a limit argument
+	ListSize int
+}
+
+// validator checks an operation against the schema and computes its cost
+type validator struct {
+	schema   *Schema
+	doc      *Document
+	vars     map[string]any
+	limits   Limits
+	errors   []*Error
+	maxDepth int
+	visiting map[string]bool
+	// fragments holds the cost of each fragment already walked, so a fragment
+	// spread many times, directly or through other fragments, is walked once
+	fragments map[string]fragmentCost
+}
+
+// fragmentCost is the complexity of a fragment and how many levels of fields
+// it nests below the selection it's spread into
+type fragmentCost struct {
+	complexity int
+	depth      int
+}
+
+func (s *Schema) validate(doc *Document, op *Operation, vars map[string]any, limits Limits) []*Error {
+	v := &validator{
+		schema:    s,
+		doc:       doc,
+		vars:      vars,
+		limits:    limits,
+		visiting:  make(map[string]bool),
+		fragments: make(map[string]fragmentCost),
+	}
+	complexity := v.selectionSet(s.Query, op.SelectionSet, 1)
+
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	if limits.MaxDepth > 0 && v.maxDepth > limits.MaxDepth {
+		return []*Error{NewError(fmt.Sprintf("Query depth %d exceeds the maximum of %d", v.maxDepth, limits.MaxDepth), ErrCodeQueryTooComplex)}
+	}
+	if limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		// The walk stops counting once past the limit, so the total isn't known
+		return []*Error{NewError(fmt.Sprintf("Query complexity exceeds the maximum of %d", limits.MaxComplexity), ErrCodeQueryTooComplex)}
+	}
+	return nil
+}
+
+func (v *validator) fail(format string, args ...any) {
+	v.errors = append(v.errors, NewError(fmt.Sprintf(format, args...), ErrCodeValidationFailed))
+}
+
+// selectionSet validates selections on obj at the given depth and returns
+// their complexity. The walk stops once the complexity passes the limit, as
+// the query is rejected whatever the remaining selections cost.
+func (v *validator) selectionSet(obj *Object, selections []Selection, depth int) int {
+	complexity := 0
+	for _, selection := range selections {
+		if v.exceeded(complexity) {
+			break
+		}
+		switch sel := selection.(type) {
+		case *Field:
+			complexity = v.add(complexity, v.field(obj, sel, depth))
+		case *FragmentSpread:
+			complexity = v.add(complexity, v.fragmentSpread(obj, sel, depth))
+		case *InlineFragment:
+			if sel.TypeCondition != "" && !v.typeCondition(obj, sel.TypeCondition) {
+				continue
+			}
+			complexity = v.add(complexity, v.selectionSet(obj, sel.SelectionSet, depth))
+		}
+	}
+	return complexity
+}
+
+// fragmentSpread validates a named fragment spread on obj and returns its
+// complexity. A fragment's fields are the same wherever it's spread, so it's
+// only walked the first time.
+func (v *validator) fragmentSpread(obj *Object, spread *FragmentSpread, depth int) int {
+	fragment, ok := v.doc.Fragments[spread.Name]
+	if !ok {
+		v.fail("Unknown fragment %q", spread.Name)
+		return 0
+	}
+	if v.visiting[spread.Name] {
+		v.fail("Cannot spread fragment %q within itself", spread.Name)
+		return 0
+	}
+	if !v.typeCondition(obj, fragment.TypeCondition) {
+		return 0
+	}
+
+	if cost, ok := v.fragments[spread.Name]; ok {
+		v.reach(depth + cost.depth)
+		return cost.complexity
+	}
+
+	outer := v.maxDepth
+	v.maxDepth = depth - 1
+	v.visiting[spread.Name] = true
+	complexity := v.selectionSet(obj, fragment.SelectionSet, depth)
+	delete(v.visiting, spread.Name)
+
+	v.fragments[spread.Name] = fragmentCost{complexity: complexity, depth: v.maxDepth - depth}
+	v.reach(outer)
+	return complexity
+}
+
+// reach records that fields were selected at the given depth
+func (v *validator) reach(depth int) {
+	if depth > v.maxDepth {
+		v.maxDepth = depth
+	}
+}
+
+// exceeded reports whether a complexity is past the limit
+func (v *validator) exceeded(complexity int) bool {
+	return v.limits.MaxComplexity > 0 && complexity > v.limits.MaxComplexity
+}
+
+// add sums complexities, stopping just past the limit so large multipliers
+// can't overflow
+func (v *validator) add(a, b int) int {
+	if v.exceeded(a) || v.exceeded(b) || v.exceeded(a+b) {
+		return v.limits.MaxComplexity + 1
+	}
+	return a + b
+}
+
+// multiply scales a complexity, stopping just past the limit like add
+func (v *validator) multiply(n, complexity int) int {
+	if v.limits.MaxComplexity > 0 && complexity > 0 && n > v.limits.MaxComplexity/complexity {
+		return v.limits.MaxComplexity + 1
+	}
+	return n * complexity
+}
+
+// typeCondition reports whether a fragment on typeName can apply to obj
+func (v *validator) typeCondition(obj *Object, typeName string) bool {
+	t, ok := v.schema.types[typeName]
+	if !ok {
+		v.fail("Unknown type %q", typeName)
+		return false
+	}
+	if t != obj {
+		v.fail("Fragment cannot be spread here as objects of type %q can never be of type %q", obj.Name, typeName)
+		return false
+	}
+	return true
+}
+
+func (v *validator) field(obj *Object, field *Field, depth int) int {
+	v.reach(depth)
+	if field.Name == "__typename" {
+		if len(field.SelectionSet) > 0 {
+			v.fail("Field \"__typename\" must not have a selection since type \"String!\" has no subfields")
+		}
+		return 0
+	}
+
+	def, ok := obj.Field(field.Name)
+	if !ok {
+		v.fail("Cannot query field %q on type %q", field.Name, obj.Name)
+		return 0
+	}
+
+	for name := range field.Arguments {
+		if !hasArgument(def, name) {
+			v.fail("Unknown argument %q on field \"%s.%s\"", name, obj.Name, field.Name)
+		}
+	}
+	args, err := coerceArguments(def, field.Arguments, v.vars)
+	if err != nil {
+		v.fail("Field \"%s.%s\": %v", obj.Name, field.Name, err)
+	}
+
+	cost := def.Cost
+	if cost == 0 {
+		cost = 1
+	}
+
+	child, isObject := namedType(def.Type).(*Object)
+	if !isObject {
+		if len(field.SelectionSet) > 0 {
+			v.fail("Field %q must not have a selection since type %q has no subfields", field.Name, def.Type)
+		}
+		return cost
+	}
+	if len(field.SelectionSet) == 0 {
+		v.fail("Field %q of type %q must have a selection of subfields", field.Name, def.Type)
+		return cost
+	}
+
+	childComplexity := v.selectionSet(child, field.SelectionSet, depth+1)
+	return v.add(cost, v.multiply(v.listMultiplier(def.Type, args), childComplexity))
+}
+
+// listMultiplier estimates how many items a list field returns, from its
+// limit or first argument when present
+func (v *validator) listMultiplier(t Type, args map[string]any) int {
+	if nonNull, ok := t.(*NonNull); ok {
+		t = nonNull.OfType
+	}
+	if _, ok := t.(*List); !ok {
+		return 1
+	}
+	for _, name := range []string{"limit", "first"} {
+		if n, ok := args[name].(int); ok && n > 0 {
+			return n
+		}
+	}
+	if v.limits.ListSize > 0 {
+		return v.limits.ListSize
+	}
+	return 1
+}
+
+func hasArgument(def *FieldDefinition, name string) bool {
+	for _, arg := range def.Args {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
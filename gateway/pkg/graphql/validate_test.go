@@ -0,0 +1,226 @@
+// gateway/pkg/graphql/validate_test.go
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSchema builds a small incident schema resolving from fixed data
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	user := NewObject("User", "")
+	user.AddField(&FieldDefinition{Name: "id", Type: NonNullOf(ID)})
+	user.AddField(&FieldDefinition{Name: "name", Type: String})
+
+	incident := NewObject("Incident", "")
+	incident.AddField(&FieldDefinition{Name: "id", Type: NonNullOf(ID)})
+	incident.AddField(&FieldDefinition{Name: "title", Type: String})
+	incident.AddField(&FieldDefinition{Name: "reporter", Type: user})
+	incident.AddField(&FieldDefinition{
+		Name: "related",
+		Type: NonNullOf(ListOf(NonNullOf(incident))),
+		Args: []*Argument{{Name: "limit", Type: Int}},
+		Resolve: func(p ResolveParams) (any, error) {
+			return []any{}, nil
+		},
+	})
+	incident.AddField(&FieldDefinition{
+		Name: "summary",
+		Type: NonNullOf(String),
+		Resolve: func(p ResolveParams) (any, error) {
+			return nil, fmt.Errorf("summary unavailable")
+		},
+	})
+
+	incidents := []any{
+		map[string]any{"id": "1", "title": "Fire", "reporter": map[string]any{"id": "u1", "name": "Ada"}},
+		map[string]any{"id": "2", "title": "Flood"},
+	}
+
+	query := NewObject("Query", "")
+	query.AddField(&FieldDefinition{
+		Name: "incident",
+		Type: incident,
+		Args: []*Argument{{Name: "id", Type: NonNullOf(ID)}},
+		Resolve: func(p ResolveParams) (any, error) {
+			for _, item := range incidents {
+				if item.(map[string]any)["id"] == p.Args["id"] {
+					return item, nil
+				}
+			}
+			return nil, nil
+		},
+	})
+	query.AddField(&FieldDefinition{
+		Name: "incidents",
+		Type: NonNullOf(ListOf(NonNullOf(incident))),
+		Args: []*Argument{{Name: "limit", Type: Int}},
+		Cost: 5,
+		Resolve: func(p ResolveParams) (any, error) {
+			return incidents, nil
+		},
+	})
+
+	schema, err := NewSchema(query)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	return schema
+}
+
+// errorCode returns the code of a response's first error
+func errorCode(resp *Response) string {
+	if len(resp.Errors) == 0 {
+		return ""
+	}
+	code, _ := resp.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+func TestValidateRejectsInvalidQueries(t *testing.T) {
+	executor := NewExecutor(testSchema(t), Limits{})
+
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"unknown field", `{ incident(id: "1") { severity } }`, `Cannot query field "severity" on type "Incident"`},
+		{"unknown argument", `{ incident(id: "1", kind: "fire") { id } }`, `Unknown argument "kind"`},
+		{"missing subfields", `{ incident(id: "1") }`, `must have a selection of subfields`},
+		{"subfields on scalar", `{ incident(id: "1") { title { id } } }`, `must not have a selection`},
+		{"unknown fragment", `{ incident(id: "1") { ...Missing } }`, `Unknown fragment "Missing"`},
+		{"fragment on wrong type", `{ incident(id: "1") { ...F } } fragment F on User { id }`, `can never be of type "User"`},
+		{"fragment cycle", `{ incident(id: "1") { ...A } } fragment A on Incident { ...B } fragment B on Incident { ...A }`, `within itself`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := executor.Execute(context.Background(), Request{Query: tt.query})
+			if resp.Data != nil {
+				t.Errorf("got data %v, want none", resp.Data)
+			}
+			if code := errorCode(resp); code != ErrCodeValidationFailed {
+				t.Fatalf("got errors %v, want a validation failure", resp.Errors)
+			}
+			if !strings.Contains(resp.Errors[0].Message, tt.message) {
+				t.Errorf("got error %q, want it to mention %q", resp.Errors[0].Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestValidateDepth(t *testing.T) {
+	executor := NewExecutor(testSchema(t), Limits{MaxDepth: 3})
+
+	tests := []struct {
+		name  string
+		query string
+		ok    bool
+	}{
+		{"within the limit", `{ incident(id: "1") { reporter { id } } }`, true},
+		{"past the limit", `{ incident(id: "1") { related { reporter { id } } } }`, false},
+		{"past the limit in a fragment", `{ incident(id: "1") { related { ...R } } } fragment R on Incident { reporter { id } }`, false},
+		{
+			"fragment spread again deeper",
+			`{ incident(id: "1") { ...R related { ...R } } } fragment R on Incident { reporter { id } }`,
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := executor.Execute(context.Background(), Request{Query: tt.query})
+			if tt.ok {
+				if len(resp.Errors) > 0 {
+					t.Errorf("got errors %v, want none", resp.Errors)
+				}
+				return
+			}
+			if code := errorCode(resp); code != ErrCodeQueryTooComplex {
+				t.Errorf("got errors %v, want the query rejected as too deep", resp.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateComplexity(t *testing.T) {
+	executor := NewExecutor(testSchema(t), Limits{MaxComplexity: 100, ListSize: 10})
+
+	tests := []struct {
+		name  string
+		query string
+		ok    bool
+	}{
+		// incidents costs 5 plus 10 assumed items of 2 fields
+		{"within the limit", `{ incidents { id title } }`, true},
+		{"limit argument shrinks the estimate", `{ incidents(limit: 2) { id related(limit: 2) { id title } } }`, true},
+		{"nested lists", `{ incidents { related { id } } }`, false},
+		{"limit argument from a variable", `query($n: Int) { incidents(limit: $n) { id title } }`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := executor.Execute(context.Background(), Request{Query: tt.query, Variables: map[string]any{"n": float64(1000)}})
+			if tt.ok {
+				if len(resp.Errors) > 0 {
+					t.Errorf("got errors %v, want none", resp.Errors)
+				}
+				return
+			}
+			if code := errorCode(resp); code != ErrCodeQueryTooComplex {
+				t.Errorf("got errors %v, want the query rejected as too complex", resp.Errors)
+			}
+		})
+	}
+}
+
+// fragmentChain builds a query where each fragment spreads the next twice, so
+// walking every spread would visit the last fragment 2^n times
+func fragmentChain(n int, leaf string) string {
+	var sb strings.Builder
+	sb.WriteString(`{ incident(id: "1") { ...F0 } }`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, " fragment F%d on Incident { ...F%d related { ...F%d } }", i, i+1, i+1)
+	}
+	fmt.Fprintf(&sb, " fragment F%d on Incident { %s }", n, leaf)
+	return sb.String()
+}
+
+func TestValidateFragmentChain(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		query  string
+	}{
+		{"complexity limit", Limits{MaxComplexity: 1000}, fragmentChain(64, "id title")},
+		{"depth limit", Limits{MaxDepth: 10}, fragmentChain(64, "id title")},
+		// Without limits the chain is still walked once per fragment
+		{"no limits", Limits{}, fragmentChain(64, "id ...Missing")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor(testSchema(t), tt.limits)
+
+			done := make(chan *Response, 1)
+			go func() {
+				done <- executor.Execute(context.Background(), Request{Query: tt.query})
+			}()
+
+			select {
+			case resp := <-done:
+				if resp.Data != nil || len(resp.Errors) == 0 {
+					t.Errorf("got data %v and errors %v, want the query rejected", resp.Data, resp.Errors)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("validating the fragment chain did not finish")
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// gateway/pkg/graphql/values.go
+package graphql
+
+import (
+	"fmt"
+)
+
+// literal converts a parsed value to a plain Go value, substituting variables.
+// The second result is false if the value is a variable that wasn't provided.
+func literal(value Value, vars map[string]any) (any, bool) {
+	switch v := value.(type) {
+	case Variable:
+		val, ok := vars[string(v)]
+		return val, ok
+	case []Value:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i], _ = literal(item, vars)
+		}
+		return list, true
+	case map[string]Value:
+		object := make(map[string]any, len(v))
+		for name, item := range v {
+			if val, ok := literal(item, vars); ok {
+				object[name] = val
+			}
+		}
+		return object, true
+	}
+	return value, true
+}
+
+// coerceInput converts a plain value to the input type
+func coerceInput(t Type, value any) (any, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return coerceInput(nonNull.OfType, value)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]any)
+		if !ok {
+			// A single value is accepted where a list is expected
+			item, err := coerceInput(t.OfType, value)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		coerced := make([]any, len(items))
+		for i, item := range items {
+			var err error
+			if coerced[i], err = coerceInput(t.OfType, item); err != nil {
+				return nil, err
+			}
+		}
+		return coerced, nil
+	case *Scalar:
+		return t.ParseValue(value)
+	}
+	return nil, fmt.Errorf("type %s cannot be used as an input", t)
+}
+
+// coerceArguments applies defaults and coerces the arguments of a field
+func coerceArguments(def *FieldDefinition, arguments map[string]Value, vars map[string]any) (map[string]any, error) {
+	args := make(map[string]any, len(def.Args))
+	for _, arg := range def.Args {
+		raw, provided := arguments[arg.Name]
+		var value any
+		if provided {
+			value, provided = literal(raw, vars)
+		}
+		if !provided {
+			if arg.Default != nil {
+				args[arg.Name] = arg.Default
+				continue
+			}
+			if isNonNull(arg.Type) {
+				return nil, fmt.Errorf("argument %q of type %s is required", arg.Name, arg.Type)
+			}
+			continue
+		}
+
+		coerced, err := coerceInput(arg.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %v", arg.Name, err)
+		}
+		args[arg.Name] = coerced
+	}
+	return args, nil
+}
+
+// coerceVariables coerces the request variables against the operation's definitions
+func (s *Schema) coerceVariables(op *Operation, values map[string]any) (map[string]any, error) {
+	vars := make(map[string]any, len(op.Variables))
+	for _, def := range op.Variables {
+		t, err := s.inputType(def.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		value, provided := values[def.Name]
+		if !provided && def.Default != nil {
+			value, provided = literal(def.Default, nil)
+		}
+		if !provided {
+			if def.Type.NonNull {
+				return nil, fmt.Errorf("variable \"$%s\" of required type %s was not provided", def.Name, def.Type)
+			}
+			continue
+		}
+
+		coerced, err := coerceInput(t, value)
+		if err != nil {
+			return nil, fmt.Errorf("variable \"$%s\" got invalid value: %v", def.Name, err)
+		}
+		vars[def.Name] = coerced
+	}
+	return vars, nil
+}
+
+// inputType resolves a variable's type reference against the schema
+func (s *Schema) inputType(ref TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := s.inputType(*ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = ListOf(elem)
+	} else {
+		named, ok := s.types[ref.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q", ref.Name)
+		}
+		if _, ok := named.(*Scalar); !ok {
+			return nil, fmt.Errorf("variable type %s must be an input type", ref.Name)
+		}
+		t = named
+	}
+	if ref.NonNull {
+		t = NonNullOf(t)
+	}
+	return t, nil
+}
+
+// shouldInclude evaluates the @skip and @include directives
+func shouldInclude(directives []*Directive, vars map[string]any) bool {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			continue
+		}
+		condition, _ := literal(directive.Arguments["if"], vars)
+		flag, _ := condition.(bool)
+		if directive.Name == "skip" && flag {
+			return false
+		}
+		if directive.Name == "include" && !flag {
+			return false
+		}
+	}
+	return true
+}
@@ -1 +1,240 @@
-package health
\ No newline at end of file
+// gateway/pkg/health/checker.go
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+)
+
+// Status is the health of the gateway or one of its dependencies
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// CheckFunc probes a dependency, returning an error if it is unhealthy
+type CheckFunc func(ctx context.Context) error
+
+// DegradedError is returned by checks whose dependency responds but reports
+// reduced functionality; it degrades the report without failing readiness
+type DegradedError struct {
+	Reason string
+}
+
+func (e *DegradedError) Error() string {
+	return e.Reason
+}
+
+// Dependency is something the gateway needs to serve traffic
+type Dependency struct {
+	Name string
+	// Critical dependencies make the gateway not ready when they are down
+	Critical bool
+	Check    CheckFunc
+}
+
+// Result is the outcome of checking one dependency
+type Result struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the aggregated health of the gateway
+type Report struct {
+	Status       Status    `json:"status"`
+	Ready        bool      `json:"ready"`
+	Version      string    `json:"version"`
+	Time         time.Time `json:"time"`
+	Uptime       string    `json:"uptime"`
+	Dependencies []Result  `json:"dependencies"`
+}
+
+// Checker runs dependency checks concurrently. Reports are reused for a short
+// interval so frequent probes from several sources don't flood the services.
+type Checker struct {
+	mu           sync.RWMutex
+	dependencies []Dependency
+	version      string
+	timeout      time.Duration
+	reuseFor     time.Duration
+	started      time.Time
+	last         *Report
+	logger       log.Logger
+}
+
+// NewChecker creates a health checker. Each dependency check is bounded by timeout.
+func NewChecker(version string, timeout time.Duration, logger log.Logger) *Checker {
+	return &Checker{
+		version:  version,
+		timeout:  timeout,
+		reuseFor: time.Second,
+		started:  time.Now(),
+		logger:   logger.WithLayer("health-checker"),
+	}
+}
+
+// Register adds dependencies to check
+func (c *Checker) Register(dependencies ...Dependency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dependencies = append(c.dependencies, dependencies...)
+	c.last = nil
+}
+
+// Check returns the current health report
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	if c.last != nil && time.Since(c.last.Time) < c.reuseFor {
+		report := *c.last
+		c.mu.RUnlock()
+		return report
+	}
+	dependencies := make([]Dependency, len(c.dependencies))
+	copy(dependencies, c.dependencies)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(dependencies))
+	var wg sync.WaitGroup
+	for i, dep := range dependencies {
+		wg.Add(1)
+		go func(i int, dep Dependency) {
+			defer wg.Done()
+			results[i] = c.run(ctx, dep)
+		}(i, dep)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	report := Report{
+		Status:       StatusUp,
+		Ready:        true,
+		Version:      c.version,
+		Time:         time.Now(),
+		Uptime:       time.Since(c.started).Round(time.Second).String(),
+		Dependencies: results,
+	}
+	for _, result := range results {
+		switch {
+		case result.Status == StatusUp:
+		case result.Status == StatusDown && result.Critical:
+			report.Status = StatusDown
+			report.Ready = false
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+
+	c.mu.Lock()
+	c.last = &report
+	c.mu.Unlock()
+
+	return report
+}
+
+// run checks a single dependency within the checker timeout
+func (c *Checker) run(ctx context.Context, dep Dependency) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- dep.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Name:      dep.Name,
+		Status:    StatusUp,
+		Critical:  dep.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	var degraded *DegradedError
+	if errors.As(err, &degraded) {
+		result.Status = StatusDegraded
+		result.Error = err.Error()
+	} else if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+		c.logger.With("dependency", dep.Name).
+			With("critical", dep.Critical).
+			With("error", err.Error()).
+			Warn("Dependency health check failed")
+	}
+	return result
+}
+
+// NATSCheck verifies the NATS connection is established and responsive
+func NATSCheck(conn *nats.Conn) CheckFunc {
+	return func(ctx context.Context) error {
+		if !conn.IsConnected() {
+			return fmt.Errorf("connection status %s", conn.Status())
+		}
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			deadline = time.Now().Add(2 * time.Second)
+		}
+		return conn.FlushTimeout(time.Until(deadline))
+	}
+}
+
+// ServiceCheck calls a service's service.<name>.health subject
+func ServiceCheck(conn *nats.Conn, name string, logger log.Logger) CheckFunc {
+	subject := "service." + name + ".health"
+	return func(ctx context.Context) error {
+		timeout := 2 * time.Second
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+
+		var resp struct {
+			Success bool `json:"success"`
+			Data    struct {
+				Status string `json:"status"`
+			} `json:"data"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := patterns.Request(conn, subject, struct{}{}, &resp, timeout, logger); err != nil {
+			return err
+		}
+		if !resp.Success {
+			if resp.Error != nil {
+				return fmt.Errorf("%s reported an error: %s", name, resp.Error.Message)
+			}
+			return fmt.Errorf("%s reported an error", name)
+		}
+		switch resp.Data.Status {
+		case "", "ok", string(StatusUp):
+			return nil
+		case string(StatusDegraded):
+			return &DegradedError{Reason: name + " reported degraded status"}
+		default:
+			return fmt.Errorf("%s reported status %q", name, resp.Data.Status)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// gateway/pkg/metrics/metrics.go
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// APIVersionRequestCounter counts requests per API version and status class
+	APIVersionRequestCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_api_version_requests_total",
+		Help: "The total number of requests per API version",
+	}, []string{"version", "deprecated", "status"})
+
+	// APIVersionLastRequestGauge records when each API version was last used,
+	// showing when an old version has no clients left
+	APIVersionLastRequestGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gateway_api_version_last_request_timestamp_seconds",
+		Help: "Unix time of the most recent request per API version",
+	}, []string{"version"})
+
+	// AuditRecordsDroppedCounter counts audit records lost before reaching
+	// monitoring-service, by reason
+	AuditRecordsDroppedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_audit_records_dropped_total",
+		Help: "The total number of audit records dropped before being published",
+	}, []string{"reason"})
+)
@@ -46,6 +46,11 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// ShutdownTimeout bounds the whole graceful shutdown, including draining
+	ShutdownTimeout time.Duration
+	// ShutdownDelay keeps serving after readiness fails so load balancers
+	// stop routing new work here before the listeners close
+	ShutdownDelay time.Duration
 }
 
 // NATSConfig provides common NATS configuration
@@ -138,11 +143,13 @@ func LoadServerConfigFromProvider(provider Provider, prefix string) ServerConfig
 	}
 	
 	return ServerConfig{
-		Port:         provider.GetIntDefault(prefix+"PORT", 8080),
-		Host:         provider.Get(prefix + "HOST"),
-		ReadTimeout:  provider.GetDurationDefault(prefix+"READ_TIMEOUT", 10*time.Second),
-		WriteTimeout: provider.GetDurationDefault(prefix+"WRITE_TIMEOUT", 10*time.Second),
-		IdleTimeout:  provider.GetDurationDefault(prefix+"IDLE_TIMEOUT", 30*time.Second),
+		Port:            provider.GetIntDefault(prefix+"PORT", 8080),
+		Host:            provider.Get(prefix + "HOST"),
+		ReadTimeout:     provider.GetDurationDefault(prefix+"READ_TIMEOUT", 10*time.Second),
+		WriteTimeout:    provider.GetDurationDefault(prefix+"WRITE_TIMEOUT", 10*time.Second),
+		IdleTimeout:     provider.GetDurationDefault(prefix+"IDLE_TIMEOUT", 30*time.Second),
+		ShutdownTimeout: provider.GetDurationDefault(prefix+"SHUTDOWN_TIMEOUT", 30*time.Second),
+		ShutdownDelay:   provider.GetDurationDefault(prefix+"SHUTDOWN_DELAY", 5*time.Second),
 	}
 }
 
@@ -113,6 +113,19 @@ func (t *MySQLTx) Rollback() error {
 
 // NewMySQLDB creates a new MySQL database connection
 func NewMySQLDB(logger log.Logger, config MySQLConfig) (DB, error) {
+	db, err := OpenMySQL(logger, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MySQLDB{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// OpenMySQL opens and verifies a MySQL connection pool, for repositories that work with *sql.DB directly
+func OpenMySQL(logger log.Logger, config MySQLConfig) (*sql.DB, error) {
 	// Build the DSN (Data Source Name)
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t",
 		config.Username,
@@ -150,15 +163,13 @@ func NewMySQLDB(logger log.Logger, config MySQLConfig) (DB, error) {
 	// Verify the connection
 	if err := db.PingContext(ctx); err != nil {
 		logger.With("error", err.Error()).Error("Failed to connect to MySQL database")
+		db.Close()
 		return nil, errors.NewDatabaseError("failed to connect to database", err)
 	}
 
 	logger.Info("Successfully connected to MySQL database")
 
-	return &MySQLDB{
-		db:     db,
-		logger: logger,
-	}, nil
+	return db, nil
 }
 
 // Execute implements the DB.Execute method
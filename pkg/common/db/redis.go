@@ -3,13 +3,19 @@ package db
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/0xsj/fn-go/pkg/common/errors"
 	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/redis/go-redis/v9"
 )
 
+// ErrRedisNil is returned when a key does not exist
+var ErrRedisNil = redis.Nil
+
 // RedisConfig holds Redis-specific configuration
 type RedisConfig struct {
 	Host     string
@@ -32,79 +38,123 @@ func DefaultRedisConfig() RedisConfig {
 	}
 }
 
-// you would likely use github.com/redis/go-redis/v9
+// RedisClient wraps a pooled go-redis client with the commands the services use
 type RedisClient struct {
-	addr     string
-	password string
-	db       int
-	timeout  time.Duration
-	logger   log.Logger
+	client *redis.Client
+	logger log.Logger
+
+	// scripts caches Lua scripts by source, so they run by their SHA1 digest
+	scripts sync.Map
 }
 
 // NewRedisClient creates a new Redis client
 func NewRedisClient(logger log.Logger, config RedisConfig) (*RedisClient, error) {
 	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
-	
+
 	logger.With("addr", addr).
 		With("db", config.DB).
 		Info("Connecting to Redis")
-	
-	// This is a placeholder. In a real app, you would create
-	// a connection to Redis and test it.
+
 	client := &RedisClient{
-		addr:     addr,
-		password: config.Password,
-		db:       config.DB,
-		timeout:  config.Timeout,
-		logger:   logger,
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			Password:     config.Password,
+			DB:           config.DB,
+			PoolSize:     config.PoolSize,
+			DialTimeout:  config.Timeout,
+			ReadTimeout:  config.Timeout,
+			WriteTimeout: config.Timeout,
+		}),
+		logger: logger,
 	}
-	
+
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 	defer cancel()
-	
+
 	if err := client.Ping(ctx); err != nil {
 		logger.With("error", err.Error()).Error("Failed to connect to Redis")
+		client.client.Close()
 		return nil, err
 	}
-	
+
 	logger.Info("Successfully connected to Redis")
 	return client, nil
 }
 
+// Do sends a command and returns its reply. Replies are decoded as string,
+// int64, []any or nil (for null bulk strings); error replies are returned as
+// errors.
+func (c *RedisClient) Do(ctx context.Context, args ...any) (any, error) {
+	reply, err := c.client.Do(ctx, args...).Result()
+	return reply, c.replyError(err)
+}
+
 // Ping checks the Redis connection
 func (c *RedisClient) Ping(ctx context.Context) error {
-	// This is a placeholder. In a real app, you would
-	// send a PING command to Redis.
-	return nil
+	return c.replyError(c.client.Ping(ctx).Err())
 }
 
-// Get retrieves a value from Redis
+// Get retrieves a value from Redis, returning ErrRedisNil if the key is missing
 func (c *RedisClient) Get(ctx context.Context, key string) (string, error) {
-	// Placeholder implementation
 	c.logger.With("key", key).Debug("Getting value from Redis")
-	return "", errors.NewInternalError("redis Get not implemented", nil)
+
+	value, err := c.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", ErrRedisNil
+	}
+	return value, c.replyError(err)
 }
 
-// Set stores a value in Redis
+// Set stores a value in Redis; a zero expiration keeps the key forever
 func (c *RedisClient) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
-	// Placeholder implementation
 	c.logger.With("key", key).
 		With("expiration", expiration.String()).
 		Debug("Setting value in Redis")
-	return errors.NewInternalError("redis Set not implemented", nil)
+
+	return c.replyError(c.client.Set(ctx, key, value, expiration).Err())
 }
 
-// Del deletes a key from Redis
-func (c *RedisClient) Del(ctx context.Context, key string) error {
-	// Placeholder implementation
-	c.logger.With("key", key).Debug("Deleting key from Redis")
-	return errors.NewInternalError("redis Del not implemented", nil)
+// SetNX stores a value only if the key does not exist, reporting whether it was set
+func (c *RedisClient) SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	set, err := c.client.SetNX(ctx, key, value, expiration).Result()
+	return set, c.replyError(err)
 }
 
-// Close closes the Redis connection
+// Del deletes keys from Redis
+func (c *RedisClient) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	c.logger.With("keys", keys).Debug("Deleting keys from Redis")
+
+	return c.replyError(c.client.Del(ctx, keys...).Err())
+}
+
+// Eval runs a Lua script with the given keys and arguments. Scripts are sent
+// once and then run by digest.
+func (c *RedisClient) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	cached, _ := c.scripts.LoadOrStore(script, redis.NewScript(script))
+
+	reply, err := cached.(*redis.Script).Run(ctx, c.client, keys, args...).Result()
+	return reply, c.replyError(err)
+}
+
+// Close closes the Redis connection pool
 func (c *RedisClient) Close() error {
 	c.logger.Info("Closing Redis connection")
-	// Placeholder implementation
-	return nil
-}
\ No newline at end of file
+	return c.client.Close()
+}
+
+// replyError passes error replies from the server through and wraps failures
+// to reach it as database errors. A null reply isn't an error.
+func (c *RedisClient) replyError(err error) error {
+	if err == nil || err == redis.Nil {
+		return nil
+	}
+	var replyErr redis.Error
+	if stderrors.As(err, &replyErr) {
+		return err
+	}
+	return errors.NewDatabaseError("redis command failed", err)
+}
@@ -34,11 +34,27 @@ func BuildWhereClause(filters map[string]any) (string, []any) {
 			continue
 		}
 		
+		// Handle ranges
+		if r, ok := value.(Range); ok {
+			if r.From != nil {
+				clauses = append(clauses, fmt.Sprintf("%s >= ?", field))
+				args = append(args, r.From)
+			}
+			if r.To != nil {
+				clauses = append(clauses, fmt.Sprintf("%s < ?", field))
+				args = append(args, r.To)
+			}
+			continue
+		}
+		
 		// Handle regular equality
 		clauses = append(clauses, fmt.Sprintf("%s = ?", field))
 		args = append(args, value)
 	}
 	
+	if len(clauses) == 0 {
+		return "", nil
+	}
 	return "WHERE " + strings.Join(clauses, " AND "), args
 }
 
@@ -74,6 +90,82 @@ func BuildLimitOffsetClause(limit, offset int) string {
 	return fmt.Sprintf("LIMIT %d", limit)
 }
 
+// BuildKeysetClause builds the condition selecting the rows after a cursor
+// position in the sort order, or before it when backward, e.g.
+// (created_at < ? OR (created_at = ? AND id < ?)). values holds the
+// position's value for each sort field.
+func BuildKeysetClause(sorts []SortOption, values []any, backward bool) (string, []any) {
+	if len(sorts) == 0 || len(sorts) != len(values) {
+		return "", nil
+	}
+
+	var clauses []string
+	var args []any
+
+	for i, sort := range sorts {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf("%s = ?", sorts[j].Field))
+			args = append(args, values[j])
+		}
+
+		op := ">"
+		if (sort.Direction == SortDescending) != backward {
+			op = "<"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s ?", sort.Field, op))
+		args = append(args, values[i])
+
+		clauses = append(clauses, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// BuildCursorQueryClauses builds the clauses selecting one keyset page. It
+// reads limit+1 rows so the caller can tell whether another page follows, and
+// reverses the order when reading backward, so those rows come last first.
+// values is nil for the first page.
+func BuildCursorQueryClauses(filters map[string]any, sorts []SortOption, values []any, backward bool, limit int) (string, []any) {
+	var clauses []string
+	whereClause, args := BuildWhereClause(filters)
+
+	if len(values) > 0 {
+		keysetClause, keysetArgs := BuildKeysetClause(sorts, values, backward)
+		if keysetClause != "" {
+			if whereClause == "" {
+				whereClause = "WHERE " + keysetClause
+			} else {
+				whereClause += " AND " + keysetClause
+			}
+			args = append(args, keysetArgs...)
+		}
+	}
+	if whereClause != "" {
+		clauses = append(clauses, whereClause)
+	}
+
+	order := sorts
+	if backward {
+		order = make([]SortOption, len(sorts))
+		for i, sort := range sorts {
+			order[i] = SortOption{Field: sort.Field, Direction: SortAscending}
+			if sort.Direction == SortAscending {
+				order[i].Direction = SortDescending
+			}
+		}
+	}
+	if orderByClause := BuildOrderByClause(order); orderByClause != "" {
+		clauses = append(clauses, orderByClause)
+	}
+
+	if limit > 0 {
+		clauses = append(clauses, BuildLimitOffsetClause(limit+1, 0))
+	}
+
+	return strings.Join(clauses, " "), args
+}
+
 // BuildQueryClauses builds complete query clauses
 func BuildQueryClauses(filters map[string]any, sorts []SortOption, limit, offset int) (string, []any) {
 	var clauses []string
@@ -147,6 +239,13 @@ func ScanRowsToMap(rows *sql.Rows) ([]map[string]any, error) {
 	return results, nil
 }
 
+// Range is a filter value selecting a field from From, inclusive, up to To,
+// exclusive. A nil bound leaves that side open.
+type Range struct {
+	From any
+	To   any
+}
+
 // SortOption defines a sort option
 type SortOption struct {
 	Field     string
@@ -99,6 +99,20 @@ func (e *AppError) WithOperation(operation string) *AppError {
 	return e
 }
 
+// FieldValidationErrors is the field holding per-field validation messages.
+// Unlike other fields, which are for logs, they are meant for the caller.
+const FieldValidationErrors = "validation_errors"
+
+// ReplyDetails returns the fields of the error meant for the caller, which
+// are its validation messages if it has any
+func (e *AppError) ReplyDetails() map[string]any {
+	validationErrors, ok := e.Fields[FieldValidationErrors]
+	if !ok {
+		return nil
+	}
+	return map[string]any{FieldValidationErrors: validationErrors}
+}
+
 func (e *AppError) Log(log Logger) {
 	contextLogger := log
 	if e.Fields != nil {
@@ -0,0 +1,320 @@
+// pkg/common/jwks/verifier.go
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Errors returned by Verify
+var (
+	ErrInvalidToken = errors.New("invalid or malformed token")
+	ErrTokenExpired = errors.New("token has expired")
+	// ErrNoKeyID marks tokens without a kid header, signed before asymmetric
+	// keys were introduced; only auth-service can still verify them
+	ErrNoKeyID = errors.New("token has no key ID")
+	// ErrUnknownKey marks tokens signed with a key missing from the key set
+	// even after refreshing it
+	ErrUnknownKey = errors.New("token signed with an unknown key")
+)
+
+// Fetcher loads the current key set
+type Fetcher func(ctx context.Context) (*Set, error)
+
+// verificationKey is a parsed public key with the algorithm it is used with
+type verificationKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// Verifier verifies tokens issued by auth-service against a cached copy of
+// its JWKS document. A token naming an unknown key triggers a refresh, at
+// most once per minRefresh, so newly rotated keys are picked up on first use.
+type Verifier struct {
+	fetch      Fetcher
+	minRefresh time.Duration
+	mu         sync.RWMutex
+	set        *Set
+	keys       map[string]verificationKey
+	fetchedAt  time.Time
+	fetching   sync.Mutex
+	stop       chan struct{}
+	done       chan struct{}
+	logger     log.Logger
+}
+
+// NewVerifier creates a verifier fetching its keys with fetch
+func NewVerifier(fetch Fetcher, minRefresh time.Duration, logger log.Logger) *Verifier {
+	return &Verifier{
+		fetch:      fetch,
+		minRefresh: minRefresh,
+		set:        &Set{Keys: []Key{}},
+		keys:       make(map[string]verificationKey),
+		logger:     logger.WithLayer("jwks"),
+	}
+}
+
+// NATSFetcher fetches the key set from auth-service
+func NATSFetcher(conn *nats.Conn, timeout time.Duration, logger log.Logger) Fetcher {
+	return func(ctx context.Context) (*Set, error) {
+		var result struct {
+			Success bool `json:"success"`
+			Data    *Set `json:"data,omitempty"`
+			Error   any  `json:"error,omitempty"`
+		}
+		if err := patterns.Request(conn, nats.SubjectAuthJWKS, struct{}{}, &result, timeout, logger); err != nil {
+			return nil, err
+		}
+		if !result.Success || result.Data == nil {
+			return nil, fmt.Errorf("auth service rejected JWKS request: %v", result.Error)
+		}
+		return result.Data, nil
+	}
+}
+
+// Update replaces the cached key set. Keys that can't be parsed are skipped,
+// so one bad entry doesn't stop the others verifying.
+func (v *Verifier) Update(set *Set) {
+	keys := make(map[string]verificationKey, len(set.Keys))
+	for _, k := range set.Keys {
+		pub, err := k.PublicKey()
+		if err != nil {
+			v.logger.With("kid", k.Kid).With("error", err.Error()).Warn("Skipping unusable JWKS key")
+			continue
+		}
+		keys[k.Kid] = verificationKey{alg: k.Alg, key: pub}
+	}
+
+	v.mu.Lock()
+	v.set = set
+	v.keys = keys
+	v.mu.Unlock()
+
+	v.logger.With("key_count", len(keys)).Debug("JWKS key set updated")
+}
+
+// Refresh fetches the key set and caches it
+func (v *Verifier) Refresh(ctx context.Context) error {
+	v.fetching.Lock()
+	defer v.fetching.Unlock()
+	return v.refreshLocked(ctx)
+}
+
+func (v *Verifier) refreshLocked(ctx context.Context) error {
+	v.mu.Lock()
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+
+	set, err := v.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	v.Update(set)
+	return nil
+}
+
+// Set returns the cached key set. Callers must not modify it.
+func (v *Verifier) Set() *Set {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.set
+}
+
+// Start fetches the key set, then refreshes it every interval until Close.
+// A failed first fetch is returned but the refreshes still start, so the
+// keys arrive once auth-service is reachable.
+func (v *Verifier) Start(interval time.Duration) error {
+	err := v.Refresh(context.Background())
+
+	v.stop = make(chan struct{})
+	v.done = make(chan struct{})
+	go func() {
+		defer close(v.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := v.Refresh(context.Background()); err != nil {
+					v.logger.With("error", err.Error()).Warn("Failed to refresh JWKS key set")
+				}
+			case <-v.stop:
+				return
+			}
+		}
+	}()
+	return err
+}
+
+// Close stops the periodic refreshes
+func (v *Verifier) Close(ctx context.Context) error {
+	if v == nil || v.stop == nil {
+		return nil
+	}
+	close(v.stop)
+	select {
+	case <-v.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SubscribeRotations replaces the key set whenever auth-service announces
+// new keys
+func (v *Verifier) SubscribeRotations(subscriber *patterns.Subscriber) (*nats.Subscription, error) {
+	return subscriber.Subscribe(nats.SubjectAuthKeysRotated, func(ctx context.Context, msg *patterns.MessageEnvelope) error {
+		var set Set
+		if err := json.Unmarshal(msg.Data, &set); err != nil {
+			v.logger.With("error", err.Error()).Warn("Malformed key rotation event, refetching JWKS")
+			return v.Refresh(ctx)
+		}
+		v.logger.With("key_count", len(set.Keys)).Info("Signing keys rotated")
+		v.Update(&set)
+		return nil
+	})
+}
+
+// Verify checks a token's signature, expiry and type, returning its claims
+func (v *Verifier) Verify(tokenString, tokenType string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, v.keyFunc, jwt.WithExpirationRequired())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNoKeyID):
+			return nil, ErrNoKeyID
+		case errors.Is(err, ErrUnknownKey):
+			return nil, ErrUnknownKey
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
+	}
+
+	if t, _ := claims["type"].(string); t != tokenType {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// VerifyAccessToken verifies an access token and returns its claims
+func (v *Verifier) VerifyAccessToken(tokenString string) (*models.TokenClaims, error) {
+	claims, err := v.Verify(tokenString, "access")
+	if err != nil {
+		return nil, err
+	}
+
+	userID, _ := claims["sub"].(string)
+	if userID == "" {
+		return nil, ErrInvalidToken
+	}
+	username, _ := claims["username"].(string)
+	email, _ := claims["email"].(string)
+	jwtID, _ := claims["jti"].(string)
+	issuer, _ := claims["iss"].(string)
+	iat, _ := claims["iat"].(float64)
+	exp, _ := claims["exp"].(float64)
+	audience, _ := claims["aud"].(string)
+	scope, _ := claims["scope"].(string)
+	actor := actorClaim(claims)
+
+	var roles []string
+	if values, ok := claims["roles"].([]any); ok {
+		for _, value := range values {
+			if role, ok := value.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	return &models.TokenClaims{
+		UserID:    userID,
+		Username:  username,
+		Email:     email,
+		Roles:     roles,
+		Scopes:    strings.Fields(scope),
+		IssuedAt:  int64(iat),
+		ExpiresAt: int64(exp),
+		Issuer:    issuer,
+		Audience:  audience,
+		JWTID:     jwtID,
+		Actor:     actor,
+	}, nil
+}
+
+// actorClaim returns the subject of a token's act claim (RFC 8693 section
+// 4.1), naming who is acting as the token's subject while impersonating them
+func actorClaim(claims jwt.MapClaims) string {
+	act, _ := claims["act"].(map[string]any)
+	actor, _ := act["sub"].(string)
+	return actor
+}
+
+// keyFunc resolves the public key named by a token's kid header. The kid is
+// checked before the algorithm, so legacy HS256 tokens report ErrNoKeyID.
+func (v *Verifier) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrNoKeyID
+	}
+	if alg := token.Method.Alg(); alg != AlgorithmRS256 && alg != AlgorithmEdDSA {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := v.lookup(kid)
+	if !ok {
+		key, ok = v.lookupAfterRefresh(kid)
+	}
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, ErrInvalidToken
+	}
+	return key.key, nil
+}
+
+func (v *Verifier) lookup(kid string) (verificationKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// lookupAfterRefresh refetches the key set for an unknown key, unless it was
+// fetched too recently, so forged kids can't flood auth-service
+func (v *Verifier) lookupAfterRefresh(kid string) (verificationKey, bool) {
+	v.fetching.Lock()
+	defer v.fetching.Unlock()
+
+	// Another request may have fetched the key while this one waited
+	if key, ok := v.lookup(kid); ok {
+		return key, true
+	}
+
+	v.mu.RLock()
+	recent := time.Since(v.fetchedAt) < v.minRefresh
+	v.mu.RUnlock()
+	if recent {
+		return verificationKey{}, false
+	}
+
+	v.logger.With("kid", kid).Info("Unknown signing key, refetching JWKS")
+	if err := v.refreshLocked(context.Background()); err != nil {
+		v.logger.With("error", err.Error()).Warn("Failed to refetch JWKS key set")
+		return verificationKey{}, false
+	}
+	return v.lookup(kid)
+}
@@ -0,0 +1,147 @@
+// pkg/common/lifecycle/lifecycle.go
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/config"
+	"github.com/0xsj/fn-go/pkg/common/log"
+)
+
+// ErrShuttingDown is reported by the readiness check once shutdown has begun
+var ErrShuttingDown = errors.New("shutting down")
+
+// defaultShutdownTimeout bounds shutdown when the configuration leaves it unset
+const defaultShutdownTimeout = 30 * time.Second
+
+// StopFunc releases one component. It should return promptly once ctx is
+// done, forcing whatever is still draining closed.
+type StopFunc func(ctx context.Context) error
+
+type hook struct {
+	name string
+	stop StopFunc
+}
+
+// Manager coordinates graceful shutdown. On SIGINT or SIGTERM it marks the
+// process not ready, waits for load balancers to notice, then stops the
+// registered components in registration order under one deadline. Register
+// the components accepting work (HTTP servers, NATS subscriptions) before the
+// ones they use (connections, database pools), so in-flight work can finish.
+type Manager struct {
+	mu       sync.Mutex
+	hooks    []hook
+	draining atomic.Bool
+	timeout  time.Duration
+	delay    time.Duration
+	logger   log.Logger
+}
+
+// NewManager creates a lifecycle manager using the shutdown settings of cfg
+func NewManager(cfg config.ServerConfig, logger log.Logger) *Manager {
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	return &Manager{
+		timeout: timeout,
+		delay:   cfg.ShutdownDelay,
+		logger:  logger.WithLayer("lifecycle"),
+	}
+}
+
+// OnShutdown registers a component to stop during shutdown
+func (m *Manager) OnShutdown(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Draining reports whether shutdown has begun
+func (m *Manager) Draining() bool {
+	return m.draining.Load()
+}
+
+// ReadyCheck fails once shutdown has begun. It has the shape of a health
+// check so readiness probes stop routing traffic to a draining process.
+func (m *Manager) ReadyCheck(ctx context.Context) error {
+	if m.Draining() {
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// Run blocks until a termination signal arrives, then shuts down. A second
+// signal during shutdown exits immediately.
+func (m *Manager) Run() error {
+	signalCh := make(chan os.Signal, 2)
+	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signalCh)
+
+	m.logger.Info("Waiting for termination signal")
+	sig := <-signalCh
+	m.logger.With("signal", sig.String()).Info("Received termination signal, shutting down")
+
+	go func() {
+		sig := <-signalCh
+		m.logger.With("signal", sig.String()).Warn("Received second termination signal, exiting immediately")
+		os.Exit(1)
+	}()
+
+	return m.Shutdown()
+}
+
+// Shutdown marks the process not ready and stops every registered component.
+// Components still run after the deadline passes, with an expired context,
+// so connections and pools are always closed. It returns the joined errors
+// of the components that failed to stop cleanly.
+func (m *Manager) Shutdown() error {
+	if m.draining.Swap(true) {
+		return nil
+	}
+
+	if m.delay > 0 {
+		m.logger.With("delay", m.delay.String()).Info("Marked not ready, waiting before draining")
+		time.Sleep(m.delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	m.mu.Lock()
+	hooks := append([]hook{}, m.hooks...)
+	m.mu.Unlock()
+
+	started := time.Now()
+	var errs []error
+	for _, h := range hooks {
+		hookLogger := m.logger.With("component", h.name)
+		hookStarted := time.Now()
+		if err := h.stop(ctx); err != nil {
+			hookLogger.With("error", err.Error()).Error("Component failed to stop cleanly")
+			errs = append(errs, err)
+			continue
+		}
+		hookLogger.With("duration_ms", time.Since(hookStarted).Milliseconds()).Info("Component stopped")
+	}
+
+	m.logger.With("duration_ms", time.Since(started).Milliseconds()).
+		With("failed", len(errs)).
+		Info("Shutdown complete")
+	return errors.Join(errs...)
+}
+
+// CloseFunc adapts a Close method to a StopFunc
+func CloseFunc(closeFn func() error) StopFunc {
+	return func(ctx context.Context) error {
+		return closeFn()
+	}
+}
@@ -2,6 +2,7 @@
 package nats
 
 import (
+	"context"
 	"os"
 	"sync"
 	"time"
@@ -16,6 +17,7 @@ type (
 	Msg = natspkg.Msg
 	Subscription = natspkg.Subscription
 	Status = natspkg.Status
+	Header = natspkg.Header
 )
 
 // Also expose common constants
@@ -23,6 +25,18 @@ const (
 	DefaultURL = natspkg.DefaultURL
 )
 
+// Errors returned when a request gets no answer
+var (
+	ErrTimeout          = natspkg.ErrTimeout
+	ErrNoResponders     = natspkg.ErrNoResponders
+	ErrConnectionClosed = natspkg.ErrConnectionClosed
+)
+
+// NewMsg creates a message for subject, to be sent with headers
+func NewMsg(subject string) *Msg {
+	return natspkg.NewMsg(subject)
+}
+
 // Client wraps a NATS connection with additional functionality
 type Client struct {
 	conn   *natspkg.Conn
@@ -92,4 +106,34 @@ func (c *Client) Conn() *natspkg.Conn {
 // Close closes the NATS connection
 func (c *Client) Close() {
 	c.conn.Close()
-}
\ No newline at end of file
+}
+// Drain stops the connection's subscriptions from receiving new messages,
+// waits for the handlers of messages already received to finish, flushes
+// pending publishes and closes the connection. The connection is closed
+// outright if ctx is done first.
+func (c *Client) Drain(ctx context.Context) error {
+	if c.conn.IsClosed() {
+		return nil
+	}
+
+	c.logger.Info("Draining NATS connection")
+	if err := c.conn.Drain(); err != nil {
+		c.conn.Close()
+		return err
+	}
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for !c.conn.IsClosed() {
+		select {
+		case <-ctx.Done():
+			c.logger.Warn("NATS drain deadline exceeded, closing connection")
+			c.conn.Close()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	c.logger.Info("NATS connection drained")
+	return nil
+}
@@ -140,6 +140,18 @@ func (p *Publisher) Publish(ctx context.Context, subject string, data any) error
 	return p.PublishEnvelope(ctx, subject, envelope)
 }
 
+// PublishEvent publishes a domain event raised while handling a request. A
+// failure is logged with the handler's logger rather than failing the request
+// that raised it, and a nil publisher publishes nothing.
+func (p *Publisher) PublishEvent(logger log.Logger, subject string, data any) {
+	if p == nil {
+		return
+	}
+	if err := p.Publish(context.Background(), subject, data); err != nil {
+		logger.With("event", subject).With("error", err.Error()).Warn("Failed to publish domain event")
+	}
+}
+
 // PublishEnvelope publishes a pre-created message envelope
 func (p *Publisher) PublishEnvelope(ctx context.Context, subject string, envelope *MessageEnvelope) error {
 	// Marshal the envelope
@@ -3,6 +3,7 @@ package patterns
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/0xsj/fn-go/pkg/common/log"
@@ -11,7 +12,30 @@ import (
 
 type RequestHandler func(data []byte) (any, error)
 
+// MsgHandler is a RequestHandler that also sees the request's headers
+type MsgHandler func(msg *nats.Msg) (any, error)
+
+// ReplyHeaderer is implemented by errors whose reply carries headers, so the
+// requester can tell them apart from other failures without parsing the body
+type ReplyHeaderer interface {
+	ReplyHeader() nats.Header
+}
+
+// ReplyDetailer is implemented by errors with details for the requester,
+// such as which request fields failed validation. They are added to the
+// error object of the reply beside its message.
+type ReplyDetailer interface {
+	ReplyDetails() map[string]any
+}
+
 func Request(conn *nats.Conn, subject string, request any, response any, timeout time.Duration, logger log.Logger) error {
+	_, err := RequestWithHeader(conn, subject, nil, request, response, timeout, logger)
+	return err
+}
+
+// RequestWithHeader sends a request with headers and returns the headers of
+// the reply
+func RequestWithHeader(conn *nats.Conn, subject string, header nats.Header, request any, response any, timeout time.Duration, logger log.Logger) (nats.Header, error) {
 	reqLogger := logger.With("subject", subject).With("operation", "Request")
 	reqLogger.Info("Preparing NATS request")
 	
@@ -24,36 +48,46 @@ func Request(conn *nats.Conn, subject string, request any, response any, timeout
 	data, err := json.Marshal(request)
 	if err != nil {
 		reqLogger.With("error", err.Error()).Error("Failed to marshal request")
-		return err
+		return nil, err
 	}
 	reqLogger.With("request_size", len(data)).Debug("Request marshaled")
 
 	// Send the request and wait for a response
 	reqLogger.Info("Sending NATS request")
 	requestSent := time.Now()
-	msg, err := conn.Request(subject, data, timeout)
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header = header
+	reply, err := conn.RequestMsg(msg, timeout)
 	if err != nil {
 		reqLogger.With("error", err.Error()).Error("Request failed")
-		return err
+		return nil, err
 	}
 	reqLogger.With("response_time_ms", time.Since(requestSent).Milliseconds()).
-		With("response_size", len(msg.Data)).
+		With("response_size", len(reply.Data)).
 		Debug("Received response")
 
 	// Unmarshal the response
 	reqLogger.Debug("Unmarshaling response")
-	err = json.Unmarshal(msg.Data, response)
+	err = json.Unmarshal(reply.Data, response)
 	if err != nil {
 		reqLogger.With("error", err.Error()).Error("Failed to unmarshal response")
-		return err
+		return reply.Header, err
 	}
 	reqLogger.Debug("Response unmarshaled successfully")
 
-	return nil
+	return reply.Header, nil
 }
 
 // HandleRequest sets up a request handler for a subject
 func HandleRequest(conn *nats.Conn, subject string, handler RequestHandler, logger log.Logger) (*nats.Subscription, error) {
+	return HandleRequestMsg(conn, subject, func(msg *nats.Msg) (any, error) {
+		return handler(msg.Data)
+	}, logger)
+}
+
+// HandleRequestMsg sets up a request handler that sees the request's headers
+func HandleRequestMsg(conn *nats.Conn, subject string, handler MsgHandler, logger log.Logger) (*nats.Subscription, error) {
 	setupLogger := logger.With("subject", subject).With("operation", "HandleRequest")
 	setupLogger.Info("Setting up request handler for subject")
 
@@ -66,17 +100,26 @@ func HandleRequest(conn *nats.Conn, subject string, handler RequestHandler, logg
 		
 		// Call the handler
 		msgLogger.Debug("Calling request handler function")
-		result, err := handler(msg.Data)
+		result, err := handler(msg)
 		
 		if err != nil {
 			// On error, send error response
 			msgLogger.With("error", err.Error()).Error("Request handler failed")
 			
+			errorBody := map[string]any{
+				"message": err.Error(),
+			}
+			var detailer ReplyDetailer
+			if errors.As(err, &detailer) {
+				for key, value := range detailer.ReplyDetails() {
+					if key != "message" {
+						errorBody[key] = value
+					}
+				}
+			}
 			errorResponse := map[string]any{
 				"success": false,
-				"error": map[string]any{
-					"message": err.Error(),
-				},
+				"error":   errorBody,
 			}
 			
 			responseData, marshalErr := json.Marshal(errorResponse)
@@ -85,8 +128,15 @@ func HandleRequest(conn *nats.Conn, subject string, handler RequestHandler, logg
 				return
 			}
 			
+			reply := nats.NewMsg(msg.Reply)
+			reply.Data = responseData
+			var headerer ReplyHeaderer
+			if errors.As(err, &headerer) {
+				reply.Header = headerer.ReplyHeader()
+			}
+			
 			msgLogger.Debug("Sending error response")
-			if respErr := msg.Respond(responseData); respErr != nil {
+			if respErr := msg.RespondMsg(reply); respErr != nil {
 				msgLogger.With("error", respErr.Error()).Error("Failed to send error response")
 			}
 			
@@ -33,6 +33,56 @@ const (
 	SubjectBuzz = "buzz"
 )
 
+// Auth event subjects shared between auth-service and its consumers
+const (
+	// SubjectAuthPermissionsChanged is published whenever a role's permissions change
+	SubjectAuthPermissionsChanged = "auth.event.permissions.changed"
+	// SubjectAuthKeysRotated carries the new JWKS document whenever the signing keys change
+	SubjectAuthKeysRotated = "auth.event.keys.rotated"
+	// SubjectAuthRefreshTokenReused is published when a rotated refresh token is
+	// presented again and its session is revoked
+	SubjectAuthRefreshTokenReused = "auth.event.security.refresh-token-reused"
+	// SubjectAuthLoginLockout is published when failed logins lock out an
+	// account or a client address
+	SubjectAuthLoginLockout = "auth.event.security.login-lockout"
+	// SubjectAuthAccountUnlocked is published when a locked account is unlocked
+	// by its user or an administrator
+	SubjectAuthAccountUnlocked = "auth.event.security.account-unlocked"
+	// SubjectAuthImpersonationStarted is published when an administrator is
+	// issued a token to act as a user
+	SubjectAuthImpersonationStarted = "auth.event.security.impersonation-started"
+	// SubjectAuthSecurityEvents matches every auth security event
+	SubjectAuthSecurityEvents = "auth.event.security.>"
+	// SubjectAuthJWKS returns the JWKS document tokens are verified against
+	SubjectAuthJWKS = "auth.jwks"
+	// SubjectAuthOAuthToken is the OAuth token endpoint, where service accounts
+	// obtain tokens with the client credentials grant
+	SubjectAuthOAuthToken = "auth.oauth.token"
+)
+
+// Service account scopes for sensitive variants of a subject. Service accounts
+// are otherwise scoped to the subjects they call.
+const (
+	// ScopeUserPassword authorizes user.get requests for the password hash
+	ScopeUserPassword = "user.get.password"
+)
+
+// Gateway control subjects, broadcast to every gateway replica by the admin API
+const (
+	// SubjectGatewayMaintenance carries a route switched into or out of maintenance
+	SubjectGatewayMaintenance = "gateway.admin.maintenance"
+	// SubjectGatewayConnectionDrop asks the replica holding a WebSocket connection to drop it
+	SubjectGatewayConnectionDrop = "gateway.admin.connections.drop"
+)
+
+// Audit subjects shared between the gateway and monitoring-service
+const (
+	// SubjectAuditRequest carries an audit record for each mutating API request
+	SubjectAuditRequest = "audit.event.request"
+	// SubjectAuditList queries the stored audit records
+	SubjectAuditList = "monitoring.audit.list"
+)
+
 // BuildSubject builds a subject string from components
 // Format: service.operation.entity.id
 func BuildSubject(service, operation, entity string, id ...string) string {
@@ -29,6 +29,20 @@ type PaginationMeta struct {
 	TotalRecords int `json:"total_records"`
 }
 
+// CursorMeta describes a keyset page: opaque cursors to the pages either side
+// of it, empty when there is no such page
+type CursorMeta struct {
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Limit int    `json:"limit"`
+}
+
+// CursorPage is the result of a cursor-paginated NATS list subject
+type CursorPage struct {
+	Items   any        `json:"items"`
+	Cursors CursorMeta `json:"cursors"`
+}
+
 var (
 	ErrBadRequestResponse = ErrorResponse{
 		Code:    "BAD_REQUEST",
@@ -0,0 +1,284 @@
+// pkg/common/response/encoders.go
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	CSV     FormatType = "text/csv"
+	NDJSON  FormatType = "application/x-ndjson"
+	MsgPack FormatType = "application/msgpack"
+)
+
+// EncodeOptions tune how a body is encoded
+type EncodeOptions struct {
+	// Columns selects and orders CSV columns; dotted names reach into nested
+	// objects. All top-level fields are written when empty.
+	Columns []string
+}
+
+// EncodeFunc writes data to w in one format
+type EncodeFunc func(w io.Writer, data any, opts EncodeOptions) error
+
+type format struct {
+	name      string
+	mediaType FormatType
+	aliases   []FormatType
+	encode    EncodeFunc
+}
+
+// Encoders is the registry of formats a response can be written in. Formats
+// are selected by media type from Accept, or by name from a format query
+// parameter. Encoders registered for a type take precedence over the format's
+// own encoder when that type is written directly through an HTTPHandler.
+type Encoders struct {
+	mu      sync.RWMutex
+	formats []*format
+	types   map[FormatType]map[reflect.Type]EncodeFunc
+}
+
+// NewEncoders creates a registry with the JSON, XML, CSV, NDJSON and
+// MessagePack formats
+func NewEncoders() *Encoders {
+	e := &Encoders{types: make(map[FormatType]map[reflect.Type]EncodeFunc)}
+	e.Register("json", JSON, encodeJSON)
+	e.Register("xml", XML, encodeXML, "text/xml")
+	e.Register("csv", CSV, encodeCSV)
+	e.Register("ndjson", NDJSON, encodeNDJSON, "application/jsonl")
+	e.Register("msgpack", MsgPack, encodeMsgPack, "application/x-msgpack")
+	e.Register("", ProblemJSON, encodeJSON)
+	return e
+}
+
+// Register adds a format, replacing any format with the same media type. A
+// format without a name can't be selected by the format query parameter.
+func (e *Encoders) Register(name string, mediaType FormatType, encode EncodeFunc, aliases ...FormatType) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	f := &format{name: name, mediaType: mediaType, aliases: aliases, encode: encode}
+	for i, existing := range e.formats {
+		if existing.mediaType == mediaType {
+			e.formats[i] = f
+			return
+		}
+	}
+	e.formats = append(e.formats, f)
+}
+
+// RegisterType adds an encoder for values of the same type as sample
+func (e *Encoders) RegisterType(mediaType FormatType, sample any, encode EncodeFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.types[mediaType] == nil {
+		e.types[mediaType] = make(map[reflect.Type]EncodeFunc)
+	}
+	e.types[mediaType][reflect.TypeOf(sample)] = encode
+}
+
+// Supports reports whether a media type can be encoded
+func (e *Encoders) Supports(mediaType FormatType) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.find(mediaType) != nil
+}
+
+// Names returns the names selectable by the format query parameter
+func (e *Encoders) Names() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var names []string
+	for _, f := range e.formats {
+		if f.name != "" {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// ByName returns the media type of a format selected by name
+func (e *Encoders) ByName(name string) (FormatType, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, f := range e.formats {
+		if f.name != "" && strings.EqualFold(f.name, name) {
+			return f.mediaType, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the format for an Accept header (RFC 9110 12.5.1),
+// preferring higher quality values, then more specific media ranges. fallback
+// answers */* and an absent or unsatisfiable header. Browsers navigating to
+// the API also get fallback, though their Accept headers prefer XML.
+func (e *Encoders) Negotiate(accept string, fallback FormatType) FormatType {
+	if strings.Contains(accept, "text/html") {
+		return fallback
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	best, bestQ, bestSpecificity := fallback, 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		mediaType, specificity, ok := e.match(mediaRange, fallback)
+		if !ok {
+			continue
+		}
+		if q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = mediaType, q, specificity
+		}
+	}
+	return best
+}
+
+// match finds the format answering a media range, with the range's specificity
+func (e *Encoders) match(mediaRange string, fallback FormatType) (FormatType, int, bool) {
+	if mediaRange == "*/*" {
+		return fallback, 0, true
+	}
+
+	if mainType, ok := strings.CutSuffix(mediaRange, "/*"); ok {
+		if strings.HasPrefix(string(fallback), mainType+"/") {
+			return fallback, 1, true
+		}
+		for _, f := range e.formats {
+			if f.name != "" && strings.HasPrefix(string(f.mediaType), mainType+"/") {
+				return f.mediaType, 1, true
+			}
+		}
+		return "", 0, false
+	}
+
+	if f := e.find(FormatType(mediaRange)); f != nil && f.name != "" {
+		return f.mediaType, 2, true
+	}
+	return "", 0, false
+}
+
+// Encode writes data to w in a format
+func (e *Encoders) Encode(w io.Writer, mediaType FormatType, data any, opts EncodeOptions) error {
+	e.mu.RLock()
+	encode := e.types[mediaType][reflect.TypeOf(data)]
+	if encode == nil {
+		if f := e.find(mediaType); f != nil {
+			encode = f.encode
+		}
+	}
+	e.mu.RUnlock()
+
+	if encode == nil {
+		return fmt.Errorf("unsupported format: %s", mediaType)
+	}
+	return encode(w, data, opts)
+}
+
+// find returns the format for a media type or one of its aliases
+func (e *Encoders) find(mediaType FormatType) *format {
+	for _, f := range e.formats {
+		if f.mediaType == mediaType {
+			return f
+		}
+		for _, alias := range f.aliases {
+			if alias == mediaType {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// contentType returns the Content-Type header value for a format
+func contentType(mediaType FormatType, charset string) string {
+	if charset == "" || mediaType == MsgPack {
+		return string(mediaType)
+	}
+	return fmt.Sprintf("%s; charset=%s", mediaType, charset)
+}
+
+func encodeJSON(w io.Writer, data any, opts EncodeOptions) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// normalize converts data to the generic values JSON decodes into, keeping
+// numbers exact, so every encoder sees the same shape as JSON clients
+func normalize(data any) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// rows returns the records of a tabular response: the data of a success
+// envelope, one record per element of a list
+func rows(value any) []any {
+	if envelope, ok := value.(map[string]any); ok {
+		if _, isEnvelope := envelope["success"]; isEnvelope {
+			value = envelope["data"]
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	default:
+		return []any{v}
+	}
+}
+
+// encodeNDJSON writes one JSON record per line, flushing after each
+func encodeNDJSON(w io.Writer, data any, opts EncodeOptions) error {
+	value, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	for _, record := range rows(value) {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+// pkg/common/response/formats.go
+package response
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// encodeCSV writes the records of a response as CSV with a header row
+func encodeCSV(w io.Writer, data any, opts EncodeOptions) error {
+	value, err := normalize(data)
+	if err != nil {
+		return err
+	}
+	records := rows(value)
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = csvColumns(records)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	row := make([]string, len(columns))
+	for _, record := range records {
+		for i, column := range columns {
+			row[i] = csvCell(lookup(record, column))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvColumns returns every top-level field of the records, sorted
+func csvColumns(records []any) []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, record := range records {
+		obj, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		for key := range obj {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	if len(columns) == 0 && len(records) > 0 {
+		// Records that aren't objects form a single column
+		columns = []string{"value"}
+	}
+	return columns
+}
+
+// lookup reads a dotted path such as category.type from a record
+func lookup(record any, path string) any {
+	obj, ok := record.(map[string]any)
+	if !ok {
+		if path == "value" {
+			return record
+		}
+		return nil
+	}
+
+	var value any = obj
+	for _, key := range strings.Split(path, ".") {
+		current, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = current[key]
+	}
+	return value
+}
+
+// csvCell formats a value for a CSV cell. Text that a spreadsheet would run
+// as a formula is prefixed with a quote (CWE-1236).
+func csvCell(value any) string {
+	if v, ok := value.(string); ok && v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return text(value)
+}
+
+// text formats a scalar as text; objects and lists are written as JSON
+func text(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	}
+}
+
+// encodeXML writes a response as XML. Objects become elements named by their
+// fields and lists repeat an item element.
+func encodeXML(w io.Writer, data any, opts EncodeOptions) error {
+	value, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	if err := writeXML(encoder, "response", value); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func writeXML(encoder *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := writeXML(encoder, key, v[key]); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case []any:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := writeXML(encoder, "item", item); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case nil:
+		return encoder.EncodeElement("", start)
+	default:
+		return encoder.EncodeElement(text(v), start)
+	}
+}
+
+// xmlName turns a field name into a valid element name
+func xmlName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		valid := unicode.IsLetter(r) || r == '_' || (i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'))
+		if !valid {
+			if i == 0 && unicode.IsDigit(r) {
+				b.WriteRune('_')
+				b.WriteRune(r)
+				continue
+			}
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// encodeMsgPack writes a response as MessagePack
+func encodeMsgPack(w io.Writer, data any, opts EncodeOptions) error {
+	value, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	if err := writeMsgPack(buf, value); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// writeMsgPack encodes a generic JSON value using the smallest MessagePack
+// representation of each value. Map keys are sorted for stable output.
+func writeMsgPack(w *bufio.Writer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		return w.WriteByte(0xc0)
+	case bool:
+		if v {
+			return w.WriteByte(0xc3)
+		}
+		return w.WriteByte(0xc2)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			writeMsgPackInt(w, n)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		w.WriteByte(0xcb)
+		return binary.Write(w, binary.BigEndian, math.Float64bits(f))
+	case string:
+		writeMsgPackHeader(w, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		_, err := w.WriteString(v)
+		return err
+	case []any:
+		writeMsgPackHeader(w, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMsgPack(w, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		writeMsgPackHeader(w, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := writeMsgPack(w, key); err != nil {
+				return err
+			}
+			if err := writeMsgPack(w, v[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("msgpack: unsupported value %T", value)
+	}
+}
+
+// writeMsgPackHeader writes the type and length of a string, array or map:
+// fixed when n < fixedLimit, otherwise with an 8 (if code8 is set), 16 or 32
+// bit length
+func writeMsgPackHeader(w *bufio.Writer, n int, fixed byte, fixedLimit int, code8, code16, code32 byte) {
+	switch {
+	case n < fixedLimit:
+		w.WriteByte(fixed | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		w.WriteByte(code8)
+		w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(code16)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(code32)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+}
+
+func writeMsgPackInt(w *bufio.Writer, n int64) {
+	switch {
+	case n >= 0 && n <= math.MaxInt8:
+		w.WriteByte(byte(n))
+	case n < 0 && n >= -32:
+		w.WriteByte(byte(int8(n)))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		w.WriteByte(0xd0)
+		w.WriteByte(byte(int8(n)))
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		w.WriteByte(0xd1)
+		binary.Write(w, binary.BigEndian, int16(n))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		w.WriteByte(0xd2)
+		binary.Write(w, binary.BigEndian, int32(n))
+	default:
+		w.WriteByte(0xd3)
+		binary.Write(w, binary.BigEndian, n)
+	}
+}
@@ -3,8 +3,6 @@ package response
 
 import (
 	"context"
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"net/http"
 
@@ -40,6 +38,7 @@ type HTTPHandler struct {
 	options  HTTPOptions
 	logger   log.Logger
 	errorMgr *ErrorManager
+	encoders *Encoders
 }
 
 func NewHTTP(logger log.Logger, opts ...HTTPOptions) *HTTPHandler {
@@ -52,16 +51,22 @@ func NewHTTP(logger log.Logger, opts ...HTTPOptions) *HTTPHandler {
 		options:  options,
 		logger:   logger,
 		errorMgr: NewErrorManager(logger),
+		encoders: NewEncoders(),
 	}
 }
 
+// Encoders returns the registry of response formats, where services register
+// their own formats and encoders for their types
+func (h *HTTPHandler) Encoders() *Encoders {
+	return h.encoders
+}
+
 func (h *HTTPHandler) Write(w http.ResponseWriter, statusCode int, data any, format FormatType) error {
-	contentType := string(format)
-	if h.options.DefaultEncoding != "" {
-		contentType = fmt.Sprintf("%s; charset=%s", format, h.options.DefaultEncoding)
+	if !h.encoders.Supports(format) {
+		return fmt.Errorf("unsupported format: %s", format)
 	}
 	
-	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Type", contentType(format, h.options.DefaultEncoding))
 	
 	if h.options.AllowCORS {
 		origin := "*"
@@ -75,14 +80,7 @@ func (h *HTTPHandler) Write(w http.ResponseWriter, statusCode int, data any, for
 	
 	w.WriteHeader(statusCode)
 	
-	switch format {
-	case JSON:
-		return json.NewEncoder(w).Encode(data)
-	case XML:
-		return xml.NewEncoder(w).Encode(data)
-	default:
-		return fmt.Errorf("unsupported format: %s", format)
-	}
+	return h.encoders.Encode(w, format, data, EncodeOptions{})
 }
 
 func (h *HTTPHandler) JSON(w http.ResponseWriter, statusCode int, data any) error {
@@ -131,6 +129,32 @@ func (h *HTTPHandler) WithPagination(w http.ResponseWriter, data any, meta Pagin
 	return h.Write(w, http.StatusOK, resp, format)
 }
 
+// WithCursor writes a keyset page with its cursors in the meta and as RFC 8288
+// Link headers. The links are query-only references, so they resolve against
+// the URL the client requested, whatever prefix a proxy stripped from r.
+func (h *HTTPHandler) WithCursor(w http.ResponseWriter, r *http.Request, page CursorPage) error {
+	if page.Cursors.Next != "" {
+		w.Header().Add("Link", cursorLink(r, page.Cursors.Next, "next"))
+	}
+	if page.Cursors.Prev != "" {
+		w.Header().Add("Link", cursorLink(r, page.Cursors.Prev, "prev"))
+	}
+
+	resp := Response{
+		Success: true,
+		Data:    page.Items,
+		Meta:    page.Cursors,
+	}
+	return h.Write(w, http.StatusOK, resp, h.options.DefaultFormat)
+}
+
+// cursorLink builds a Link header value for the request with another cursor
+func cursorLink(r *http.Request, cursor, rel string) string {
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	return fmt.Sprintf(`<?%s>; rel="%s"`, query.Encode(), rel)
+}
+
 func (h *HTTPHandler) NoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -215,30 +239,40 @@ func (h *HTTPHandler) File(w http.ResponseWriter, data []byte, filename string,
 	return h.Stream(w, data, contentType)
 }
 
+// FormatMiddleware negotiates the response format from the format query
+// parameter or, without one, the Accept header. Handlers keep writing JSON:
+// successful JSON responses are transcoded to the negotiated format once the
+// handler returns, so middleware working on JSON bodies sits inside it.
+// Errors stay problem details and other content passes through unchanged.
 func (h *HTTPHandler) FormatMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		accept := r.Header.Get("Accept")
-		format := h.options.DefaultFormat
-		
-		if accept != "" {
-			switch accept {
-			case "application/json":
-				format = JSON
-			case "application/xml":
-				format = XML
+		format := h.encoders.Negotiate(r.Header.Get("Accept"), h.options.DefaultFormat)
+		if name := r.URL.Query().Get("format"); name != "" {
+			var ok bool
+			if format, ok = h.encoders.ByName(name); !ok {
+				h.Problem(w, r, Problem{
+					Type:   "/problems/unsupported-format",
+					Title:  "Unsupported format",
+					Status: http.StatusBadRequest,
+					Detail: "Format " + name + " is not supported",
+					Code:   "UNSUPPORTED_FORMAT",
+				}.WithExtension("supported_formats", h.encoders.Names()))
+				return
 			}
 		}
 		
-		if r.URL.Query().Get("format") == "xml" {
-			format = XML
-		} else if r.URL.Query().Get("format") == "json" {
-			format = JSON
+		w.Header().Add("Vary", "Accept")
+		r = r.WithContext(contextWithFormat(r.Context(), format))
+		if format == JSON {
+			next.ServeHTTP(w, r)
+			return
 		}
 		
-		ctx := r.Context()
-		ctx = contextWithFormat(ctx, format)
-		
-		next.ServeHTTP(w, r.WithContext(ctx))
+		tw := &transcodingWriter{ResponseWriter: w}
+		next.ServeHTTP(tw, r)
+		if tw.buffering {
+			h.transcode(w, r, tw, format)
+		}
 	})
 }
 
@@ -0,0 +1,110 @@
+// pkg/common/response/negotiation.go
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// transcodingWriter buffers a successful JSON response for transcoding and
+// passes anything else, such as errors and file downloads, straight through
+type transcodingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (tw *transcodingWriter) WriteHeader(status int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.status = status
+
+	mediaType, _, _ := mime.ParseMediaType(tw.Header().Get("Content-Type"))
+	if status >= http.StatusOK && status < http.StatusMultipleChoices && status != http.StatusNoContent &&
+		mediaType == string(JSON) {
+		tw.buffering = true
+		return
+	}
+	tw.ResponseWriter.WriteHeader(status)
+}
+
+func (tw *transcodingWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.buffering {
+		return tw.body.Write(b)
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Flush flushes responses that pass through
+func (tw *transcodingWriter) Flush() {
+	if tw.buffering {
+		return
+	}
+	if flusher, ok := tw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (tw *transcodingWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+// transcode re-encodes a buffered JSON response in format. The JSON is sent
+// unchanged if it can't be transcoded.
+func (h *HTTPHandler) transcode(w http.ResponseWriter, r *http.Request, tw *transcodingWriter, format FormatType) {
+	decoder := json.NewDecoder(bytes.NewReader(tw.body.Bytes()))
+	decoder.UseNumber()
+	var value any
+	err := decoder.Decode(&value)
+
+	var out bytes.Buffer
+	if err == nil {
+		err = h.encoders.Encode(&out, format, value, EncodeOptions{Columns: columns(r)})
+	}
+	if err != nil {
+		h.logger.With("format", string(format)).With("path", r.URL.Path).With("error", err.Error()).
+			Warn("Failed to transcode response, sending JSON")
+		w.WriteHeader(tw.status)
+		w.Write(tw.body.Bytes())
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", contentType(format, h.options.DefaultEncoding))
+	header.Del("Content-Length")
+	// Validators were computed for the JSON representation
+	header.Del("ETag")
+	if format == CSV {
+		name := path.Base(r.URL.Path)
+		if name == "/" || name == "." {
+			name = "export"
+		}
+		header.Set("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+	}
+
+	w.WriteHeader(tw.status)
+	w.Write(out.Bytes())
+}
+
+// columns reads the CSV column selection from a comma separated query parameter
+func columns(r *http.Request) []string {
+	var selected []string
+	for _, column := range strings.Split(r.URL.Query().Get("columns"), ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			selected = append(selected, column)
+		}
+	}
+	return selected
+}
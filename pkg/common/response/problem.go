@@ -0,0 +1,92 @@
+// pkg/common/response/problem.go
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemJSON is the media type for RFC 7807 problem details
+const ProblemJSON FormatType = "application/problem+json"
+
+// Problem represents an RFC 7807 problem details object
+type Problem struct {
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Status     int            `json:"status"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Code       string         `json:"code,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON flattens extension members into the top-level problem object
+func (p Problem) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(p.Extensions)+6)
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+
+	problemType := p.Type
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+	out["type"] = problemType
+	out["title"] = p.Title
+	out["status"] = p.Status
+	if p.Detail != "" {
+		out["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		out["instance"] = p.Instance
+	}
+	if p.Code != "" {
+		out["code"] = p.Code
+	}
+
+	return json.Marshal(out)
+}
+
+// NewProblem creates a problem for the given status using the standard status text as title
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithExtension returns a copy of the problem with an additional extension member
+func (p Problem) WithExtension(key string, value any) Problem {
+	ext := make(map[string]any, len(p.Extensions)+1)
+	for k, v := range p.Extensions {
+		ext[k] = v
+	}
+	ext[key] = value
+	p.Extensions = ext
+	return p
+}
+
+// Problem writes an RFC 7807 problem details response
+func (h *HTTPHandler) Problem(w http.ResponseWriter, r *http.Request, problem Problem) error {
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Instance == "" && r != nil {
+		problem.Instance = r.URL.Path
+	}
+
+	h.logger.With("status_code", problem.Status).
+		With("code", problem.Code).
+		With("instance", problem.Instance).
+		Debug("Writing problem response")
+
+	w.Header().Set("Content-Type", string(ProblemJSON))
+	w.WriteHeader(problem.Status)
+	return json.NewEncoder(w).Encode(problem)
+}
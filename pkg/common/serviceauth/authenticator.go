@@ -0,0 +1,99 @@
+// pkg/common/serviceauth/authenticator.go
+package serviceauth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0xsj/fn-go/pkg/common/jwks"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+)
+
+// Handler handles a request knowing the calling service, which is nil when
+// the request carried no token
+type Handler func(caller *Caller, data []byte) (any, error)
+
+// Authenticator verifies the service tokens sent to a service's handlers
+// against auth-service's published signing keys
+type Authenticator struct {
+	verifier *jwks.Verifier
+	logger   log.Logger
+}
+
+// NewAuthenticator creates an authenticator verifying tokens with verifier
+func NewAuthenticator(verifier *jwks.Verifier, logger log.Logger) *Authenticator {
+	return &Authenticator{
+		verifier: verifier,
+		logger:   logger.WithLayer("service-auth"),
+	}
+}
+
+// Authenticate returns the service named by a request's token, or nil when
+// the request has none. A token that fails verification is an error.
+func (a *Authenticator) Authenticate(header nats.Header) (*Caller, error) {
+	value := header.Get(HeaderAuthorization)
+	if value == "" {
+		return nil, nil
+	}
+
+	token, ok := strings.CutPrefix(value, "Bearer ")
+	if !ok || token == "" {
+		return nil, &Error{Code: ErrorInvalidToken, Message: "Malformed service token"}
+	}
+
+	claims, err := a.verifier.Verify(token, TokenType)
+	if err != nil {
+		a.logger.With("error", err.Error()).Warn("Rejected service token")
+		return nil, &Error{Code: ErrorInvalidToken, Message: "Service token is invalid or expired"}
+	}
+
+	clientID, _ := claims["client_id"].(string)
+	scope, _ := claims["scope"].(string)
+	if clientID == "" {
+		return nil, &Error{Code: ErrorInvalidToken, Message: "Service token names no client"}
+	}
+
+	return &Caller{ClientID: clientID, Scopes: strings.Fields(scope)}, nil
+}
+
+// HandleRequest sets up a handler that is told which service is calling.
+// Requests with an invalid token are rejected before reaching it.
+func (a *Authenticator) HandleRequest(conn *nats.Conn, subject string, handler Handler, logger log.Logger) (*nats.Subscription, error) {
+	return patterns.HandleRequestMsg(conn, subject, func(msg *nats.Msg) (any, error) {
+		caller, err := a.Authenticate(msg.Header)
+		if err != nil {
+			return nil, err
+		}
+		return handler(caller, msg.Data)
+	}, logger)
+}
+
+// Require sets up a handler for a subject that only services whose scopes
+// cover it may call
+func (a *Authenticator) Require(conn *nats.Conn, subject string, handler patterns.RequestHandler, logger log.Logger) (*nats.Subscription, error) {
+	return a.HandleRequest(conn, subject, func(caller *Caller, data []byte) (any, error) {
+		if err := a.Authorize(caller, subject); err != nil {
+			return nil, err
+		}
+		return handler(data)
+	}, logger)
+}
+
+// Authorize checks the caller's scopes cover a subject, or a scope guarding
+// a sensitive variant of one
+func (a *Authenticator) Authorize(caller *Caller, scope string) error {
+	if caller == nil {
+		a.logger.With("scope", scope).Warn("Anonymous request for a protected subject")
+		return &Error{Code: ErrorInvalidToken, Message: "A service token is required"}
+	}
+	if !caller.Allows(scope) {
+		a.logger.With("client_id", caller.ClientID).With("scope", scope).Warn("Service not authorized for subject")
+		return &Error{
+			Code:    ErrorInsufficientScope,
+			Message: fmt.Sprintf("Service %s is not authorized for %s", caller.ClientID, scope),
+		}
+	}
+	return nil
+}
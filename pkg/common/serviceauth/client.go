@@ -0,0 +1,67 @@
+// pkg/common/serviceauth/client.go
+package serviceauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+)
+
+// Client sends requests to other services carrying the caller's service token
+type Client struct {
+	conn    *nats.Conn
+	tokens  *TokenSource
+	timeout time.Duration
+	logger  log.Logger
+}
+
+// NewClient creates a client authenticating with tokens. Without a token
+// source requests are sent anonymously, as before service accounts.
+func NewClient(conn *nats.Conn, tokens *TokenSource, timeout time.Duration, logger log.Logger) *Client {
+	return &Client{
+		conn:    conn,
+		tokens:  tokens,
+		timeout: timeout,
+		logger:  logger.WithLayer("service-client"),
+	}
+}
+
+// Request sends a request with the service token and unmarshals the reply
+// into response. A reply rejecting the token as invalid, e.g. because it was
+// signed with a retired key, is retried once with a new token.
+func (c *Client) Request(ctx context.Context, subject string, request any, response any) error {
+	if c.tokens == nil {
+		return patterns.Request(c.conn, subject, request, response, c.timeout, c.logger)
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to obtain service token: %w", err)
+		}
+
+		header := nats.Header{}
+		header.Set(HeaderAuthorization, "Bearer "+token)
+
+		var reply json.RawMessage
+		replyHeader, err := patterns.RequestWithHeader(c.conn, subject, header, request, &reply, c.timeout, c.logger)
+		if err != nil {
+			return err
+		}
+
+		if code, ok := IsAuthError(replyHeader); ok {
+			c.logger.With("subject", subject).With("reason", code).Warn("Service token rejected")
+			if code == ErrorInvalidToken && attempt == 0 {
+				c.tokens.Invalidate()
+				continue
+			}
+		}
+
+		return json.Unmarshal(reply, response)
+	}
+}
@@ -0,0 +1,123 @@
+// pkg/common/serviceauth/serviceauth.go
+
+// Package serviceauth authenticates services calling each other over NATS.
+// A service account is an OAuth client of type "service" whose scopes are
+// the NATS subjects it may call. Callers obtain short-lived tokens with the
+// client credentials grant and send them in a request header; handlers of
+// sensitive subjects verify them against auth-service's published keys.
+package serviceauth
+
+import (
+	"strings"
+
+	"github.com/0xsj/fn-go/pkg/common/nats"
+)
+
+// TokenType is the type claim of service tokens, so they can never pass as a
+// user's access token
+const TokenType = "service"
+
+// Headers carrying service credentials
+const (
+	// HeaderAuthorization carries "Bearer <token>" on requests
+	HeaderAuthorization = "Authorization"
+	// HeaderAuthError names why a handler rejected the caller on replies
+	HeaderAuthError = "Service-Auth-Error"
+)
+
+// Reasons a handler rejects a caller, sent in HeaderAuthError
+const (
+	// ErrorInvalidToken means no token, or an invalid or expired one, was sent.
+	// Clients discard their token and retry with a new one.
+	ErrorInvalidToken = "invalid_token"
+	// ErrorInsufficientScope means the token doesn't cover the subject
+	ErrorInsufficientScope = "insufficient_scope"
+)
+
+// Caller is a service authenticated by its token
+type Caller struct {
+	ClientID string
+	Scopes   []string
+}
+
+// Allows reports whether the caller's scopes cover a subject
+func (c *Caller) Allows(subject string) bool {
+	if c == nil {
+		return false
+	}
+	for _, scope := range c.Scopes {
+		if MatchSubject(scope, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchSubject matches a subject against a pattern using NATS wildcards: "*"
+// matches one token and a trailing ">" matches one or more
+func MatchSubject(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" && i == len(patternTokens)-1 {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
+
+// ValidScope reports whether a scope is a subject pattern a service account
+// can be granted. A bare ">" would grant every subject and is refused.
+func ValidScope(scope string) bool {
+	if scope == "" || scope == ">" {
+		return false
+	}
+	tokens := strings.Split(scope, ".")
+	for i, token := range tokens {
+		switch {
+		case token == "":
+			return false
+		case token == ">" && i != len(tokens)-1:
+			return false
+		case strings.ContainsAny(token, " \t") || (len(token) > 1 && strings.ContainsAny(token, "*>")):
+			return false
+		}
+	}
+	return true
+}
+
+// Error is a rejection of the caller. Its reply names the reason in
+// HeaderAuthError.
+type Error struct {
+	Code    string
+	Message string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ReplyHeader implements patterns.ReplyHeaderer
+func (e *Error) ReplyHeader() nats.Header {
+	header := nats.Header{}
+	header.Set(HeaderAuthError, e.Code)
+	return header
+}
+
+// IsAuthError reports whether a reply header marks a rejected caller,
+// returning the reason
+func IsAuthError(header nats.Header) (string, bool) {
+	if header == nil {
+		return "", false
+	}
+	code := header.Get(HeaderAuthError)
+	return code, code != ""
+}
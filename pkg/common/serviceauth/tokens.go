@@ -0,0 +1,137 @@
+// pkg/common/serviceauth/tokens.go
+package serviceauth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+)
+
+// maxRefreshMargin caps how long before expiry a token is replaced. Shorter
+// tokens are replaced with a quarter of their lifetime left.
+const maxRefreshMargin = time.Minute
+
+// Token is a service token with its lifetime
+type Token struct {
+	Value     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// refreshAt is when the token should be replaced
+func (t *Token) refreshAt() time.Time {
+	margin := t.ExpiresAt.Sub(t.IssuedAt) / 4
+	if margin > maxRefreshMargin {
+		margin = maxRefreshMargin
+	}
+	return t.ExpiresAt.Add(-margin)
+}
+
+// Fetcher obtains a new service token
+type Fetcher func(ctx context.Context) (*Token, error)
+
+// Credentials identify a service account
+type Credentials struct {
+	ClientID     string
+	ClientSecret string
+	// Scopes narrows the token to some of the account's subjects; empty
+	// requests all of them
+	Scopes []string
+}
+
+// ClientCredentialsFetcher obtains tokens from auth-service with the client
+// credentials grant
+func ClientCredentialsFetcher(conn *nats.Conn, credentials Credentials, timeout time.Duration, logger log.Logger) Fetcher {
+	return func(ctx context.Context) (*Token, error) {
+		var result struct {
+			Success bool `json:"success"`
+			Data    struct {
+				AccessToken      string `json:"access_token"`
+				ExpiresIn        int64  `json:"expires_in"`
+				Error            string `json:"error,omitempty"`
+				ErrorDescription string `json:"error_description,omitempty"`
+			} `json:"data,omitempty"`
+			Error any `json:"error,omitempty"`
+		}
+
+		req := map[string]string{
+			"grant_type":    "client_credentials",
+			"client_id":     credentials.ClientID,
+			"client_secret": credentials.ClientSecret,
+			"scope":         strings.Join(credentials.Scopes, " "),
+		}
+
+		issuedAt := time.Now()
+		if err := patterns.Request(conn, nats.SubjectAuthOAuthToken, req, &result, timeout, logger); err != nil {
+			return nil, err
+		}
+		if !result.Success {
+			return nil, fmt.Errorf("auth service rejected token request: %v", result.Error)
+		}
+		if result.Data.Error != "" {
+			return nil, fmt.Errorf("auth service refused client credentials: %s: %s", result.Data.Error, result.Data.ErrorDescription)
+		}
+
+		return &Token{
+			Value:     result.Data.AccessToken,
+			IssuedAt:  issuedAt,
+			ExpiresAt: issuedAt.Add(time.Duration(result.Data.ExpiresIn) * time.Second),
+		}, nil
+	}
+}
+
+// TokenSource caches a service token, fetching a new one shortly before it
+// expires. Concurrent callers share a single fetch.
+type TokenSource struct {
+	fetch  Fetcher
+	mu     sync.Mutex
+	token  *Token
+	logger log.Logger
+}
+
+// NewTokenSource creates a token source fetching its tokens with fetch
+func NewTokenSource(fetch Fetcher, logger log.Logger) *TokenSource {
+	return &TokenSource{
+		fetch:  fetch,
+		logger: logger.WithLayer("service-tokens"),
+	}
+}
+
+// Token returns a current token, fetching one when none is cached or the
+// cached one is due for replacement
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token != nil && now.Before(s.token.refreshAt()) {
+		return s.token.Value, nil
+	}
+
+	token, err := s.fetch(ctx)
+	if err != nil {
+		// A token that hasn't expired yet still works until auth-service is back
+		if s.token != nil && now.Before(s.token.ExpiresAt) {
+			s.logger.With("error", err.Error()).Warn("Failed to renew service token, using the current one")
+			return s.token.Value, nil
+		}
+		return "", err
+	}
+
+	s.token = token
+	s.logger.With("expires_at", token.ExpiresAt).Debug("Service token obtained")
+	return token.Value, nil
+}
+
+// Invalidate discards the cached token, e.g. after a handler rejected it
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = nil
+	s.mu.Unlock()
+}
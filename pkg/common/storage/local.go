@@ -0,0 +1,159 @@
+// pkg/common/storage/local.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// contentTypeSuffix names the sidecar file holding an object's content type
+const contentTypeSuffix = ".content-type"
+
+// LocalStore keeps objects as files under a root directory. Mounting the same
+// volume on every replica lets any of them serve an object another stored.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a store rooted at dir, creating it if needed
+func NewLocalStore(dir string) (*LocalStore, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Put implements Store
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, contentType string) (ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	// Write to a temporary file and rename it into place once complete
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, &contextReader{ctx: ctx, r: r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	if err := os.WriteFile(path+contentTypeSuffix, []byte(contentType), 0o640); err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(path + contentTypeSuffix)
+		return ObjectInfo{}, err
+	}
+
+	return ObjectInfo{
+		Key:         key,
+		Size:        size,
+		ContentType: contentType,
+		ModTime:     time.Now(),
+	}, nil
+}
+
+// Open implements Store
+func (s *LocalStore) Open(ctx context.Context, key string) (Object, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	contentType := "application/octet-stream"
+	if raw, err := os.ReadFile(path + contentTypeSuffix); err == nil && len(raw) > 0 {
+		contentType = string(raw)
+	}
+
+	return &localObject{
+		File: file,
+		info: ObjectInfo{
+			Key:         key,
+			Size:        stat.Size(),
+			ContentType: contentType,
+			ModTime:     stat.ModTime(),
+		},
+	}, nil
+}
+
+// Delete implements Store
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(path + contentTypeSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path maps a key to a file under the root, rejecting keys that escape it
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if key == "" || clean == "/" || strings.HasSuffix(clean, contentTypeSuffix) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+// localObject is an open file in a LocalStore
+type localObject struct {
+	*os.File
+	info ObjectInfo
+}
+
+// Info implements Object
+func (o *localObject) Info() ObjectInfo {
+	return o.info
+}
+
+// contextReader stops a copy once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
@@ -0,0 +1,39 @@
+// pkg/common/storage/storage.go
+package storage
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = stderrors.New("storage: object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	ModTime     time.Time `json:"mod_time"`
+}
+
+// Object is an open stored object. It supports seeking so callers can serve
+// byte ranges without reading the whole object.
+type Object interface {
+	io.ReadSeekCloser
+	Info() ObjectInfo
+}
+
+// Store keeps binary objects outside of the services' databases and message
+// payloads, which can't carry large files
+type Store interface {
+	// Put streams r into the object at key. The object only becomes visible
+	// once r is fully read, so a failed or aborted upload leaves nothing behind.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (ObjectInfo, error)
+	// Open opens the object at key, returning ErrNotFound if it doesn't exist
+	Open(ctx context.Context, key string) (Object, error)
+	// Delete removes the object at key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
@@ -2,9 +2,17 @@ module github.com/0xsj/fn-go/pkg
 
 go 1.24.3
 
-require github.com/nats-io/nats.go v1.42.0
+require (
+	github.com/golang-jwt/jwt/v5 v5.3.1
+	github.com/nats-io/nats.go v1.42.0
+	github.com/redis/go-redis/v9 v9.22.0
+)
 
-require filippo.io/edwards25519 v1.1.0 // indirect
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	go.uber.org/atomic v1.11.0 // indirect
+)
 
 require (
 	github.com/go-sql-driver/mysql v1.9.2
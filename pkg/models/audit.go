@@ -0,0 +1,28 @@
+// pkg/models/audit.go
+package models
+
+import "time"
+
+// AuditRecord records a mutating request made through the gateway, or any
+// request made while impersonating a user: who made it, what it asked for
+// and how it was answered. Params have sensitive values
+// redacted before the record leaves the gateway.
+type AuditRecord struct {
+	ID            string         `json:"id"`
+	Time          time.Time      `json:"time"`
+	UserID        string         `json:"user_id,omitempty"`
+	PrincipalID   string         `json:"principal_id,omitempty"`
+	PrincipalType string         `json:"principal_type,omitempty"`
+	ActorID       string         `json:"actor_id,omitempty"` // the administrator impersonating the user, if any
+	Roles         []string       `json:"roles,omitempty"`
+	Method        string         `json:"method"`
+	Path          string         `json:"path"`
+	Route         string         `json:"route,omitempty"`
+	Subject       string         `json:"subject,omitempty"`
+	Params        map[string]any `json:"params,omitempty"`
+	Status        int            `json:"status"`
+	LatencyMS     int64          `json:"latency_ms"`
+	CorrelationID string         `json:"correlation_id"`
+	ClientIP      string         `json:"client_ip"`
+	UserAgent     string         `json:"user_agent,omitempty"`
+}
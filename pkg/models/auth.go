@@ -6,23 +6,33 @@ import "time"
 type TokenType string
 
 const (
-    TokenTypeAccess  TokenType = "access"
-    TokenTypeRefresh TokenType = "refresh"
-    TokenTypeReset   TokenType = "reset"
-    TokenTypeVerify  TokenType = "verify"
+    TokenTypeAccess    TokenType = "access"
+    TokenTypeRefresh   TokenType = "refresh"
+    TokenTypeReset     TokenType = "reset"
+    TokenTypeVerify    TokenType = "verify"
+    TokenTypeUnlock    TokenType = "unlock"     // emailed to unlock an account locked out by failed logins
+    TokenTypeMagicLink TokenType = "magic_link" // emailed to log in without a password
 )
 
 type Token struct {
     ID        string    `json:"id"`
     UserID    string    `json:"user_id"`
+    FamilyID  string    `json:"family_id,omitempty"` // the session a refresh token belongs to
+    Generation int      `json:"generation"`          // refreshes since the family's first token
     Type      TokenType `json:"type"`
     Value     string    `json:"value"`
     ExpiresAt time.Time `json:"expires_at"`
     RevokedAt *time.Time `json:"revoked_at,omitempty"`
+    ReplacedBy string   `json:"replaced_by,omitempty"` // the token a refresh rotated this one into
     CreatedAt time.Time `json:"created_at"`
     Metadata  map[string]any `json:"metadata,omitempty"`
 }
 
+// IsRotated reports whether a refresh has already replaced the token
+func (t *Token) IsRotated() bool {
+    return t.ReplacedBy != ""
+}
+
 type TokenClaims struct {
     UserID    string            `json:"sub"`
     Username  string            `json:"username"`
@@ -34,13 +44,15 @@ type TokenClaims struct {
     Issuer    string            `json:"iss"`
     Audience  string            `json:"aud,omitempty"`
     JWTID     string            `json:"jti"`
+    Actor     string            `json:"actor,omitempty"` // the administrator acting as the user while impersonating them
     Custom    map[string]any `json:"custom,omitempty"`
 }
 
 type Session struct {
     ID           string    `json:"id"`
     UserID       string    `json:"user_id"`
-    RefreshToken string    `json:"refresh_token"`
+    RefreshToken string    `json:"refresh_token"` // the family's current refresh token
+    Generation   int       `json:"generation"`    // generation of the current refresh token
     UserAgent    string    `json:"user_agent"`
     IPAddress    string    `json:"ip_address"`
     LastActive   time.Time `json:"last_active"`
@@ -59,7 +71,205 @@ type Permission struct {
 }
 
 type RolePermission struct {
-    RoleID       string    `json:"role_id"`
-    PermissionID string    `json:"permission_id"`
+    RoleID       string            `json:"role_id"`
+    PermissionID string            `json:"permission_id"`
+    Conditions   []PolicyCondition `json:"conditions,omitempty"` // the grant only applies when all hold
+    CreatedAt    time.Time         `json:"created_at"`
+}
+
+// APIKey is a long-lived credential for machine clients. Only a hash of the
+// key is stored; the prefix identifies the key without revealing it.
+type APIKey struct {
+    ID          string     `json:"id"`
+    Name        string     `json:"name"`
+    Prefix      string     `json:"prefix"`
+    KeyHash     string     `json:"-"`
+    OwnerID     string     `json:"owner_id"`
+    Scopes      []string   `json:"scopes"`
+    ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+    LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+    RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+    RotatedFrom string     `json:"rotated_from,omitempty"`
+    CreatedAt   time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the key is neither revoked nor expired
+func (k *APIKey) IsActive(now time.Time) bool {
+    if k.RevokedAt != nil {
+        return false
+    }
+    return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// MFAEnrollment is a user's TOTP second factor. The secret is stored
+// encrypted and recovery codes only as hashes; an enrollment counts once
+// the user has confirmed it with a first code.
+type MFAEnrollment struct {
+    UserID          string     `json:"user_id"`
+    SecretEncrypted []byte     `json:"-"`
+    RecoveryCodes   []string   `json:"-"` // hashes of the unused codes
+    LastUsedStep    int64      `json:"-"` // codes at or before this step are spent
+    ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+    CreatedAt       time.Time  `json:"created_at"`
+    UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// IsConfirmed reports whether the enrollment has been completed
+func (e *MFAEnrollment) IsConfirmed() bool {
+    return e.ConfirmedAt != nil
+}
+
+// PasswordHistoryEntry is a password a user has had, kept as its hash so it
+// can't be reused. The newest entry is the current password.
+type PasswordHistoryEntry struct {
+    ID           string    `json:"id"`
+    UserID       string    `json:"user_id"`
+    PasswordHash string    `json:"-"`
     CreatedAt    time.Time `json:"created_at"`
-}
\ No newline at end of file
+}
+
+// SigningKey is a key auth-service signs tokens with. The private key is
+// stored encrypted. The public key is published from creation until the key
+// expires, so verifiers know it before it signs anything and keep it while
+// the tokens it signed are still valid.
+type SigningKey struct {
+    ID                  string     `json:"id"` // the kid header of the tokens it signs
+    Algorithm           string     `json:"algorithm"`
+    PrivateKeyEncrypted []byte     `json:"-"`
+    PublicKey           []byte     `json:"-"` // PKIX, DER encoded
+    ActivatesAt         time.Time  `json:"activates_at"`
+    ExpiresAt           *time.Time `json:"expires_at,omitempty"`
+    CreatedAt           time.Time  `json:"created_at"`
+}
+
+// IsPublished reports whether the key can still verify tokens
+func (k *SigningKey) IsPublished(now time.Time) bool {
+    return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+type OAuthClientType string
+
+const (
+    OAuthClientConfidential OAuthClientType = "confidential" // authenticates with a client secret
+    OAuthClientPublic       OAuthClientType = "public"       // e.g. single-page and mobile apps
+    OAuthClientService      OAuthClientType = "service"      // a service account using client credentials; scopes are NATS subjects
+)
+
+// OAuthClient is a partner application allowed to sign users in through the
+// authorization code flow. Only a hash of a confidential client's secret is
+// stored.
+type OAuthClient struct {
+    ID           string          `json:"id"` // the client_id
+    Name         string          `json:"name"`
+    Type         OAuthClientType `json:"type"`
+    SecretHash   string          `json:"-"`
+    RedirectURIs []string        `json:"redirect_uris"`
+    Scopes       []string        `json:"scopes"` // the most the client may request
+    CreatedAt    time.Time       `json:"created_at"`
+    UpdatedAt    time.Time       `json:"updated_at"`
+    RevokedAt    *time.Time      `json:"revoked_at,omitempty"`
+}
+
+// IsConfidential reports whether the client must authenticate with its secret
+func (c *OAuthClient) IsConfidential() bool {
+    return c.Type == OAuthClientConfidential || c.Type == OAuthClientService
+}
+
+// IsService reports whether the client is a service account, which acts as
+// itself rather than on behalf of a user
+func (c *OAuthClient) IsService() bool {
+    return c.Type == OAuthClientService
+}
+
+// IsActive reports whether the client has not been revoked
+func (c *OAuthClient) IsActive() bool {
+    return c.RevokedAt == nil
+}
+
+// AllowsRedirectURI reports whether a redirect URI is registered for the
+// client. URIs must match exactly.
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+    for _, registered := range c.RedirectURIs {
+        if registered == uri {
+            return true
+        }
+    }
+    return false
+}
+
+// OAuthConsent records the scopes a user has granted a client, so the user
+// isn't asked again for scopes already granted
+type OAuthConsent struct {
+    UserID    string    `json:"user_id"`
+    ClientID  string    `json:"client_id"`
+    Scopes    []string  `json:"scopes"`
+    GrantedAt time.Time `json:"granted_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AuthorizationCode is a one-time code a client exchanges for tokens. Only a
+// hash of the code is stored, with the PKCE challenge the exchange must answer.
+type AuthorizationCode struct {
+    CodeHash            string     `json:"-"`
+    ClientID            string     `json:"client_id"`
+    UserID              string     `json:"user_id"`
+    RedirectURI         string     `json:"redirect_uri"`
+    Scopes              []string   `json:"scopes"`
+    CodeChallenge       string     `json:"-"`
+    CodeChallengeMethod string     `json:"code_challenge_method"`
+    Nonce               string     `json:"-"`
+    SessionID           string     `json:"session_id,omitempty"` // the session the exchange started
+    ExpiresAt           time.Time  `json:"expires_at"`
+    UsedAt              *time.Time `json:"used_at,omitempty"`
+    CreatedAt           time.Time  `json:"created_at"`
+}
+
+// IsUsed reports whether the code has already been exchanged
+func (c *AuthorizationCode) IsUsed() bool {
+    return c.UsedAt != nil
+}
+
+// RoleDefinition is a named set of permissions. A role inherits every
+// permission of its parent, e.g. dispatcher builds on customer.
+type RoleDefinition struct {
+    ID          string    `json:"id"`
+    Name        string    `json:"name"`
+    Description string    `json:"description"`
+    ParentID    string    `json:"parent_id,omitempty"`
+    CreatedAt   time.Time `json:"created_at"`
+    UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserRole assigns a role to a user on top of the role on their profile
+type UserRole struct {
+    UserID     string    `json:"user_id"`
+    RoleID     string    `json:"role_id"`
+    AssignedAt time.Time `json:"assigned_at"`
+    AssignedBy string    `json:"assigned_by,omitempty"`
+}
+
+// Policy condition operators
+const (
+    PolicyOpEquals    = "eq"
+    PolicyOpNotEquals = "ne"
+    PolicyOpIn        = "in"
+    PolicyOpContains  = "contains"
+)
+
+// PolicyCondition restricts a permission grant by the attributes of the
+// caller ("subject.*") and of the resource acted on ("resource.*"), e.g.
+// resource.reported_by eq subject.id. Value is a literal unless it names
+// another attribute.
+type PolicyCondition struct {
+    Attribute string `json:"attribute"`
+    Operator  string `json:"operator"`
+    Value     string `json:"value"`
+}
+
+// PermissionGrant is a permission as granted to one of a user's roles
+type PermissionGrant struct {
+    Permission
+    RoleID     string            `json:"role_id"`
+    RoleName   string            `json:"role_name"`
+    Conditions []PolicyCondition `json:"conditions,omitempty"`
+}
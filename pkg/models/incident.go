@@ -102,6 +102,8 @@ type IncidentAttachment struct {
     ContentType string    `json:"content_type"`
     UploadedBy  string    `json:"uploaded_by"`
     StoragePath string    `json:"storage_path"`
+    // Checksum is the hex SHA-256 of the file contents
+    Checksum    string    `json:"checksum,omitempty"`
     CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -0,0 +1,212 @@
+// pkg/repository/cursor.go
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/errors"
+)
+
+// CursorTimeFormat is how times are held in cursors: UTC, fixed width, so it
+// compares as a MySQL DATETIME and sorts correctly as a string
+const CursorTimeFormat = "2006-01-02 15:04:05.000000"
+
+// Cursor marks a position in a keyset-ordered list: the sort key values of
+// the row it points past, and whether to read the rows before it instead
+type Cursor struct {
+	Values   []any `json:"v"`
+	Backward bool  `json:"b,omitempty"`
+}
+
+// NewCursor creates a cursor after a row with the given sort key values
+func NewCursor(backward bool, values ...any) *Cursor {
+	normalized := make([]any, len(values))
+	for i, value := range values {
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(CursorTimeFormat)
+		}
+		normalized[i] = value
+	}
+	return &Cursor{Values: normalized, Backward: backward}
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor handed back by a client, checking it carries
+// one value per sort key
+func DecodeCursor(s string, keys int) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid cursor", err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.NewBadRequestError("Invalid cursor", err)
+	}
+	if len(cursor.Values) != keys {
+		return nil, errors.NewBadRequestError("Invalid cursor", nil)
+	}
+	return &cursor, nil
+}
+
+// PageRequest carries the pagination parameters of a list subject. Limit
+// accepts a number or, as the gateway forwards query parameters, a string.
+type PageRequest struct {
+	Cursor string      `json:"cursor,omitempty"`
+	Limit  json.Number `json:"limit,omitempty"`
+}
+
+// Parse decodes the cursor and bounds the limit, which defaults to defaultLimit
+func (p PageRequest) Parse(keys, defaultLimit, maxLimit int) (*Cursor, int, error) {
+	limit := defaultLimit
+	if p.Limit != "" {
+		n, err := strconv.Atoi(p.Limit.String())
+		if err != nil || n <= 0 {
+			return nil, 0, errors.NewBadRequestError("Limit must be a positive integer", err)
+		}
+		limit = min(n, maxLimit)
+	}
+
+	if p.Cursor == "" {
+		return nil, limit, nil
+	}
+	cursor, err := DecodeCursor(p.Cursor, keys)
+	if err != nil {
+		return nil, 0, err
+	}
+	return cursor, limit, nil
+}
+
+// PageCursors are the encoded cursors to the pages either side of a page;
+// empty when there is no such page
+type PageCursors struct {
+	Next string
+	Prev string
+}
+
+// Page trims rows fetched for a cursor (limit+1 of them, in reverse when
+// reading backward) to one page in sort order and returns the cursors to the
+// pages either side. key returns the sort key values of a row.
+func Page[T any](rows []T, limit int, cursor *Cursor, key func(T) []any) ([]T, PageCursors) {
+	backward := cursor != nil && cursor.Backward
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	var cursors PageCursors
+	if len(rows) == 0 {
+		return rows, cursors
+	}
+
+	// Rows exist past a page in its reading direction when more were
+	// fetched, and in the other direction whenever a cursor was followed
+	first, last := rows[0], rows[len(rows)-1]
+	if (backward && more) || (!backward && cursor != nil) {
+		cursors.Prev = NewCursor(true, key(first)...).Encode()
+	}
+	if (!backward && more) || backward {
+		cursors.Next = NewCursor(false, key(last)...).Encode()
+	}
+	return rows, cursors
+}
+
+// PageSlice pages items held in memory the way BuildCursorQueryClauses pages
+// a table, sorting them by key in one direction. It serves stores and mocks
+// that don't have a database behind them yet.
+func PageSlice[T any](items []T, cursor *Cursor, limit int, descending bool, key func(T) []any) ([]T, PageCursors) {
+	sorted := append([]T{}, items...)
+	backward := cursor != nil && cursor.Backward
+	sort.SliceStable(sorted, func(i, j int) bool {
+		c := CompareKeys(key(sorted[i]), key(sorted[j]))
+		// Reading backward walks the order in reverse
+		if descending != backward {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	rows := make([]T, 0, limit+1)
+	for _, item := range sorted {
+		if cursor != nil {
+			c := CompareKeys(key(item), cursor.Values)
+			if descending != backward {
+				c = -c
+			}
+			if c <= 0 {
+				continue
+			}
+		}
+		rows = append(rows, item)
+		if len(rows) > limit {
+			break
+		}
+	}
+	return Page(rows, limit, cursor, key)
+}
+
+// CompareKeys orders two sort keys field by field, comparing numbers by value
+// and anything else, including times held as CursorTimeFormat, as strings
+func CompareKeys(a, b []any) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareValues(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+func compareValues(a, b any) int {
+	x, xNumeric := number(a)
+	y, yNumeric := number(b)
+	if xNumeric && yNumeric {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(keyString(a), keyString(b))
+}
+
+// number converts the numeric values found in keys and decoded cursors
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// keyString formats a value the way a cursor holds it
+func keyString(v any) string {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(CursorTimeFormat)
+	}
+	return fmt.Sprint(v)
+}
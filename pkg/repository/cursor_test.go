@@ -0,0 +1,238 @@
+// pkg/repository/cursor_test.go
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type pagedItem struct {
+	ID       string
+	Priority int
+	Created  time.Time
+}
+
+func pagedItemKey(item pagedItem) []any {
+	return []any{item.Created, item.ID}
+}
+
+// pagedItems returns n items, created in pairs so the ID has to break ties
+func pagedItems(n int) []pagedItem {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	items := make([]pagedItem, n)
+	for i := range items {
+		items[i] = pagedItem{
+			ID:       fmt.Sprintf("item-%02d", i),
+			Priority: n - i,
+			Created:  base.Add(time.Duration(i/2) * time.Minute),
+		}
+	}
+	return items
+}
+
+func ids(items []pagedItem) []string {
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = item.ID
+	}
+	return result
+}
+
+// decode parses a cursor as a client would hand it back
+func decode(t *testing.T, encoded string) *Cursor {
+	t.Helper()
+
+	cursor, err := DecodeCursor(encoded, 2)
+	if err != nil {
+		t.Fatalf("DecodeCursor(%q): %v", encoded, err)
+	}
+	return cursor
+}
+
+func TestPageSliceWalksForwardAndBack(t *testing.T) {
+	items := pagedItems(7)
+
+	// Shuffled input is paged in sort order
+	shuffled := []pagedItem{items[4], items[0], items[6], items[2], items[1], items[5], items[3]}
+
+	page, cursors := PageSlice(shuffled, nil, 3, false, pagedItemKey)
+	if got, want := ids(page), ids(items[0:3]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("first page: got %v, want %v", got, want)
+	}
+	if cursors.Prev != "" {
+		t.Errorf("first page has a previous cursor %q", cursors.Prev)
+	}
+
+	page, cursors = PageSlice(shuffled, decode(t, cursors.Next), 3, false, pagedItemKey)
+	if got, want := ids(page), ids(items[3:6]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("second page: got %v, want %v", got, want)
+	}
+	second := cursors
+
+	page, cursors = PageSlice(shuffled, decode(t, cursors.Next), 3, false, pagedItemKey)
+	if got, want := ids(page), ids(items[6:7]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("last page: got %v, want %v", got, want)
+	}
+	if cursors.Next != "" {
+		t.Errorf("last page has a next cursor %q", cursors.Next)
+	}
+
+	// Going back from the last page returns the second page in sort order
+	page, cursors = PageSlice(shuffled, decode(t, cursors.Prev), 3, false, pagedItemKey)
+	if got, want := ids(page), ids(items[3:6]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("back to second page: got %v, want %v", got, want)
+	}
+	if cursors.Next == "" || cursors.Prev == "" {
+		t.Errorf("second page read backward has cursors %+v, want both", cursors)
+	}
+	if !reflect.DeepEqual(decode(t, cursors.Next).Values, decode(t, second.Next).Values) {
+		t.Errorf("second page read backward points next to %v, want %v", decode(t, cursors.Next).Values, decode(t, second.Next).Values)
+	}
+
+	page, cursors = PageSlice(shuffled, decode(t, cursors.Prev), 3, false, pagedItemKey)
+	if got, want := ids(page), ids(items[0:3]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("back to first page: got %v, want %v", got, want)
+	}
+	if cursors.Prev != "" {
+		t.Errorf("first page read backward has a previous cursor %q", cursors.Prev)
+	}
+}
+
+func TestPageSliceDescending(t *testing.T) {
+	items := pagedItems(5)
+	key := func(item pagedItem) []any { return []any{item.Priority} }
+
+	var got []string
+	var cursor *Cursor
+	for pages := 0; ; pages++ {
+		if pages > len(items) {
+			t.Fatal("paging did not finish")
+		}
+		page, cursors := PageSlice(items, cursor, 2, true, key)
+		got = append(got, ids(page)...)
+		if cursors.Next == "" {
+			break
+		}
+		// Numbers come back from JSON as float64 and still compare by value
+		decoded, err := DecodeCursor(cursors.Next, 1)
+		if err != nil {
+			t.Fatalf("DecodeCursor: %v", err)
+		}
+		cursor = decoded
+	}
+
+	// Priority falls as the index rises, so descending priority is index order
+	if want := ids(items); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPageSliceEmpty(t *testing.T) {
+	page, cursors := PageSlice(nil, nil, 10, false, pagedItemKey)
+	if len(page) != 0 || cursors != (PageCursors{}) {
+		t.Errorf("got page %v with cursors %+v, want an empty page without cursors", page, cursors)
+	}
+
+	// A cursor past the end gives an empty page, with nowhere to go
+	past := NewCursor(false, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), "z")
+	page, cursors = PageSlice(pagedItems(3), past, 10, false, pagedItemKey)
+	if len(page) != 0 || cursors != (PageCursors{}) {
+		t.Errorf("got page %v with cursors %+v past the end, want an empty page without cursors", ids(page), cursors)
+	}
+}
+
+func TestPageTrimsBackwardRows(t *testing.T) {
+	items := pagedItems(5)
+	// A backward query returns rows nearest the cursor first, limit+1 of them
+	rows := []pagedItem{items[4], items[3], items[2], items[1]}
+
+	page, cursors := Page(rows, 3, NewCursor(true, items[4].Created, "item-99"), pagedItemKey)
+	if got, want := ids(page), ids(items[2:5]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	prev := decode(t, cursors.Prev)
+	if !prev.Backward || !reflect.DeepEqual(prev.Values, []any{items[2].Created.Format(CursorTimeFormat), items[2].ID}) {
+		t.Errorf("previous cursor %+v doesn't point before %s", prev, items[2].ID)
+	}
+	next := decode(t, cursors.Next)
+	if next.Backward || !reflect.DeepEqual(next.Values, []any{items[4].Created.Format(CursorTimeFormat), items[4].ID}) {
+		t.Errorf("next cursor %+v doesn't point after %s", next, items[4].ID)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 15, 123456000, time.FixedZone("EST", -5*60*60))
+
+	cursor := decode(t, NewCursor(true, created, "item-01").Encode())
+	if !cursor.Backward {
+		t.Error("cursor lost its direction")
+	}
+	if want := []any{"2024-03-01 17:30:15.123456", "item-01"}; !reflect.DeepEqual(cursor.Values, want) {
+		t.Errorf("got values %v, want %v", cursor.Values, want)
+	}
+}
+
+func TestDecodeCursorErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"not base64", "!!!"},
+		{"not JSON", "bm90IGpzb24"},
+		{"wrong number of values", NewCursor(false, "only-one").Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.encoded, 2); err == nil {
+				t.Errorf("DecodeCursor(%q) succeeded, want an error", tt.encoded)
+			}
+		})
+	}
+}
+
+func TestPageRequestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     PageRequest
+		limit   int
+		wantErr bool
+	}{
+		{"default limit", PageRequest{}, 20, false},
+		{"limit as number", PageRequest{Limit: "5"}, 5, false},
+		{"limit capped", PageRequest{Limit: "500"}, 100, false},
+		{"zero limit", PageRequest{Limit: "0"}, 0, true},
+		{"negative limit", PageRequest{Limit: "-1"}, 0, true},
+		{"limit not a number", PageRequest{Limit: "ten"}, 0, true},
+		{"bad cursor", PageRequest{Cursor: "!!!"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, limit, err := tt.req.Parse(2, 20, 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (limit != tt.limit || cursor != nil) {
+				t.Errorf("got limit %d and cursor %v, want limit %d and no cursor", limit, cursor, tt.limit)
+			}
+		})
+	}
+}
+
+func TestPageRequestLimitFromJSON(t *testing.T) {
+	// The gateway forwards query parameters as strings; services send numbers
+	for _, body := range []string{`{"limit":7}`, `{"limit":"7"}`} {
+		var req PageRequest
+		if err := json.Unmarshal([]byte(body), &req); err != nil {
+			t.Fatalf("unmarshal %s: %v", body, err)
+		}
+		if _, limit, err := req.Parse(2, 20, 100); err != nil || limit != 7 {
+			t.Errorf("%s: got limit %d and error %v, want 7", body, limit, err)
+		}
+	}
+}
@@ -5,6 +5,9 @@ type QueryOptions struct {
 	Sort     []SortOption
 	Limit    int
 	Offset   int
+	// Cursor selects a keyset page instead of Offset. Sort must end with a
+	// unique field so every row has a distinct position.
+	Cursor   *Cursor
 }
 
 type SortOption struct {
@@ -60,6 +63,16 @@ func WithPagination(limit, offset int) QueryOption {
 	}
 }
 
+// WithCursor selects the page of limit rows after cursor, or the first page if
+// cursor is nil
+func WithCursor(cursor *Cursor, limit int) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.Cursor = cursor
+		opts.Limit = limit
+		opts.Offset = 0
+	}
+}
+
 // ApplyOptions applies all query options
 func ApplyOptions(opts ...QueryOption) QueryOptions {
 	options := QueryOptions{}
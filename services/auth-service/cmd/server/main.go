@@ -1,14 +1,24 @@
+// services/auth-service/cmd/server/main.go
 package main
 
 import (
+	"context"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
+	"github.com/0xsj/fn-go/pkg/common/db"
+	"github.com/0xsj/fn-go/pkg/common/lifecycle"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/serviceauth"
+	"github.com/0xsj/fn-go/services/auth-service/internal/client"
+	"github.com/0xsj/fn-go/services/auth-service/internal/config"
+	"github.com/0xsj/fn-go/services/auth-service/internal/handlers"
+	"github.com/0xsj/fn-go/services/auth-service/internal/lockout"
+	repository "github.com/0xsj/fn-go/services/auth-service/internal/repository/mysql"
+	"github.com/0xsj/fn-go/services/auth-service/internal/service"
+	"github.com/0xsj/fn-go/services/auth-service/internal/validation"
+	"github.com/0xsj/fn-go/services/auth-service/pkg/jwt"
 )
 
 func main() {
@@ -17,44 +27,168 @@ func main() {
 	logger = logger.WithLayer("auth-service")
 	logger.Info("Initializing auth service")
 
+	// Load configuration
+	logger.Info("Loading configuration")
+	cfg, err := config.Load(logger)
+	if err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to load configuration")
+	}
+
+	// Initialize database connection
+	logger.Info("Connecting to database")
+	dbConfig := db.MySQLConfig{
+		DatabaseConfig: db.DatabaseConfig{
+			Host:            cfg.Database.Host,
+			Port:            cfg.Database.Port,
+			Username:        cfg.Database.Username,
+			Password:        cfg.Database.Password,
+			Database:        cfg.Database.Database,
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+			ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+			Timeout:         cfg.Database.Timeout,
+		},
+		ParseTime: true,
+		Charset:   "utf8mb4",
+	}
+
+	sqlDB, err := db.OpenMySQL(logger, dbConfig)
+	if err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to connect to database")
+	}
+	logger.Info("Successfully connected to database")
+
 	// Initialize NATS client
 	logger.Info("Connecting to NATS server")
-	config := nats.DefaultConfig()
-	client, err := nats.NewClient(logger, config)
+	natsConfig := nats.Config{
+		URLs:          []string{cfg.NATS.URL},
+		MaxReconnect:  cfg.NATS.MaxReconnects,
+		ReconnectWait: cfg.NATS.ReconnectWait,
+		Timeout:       cfg.NATS.RequestTimeout,
+	}
+
+	natsClient, err := nats.NewClient(logger, natsConfig)
 	if err != nil {
 		logger.With("error", err.Error()).Fatal("Failed to connect to NATS")
 	}
-	defer client.Close()
 	logger.Info("Successfully connected to NATS server")
 
+	// Initialize repositories and clients
+	authRepo := repository.NewAuthRepository(sqlDB, logger)
+	publisher := patterns.NewPublisher(natsClient.Conn(), cfg.Service.Name, logger)
+
+	// Load the signing keys before anything can issue a token
+	keySet := jwt.NewKeySet()
+	keyManager, err := service.NewSigningKeyManager(authRepo, keySet, publisher, cfg.Signing, logger)
+	if err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to create signing key manager")
+	}
+	if err := keyManager.Start(context.Background()); err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to load signing keys")
+	}
+
+	var legacySecret string
+	if cfg.Signing.AcceptLegacyTokens {
+		legacySecret = cfg.Auth.JWTSecret
+	}
+	jwtManager := jwt.NewJWTManager(keySet, legacySecret, cfg.Auth.AccessTokenExpiry, cfg.Auth.RefreshTokenExpiry)
+
+	// Calls to user-service carry a service token auth-service issues itself
+	serviceTokens := serviceauth.NewTokenSource(
+		jwtManager.ServiceTokenFetcher(cfg.Service.Name, cfg.Service.Scopes, cfg.OAuth.ServiceTokenExpiry), logger)
+	userClient := client.NewNATSUserClient(natsClient.Conn(), serviceTokens, logger)
+	notificationClient := client.NewNATSNotificationClient(natsClient.Conn(), serviceTokens, logger)
+
+	// Failed logins are counted in Redis so every replica enforces the same lockouts
+	redisClient := newRedisClient(cfg, logger)
+	lockoutGuard := newLockoutGuard(cfg, redisClient, logger)
+	passwordChecker := newPasswordChecker(cfg, logger)
+
+	// Initialize services
+	authService := service.NewAuthService(authRepo, userClient, jwtManager, publisher, lockoutGuard, notificationClient, passwordChecker, cfg, logger)
+
+	// Create handlers
+	healthHandler := handlers.NewHealthHandler(authService, logger)
+	authHandler := handlers.NewAuthHandler(authService, logger)
+	keyHandler := handlers.NewKeyHandler(keyManager, logger)
+
 	// Register handlers
 	logger.Info("Setting up request handlers")
-	setupHandlers(client.Conn(), logger)
+	healthHandler.RegisterHandlers(natsClient.Conn())
+	authHandler.RegisterHandlers(natsClient.Conn())
+	keyHandler.RegisterHandlers(natsClient.Conn())
 	logger.Info("Handlers registered, service is ready")
 
-	// Wait for termination signal
-	logger.Info("Waiting for termination signal")
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
-	<-signalCh
+	// Drain NATS before closing the database, so handlers in flight can
+	// finish their queries
+	shutdown := lifecycle.NewManager(cfg.Server, logger)
+	shutdown.OnShutdown("signing-keys", keyManager.Close)
+	shutdown.OnShutdown("nats", natsClient.Drain)
+	shutdown.OnShutdown("database", lifecycle.CloseFunc(sqlDB.Close))
+	if redisClient != nil {
+		shutdown.OnShutdown("redis", lifecycle.CloseFunc(redisClient.Close))
+	}
+	if err := shutdown.Run(); err != nil {
+		logger.With("error", err.Error()).Error("Graceful shutdown incomplete")
+		os.Exit(1)
+	}
+	logger.Info("Shutdown complete")
+}
+
+// newRedisClient connects to Redis when the lockout store uses it. It returns
+// nil if Redis isn't needed or can't be reached.
+func newRedisClient(cfg *config.Config, logger log.Logger) *db.RedisClient {
+	if cfg.Lockout.Backend != config.LockoutBackendRedis {
+		return nil
+	}
+
+	redisClient, err := db.NewRedisClient(logger, cfg.Redis)
+	if err != nil {
+		logger.With("error", err.Error()).Warn("Redis unavailable, falling back to per-replica memory")
+		return nil
+	}
+	return redisClient
+}
+
+// newLockoutGuard builds the login lockout guard, sharing failures across
+// replicas through Redis when configured and falling back to per-replica
+// memory otherwise
+func newLockoutGuard(cfg *config.Config, redisClient *db.RedisClient, logger log.Logger) *lockout.Guard {
+	var store lockout.Store = lockout.NewMemoryStore()
+	if redisClient != nil {
+		store = lockout.NewFallbackStore(lockout.NewRedisStore(redisClient, "auth:lockout:"), store, logger)
+	}
 
-	logger.Info("Shutting down")
+	return lockout.NewGuard(store, lockout.Policy{
+		MaxAttempts:   cfg.Lockout.MaxAttempts,
+		IPMaxAttempts: cfg.Lockout.IPMaxAttempts,
+		BaseDuration:  cfg.Lockout.BaseDuration,
+		MaxDuration:   cfg.Lockout.MaxDuration,
+		Window:        cfg.Lockout.Window,
+	}, logger)
 }
 
-func setupHandlers(conn *nats.Conn, logger log.Logger) {
-	patterns.HandleRequest(conn, "service.auth.health", func(data []byte) (any, error) {
-		handlerLogger := logger.With("subject", "service.auth.health")
-		handlerLogger.Info("Received health check request")
-		
-		response := map[string]any{
-			"service":  "auth-service",
-			"status":   "ok",
-			"time":     time.Now().Format(time.RFC3339),
-			"version":  "1.0.0",
-			"features": []string{"authentication", "authorization", "token-management"},
+// newPasswordChecker builds the password policy checker, opening the
+// breached password list when one is configured
+func newPasswordChecker(cfg *config.Config, logger log.Logger) *validation.PasswordChecker {
+	var breached *validation.BreachedList
+	if cfg.Password.BreachedList != "" {
+		var err error
+		breached, err = validation.OpenBreachedList(cfg.Password.BreachedList, logger)
+		if err != nil {
+			logger.With("error", err.Error()).Fatal("Failed to open breached password list")
 		}
-		
-		handlerLogger.Info("Returning health check response")
-		return response, nil
-	}, logger)
-}
\ No newline at end of file
+		logger.With("dir", cfg.Password.BreachedList).Info("Screening passwords against breached password list")
+	}
+
+	return validation.NewPasswordChecker(validation.PasswordPolicy{
+		MinLength:     cfg.Password.MinLength,
+		MaxLength:     cfg.Password.MaxLength,
+		RequireUpper:  cfg.Password.RequireUpper,
+		RequireLower:  cfg.Password.RequireLower,
+		RequireDigit:  cfg.Password.RequireDigit,
+		RequireSymbol: cfg.Password.RequireSymbol,
+		RejectSimilar: cfg.Password.RejectSimilar,
+	}, breached)
+}
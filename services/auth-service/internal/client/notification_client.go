@@ -0,0 +1,61 @@
+// services/auth-service/internal/client/notification_client.go
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/serviceauth"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/service"
+)
+
+// NATSNotificationClient implements NotificationClient using NATS for
+// communication. Requests carry auth-service's service token.
+type NATSNotificationClient struct {
+	client *serviceauth.Client
+	logger log.Logger
+}
+
+// NewNATSNotificationClient creates a new NATS-based notification service client
+func NewNATSNotificationClient(conn *nats.Conn, tokens *serviceauth.TokenSource, logger log.Logger) service.NotificationClient {
+	logger = logger.WithLayer("nats-notification-client")
+	return &NATSNotificationClient{
+		client: serviceauth.NewClient(conn, tokens, 5*time.Second, logger),
+		logger: logger,
+	}
+}
+
+// SendEmail sends a high-priority email to one recipient
+func (c *NATSNotificationClient) SendEmail(ctx context.Context, to, subject, content string) error {
+	c.logger.With("subject", subject).Debug("Sending email via NATS")
+
+	request := map[string]any{
+		"type":       models.NotificationTypeEmail,
+		"recipients": []string{to},
+		"subject":    subject,
+		"content":    content,
+		"priority":   models.NotificationPriorityHigh,
+	}
+
+	var response struct {
+		Success bool `json:"success"`
+		Error   any  `json:"error,omitempty"`
+	}
+
+	err := c.client.Request(ctx, "notification.send", request, &response)
+	if err != nil {
+		c.logger.With("error", err.Error()).Error("NATS request failed")
+		return domain.WithOperation(err, "notification_service_request")
+	}
+
+	if !response.Success {
+		c.logger.With("error", response.Error).Warn("Notification service returned error")
+		return domain.NewInternalError("Failed to send email")
+	}
+
+	return nil
+}
@@ -7,25 +7,25 @@ import (
 
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
-	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/serviceauth"
 	"github.com/0xsj/fn-go/pkg/models"
 	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
 	"github.com/0xsj/fn-go/services/auth-service/internal/service"
 )
 
-// NATSUserClient implements UserServiceClient using NATS for communication
+// NATSUserClient implements UserServiceClient using NATS for communication.
+// Requests carry auth-service's service token.
 type NATSUserClient struct {
-	conn    *nats.Conn
-	logger  log.Logger
-	timeout time.Duration
+	client *serviceauth.Client
+	logger log.Logger
 }
 
 // NewNATSUserClient creates a new NATS-based user service client
-func NewNATSUserClient(conn *nats.Conn, logger log.Logger) service.UserServiceClient {
+func NewNATSUserClient(conn *nats.Conn, tokens *serviceauth.TokenSource, logger log.Logger) service.UserServiceClient {
+	logger = logger.WithLayer("nats-user-client")
 	return &NATSUserClient{
-		conn:    conn,
-		logger:  logger.WithLayer("nats-user-client"),
-		timeout: 5 * time.Second,
+		client: serviceauth.NewClient(conn, tokens, 5*time.Second, logger),
+		logger: logger,
 	}
 }
 
@@ -41,7 +41,7 @@ func (c *NATSUserClient) GetUser(ctx context.Context, userID string) (*models.Us
 		Error   any          `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.get", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.get", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return nil, domain.WithOperation(err, "user_service_request")
@@ -59,6 +59,41 @@ func (c *NATSUserClient) GetUser(ctx context.Context, userID string) (*models.Us
 	return response.Data, nil
 }
 
+// GetUserWithPassword gets a user by ID with their password hash
+func (c *NATSUserClient) GetUserWithPassword(ctx context.Context, userID string) (*models.User, error) {
+	c.logger.With("user_id", userID).Debug("Getting user credentials via NATS")
+
+	request := map[string]any{"id": userID, "includePassword": true}
+
+	var response struct {
+		Success bool `json:"success"`
+		Data    *struct {
+			*models.User
+			PasswordHash string `json:"passwordHash"`
+		} `json:"data,omitempty"`
+		Error any `json:"error,omitempty"`
+	}
+
+	err := c.client.Request(ctx, "user.get", request, &response)
+	if err != nil {
+		c.logger.With("error", err.Error()).Error("NATS request failed")
+		return nil, domain.WithOperation(err, "user_service_request")
+	}
+
+	if !response.Success {
+		c.logger.With("error", response.Error).Warn("User service returned error")
+		return nil, domain.NewUserNotFoundError(userID)
+	}
+
+	if response.Data == nil || response.Data.User == nil {
+		return nil, domain.NewUserNotFoundError(userID)
+	}
+
+	user := response.Data.User
+	user.Password = response.Data.PasswordHash
+	return user, nil
+}
+
 // GetUserByEmail gets a user by email
 func (c *NATSUserClient) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	c.logger.With("email", email).Debug("Getting user by email via NATS")
@@ -71,7 +106,7 @@ func (c *NATSUserClient) GetUserByEmail(ctx context.Context, email string) (*mod
 		Error   any          `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.get_by_email", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.get_by_email", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return nil, domain.WithOperation(err, "user_service_request")
@@ -101,7 +136,7 @@ func (c *NATSUserClient) GetUserByUsername(ctx context.Context, username string)
 		Error   any          `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.get_by_username", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.get_by_username", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return nil, domain.WithOperation(err, "user_service_request")
@@ -139,7 +174,7 @@ func (c *NATSUserClient) CreateUser(ctx context.Context, user *models.User) (*mo
 		Error   any          `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.create", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.create", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return nil, domain.WithOperation(err, "user_service_request")
@@ -176,7 +211,7 @@ func (c *NATSUserClient) UpdateUser(ctx context.Context, userID string, updates
 		Error   any          `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.update", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.update", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return nil, domain.WithOperation(err, "user_service_request")
@@ -208,7 +243,7 @@ func (c *NATSUserClient) UpdateLastLogin(ctx context.Context, userID string, log
 		Error   any  `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.update_last_login", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.update_last_login", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return domain.WithOperation(err, "user_service_request")
@@ -233,7 +268,7 @@ func (c *NATSUserClient) IncrementFailedLogins(ctx context.Context, userID strin
 		Error   any  `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.increment_failed_logins", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.increment_failed_logins", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return domain.WithOperation(err, "user_service_request")
@@ -258,7 +293,7 @@ func (c *NATSUserClient) ResetFailedLogins(ctx context.Context, userID string) e
 		Error   any  `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.reset_failed_logins", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.reset_failed_logins", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return domain.WithOperation(err, "user_service_request")
@@ -286,7 +321,7 @@ func (c *NATSUserClient) SetEmailVerified(ctx context.Context, userID string, ve
 		Error   any  `json:"error,omitempty"`
 	}
 
-	err := patterns.Request(c.conn, "user.set_email_verified", request, &response, c.timeout, c.logger)
+	err := c.client.Request(ctx, "user.set_email_verified", request, &response)
 	if err != nil {
 		c.logger.With("error", err.Error()).Error("NATS request failed")
 		return domain.WithOperation(err, "user_service_request")
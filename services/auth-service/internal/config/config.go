@@ -2,24 +2,35 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/0xsj/fn-go/pkg/common/config"
+	"github.com/0xsj/fn-go/pkg/common/db"
 	"github.com/0xsj/fn-go/pkg/common/log"
 )
 
 type Config struct {
-	Service   ServiceConfig
-	Server    config.ServerConfig
-	Database  config.DatabaseConfig
-	NATS      config.NATSConfig
-	Logging   config.LogConfig
-	Auth      AuthConfig
+	Service       ServiceConfig
+	Server        config.ServerConfig
+	Database      config.DatabaseConfig
+	NATS          config.NATSConfig
+	Logging       config.LogConfig
+	Auth          AuthConfig
+	MFA           MFAConfig
+	Signing       SigningConfig
+	OAuth         OAuthConfig
+	Lockout       LockoutConfig
+	Password      PasswordConfig
+	MagicLink     MagicLinkConfig
+	Impersonation ImpersonationConfig
+	Redis         db.RedisConfig
 }
 
 type ServiceConfig struct {
 	Name    string
 	Version string
+	Scopes  []string // subjects auth-service's own service token may call
 }
 
 type AuthConfig struct {
@@ -27,8 +38,82 @@ type AuthConfig struct {
 	AccessTokenExpiry   time.Duration
 	RefreshTokenExpiry  time.Duration
 	PasswordHashCost    int
-	MaxLoginAttempts    int
-	LoginLockoutPeriod  time.Duration
+}
+
+// MFAConfig configures TOTP second factors
+type MFAConfig struct {
+	Issuer          string        // shown beside the account in authenticator apps
+	EncryptionKey   string        // encrypts stored TOTP secrets
+	ChallengeExpiry time.Duration // how long a login has to present its second factor
+	RequiredRoles   []string      // roles that may not log in without a second factor
+	RecoveryCodes   int           // recovery codes issued per enrollment
+}
+
+// SigningConfig configures the asymmetric keys tokens are signed with
+type SigningConfig struct {
+	Algorithm          string        // RS256 or EdDSA
+	EncryptionKey      string        // encrypts stored private keys
+	RotationInterval   time.Duration // age at which the signing key is replaced; 0 disables rotation
+	PublishLead        time.Duration // how long a new key is published before it signs
+	Overlap            time.Duration // how long a replaced key keeps verifying
+	RefreshInterval    time.Duration // how often the key set is reloaded from the database
+	AcceptLegacyTokens bool          // verify kid-less HS256 tokens with JWTSecret
+}
+
+// OAuthConfig configures the OAuth2 / OpenID Connect authorization server
+type OAuthConfig struct {
+	Issuer             string        // public URL of the gateway, the iss of ID tokens
+	AuthorizePage      string        // sign-in page that drives /oauth/authorize for the user
+	CodeExpiry         time.Duration // how long an authorization code can be exchanged
+	IDTokenExpiry      time.Duration
+	ServiceTokenExpiry time.Duration // lifetime of client credentials tokens
+}
+
+// Lockout backends
+const (
+	LockoutBackendMemory = "memory"
+	LockoutBackendRedis  = "redis"
+)
+
+// LockoutConfig configures progressive lockout after failed logins
+type LockoutConfig struct {
+	MaxAttempts       int           // failures per account before it locks
+	IPMaxAttempts     int           // failures per client address before it locks
+	BaseDuration      time.Duration // the first lockout, doubled by each further failure
+	MaxDuration       time.Duration // the longest lockout
+	Window            time.Duration // how long failures are remembered once lockouts end
+	Backend           string        // "redis" shares lockouts across replicas; "memory" is per replica
+	UnlockURL         string        // page redeeming unlock tokens, linked with ?token= in the email
+	UnlockTokenExpiry time.Duration
+}
+
+// PasswordConfig configures the password policy applied when a password is
+// set at registration, on change and on reset
+type PasswordConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectSimilar bool          // reject passwords containing the username or email
+	HistorySize   int           // previous passwords that can't be reused; 0 disables
+	MaxAge        time.Duration // age at which logins are told to change the password; 0 disables
+	BreachedList  string        // directory of breached password SHA-1 prefix buckets; empty disables screening
+}
+
+// MagicLinkConfig configures passwordless login links emailed to users
+type MagicLinkConfig struct {
+	URL      string        // page exchanging login links, linked with ?token= in the email
+	Expiry   time.Duration // how long a link works
+	Interval time.Duration // the least time between links emailed to one user
+	BindIP   bool          // only accept a link from the address that asked for it
+}
+
+// ImpersonationConfig configures the tokens administrators act as users with
+type ImpersonationConfig struct {
+	Duration    time.Duration // how long an impersonation lasts unless asked for less
+	MaxDuration time.Duration // the longest impersonation that can be asked for
 }
 
 func Load(logger log.Logger) (*Config, error) {
@@ -45,13 +130,16 @@ func Load(logger log.Logger) (*Config, error) {
         Service: ServiceConfig{
             Name:    provider.GetDefault("NAME", "auth-service"),
             Version: provider.GetDefault("VERSION", "1.0.0"),
+            Scopes:  splitList(provider.GetDefault("SERVICE_SCOPES", "user.>,notification.send")),
         },
         Server: config.ServerConfig{
-            Port:         provider.GetIntDefault("PORT", 8080),
-            Host:         provider.GetDefault("HOST", ""),
-            ReadTimeout:  provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
-            WriteTimeout: provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
-            IdleTimeout:  provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+            Port:            provider.GetIntDefault("PORT", 8080),
+            Host:            provider.GetDefault("HOST", ""),
+            ReadTimeout:     provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
+            WriteTimeout:    provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
+            IdleTimeout:     provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+            ShutdownTimeout: provider.GetDurationDefault("SHUTDOWN_TIMEOUT", 30*time.Second),
+            ShutdownDelay:   provider.GetDurationDefault("SHUTDOWN_DELAY", 0),
         },
         Database: config.DatabaseConfig{
             Host:            provider.GetDefault("DB_HOST", "localhost"),
@@ -82,10 +170,86 @@ func Load(logger log.Logger) (*Config, error) {
             AccessTokenExpiry:  provider.GetDurationDefault("ACCESS_TOKEN_EXPIRY", 15*time.Minute),
             RefreshTokenExpiry: provider.GetDurationDefault("REFRESH_TOKEN_EXPIRY", 7*24*time.Hour),
             PasswordHashCost:   provider.GetIntDefault("PASSWORD_HASH_COST", 10),
-            MaxLoginAttempts:   provider.GetIntDefault("MAX_LOGIN_ATTEMPTS", 5),
-            LoginLockoutPeriod: provider.GetDurationDefault("LOGIN_LOCKOUT_PERIOD", 15*time.Minute),
+        },
+        MFA: MFAConfig{
+            Issuer:          provider.GetDefault("MFA_ISSUER", "fn-go"),
+            EncryptionKey:   provider.GetDefault("MFA_ENCRYPTION_KEY", "dev-mfa-key-change-in-production"),
+            ChallengeExpiry: provider.GetDurationDefault("MFA_CHALLENGE_EXPIRY", 5*time.Minute),
+            RequiredRoles:   splitList(provider.GetDefault("MFA_REQUIRED_ROLES", "admin,dispatcher")),
+            RecoveryCodes:   provider.GetIntDefault("MFA_RECOVERY_CODES", 10),
+        },
+        Signing: SigningConfig{
+            Algorithm:          provider.GetDefault("SIGNING_ALGORITHM", "RS256"),
+            EncryptionKey:      provider.GetDefault("SIGNING_KEY_ENCRYPTION_KEY", "dev-signing-key-change-in-production"),
+            RotationInterval:   provider.GetDurationDefault("SIGNING_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+            PublishLead:        provider.GetDurationDefault("SIGNING_KEY_PUBLISH_LEAD", 10*time.Minute),
+            RefreshInterval:    provider.GetDurationDefault("SIGNING_KEY_REFRESH_INTERVAL", time.Minute),
+            AcceptLegacyTokens: provider.GetBoolDefault("JWT_ACCEPT_LEGACY", false),
+        },
+        OAuth: OAuthConfig{
+            Issuer:             strings.TrimSuffix(provider.GetDefault("OAUTH_ISSUER", "http://localhost:8080"), "/"),
+            CodeExpiry:         provider.GetDurationDefault("OAUTH_CODE_EXPIRY", time.Minute),
+            IDTokenExpiry:      provider.GetDurationDefault("OAUTH_ID_TOKEN_EXPIRY", time.Hour),
+            ServiceTokenExpiry: provider.GetDurationDefault("OAUTH_SERVICE_TOKEN_EXPIRY", 15*time.Minute),
+        },
+        Lockout: LockoutConfig{
+            MaxAttempts:       provider.GetIntDefault("MAX_LOGIN_ATTEMPTS", 5),
+            IPMaxAttempts:     provider.GetIntDefault("LOGIN_IP_MAX_ATTEMPTS", 20),
+            BaseDuration:      provider.GetDurationDefault("LOGIN_LOCKOUT_PERIOD", time.Minute),
+            MaxDuration:       provider.GetDurationDefault("LOGIN_LOCKOUT_MAX", 24*time.Hour),
+            Window:            provider.GetDurationDefault("LOGIN_FAILURE_WINDOW", time.Hour),
+            Backend:           provider.GetDefault("LOCKOUT_BACKEND", LockoutBackendRedis),
+            UnlockURL:         provider.GetDefault("UNLOCK_URL", "http://localhost:3000/unlock"),
+            UnlockTokenExpiry: provider.GetDurationDefault("UNLOCK_TOKEN_EXPIRY", time.Hour),
+        },
+        Password: PasswordConfig{
+            MinLength:     provider.GetIntDefault("PASSWORD_MIN_LENGTH", 8),
+            MaxLength:     provider.GetIntDefault("PASSWORD_MAX_LENGTH", 128),
+            RequireUpper:  provider.GetBoolDefault("PASSWORD_REQUIRE_UPPER", true),
+            RequireLower:  provider.GetBoolDefault("PASSWORD_REQUIRE_LOWER", false),
+            RequireDigit:  provider.GetBoolDefault("PASSWORD_REQUIRE_DIGIT", true),
+            RequireSymbol: provider.GetBoolDefault("PASSWORD_REQUIRE_SYMBOL", false),
+            RejectSimilar: provider.GetBoolDefault("PASSWORD_REJECT_SIMILAR", true),
+            HistorySize:   provider.GetIntDefault("PASSWORD_HISTORY", 5),
+            MaxAge:        provider.GetDurationDefault("PASSWORD_MAX_AGE", 0),
+            BreachedList:  provider.GetDefault("PASSWORD_BREACHED_LIST", ""),
+        },
+        MagicLink: MagicLinkConfig{
+            URL:      provider.GetDefault("MAGIC_LINK_URL", "http://localhost:3000/login/magic"),
+            Expiry:   provider.GetDurationDefault("MAGIC_LINK_EXPIRY", 15*time.Minute),
+            Interval: provider.GetDurationDefault("MAGIC_LINK_INTERVAL", time.Minute),
+            BindIP:   provider.GetBoolDefault("MAGIC_LINK_BIND_IP", false),
+        },
+        Impersonation: ImpersonationConfig{
+            Duration:    provider.GetDurationDefault("IMPERSONATION_DURATION", 15*time.Minute),
+            MaxDuration: provider.GetDurationDefault("IMPERSONATION_MAX_DURATION", time.Hour),
+        },
+        Redis: db.RedisConfig{
+            Host:     provider.GetDefault("REDIS_HOST", "localhost"),
+            Port:     provider.GetIntDefault("REDIS_PORT", 6379),
+            Password: provider.GetDefault("REDIS_PASSWORD", ""),
+            DB:       provider.GetIntDefault("REDIS_DB", 0),
+            PoolSize: provider.GetIntDefault("REDIS_POOL_SIZE", 10),
+            Timeout:  provider.GetDurationDefault("REDIS_TIMEOUT", 2*time.Second),
         },
     }
     
+    // A replaced key must outlive every token it signed
+    cfg.Signing.Overlap = provider.GetDurationDefault("SIGNING_KEY_OVERLAP", cfg.Auth.RefreshTokenExpiry)
+    
+    // Without a sign-in page of their own, clients are sent to the API endpoint
+    cfg.OAuth.AuthorizePage = provider.GetDefault("OAUTH_AUTHORIZE_PAGE", cfg.OAuth.Issuer+"/oauth/authorize")
+    
     return cfg, nil
-}
\ No newline at end of file
+}
+
+// splitList splits a comma-separated list, dropping blank entries
+func splitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
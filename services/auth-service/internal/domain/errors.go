@@ -2,6 +2,10 @@
 package domain
 
 import (
+	"math"
+	"strings"
+	"time"
+
 	"github.com/0xsj/fn-go/pkg/common/errors"
 )
 
@@ -18,6 +22,25 @@ const (
 	ErrCodeInvalidCredentials = "INVALID_CREDENTIALS"
 	ErrCodeInvalidAuthInput   = "INVALID_AUTH_INPUT"
 	ErrCodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	ErrCodeAPIKeyNotFound     = "API_KEY_NOT_FOUND"
+	ErrCodeMFANotEnrolled     = "MFA_NOT_ENROLLED"
+	ErrCodeMFAAlreadyEnrolled = "MFA_ALREADY_ENROLLED"
+	ErrCodeInvalidMFACode     = "INVALID_MFA_CODE"
+	ErrCodeMFARequired        = "MFA_REQUIRED"
+	ErrCodeTokenReused        = "REFRESH_TOKEN_REUSED"
+)
+
+// OAuth authorization server error codes
+const (
+	ErrCodeOAuthClientNotFound       = "OAUTH_CLIENT_NOT_FOUND"
+	ErrCodeOAuthConsentNotFound      = "OAUTH_CONSENT_NOT_FOUND"
+	ErrCodeAuthorizationCodeNotFound = "AUTHORIZATION_CODE_NOT_FOUND"
+)
+
+// Role and policy error codes
+const (
+	ErrCodeRoleNotFound     = "ROLE_NOT_FOUND"
+	ErrCodeUserRoleNotFound = "USER_ROLE_NOT_FOUND"
 )
 
 // Register domain-specific error codes
@@ -77,6 +100,61 @@ func init() {
 		func(message string, err error) *errors.AppError {
 			return errors.NewRateLimitedError(message, err).WithField("domain", "auth")
 		})
+	
+	errors.RegisterErrorCode(ErrCodeAPIKeyNotFound,
+		func(message string, err error) *errors.AppError {
+			return errors.NewNotFoundError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeMFANotEnrolled,
+		func(message string, err error) *errors.AppError {
+			return errors.NewNotFoundError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeMFAAlreadyEnrolled,
+		func(message string, err error) *errors.AppError {
+			return errors.NewConflictError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeInvalidMFACode,
+		func(message string, err error) *errors.AppError {
+			return errors.NewUnauthorizedError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeMFARequired,
+		func(message string, err error) *errors.AppError {
+			return errors.NewForbiddenError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeTokenReused,
+		func(message string, err error) *errors.AppError {
+			return errors.NewUnauthorizedError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeOAuthClientNotFound,
+		func(message string, err error) *errors.AppError {
+			return errors.NewNotFoundError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeOAuthConsentNotFound,
+		func(message string, err error) *errors.AppError {
+			return errors.NewNotFoundError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeAuthorizationCodeNotFound,
+		func(message string, err error) *errors.AppError {
+			return errors.NewNotFoundError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeRoleNotFound,
+		func(message string, err error) *errors.AppError {
+			return errors.NewNotFoundError(message, err).WithField("domain", "auth")
+		})
+	
+	errors.RegisterErrorCode(ErrCodeUserRoleNotFound,
+		func(message string, err error) *errors.AppError {
+			return errors.NewNotFoundError(message, err).WithField("domain", "auth")
+		})
 }
 
 // NewTokenNotFoundError creates a new token not found error
@@ -152,7 +230,14 @@ func NewInvalidAuthInputError(message string, err error) error {
 // NewInvalidAuthInputWithValidation creates a new invalid auth input error with validation details
 func NewInvalidAuthInputWithValidation(message string, validationErrors map[string]string) error {
 	return errors.ErrorFromCode(ErrCodeInvalidAuthInput, message, errors.ErrValidationFailed).
-		WithField("validation_errors", validationErrors)
+		WithField(errors.FieldValidationErrors, validationErrors)
+}
+
+// NewPasswordPolicyError creates an error for a password that breaks the
+// password policy, listing each violation under the request field it came in
+func NewPasswordPolicyError(field string, violations []string) error {
+	return NewInvalidAuthInputWithValidation("Password does not meet the password policy",
+		map[string]string{field: strings.Join(violations, "; ")})
 }
 
 // NewTooManyRequestsError creates a new rate limit error
@@ -162,6 +247,90 @@ func NewTooManyRequestsError(message string) error {
 		errors.ErrRateLimited)
 }
 
+// NewAPIKeyNotFoundError creates a new API key not found error
+func NewAPIKeyNotFoundError(keyID string) error {
+	return errors.ErrorFromCode(ErrCodeAPIKeyNotFound,
+		"API key not found",
+		errors.ErrNotFound).WithField("keyID", keyID)
+}
+
+// NewMFANotEnrolledError creates a new MFA not enrolled error
+func NewMFANotEnrolledError(userID string) error {
+	return errors.ErrorFromCode(ErrCodeMFANotEnrolled,
+		"No second factor is enrolled",
+		errors.ErrNotFound).WithField("userID", userID)
+}
+
+// NewMFAAlreadyEnrolledError creates a new MFA already enrolled error
+func NewMFAAlreadyEnrolledError(userID string) error {
+	return errors.ErrorFromCode(ErrCodeMFAAlreadyEnrolled,
+		"A second factor is already enrolled",
+		errors.ErrConflict).WithField("userID", userID)
+}
+
+// NewInvalidMFACodeError creates a new invalid MFA code error
+func NewInvalidMFACodeError() error {
+	return errors.ErrorFromCode(ErrCodeInvalidMFACode,
+		"Invalid authentication code",
+		errors.ErrUnauthorized)
+}
+
+// NewMFARequiredError creates a new error for removing a second factor the user's role requires
+func NewMFARequiredError(userID, role string) error {
+	return errors.ErrorFromCode(ErrCodeMFARequired,
+		"A second factor is required for this role",
+		errors.ErrForbidden).
+		WithField("userID", userID).
+		WithField("role", role)
+}
+
+// NewTokenReusedError creates an error for a refresh token presented after
+// it was rotated; its whole family has been revoked
+func NewTokenReusedError(sessionID string) error {
+	return errors.ErrorFromCode(ErrCodeTokenReused,
+		"Refresh token has already been used; the session has been revoked",
+		errors.ErrUnauthorized).WithField("sessionID", sessionID)
+}
+
+// NewOAuthClientNotFoundError creates a new OAuth client not found error
+func NewOAuthClientNotFoundError(clientID string) error {
+	return errors.ErrorFromCode(ErrCodeOAuthClientNotFound,
+		"OAuth client not found",
+		errors.ErrNotFound).WithField("clientID", clientID)
+}
+
+// NewOAuthConsentNotFoundError creates a new OAuth consent not found error
+func NewOAuthConsentNotFoundError(userID, clientID string) error {
+	return errors.ErrorFromCode(ErrCodeOAuthConsentNotFound,
+		"OAuth consent not found",
+		errors.ErrNotFound).
+		WithField("userID", userID).
+		WithField("clientID", clientID)
+}
+
+// NewAuthorizationCodeNotFoundError creates a new authorization code not found error
+func NewAuthorizationCodeNotFoundError() error {
+	return errors.ErrorFromCode(ErrCodeAuthorizationCodeNotFound,
+		"Authorization code not found",
+		errors.ErrNotFound)
+}
+
+// NewRoleNotFoundError creates a new role not found error
+func NewRoleNotFoundError(role string) error {
+	return errors.ErrorFromCode(ErrCodeRoleNotFound,
+		"Role not found",
+		errors.ErrNotFound).WithField("role", role)
+}
+
+// NewUserRoleNotFoundError creates a new error for a role the user wasn't assigned
+func NewUserRoleNotFoundError(userID, roleID string) error {
+	return errors.ErrorFromCode(ErrCodeUserRoleNotFound,
+		"Role is not assigned to the user",
+		errors.ErrNotFound).
+		WithField("userID", userID).
+		WithField("roleID", roleID)
+}
+
 func NewUserNotFoundError(identifier string) error {
 	return errors.ErrorFromCode("USER_NOT_FOUND",
 		"User not found",
@@ -174,17 +343,47 @@ func NewUserAlreadyExistsError(identifier string) error {
 		errors.ErrConflict).WithField("identifier", identifier)
 }
 
+// NewImpersonationForbiddenError creates an error for an impersonation that
+// isn't allowed, e.g. of another administrator
+func NewImpersonationForbiddenError(userID, reason string) error {
+	return errors.ErrorFromCode("IMPERSONATION_FORBIDDEN",
+		"Impersonation forbidden: "+reason,
+		errors.ErrForbidden).WithField("userID", userID)
+}
+
 func NewAccountInactiveError(userID string) error {
 	return errors.ErrorFromCode("ACCOUNT_INACTIVE",
 		"Account is inactive",
 		errors.ErrForbidden).WithField("userID", userID)
 }
 
-// NewAccountLockedError creates a new account locked error
-func NewAccountLockedError(userID string) error {
+// NewAccountLockedError creates a new account locked error; the lock lifts
+// after retryAfter
+func NewAccountLockedError(userID string, retryAfter time.Duration) error {
 	return errors.ErrorFromCode("ACCOUNT_LOCKED",
-		"Account is locked due to too many failed login attempts",
-		errors.ErrForbidden).WithField("userID", userID)
+		"Account is temporarily locked due to too many failed login attempts, try again in "+waitTime(retryAfter),
+		errors.ErrForbidden).
+		WithField("userID", userID).
+		WithField("retryAfterSeconds", int(math.Ceil(retryAfter.Seconds())))
+}
+
+// NewLoginThrottledError creates an error for logins from an address locked
+// out by its failed logins
+func NewLoginThrottledError(ipAddress string, retryAfter time.Duration) error {
+	return errors.ErrorFromCode(ErrCodeTooManyRequests,
+		"Too many failed login attempts from this address, try again in "+waitTime(retryAfter),
+		errors.ErrRateLimited).
+		WithField("ipAddress", ipAddress).
+		WithField("retryAfterSeconds", int(math.Ceil(retryAfter.Seconds())))
+}
+
+// waitTime rounds a wait up to whole seconds, or whole minutes once it's
+// longer than one
+func waitTime(d time.Duration) string {
+	if d > time.Minute {
+		return (d + time.Minute - 1).Truncate(time.Minute).String()
+	}
+	return (d + time.Second - 1).Truncate(time.Second).String()
 }
 
 // NewEmailAlreadyVerifiedError creates a new email already verified error
@@ -243,6 +442,42 @@ var (
 		return errors.IsErrorCode(err, ErrCodeTooManyRequests)
 	}
 	
+	IsAPIKeyNotFound = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeAPIKeyNotFound)
+	}
+	
+	IsMFANotEnrolled = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeMFANotEnrolled)
+	}
+	
+	IsInvalidMFACode = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeInvalidMFACode)
+	}
+	
+	IsTokenReused = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeTokenReused)
+	}
+	
+	IsOAuthClientNotFound = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeOAuthClientNotFound)
+	}
+	
+	IsOAuthConsentNotFound = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeOAuthConsentNotFound)
+	}
+	
+	IsAuthorizationCodeNotFound = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeAuthorizationCodeNotFound)
+	}
+	
+	IsRoleNotFound = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeRoleNotFound)
+	}
+	
+	IsUserRoleNotFound = func(err error) bool {
+		return errors.IsErrorCode(err, ErrCodeUserRoleNotFound)
+	}
+	
 	// Re-export common error checks from errors package
 	IsNotFound = errors.IsNotFound
 	IsConflict = errors.IsConflict
@@ -0,0 +1,49 @@
+// services/auth-service/internal/domain/oauth.go
+package domain
+
+import (
+	stderrors "errors"
+)
+
+// OAuth 2.0 error codes (RFC 6749 section 5.2 and 4.1.2.1, RFC 6750 section 3.1)
+const (
+	OAuthInvalidRequest          = "invalid_request"
+	OAuthInvalidClient           = "invalid_client"
+	OAuthInvalidGrant            = "invalid_grant"
+	OAuthUnauthorizedClient      = "unauthorized_client"
+	OAuthUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+	OAuthInvalidScope            = "invalid_scope"
+	OAuthAccessDenied            = "access_denied"
+	OAuthInvalidToken            = "invalid_token"
+	OAuthInsufficientScope       = "insufficient_scope"
+)
+
+// OAuthError is an error the OAuth endpoints report to clients in the
+// protocol's own format rather than as an application error
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+// Error implements the error interface
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// NewOAuthError creates a new OAuth protocol error
+func NewOAuthError(code, description string) error {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// AsOAuthError returns the OAuth protocol error in err's chain, if any
+func AsOAuthError(err error) (*OAuthError, bool) {
+	var oauthErr *OAuthError
+	if stderrors.As(err, &oauthErr) {
+		return oauthErr, true
+	}
+	return nil, false
+}
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/models"
+)
+
 
 type LoginRequest struct {
 	Username string `json:"username" validate:"required"`
@@ -21,6 +27,8 @@ type RegisterRequest struct {
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required"`
+	UserAgent string `json:"userAgent,omitempty"`
+	IPAddress string `json:"ipAddress,omitempty"`
 }
 
 // ValidateTokenRequest represents a token validation request
@@ -59,6 +67,46 @@ type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
 
+// RedeemUnlockTokenRequest unlocks an account with the token emailed to its
+// user when the account was locked out
+type RedeemUnlockTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// UnlockAccountRequest unlocks an account on behalf of an administrator
+type UnlockAccountRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	UnlockedBy string `json:"unlockedBy,omitempty"` // the administrator
+}
+
+// ImpersonateRequest asks for a token letting an administrator act as a
+// user. The reason is kept with the impersonation for the audit trail.
+type ImpersonateRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	ActorID string `json:"actorId" validate:"required"` // the administrator
+	Reason string `json:"reason" validate:"required"`
+	DurationSeconds int `json:"durationSeconds,omitempty"` // capped at the configured maximum
+	IPAddress string `json:"ipAddress,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+// RequestMagicLinkRequest asks for a login link to be emailed. A DeviceID
+// binds the link to the device asking, so only it can log in with it.
+type RequestMagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	DeviceID string `json:"deviceId,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
+// ExchangeMagicLinkRequest logs in with the token from a login link
+type ExchangeMagicLinkRequest struct {
+	Token string `json:"token" validate:"required"`
+	DeviceID string `json:"deviceId,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
 // VerifyEmailRequest represents an email verification request
 type VerifyEmailRequest struct {
 	Token string `json:"token" validate:"required"`
@@ -76,4 +124,164 @@ type CreatePermissionRequest struct {
 type AssignPermissionRequest struct {
 	RoleID string `json:"roleId" validate:"required"`
 	PermissionID string `json:"permissionId" validate:"required"`
-}
\ No newline at end of file
+	Conditions []models.PolicyCondition `json:"conditions,omitempty"` // the grant only applies when all hold
+}
+
+// CreateAPIKeyRequest represents an API key creation request
+type CreateAPIKeyRequest struct {
+	OwnerID string `json:"ownerId" validate:"required"`
+	Name string `json:"name" validate:"required"`
+	Scopes []string `json:"scopes" validate:"required"` // "resource:action", "resource:*" or "*"
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// RotateAPIKeyRequest represents an API key rotation request
+type RotateAPIKeyRequest struct {
+	ID string `json:"id" validate:"required"`
+	OwnerID string `json:"ownerId" validate:"required"`
+	GracePeriodSeconds int `json:"gracePeriodSeconds,omitempty"` // how long the old key keeps working
+}
+
+// RevokeAPIKeyRequest represents an API key revocation request
+type RevokeAPIKeyRequest struct {
+	ID string `json:"id" validate:"required"`
+	OwnerID string `json:"ownerId" validate:"required"`
+}
+
+// ValidateAPIKeyRequest represents an API key validation request
+type ValidateAPIKeyRequest struct {
+	Key string `json:"key" validate:"required"`
+}
+
+// EnrollMFARequest starts TOTP enrollment, either for a logged-in user or
+// for a login whose challenge requires enrolling first
+type EnrollMFARequest struct {
+	UserID string `json:"userId,omitempty"`
+	MFAToken string `json:"mfaToken,omitempty"`
+}
+
+// ConfirmMFARequest completes enrollment with a first code from the authenticator
+type ConfirmMFARequest struct {
+	UserID string `json:"userId" validate:"required"`
+	Code string `json:"code" validate:"required"`
+}
+
+// VerifyMFARequest is the second step of a login. Either a current code or an
+// unused recovery code answers the challenge.
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code string `json:"code,omitempty"`
+	RecoveryCode string `json:"recoveryCode,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
+// DisableMFARequest removes a user's second factor, proven with a current code
+// or recovery code
+type DisableMFARequest struct {
+	UserID string `json:"userId" validate:"required"`
+	Code string `json:"code,omitempty"`
+	RecoveryCode string `json:"recoveryCode,omitempty"`
+}
+
+// RegenerateRecoveryCodesRequest replaces a user's recovery codes
+type RegenerateRecoveryCodesRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	Code string `json:"code" validate:"required"`
+}
+
+// RegisterOAuthClientRequest registers a partner application
+type RegisterOAuthClientRequest struct {
+	Name string `json:"name" validate:"required"`
+	Type string `json:"type" validate:"required"` // "confidential", "public" or "service"
+	RedirectURIs []string `json:"redirectUris,omitempty"` // none for service accounts
+	Scopes []string `json:"scopes" validate:"required"` // OpenID scopes and "resource:action" API scopes, or NATS subjects for service accounts
+}
+
+// AuthorizeRequest is an authorization request made by a signed-in user on
+// behalf of a client. The protocol fields keep their OAuth parameter names.
+// Without a decision the user is asked for consent if they haven't granted
+// the requested scopes yet.
+type AuthorizeRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	ActorID string `json:"actorId,omitempty"` // set when an administrator is impersonating the user
+	ClientID string `json:"client_id"`
+	RedirectURI string `json:"redirect_uri,omitempty"`
+	ResponseType string `json:"response_type"`
+	Scope string `json:"scope,omitempty"`
+	State string `json:"state,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+	CodeChallenge string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+	Decision string `json:"decision,omitempty"` // "approve" or "deny"
+}
+
+// OAuthTokenRequest is a token endpoint request (RFC 6749 section 4.1.3 and
+// 6). The client authenticates with its credentials, whether they came in
+// the Authorization header or the body.
+type OAuthTokenRequest struct {
+	GrantType string `json:"grant_type"`
+	Code string `json:"code,omitempty"`
+	RedirectURI string `json:"redirect_uri,omitempty"`
+	CodeVerifier string `json:"code_verifier,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope string `json:"scope,omitempty"` // narrows a client credentials token
+	ClientID string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
+// UserInfoRequest carries the access token presented to the userinfo endpoint
+type UserInfoRequest struct {
+	AccessToken string `json:"accessToken" validate:"required"`
+}
+
+// IntrospectRequest is a token introspection request (RFC 7662) from a
+// confidential client
+type IntrospectRequest struct {
+	Token string `json:"token"`
+	TokenTypeHint string `json:"token_type_hint,omitempty"`
+	ClientID string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// RevokeOAuthConsentRequest withdraws a user's consent to a client
+type RevokeOAuthConsentRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	ClientID string `json:"clientId" validate:"required"`
+}
+
+// SetRoleParentRequest makes a role inherit another's permissions
+type SetRoleParentRequest struct {
+	RoleID string `json:"roleId" validate:"required"`
+	ParentID string `json:"parentId,omitempty"` // empty stops inheriting
+}
+
+// AssignUserRoleRequest assigns a role, by ID or name, to a user
+type AssignUserRoleRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	Role string `json:"role" validate:"required"`
+	AssignedBy string `json:"assignedBy,omitempty"`
+}
+
+// RevokeUserRoleRequest removes a role, by ID or name, from a user
+type RevokeUserRoleRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	Role string `json:"role" validate:"required"`
+}
+
+// SetUserAttributesRequest replaces the attributes policies know a user by
+type SetUserAttributesRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// EvaluatePolicyRequest asks whether a user may perform an action, given the
+// attributes of the resource acted on
+type EvaluatePolicyRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	Resource string `json:"resource" validate:"required"`
+	Action string `json:"action" validate:"required"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
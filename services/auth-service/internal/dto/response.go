@@ -15,6 +15,15 @@ type LoginResponse struct {
 	ExpiresIn int64 `json:"expiresIn"` // seconds until access token expires
 	User UserInfo `json:"user"`
 	SessionID string `json:"sessionId"`
+	// When a second factor is owed no tokens are issued; the login finishes
+	// by presenting MFAToken with a code to auth.mfa.verify
+	MFARequired bool `json:"mfaRequired,omitempty"`
+	MFAEnrollmentRequired bool `json:"mfaEnrollmentRequired,omitempty"`
+	MFAToken string `json:"mfaToken,omitempty"`
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"` // set when the login completed an enrollment
+	// Set when the password is older than the policy's maximum age; the
+	// client should have the user change it before carrying on
+	PasswordExpired bool `json:"passwordExpired,omitempty"`
 }
 
 // RefreshTokenResponse represents a successful token refresh response
@@ -23,6 +32,7 @@ type RefreshTokenResponse struct {
 	RefreshToken string `json:"refreshToken"`
 	TokenType string `json:"tokenType"` // "Bearer"
 	ExpiresIn int64 `json:"expiresIn"` // seconds until access token expires
+	Scope string `json:"scope,omitempty"` // set for tokens issued to an OAuth client
 }
 
 // ValidateTokenResponse represents a token validation response
@@ -63,6 +73,8 @@ type PermissionResponse struct {
 	Description string `json:"description,omitempty"`
 	Resource string `json:"resource"`
 	Action string `json:"action"`
+	Role string `json:"role,omitempty"` // the role granting it
+	Conditions []models.PolicyCondition `json:"conditions,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
@@ -73,6 +85,273 @@ type RolePermissionsResponse struct {
 	Permissions []PermissionResponse `json:"permissions"`
 }
 
+// PermissionsChangedEvent is published when permissions are assigned to or
+// revoked from a role, roles change parents, or a user's roles change
+type PermissionsChangedEvent struct {
+	RoleID       string    `json:"roleId"`
+	PermissionID string    `json:"permissionId,omitempty"`
+	UserID       string    `json:"userId,omitempty"` // set when a single user's roles or attributes changed
+	Change       string    `json:"change"`
+	ChangedAt    time.Time `json:"changedAt"`
+}
+
+// RefreshTokenReusedEvent is published when a rotated refresh token is
+// presented again, a sign it was stolen; the session is revoked
+type RefreshTokenReusedEvent struct {
+	UserID string `json:"userId"`
+	SessionID string `json:"sessionId"`
+	TokenID string `json:"tokenId"`
+	Generation int `json:"generation"` // generation of the replayed token
+	CurrentGeneration int `json:"currentGeneration"`
+	RevokedTokens int `json:"revokedTokens"`
+	IPAddress string `json:"ipAddress,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// LoginLockoutEvent is published when failed logins lock out an account or
+// a client address. Many address lockouts in a short time suggest
+// credential stuffing.
+type LoginLockoutEvent struct {
+	Scope string `json:"scope"` // "account" or "ip"
+	UserID string `json:"userId,omitempty"` // the account, when it exists
+	Username string `json:"username,omitempty"` // as given in the failed login
+	IPAddress string `json:"ipAddress,omitempty"`
+	Failures int `json:"failures"`
+	LockedUntil time.Time `json:"lockedUntil"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// AccountUnlockedEvent is published when a locked account is unlocked
+type AccountUnlockedEvent struct {
+	UserID string `json:"userId"`
+	UnlockedBy string `json:"unlockedBy"` // the administrator, or "self" for an unlock token
+	UnlockedAt time.Time `json:"unlockedAt"`
+}
+
+// ImpersonationResponse carries a token acting as a user for an
+// administrator. There is no refresh token; the impersonation ends when the
+// access token expires.
+type ImpersonationResponse struct {
+	AccessToken string `json:"accessToken"`
+	TokenType string `json:"tokenType"` // "Bearer"
+	ExpiresIn int64 `json:"expiresIn"` // seconds until the impersonation ends
+	ExpiresAt time.Time `json:"expiresAt"`
+	User UserInfo `json:"user"` // the user impersonated
+	ActorID string `json:"actorId"`
+}
+
+// ImpersonationStartedEvent is published when an administrator is issued a
+// token to act as a user
+type ImpersonationStartedEvent struct {
+	UserID string `json:"userId"`
+	ActorID string `json:"actorId"`
+	Reason string `json:"reason"`
+	IPAddress string `json:"ipAddress,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// APIKeyInfo represents API key metadata; the key itself is never returned after creation
+type APIKeyInfo struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Prefix string `json:"prefix"`
+	OwnerID string `json:"ownerId"`
+	Scopes []string `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	RotatedFrom string `json:"rotatedFrom,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// APIKeyCreatedResponse carries a newly issued key, shown to the caller only once
+type APIKeyCreatedResponse struct {
+	APIKeyInfo
+	Key string `json:"key"`
+}
+
+// ValidateAPIKeyResponse represents an API key validation response
+type ValidateAPIKeyResponse struct {
+	Valid bool `json:"valid"`
+	Key *APIKeyInfo `json:"key,omitempty"`
+	User UserInfo `json:"user"` // the key's owner
+}
+
+// MFAEnrollmentResponse carries a new TOTP secret. Clients render the
+// provisioning URI as a QR code for authenticator apps to scan.
+type MFAEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+	Issuer string `json:"issuer"`
+	Account string `json:"account"`
+}
+
+// MFARecoveryCodesResponse carries recovery codes, shown to the user only once
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFAStatusResponse describes a user's second factor
+type MFAStatusResponse struct {
+	Enabled bool `json:"enabled"`
+	Pending bool `json:"pending"` // enrolled but not yet confirmed
+	Required bool `json:"required"` // the user's role requires a second factor
+	RecoveryCodesRemaining int `json:"recoveryCodesRemaining"`
+	ConfirmedAt *time.Time `json:"confirmedAt,omitempty"`
+}
+
+// SigningKeyInfo describes a token signing key; the private half is never returned
+type SigningKeyInfo struct {
+	ID string `json:"id"`
+	Algorithm string `json:"algorithm"`
+	Signing bool `json:"signing"` // the key new tokens are signed with
+	ActivatesAt time.Time `json:"activatesAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// OAuthClientInfo describes a registered OAuth client
+type OAuthClientInfo struct {
+	ID string `json:"id"` // the client_id
+	Name string `json:"name"`
+	Type string `json:"type"`
+	RedirectURIs []string `json:"redirectUris"`
+	Scopes []string `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// OAuthClientCreatedResponse carries a new client's secret, shown only once.
+// Public clients have no secret.
+type OAuthClientCreatedResponse struct {
+	OAuthClientInfo
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
+// AuthorizeResponse answers an authorization request. Either the user must
+// first consent to the listed scopes, or the user agent is sent to RedirectTo,
+// carrying the code or an error for the client.
+type AuthorizeResponse struct {
+	ConsentRequired bool `json:"consentRequired"`
+	Client *OAuthClientInfo `json:"client,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	RedirectTo string `json:"redirectTo,omitempty"`
+}
+
+// OAuthConsentInfo describes scopes a user has granted a client
+type OAuthConsentInfo struct {
+	ClientID string `json:"clientId"`
+	ClientName string `json:"clientName"`
+	Scopes []string `json:"scopes"`
+	GrantedAt time.Time `json:"grantedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OAuthTokenResponse is a token endpoint response (RFC 6749 section 5.1)
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType string `json:"token_type"`
+	ExpiresIn int64 `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken string `json:"id_token,omitempty"`
+	Scope string `json:"scope,omitempty"`
+}
+
+// OAuthErrorResponse is an OAuth error (RFC 6749 section 5.2). It is sent as
+// a successful reply, so the gateway can pass it on in the protocol's format.
+type OAuthErrorResponse struct {
+	Error string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// IntrospectResponse is a token introspection response (RFC 7662 section 2.2)
+type IntrospectResponse struct {
+	Active bool `json:"active"`
+	Scope string `json:"scope,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64 `json:"exp,omitempty"`
+	IssuedAt int64 `json:"iat,omitempty"`
+	Subject string `json:"sub,omitempty"`
+	Audience string `json:"aud,omitempty"`
+	Issuer string `json:"iss,omitempty"`
+}
+
+// DiscoveryDocument is the OpenID Provider metadata (OpenID Connect
+// Discovery 1.0 section 3)
+type DiscoveryDocument struct {
+	Issuer string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint string `json:"token_endpoint"`
+	UserInfoEndpoint string `json:"userinfo_endpoint"`
+	JWKSURI string `json:"jwks_uri"`
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+	ScopesSupported []string `json:"scopes_supported"`
+	ResponseTypesSupported []string `json:"response_types_supported"`
+	GrantTypesSupported []string `json:"grant_types_supported"`
+	SubjectTypesSupported []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported []string `json:"claims_supported"`
+}
+
+// RoleResponse represents a role and the roles it inherits from
+type RoleResponse struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ParentID string `json:"parentId,omitempty"`
+	Inherits []string `json:"inherits,omitempty"` // ancestor role names, nearest first
+}
+
+// UserRolesResponse represents the roles a user holds
+type UserRolesResponse struct {
+	UserID string `json:"userId"`
+	ProfileRole string `json:"profileRole"` // the role on the user's profile
+	Assigned []UserRoleInfo `json:"assigned"`
+	Effective []string `json:"effective"` // every role held, including inherited ones
+	Attributes map[string]string `json:"attributes"`
+}
+
+// UserRoleInfo represents a role assigned to a user
+type UserRoleInfo struct {
+	RoleID string `json:"roleId"`
+	RoleName string `json:"roleName"`
+	AssignedAt time.Time `json:"assignedAt"`
+	AssignedBy string `json:"assignedBy,omitempty"`
+}
+
+// PolicyDecision explains whether a user may perform an action: which of
+// their grants were considered and how each condition evaluated
+type PolicyDecision struct {
+	Allowed bool `json:"allowed"`
+	Permission string `json:"permission"`
+	Reason string `json:"reason"`
+	Roles []string `json:"roles"`
+	Subject map[string]any `json:"subject"`
+	Grants []GrantEvaluation `json:"grants"`
+}
+
+// GrantEvaluation is the evaluation of one grant of the requested permission
+type GrantEvaluation struct {
+	Role string `json:"role"`
+	InheritedVia string `json:"inheritedVia,omitempty"` // the held role it was inherited through
+	Applies bool `json:"applies"`
+	Conditions []ConditionResult `json:"conditions,omitempty"`
+}
+
+// ConditionResult is the evaluation of one grant condition
+type ConditionResult struct {
+	models.PolicyCondition
+	Satisfied bool `json:"satisfied"`
+	Detail string `json:"detail"`
+}
+
 // TokenInfoResponse represents token information (for debugging/admin)
 type TokenInfoResponse struct {
 	TokenID string `json:"tokenId"`
@@ -94,11 +373,20 @@ type AuthStatsResponse struct {
 // Helper functions to convert models to DTOs
 
 // FromUser converts a user model to UserInfo DTO
-func FromUser(user interface{}) UserInfo {
-	// This would need to be adapted based on how you get user info from user service
-	// For now, returning a basic structure
+func FromUser(user *models.User) UserInfo {
+	if user == nil {
+		return UserInfo{}
+	}
 	return UserInfo{
-		// Will be populated based on actual user data from user service
+		ID: user.ID,
+		Username: user.Username,
+		Email: user.Email,
+		FirstName: user.FirstName,
+		LastName: user.LastName,
+		Role: string(user.Role),
+		Status: string(user.Status),
+		EmailVerified: user.EmailVerified,
+		LastLoginAt: user.LastLoginAt,
 	}
 }
 
@@ -135,4 +423,70 @@ func FromSession(session *models.Session) SessionInfo {
 		ExpiresAt: session.ExpiresAt,
 		CreatedAt: session.CreatedAt,
 	}
-}
\ No newline at end of file
+}
+
+// FromAPIKey converts an API key model to APIKeyInfo DTO
+func FromAPIKey(key *models.APIKey) APIKeyInfo {
+	return APIKeyInfo{
+		ID: key.ID,
+		Name: key.Name,
+		Prefix: key.Prefix,
+		OwnerID: key.OwnerID,
+		Scopes: key.Scopes,
+		ExpiresAt: key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt: key.RevokedAt,
+		RotatedFrom: key.RotatedFrom,
+		CreatedAt: key.CreatedAt,
+	}
+}
+
+// FromAPIKeys converts a slice of API key models to DTOs
+func FromAPIKeys(keys []*models.APIKey) []APIKeyInfo {
+	infos := make([]APIKeyInfo, len(keys))
+	for i, key := range keys {
+		infos[i] = FromAPIKey(key)
+	}
+	return infos
+}
+
+// FromOAuthClient converts an OAuth client model to OAuthClientInfo DTO
+func FromOAuthClient(client *models.OAuthClient) OAuthClientInfo {
+	return OAuthClientInfo{
+		ID: client.ID,
+		Name: client.Name,
+		Type: string(client.Type),
+		RedirectURIs: client.RedirectURIs,
+		Scopes: client.Scopes,
+		CreatedAt: client.CreatedAt,
+		RevokedAt: client.RevokedAt,
+	}
+}
+
+// FromOAuthClients converts a slice of OAuth client models to DTOs
+func FromOAuthClients(clients []*models.OAuthClient) []OAuthClientInfo {
+	infos := make([]OAuthClientInfo, len(clients))
+	for i, client := range clients {
+		infos[i] = FromOAuthClient(client)
+	}
+	return infos
+}
+
+// FromPermissionGrant converts a permission grant to PermissionResponse DTO
+func FromPermissionGrant(grant *models.PermissionGrant) PermissionResponse {
+	response := FromPermission(&grant.Permission)
+	response.Role = grant.RoleName
+	response.Conditions = grant.Conditions
+	return response
+}
+
+// FromRole converts a role model to RoleResponse DTO
+func FromRole(role *models.RoleDefinition, inherits []string) RoleResponse {
+	return RoleResponse{
+		ID: role.ID,
+		Name: role.Name,
+		Description: role.Description,
+		ParentID: role.ParentID,
+		Inherits: inherits,
+	}
+}
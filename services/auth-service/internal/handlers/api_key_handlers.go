@@ -0,0 +1,184 @@
+// services/auth-service/internal/handlers/api_key_handlers.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// CreateAPIKey handles API key creation requests
+func (h *AuthHandler) CreateAPIKey(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.apikeys.create")
+	handlerLogger.Info("Received create API key request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.CreateAPIKeyRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal create API key request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("owner_id", req.OwnerID).With("name", req.Name)
+
+	if req.OwnerID == "" || req.Name == "" {
+		handlerLogger.Warn("Missing owner ID or name")
+		return nil, domain.NewInvalidAuthInputError("Owner ID and name are required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.CreateAPIKey(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Create API key failed")
+		return nil, err
+	}
+
+	handlerLogger.With("key_id", response.ID).Info("API key created")
+	return response, nil
+}
+
+// ListAPIKeys handles list API keys requests
+func (h *AuthHandler) ListAPIKeys(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.apikeys.list")
+	handlerLogger.Debug("Received list API keys request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req struct {
+		OwnerID string `json:"ownerId"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal list API keys request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("owner_id", req.OwnerID)
+
+	if req.OwnerID == "" {
+		handlerLogger.Warn("Missing owner ID")
+		return nil, domain.NewInvalidAuthInputError("Owner ID is required", nil)
+	}
+
+	ctx := context.Background()
+	keys, err := h.authService.ListAPIKeys(ctx, req.OwnerID)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("List API keys failed")
+		return nil, err
+	}
+
+	handlerLogger.With("key_count", len(keys)).Debug("API keys retrieved")
+	return keys, nil
+}
+
+// RotateAPIKey handles API key rotation requests
+func (h *AuthHandler) RotateAPIKey(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.apikeys.rotate")
+	handlerLogger.Info("Received rotate API key request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.RotateAPIKeyRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal rotate API key request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("key_id", req.ID).With("owner_id", req.OwnerID)
+
+	if req.ID == "" || req.OwnerID == "" {
+		handlerLogger.Warn("Missing key ID or owner ID")
+		return nil, domain.NewInvalidAuthInputError("Key ID and owner ID are required", nil)
+	}
+	if req.GracePeriodSeconds < 0 {
+		handlerLogger.Warn("Negative grace period")
+		return nil, domain.NewInvalidAuthInputError("Grace period cannot be negative", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.RotateAPIKey(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Rotate API key failed")
+		return nil, err
+	}
+
+	handlerLogger.With("new_key_id", response.ID).Info("API key rotated")
+	return response, nil
+}
+
+// RevokeAPIKey handles API key revocation requests
+func (h *AuthHandler) RevokeAPIKey(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.apikeys.revoke")
+	handlerLogger.Info("Received revoke API key request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.RevokeAPIKeyRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal revoke API key request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("key_id", req.ID).With("owner_id", req.OwnerID)
+
+	if req.ID == "" || req.OwnerID == "" {
+		handlerLogger.Warn("Missing key ID or owner ID")
+		return nil, domain.NewInvalidAuthInputError("Key ID and owner ID are required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.RevokeAPIKey(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Revoke API key failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("API key revoked")
+	return map[string]any{"success": true, "message": "API key revoked successfully"}, nil
+}
+
+// ValidateAPIKey handles API key validation requests from the gateway
+func (h *AuthHandler) ValidateAPIKey(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.apikeys.validate")
+	handlerLogger.Debug("Received validate API key request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.ValidateAPIKeyRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal validate API key request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	if req.Key == "" {
+		handlerLogger.Warn("Missing API key")
+		return nil, domain.NewInvalidAuthInputError("API key is required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.ValidateAPIKey(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("API key validation failed")
+		return nil, err
+	}
+
+	handlerLogger.With("valid", response.Valid).Debug("API key validation completed")
+	return response, nil
+}
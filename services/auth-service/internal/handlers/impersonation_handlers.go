@@ -0,0 +1,53 @@
+// services/auth-service/internal/handlers/impersonation_handlers.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// Impersonate handles an administrator asking for a token to act as a user
+func (h *AuthHandler) Impersonate(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.users.impersonate")
+	handlerLogger.Info("Received impersonation request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.ImpersonateRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal impersonation request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	if req.UserID == "" || req.ActorID == "" {
+		handlerLogger.Warn("Missing user or actor ID")
+		return nil, domain.NewInvalidAuthInputError("User ID and actor ID are required", nil)
+	}
+	if req.Reason == "" {
+		handlerLogger.Warn("Missing impersonation reason")
+		return nil, domain.NewInvalidAuthInputError("A reason for the impersonation is required", nil)
+	}
+	if req.DurationSeconds < 0 {
+		handlerLogger.Warn("Negative impersonation duration")
+		return nil, domain.NewInvalidAuthInputError("Duration must not be negative", nil)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID).With("actor_id", req.ActorID)
+
+	ctx := context.Background()
+	response, err := h.authService.Impersonate(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Impersonation failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("Impersonation token issued")
+	return response, nil
+}
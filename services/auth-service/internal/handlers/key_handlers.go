@@ -0,0 +1,92 @@
+// services/auth-service/internal/handlers/key_handlers.go
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/services/auth-service/internal/service"
+)
+
+// KeyHandler handles signing key requests
+type KeyHandler struct {
+	keyService service.SigningKeyService
+	logger     log.Logger
+}
+
+// NewKeyHandler creates a new signing key handler
+func NewKeyHandler(keyService service.SigningKeyService, logger log.Logger) *KeyHandler {
+	return &KeyHandler{
+		keyService: keyService,
+		logger:     logger.WithLayer("key-handler"),
+	}
+}
+
+// RegisterHandlers registers signing key handlers with NATS
+func (h *KeyHandler) RegisterHandlers(conn *nats.Conn) {
+	// Public keys for token verifiers
+	patterns.HandleRequest(conn, nats.SubjectAuthJWKS, h.JWKS, h.logger)
+
+	// Key administration
+	patterns.HandleRequest(conn, "auth.keys.list", h.ListSigningKeys, h.logger)
+	patterns.HandleRequest(conn, "auth.keys.rotate", h.RotateSigningKey, h.logger)
+}
+
+// JWKS handles requests for the published public keys
+func (h *KeyHandler) JWKS(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", nats.SubjectAuthJWKS)
+	handlerLogger.Debug("Received JWKS request")
+
+	ctx := context.Background()
+	set, err := h.keyService.JWKS(ctx)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to build JWKS document")
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// ListSigningKeys handles requests to list the signing keys
+func (h *KeyHandler) ListSigningKeys(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.keys.list")
+	handlerLogger.Debug("Received list signing keys request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	ctx := context.Background()
+	keys, err := h.keyService.ListSigningKeys(ctx)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to list signing keys")
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RotateSigningKey handles requests to rotate the signing key ahead of schedule
+func (h *KeyHandler) RotateSigningKey(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.keys.rotate")
+	handlerLogger.Info("Received rotate signing key request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	ctx := context.Background()
+	key, err := h.keyService.RotateSigningKey(ctx)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Signing key rotation failed")
+		return nil, err
+	}
+
+	handlerLogger.With("kid", key.ID).Info("Signing key rotated")
+	return key, nil
+}
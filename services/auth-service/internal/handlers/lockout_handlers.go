@@ -0,0 +1,75 @@
+// services/auth-service/internal/handlers/lockout_handlers.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// RedeemUnlockToken handles unlocking an account with its emailed token
+func (h *AuthHandler) RedeemUnlockToken(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.unlock")
+	handlerLogger.Info("Received unlock token request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.RedeemUnlockTokenRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal unlock token request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	if req.Token == "" {
+		handlerLogger.Warn("Missing unlock token")
+		return nil, domain.NewInvalidAuthInputError("Unlock token is required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.RedeemUnlockToken(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Unlock failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("Account unlocked with token")
+	return map[string]any{"success": true, "message": "Account unlocked successfully"}, nil
+}
+
+// UnlockAccount handles an administrator unlocking an account
+func (h *AuthHandler) UnlockAccount(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.users.unlock")
+	handlerLogger.Info("Received unlock account request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.UnlockAccountRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal unlock account request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	if req.UserID == "" {
+		handlerLogger.Warn("Missing user ID")
+		return nil, domain.NewInvalidAuthInputError("User ID is required", nil)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	ctx := context.Background()
+	if err := h.authService.UnlockAccount(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Unlock account failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("Account unlocked")
+	return map[string]any{"success": true, "message": "Account unlocked successfully"}, nil
+}
@@ -0,0 +1,77 @@
+// services/auth-service/internal/handlers/magic_link_handlers.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// RequestMagicLink handles requests to email a passwordless login link
+func (h *AuthHandler) RequestMagicLink(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.magic-link.request")
+	handlerLogger.Info("Received magic link request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.RequestMagicLinkRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal magic link request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	if req.Email == "" {
+		handlerLogger.Warn("Missing email")
+		return nil, domain.NewInvalidAuthInputError("Email is required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.RequestMagicLink(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Magic link request failed")
+		return nil, err
+	}
+
+	// Always return success to prevent email enumeration
+	return map[string]any{
+		"success": true,
+		"message": "If the email exists, a login link has been sent",
+	}, nil
+}
+
+// ExchangeMagicLink handles logging in with a magic link's token
+func (h *AuthHandler) ExchangeMagicLink(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.magic-link.exchange")
+	handlerLogger.Info("Received magic link login request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.ExchangeMagicLinkRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal magic link login request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	if req.Token == "" {
+		handlerLogger.Warn("Missing magic link token")
+		return nil, domain.NewInvalidAuthInputError("Token is required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.ExchangeMagicLink(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Magic link login failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("Magic link login successful")
+	return response, nil
+}
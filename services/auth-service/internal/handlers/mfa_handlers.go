@@ -0,0 +1,250 @@
+// services/auth-service/internal/handlers/mfa_handlers.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// VerifyMFA handles the second step of a login
+func (h *AuthHandler) VerifyMFA(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.mfa.verify")
+	handlerLogger.Info("Received MFA verification request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.VerifyMFARequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal MFA verification request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	if req.MFAToken == "" {
+		handlerLogger.Warn("Missing MFA token")
+		return nil, domain.NewInvalidAuthInputError("MFA token is required", nil)
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		handlerLogger.Warn("Missing MFA code")
+		return nil, domain.NewInvalidAuthInputError("Code or recovery code is required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.VerifyMFA(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Warn("MFA verification failed")
+		return nil, err
+	}
+
+	handlerLogger.With("user_id", response.User.ID).Info("MFA verification successful")
+	return response, nil
+}
+
+// EnrollMFA handles TOTP enrollment requests
+func (h *AuthHandler) EnrollMFA(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.mfa.enroll")
+	handlerLogger.Info("Received MFA enrollment request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.EnrollMFARequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal MFA enrollment request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	if req.UserID == "" && req.MFAToken == "" {
+		handlerLogger.Warn("Missing user ID or MFA token")
+		return nil, domain.NewInvalidAuthInputError("User ID or MFA token is required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.EnrollMFA(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("MFA enrollment failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("MFA enrollment started")
+	return response, nil
+}
+
+// ConfirmMFA handles TOTP enrollment confirmation requests
+func (h *AuthHandler) ConfirmMFA(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.mfa.confirm")
+	handlerLogger.Info("Received MFA confirmation request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.ConfirmMFARequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal MFA confirmation request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	if req.UserID == "" || req.Code == "" {
+		handlerLogger.Warn("Missing user ID or code")
+		return nil, domain.NewInvalidAuthInputError("User ID and code are required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.ConfirmMFA(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Warn("MFA confirmation failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("MFA enrollment confirmed")
+	return response, nil
+}
+
+// DisableMFA handles requests to remove a user's second factor
+func (h *AuthHandler) DisableMFA(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.mfa.disable")
+	handlerLogger.Info("Received disable MFA request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.DisableMFARequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal disable MFA request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	if req.UserID == "" {
+		handlerLogger.Warn("Missing user ID")
+		return nil, domain.NewInvalidAuthInputError("User ID is required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.DisableMFA(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Disable MFA failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("MFA disabled")
+	return map[string]any{"success": true, "message": "Two-factor authentication disabled"}, nil
+}
+
+// ResetMFA handles administrator requests to remove a user's second factor
+func (h *AuthHandler) ResetMFA(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.mfa.reset")
+	handlerLogger.Info("Received reset MFA request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal reset MFA request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	if req.UserID == "" {
+		handlerLogger.Warn("Missing user ID")
+		return nil, domain.NewInvalidAuthInputError("User ID is required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.ResetMFA(ctx, req.UserID); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Reset MFA failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("MFA reset")
+	return map[string]any{"success": true, "message": "Two-factor authentication reset"}, nil
+}
+
+// RegenerateRecoveryCodes handles requests for a new set of recovery codes
+func (h *AuthHandler) RegenerateRecoveryCodes(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.mfa.recovery-codes")
+	handlerLogger.Info("Received regenerate recovery codes request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.RegenerateRecoveryCodesRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal regenerate recovery codes request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	if req.UserID == "" || req.Code == "" {
+		handlerLogger.Warn("Missing user ID or code")
+		return nil, domain.NewInvalidAuthInputError("User ID and code are required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.RegenerateRecoveryCodes(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Regenerate recovery codes failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("Recovery codes regenerated")
+	return response, nil
+}
+
+// GetMFAStatus handles MFA status requests
+func (h *AuthHandler) GetMFAStatus(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.mfa.status")
+	handlerLogger.Debug("Received MFA status request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal MFA status request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	if req.UserID == "" {
+		handlerLogger.Warn("Missing user ID")
+		return nil, domain.NewInvalidAuthInputError("User ID is required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.GetMFAStatus(ctx, req.UserID)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Get MFA status failed")
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,299 @@
+// services/auth-service/internal/handlers/oauth_handlers.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// RegisterOAuthClient handles OAuth client registration requests
+func (h *AuthHandler) RegisterOAuthClient(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.clients.register")
+	handlerLogger.Info("Received register OAuth client request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.RegisterOAuthClientRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal register OAuth client request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("name", req.Name).With("type", req.Type)
+
+	if req.Name == "" || req.Type == "" {
+		handlerLogger.Warn("Missing client name or type")
+		return nil, domain.NewInvalidAuthInputError("Client name and type are required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.RegisterOAuthClient(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Register OAuth client failed")
+		return nil, err
+	}
+
+	handlerLogger.With("client_id", response.ID).Info("OAuth client registered")
+	return response, nil
+}
+
+// ListOAuthClients handles list OAuth clients requests
+func (h *AuthHandler) ListOAuthClients(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.clients.list")
+	handlerLogger.Debug("Received list OAuth clients request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	ctx := context.Background()
+	clients, err := h.authService.ListOAuthClients(ctx)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("List OAuth clients failed")
+		return nil, err
+	}
+
+	handlerLogger.With("client_count", len(clients)).Debug("OAuth clients retrieved")
+	return clients, nil
+}
+
+// RevokeOAuthClient handles OAuth client revocation requests
+func (h *AuthHandler) RevokeOAuthClient(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.clients.revoke")
+	handlerLogger.Info("Received revoke OAuth client request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req struct {
+		ClientID string `json:"clientId"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal revoke OAuth client request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("client_id", req.ClientID)
+
+	if req.ClientID == "" {
+		handlerLogger.Warn("Missing client ID")
+		return nil, domain.NewInvalidAuthInputError("Client ID is required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.RevokeOAuthClient(ctx, req.ClientID); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Revoke OAuth client failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("OAuth client revoked")
+	return map[string]string{"message": "OAuth client revoked successfully"}, nil
+}
+
+// Authorize handles authorization requests made by signed-in users
+func (h *AuthHandler) Authorize(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.authorize")
+	handlerLogger.Info("Received authorization request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.AuthorizeRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal authorization request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID).With("client_id", req.ClientID)
+
+	if req.UserID == "" || req.ClientID == "" {
+		handlerLogger.Warn("Missing user ID or client ID")
+		return nil, domain.NewInvalidAuthInputError("User ID and client_id are required", nil)
+	}
+
+	ctx := context.Background()
+	response, err := h.authService.Authorize(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Authorization request failed")
+		return nil, err
+	}
+
+	handlerLogger.With("consent_required", response.ConsentRequired).Debug("Authorization request handled")
+	return response, nil
+}
+
+// ListOAuthConsents handles requests for the clients a user has granted access
+func (h *AuthHandler) ListOAuthConsents(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.consents.list")
+	handlerLogger.Debug("Received list OAuth consents request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal list OAuth consents request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	if req.UserID == "" {
+		handlerLogger.Warn("Missing user ID")
+		return nil, domain.NewInvalidAuthInputError("User ID is required", nil)
+	}
+
+	ctx := context.Background()
+	consents, err := h.authService.ListOAuthConsents(ctx, req.UserID)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("List OAuth consents failed")
+		return nil, err
+	}
+
+	return consents, nil
+}
+
+// RevokeOAuthConsent handles requests to withdraw consent to a client
+func (h *AuthHandler) RevokeOAuthConsent(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.consents.revoke")
+	handlerLogger.Info("Received revoke OAuth consent request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.RevokeOAuthConsentRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal revoke OAuth consent request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID).With("client_id", req.ClientID)
+
+	if req.UserID == "" || req.ClientID == "" {
+		handlerLogger.Warn("Missing user ID or client ID")
+		return nil, domain.NewInvalidAuthInputError("User ID and client ID are required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.RevokeOAuthConsent(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Revoke OAuth consent failed")
+		return nil, err
+	}
+
+	return map[string]string{"message": "OAuth consent revoked successfully"}, nil
+}
+
+// OAuthToken handles token endpoint requests
+func (h *AuthHandler) OAuthToken(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.token")
+	handlerLogger.Info("Received OAuth token request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.OAuthTokenRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal OAuth token request")
+		return oauthError(domain.NewOAuthError(domain.OAuthInvalidRequest, "Invalid request format"), handlerLogger)
+	}
+
+	handlerLogger = handlerLogger.With("client_id", req.ClientID).With("grant_type", req.GrantType)
+
+	ctx := context.Background()
+	response, err := h.authService.OAuthToken(ctx, req)
+	if err != nil {
+		return oauthError(err, handlerLogger)
+	}
+
+	handlerLogger.Info("OAuth tokens issued")
+	return response, nil
+}
+
+// UserInfo handles userinfo endpoint requests
+func (h *AuthHandler) UserInfo(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.userinfo")
+	handlerLogger.Debug("Received userinfo request")
+
+	var req dto.UserInfoRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.AccessToken == "" {
+		handlerLogger.Warn("Userinfo request without an access token")
+		return oauthError(domain.NewOAuthError(domain.OAuthInvalidToken, "An access token is required"), handlerLogger)
+	}
+
+	ctx := context.Background()
+	claims, err := h.authService.UserInfo(ctx, req)
+	if err != nil {
+		return oauthError(err, handlerLogger)
+	}
+
+	return claims, nil
+}
+
+// Introspect handles token introspection requests
+func (h *AuthHandler) Introspect(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.introspect")
+	handlerLogger.Debug("Received token introspection request")
+
+	var req dto.IntrospectRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal introspection request")
+		return oauthError(domain.NewOAuthError(domain.OAuthInvalidRequest, "Invalid request format"), handlerLogger)
+	}
+
+	handlerLogger = handlerLogger.With("client_id", req.ClientID)
+
+	ctx := context.Background()
+	response, err := h.authService.Introspect(ctx, req)
+	if err != nil {
+		return oauthError(err, handlerLogger)
+	}
+
+	return response, nil
+}
+
+// Discovery handles requests for the OpenID Provider metadata
+func (h *AuthHandler) Discovery(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.oauth.discovery")
+	handlerLogger.Debug("Received discovery request")
+
+	ctx := context.Background()
+	return h.authService.Discovery(ctx)
+}
+
+// oauthError replies with OAuth protocol errors as payloads in the
+// protocol's format, which the gateway passes on to the client. Any other
+// error fails the request as usual.
+func oauthError(err error, logger log.Logger) (any, error) {
+	oauthErr, ok := domain.AsOAuthError(err)
+	if !ok {
+		logger.With("error", err.Error()).Error("OAuth request failed")
+		return nil, err
+	}
+
+	logger.With("error", oauthErr.Code).Warn("OAuth request rejected")
+	return dto.OAuthErrorResponse{
+		Error:            oauthErr.Code,
+		ErrorDescription: oauthErr.Description,
+	}, nil
+}
@@ -0,0 +1,234 @@
+// services/auth-service/internal/handlers/policy_handlers.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// EvaluatePolicy handles requests to decide, and explain, whether a user may
+// perform an action on a resource
+func (h *AuthHandler) EvaluatePolicy(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.policy.evaluate")
+	handlerLogger.Debug("Received evaluate policy request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.EvaluatePolicyRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal evaluate policy request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID).With("resource", req.Resource).With("action", req.Action)
+
+	if req.UserID == "" || req.Resource == "" || req.Action == "" {
+		handlerLogger.Warn("Missing required policy evaluation fields")
+		return nil, domain.NewInvalidAuthInputError("User ID, resource, and action are required", nil)
+	}
+
+	ctx := context.Background()
+	decision, err := h.authService.EvaluatePolicy(ctx, req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Evaluate policy failed")
+		return nil, err
+	}
+
+	handlerLogger.With("allowed", decision.Allowed).Debug("Policy evaluation completed")
+	return decision, nil
+}
+
+// ListRoles handles list roles requests
+func (h *AuthHandler) ListRoles(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.roles.list")
+	handlerLogger.Debug("Received list roles request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	ctx := context.Background()
+	roles, err := h.authService.ListRoles(ctx)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("List roles failed")
+		return nil, err
+	}
+
+	handlerLogger.With("role_count", len(roles)).Debug("Roles retrieved")
+	return roles, nil
+}
+
+// SetRoleParent handles requests to change the role a role inherits from
+func (h *AuthHandler) SetRoleParent(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.roles.parent")
+	handlerLogger.Info("Received set role parent request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.SetRoleParentRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal set role parent request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("role_id", req.RoleID).With("parent_id", req.ParentID)
+
+	if req.RoleID == "" {
+		handlerLogger.Warn("Missing role ID")
+		return nil, domain.NewInvalidAuthInputError("Role ID is required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.SetRoleParent(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Set role parent failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("Role parent set")
+	return map[string]any{"success": true, "message": "Role parent updated successfully"}, nil
+}
+
+// GetUserRoles handles requests for the roles and attributes of a user
+func (h *AuthHandler) GetUserRoles(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.users.roles.get")
+	handlerLogger.Debug("Received get user roles request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal get user roles request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	if req.UserID == "" {
+		handlerLogger.Warn("Missing user ID")
+		return nil, domain.NewInvalidAuthInputError("User ID is required", nil)
+	}
+
+	ctx := context.Background()
+	roles, err := h.authService.GetUserRoles(ctx, req.UserID)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Get user roles failed")
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// AssignUserRole handles requests to assign a role to a user
+func (h *AuthHandler) AssignUserRole(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.users.roles.assign")
+	handlerLogger.Info("Received assign user role request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.AssignUserRoleRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal assign user role request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID).With("role", req.Role)
+
+	if req.UserID == "" || req.Role == "" {
+		handlerLogger.Warn("Missing user ID or role")
+		return nil, domain.NewInvalidAuthInputError("User ID and role are required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.AssignUserRole(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Assign user role failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("User role assigned")
+	return map[string]any{"success": true, "message": "Role assigned to user successfully"}, nil
+}
+
+// RevokeUserRole handles requests to remove a role from a user
+func (h *AuthHandler) RevokeUserRole(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.users.roles.revoke")
+	handlerLogger.Info("Received revoke user role request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.RevokeUserRoleRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal revoke user role request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID).With("role", req.Role)
+
+	if req.UserID == "" || req.Role == "" {
+		handlerLogger.Warn("Missing user ID or role")
+		return nil, domain.NewInvalidAuthInputError("User ID and role are required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.RevokeUserRole(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Revoke user role failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("User role revoked")
+	return map[string]any{"success": true, "message": "Role revoked from user successfully"}, nil
+}
+
+// SetUserAttributes handles requests to replace the attributes of a user
+func (h *AuthHandler) SetUserAttributes(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "auth.users.attributes.set")
+	handlerLogger.Info("Received set user attributes request")
+
+	startTime := time.Now()
+	defer func() {
+		handlerLogger.With("duration_ms", time.Since(startTime).Milliseconds()).Debug("Request handling completed")
+	}()
+
+	var req dto.SetUserAttributesRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal set user attributes request")
+		return nil, domain.NewInvalidAuthInputError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("user_id", req.UserID)
+
+	if req.UserID == "" {
+		handlerLogger.Warn("Missing user ID")
+		return nil, domain.NewInvalidAuthInputError("User ID is required", nil)
+	}
+
+	ctx := context.Background()
+	if err := h.authService.SetUserAttributes(ctx, req); err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Set user attributes failed")
+		return nil, err
+	}
+
+	handlerLogger.Info("User attributes set")
+	return map[string]any{"success": true, "message": "User attributes updated successfully"}, nil
+}
@@ -0,0 +1,186 @@
+// services/auth-service/internal/lockout/guard.go
+
+// Package lockout throttles password guessing. Failed logins are counted per
+// username and per client address; past a threshold each further failure
+// locks the username or address for twice as long as the last lockout.
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+)
+
+// Scopes a lockout applies to
+const (
+	ScopeAccount = "account" // every login for the username
+	ScopeIP      = "ip"      // every login from the client address
+)
+
+// Policy configures progressive lockout
+type Policy struct {
+	MaxAttempts   int           // failures per username before it locks; 0 disables
+	IPMaxAttempts int           // failures per address before it locks; 0 disables
+	BaseDuration  time.Duration // the first lockout
+	MaxDuration   time.Duration // the longest lockout
+	Window        time.Duration // how long failures are remembered once lockouts end
+}
+
+// Backoff returns how long a key with failures locks for: nothing below the
+// threshold, then BaseDuration doubling with each failure up to MaxDuration
+func (p Policy) Backoff(failures, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	d := p.BaseDuration
+	for i := threshold; i < failures && d < p.MaxDuration; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxDuration)
+}
+
+// Lockout is a username or address barred from logging in
+type Lockout struct {
+	Scope    string
+	Key      string        // the username or address
+	Failures int           // failures counted when the lock was taken
+	Duration time.Duration // how long the lock lasts from now
+}
+
+// Guard applies the lockout policy to logins
+type Guard struct {
+	store  Store
+	policy Policy
+	logger log.Logger
+}
+
+// NewGuard creates a new guard
+func NewGuard(store Store, policy Policy, logger log.Logger) *Guard {
+	return &Guard{
+		store:  store,
+		policy: policy,
+		logger: logger.WithLayer("login-lockout"),
+	}
+}
+
+// Check returns the lockout barring a login for username from ip, or nil if
+// it may go ahead. Usernames are matched case-insensitively.
+func (g *Guard) Check(ctx context.Context, username, ip string) (*Lockout, error) {
+	for _, subject := range g.subjects(username, ip) {
+		d, err := g.store.LockedFor(ctx, subject.storeKey())
+		if err != nil {
+			return nil, err
+		}
+		if d > 0 {
+			subject.Duration = d
+			return &subject, nil
+		}
+	}
+	return nil, nil
+}
+
+// Fail records a failed login for username from ip and returns the lockouts
+// it triggered
+func (g *Guard) Fail(ctx context.Context, username, ip string) ([]Lockout, error) {
+	// The count has to outlive the longest lockout, or the backoff would
+	// start over each time a lock ends
+	window := g.policy.Window + g.policy.MaxDuration
+
+	var lockouts []Lockout
+	for _, subject := range g.subjects(username, ip) {
+		failures, err := g.store.Fail(ctx, subject.storeKey(), window)
+		if err != nil {
+			return lockouts, err
+		}
+
+		d := g.policy.Backoff(failures, g.threshold(subject.Scope))
+		if d == 0 {
+			continue
+		}
+		if err := g.store.Lock(ctx, subject.storeKey(), d); err != nil {
+			return lockouts, err
+		}
+
+		subject.Failures = failures
+		subject.Duration = d
+		lockouts = append(lockouts, subject)
+		g.logger.With("scope", subject.Scope).
+			With("key", subject.Key).
+			With("failures", failures).
+			With("duration", d.String()).
+			Warn("Login locked out")
+	}
+	return lockouts, nil
+}
+
+// Succeed forgets the failures of a username that logged in. Its address
+// keeps its count, since credential stuffing succeeds now and then.
+func (g *Guard) Succeed(ctx context.Context, username string) error {
+	return g.store.Reset(ctx, accountKey(username))
+}
+
+// Unlock lifts the lockouts of usernames and forgets their failures
+func (g *Guard) Unlock(ctx context.Context, usernames ...string) error {
+	for _, username := range usernames {
+		if username == "" {
+			continue
+		}
+		if err := g.store.Reset(ctx, accountKey(username)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cooldown reports whether an action keyed by key may go ahead, and if so
+// bars it for d. It spaces out actions like emailing login links, which
+// shouldn't be repeatable as fast as requests arrive.
+func (g *Guard) Cooldown(ctx context.Context, key string, d time.Duration) (bool, error) {
+	storeKey := "cooldown:" + key
+	remaining, err := g.store.LockedFor(ctx, storeKey)
+	if err != nil {
+		return false, err
+	}
+	if remaining > 0 {
+		return false, nil
+	}
+	return true, g.store.Lock(ctx, storeKey, d)
+}
+
+// subjects returns the username and address a login counts against. The
+// address comes first, so a throttled address can't probe which usernames
+// are locked.
+func (g *Guard) subjects(username, ip string) []Lockout {
+	var subjects []Lockout
+	if ip != "" && g.policy.IPMaxAttempts > 0 {
+		subjects = append(subjects, Lockout{Scope: ScopeIP, Key: ip})
+	}
+	if username = normalize(username); username != "" && g.policy.MaxAttempts > 0 {
+		subjects = append(subjects, Lockout{Scope: ScopeAccount, Key: username})
+	}
+	return subjects
+}
+
+func (g *Guard) threshold(scope string) int {
+	if scope == ScopeIP {
+		return g.policy.IPMaxAttempts
+	}
+	return g.policy.MaxAttempts
+}
+
+func (l Lockout) storeKey() string {
+	if l.Scope == ScopeIP {
+		return "ip:" + l.Key
+	}
+	return accountKey(l.Key)
+}
+
+func accountKey(username string) string {
+	return "user:" + normalize(username)
+}
+
+func normalize(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
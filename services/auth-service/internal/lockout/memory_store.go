@@ -0,0 +1,104 @@
+// services/auth-service/internal/lockout/memory_store.go
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// entry is the failures and lockout of one key
+type entry struct {
+	failures    int
+	forgetAt    time.Time // when the failures expire
+	lockedUntil time.Time
+}
+
+// MemoryStore keeps failures and lockouts in process memory. Expired keys are
+// swept periodically, so memory is bounded by the keys failing recently.
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]*entry
+	lastSweep  time.Time
+	sweepEvery time.Duration
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:    make(map[string]*entry),
+		lastSweep:  time.Now(),
+		sweepEvery: time.Minute,
+	}
+}
+
+// Fail implements Store
+func (s *MemoryStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{}
+		s.entries[key] = e
+	}
+	if now.After(e.forgetAt) {
+		e.failures = 0
+	}
+	e.failures++
+	e.forgetAt = now.Add(window)
+	return e.failures, nil
+}
+
+// Lock implements Store
+func (s *MemoryStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{}
+		s.entries[key] = e
+	}
+	e.lockedUntil = time.Now().Add(d)
+	return nil
+}
+
+// LockedFor implements Store
+func (s *MemoryStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return 0, nil
+	}
+	return max(time.Until(e.lockedUntil), 0), nil
+}
+
+// Reset implements Store
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// sweep drops keys whose failures and lockout have both expired; callers
+// hold the lock
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepEvery {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if now.After(e.forgetAt) && now.After(e.lockedUntil) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// services/auth-service/internal/lockout/redis_store.go
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/db"
+)
+
+// failScript counts a failure and restarts the window, atomically so
+// concurrent failures across replicas are all counted
+const failScript = `
+local failures = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return failures
+`
+
+// RedisStore keeps failures and lockouts in Redis, shared by every
+// auth-service replica. Both are keys with a TTL.
+type RedisStore struct {
+	client *db.RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a new Redis-backed store
+func NewRedisStore(client *db.RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Fail implements Store
+func (s *RedisStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	reply, err := s.client.Eval(ctx, failScript, []string{s.failuresKey(key)}, window.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+
+	failures, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected lockout script reply: %v", reply)
+	}
+	return int(failures), nil
+}
+
+// Lock implements Store
+func (s *RedisStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	return s.client.Set(ctx, s.lockKey(key), "1", d)
+}
+
+// LockedFor implements Store
+func (s *RedisStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	reply, err := s.client.Do(ctx, "PTTL", s.lockKey(key))
+	if err != nil {
+		return 0, err
+	}
+
+	ttl, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected PTTL reply: %v", reply)
+	}
+	// -2 means no lock; a lock always has a TTL
+	if ttl <= 0 {
+		return 0, nil
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
+// Reset implements Store
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.failuresKey(key), s.lockKey(key))
+}
+
+func (s *RedisStore) failuresKey(key string) string {
+	return s.prefix + "failures:" + key
+}
+
+func (s *RedisStore) lockKey(key string) string {
+	return s.prefix + "lock:" + key
+}
@@ -0,0 +1,89 @@
+// services/auth-service/internal/lockout/store.go
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+)
+
+// Store counts failed logins and holds lockouts. Both expire on their own, so
+// a lockout ends without anything having to lift it.
+type Store interface {
+	// Fail counts a failure against key and returns the failures counted.
+	// The count is forgotten once window passes without another failure.
+	Fail(ctx context.Context, key string, window time.Duration) (int, error)
+
+	// Lock locks key for d
+	Lock(ctx context.Context, key string, d time.Duration) error
+
+	// LockedFor returns how much longer key is locked, or 0 if it isn't
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+
+	// Reset forgets key's failures and lifts its lockout
+	Reset(ctx context.Context, key string) error
+}
+
+// FallbackStore uses a primary store and falls back to a secondary one when
+// the primary errors, so a Redis outage degrades to per-replica lockouts
+// instead of failing every login
+type FallbackStore struct {
+	primary   Store
+	secondary Store
+	logger    log.Logger
+}
+
+// NewFallbackStore creates a new fallback store
+func NewFallbackStore(primary, secondary Store, logger log.Logger) *FallbackStore {
+	return &FallbackStore{
+		primary:   primary,
+		secondary: secondary,
+		logger:    logger,
+	}
+}
+
+// Fail implements Store
+func (s *FallbackStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	failures, err := s.primary.Fail(ctx, key, window)
+	if err == nil {
+		return failures, nil
+	}
+
+	s.logger.With("error", err.Error()).Warn("Primary lockout store failed, using fallback")
+	return s.secondary.Fail(ctx, key, window)
+}
+
+// Lock implements Store
+func (s *FallbackStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	if err := s.primary.Lock(ctx, key, d); err != nil {
+		s.logger.With("error", err.Error()).Warn("Primary lockout store failed, using fallback")
+		return s.secondary.Lock(ctx, key, d)
+	}
+	return nil
+}
+
+// LockedFor implements Store. A lock held by either store counts, since it
+// may have been taken while the primary was down.
+func (s *FallbackStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	secondary, err := s.secondary.LockedFor(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	primary, err := s.primary.LockedFor(ctx, key)
+	if err != nil {
+		s.logger.With("error", err.Error()).Warn("Primary lockout store failed, using fallback")
+		return secondary, nil
+	}
+	return max(primary, secondary), nil
+}
+
+// Reset implements Store
+func (s *FallbackStore) Reset(ctx context.Context, key string) error {
+	primaryErr := s.primary.Reset(ctx, key)
+	if err := s.secondary.Reset(ctx, key); err != nil {
+		return err
+	}
+	return primaryErr
+}
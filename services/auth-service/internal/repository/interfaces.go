@@ -19,12 +19,26 @@ type TokenRepository interface {
 	// GetTokenByValue retrieves a token by its value (the actual token string)
 	GetTokenByValue(ctx context.Context, value string) (*models.Token, error)
 	
+	// GetRefreshToken retrieves a refresh token by value, including revoked ones
+	GetRefreshToken(ctx context.Context, value string) (*models.Token, error)
+	
+	// RotateRefreshToken revokes a live refresh token and stores its replacement,
+	// returning false if the token had already been revoked
+	RotateRefreshToken(ctx context.Context, tokenID string, next *models.Token) (bool, error)
+	
+	// RevokeTokenFamily revokes all tokens of a refresh token family
+	RevokeTokenFamily(ctx context.Context, familyID string) (int, error)
+	
 	// GetTokensByUserID retrieves all tokens for a specific user
 	// GetTokensByUserID(ctx context.Context, userID string, tokenType string) ([]*models.Token, error)
 	
 	// RevokeToken marks a token as revoked
 	RevokeToken(ctx context.Context, tokenID string) error
 	
+	// ConsumeToken revokes a single-use token, returning false if it had
+	// already been revoked
+	ConsumeToken(ctx context.Context, tokenID string) (bool, error)
+	
 	// RevokeAllTokensForUser revokes all tokens for a user
 	RevokeAllTokensForUser(ctx context.Context, userID string, tokenType string) error
 	
@@ -94,14 +108,160 @@ type PermissionRepository interface {
 
 // RolePermissionRepository defines the interface for role-permission mappings
 type RolePermissionRepository interface {
-	// AssignPermissionToRole assigns a permission to a role
-	AssignPermissionToRole(ctx context.Context, roleID string, permissionID string) error
+	// AssignPermissionToRole assigns a permission to a role, replacing the
+	// conditions of an existing grant
+	AssignPermissionToRole(ctx context.Context, roleID string, permissionID string, conditions []models.PolicyCondition) error
 	
 	// RevokePermissionFromRole revokes a permission from a role
 	RevokePermissionFromRole(ctx context.Context, roleID string, permissionID string) error
 	
 	// GetRolesByPermission retrieves all roles that have a specific permission
 	GetRolesByPermission(ctx context.Context, permissionID string) ([]string, error)
+	
+	// GetPermissionGrants retrieves the permissions granted to any of the roles
+	GetPermissionGrants(ctx context.Context, roleIDs []string) ([]*models.PermissionGrant, error)
+}
+
+// RoleRepository defines the interface for roles, their inheritance and
+// their assignment to users
+type RoleRepository interface {
+	// ListRoles retrieves all roles
+	ListRoles(ctx context.Context) ([]*models.RoleDefinition, error)
+	
+	// GetRole retrieves a role by ID or name
+	GetRole(ctx context.Context, idOrName string) (*models.RoleDefinition, error)
+	
+	// SetRoleParent sets the role a role inherits from; an empty parent clears it
+	SetRoleParent(ctx context.Context, roleID string, parentID string) error
+	
+	// ListUserRoles retrieves the roles assigned to a user
+	ListUserRoles(ctx context.Context, userID string) ([]*models.UserRole, error)
+	
+	// AssignUserRole assigns a role to a user
+	AssignUserRole(ctx context.Context, userRole *models.UserRole) error
+	
+	// RevokeUserRole removes a role from a user
+	RevokeUserRole(ctx context.Context, userID string, roleID string) error
+	
+	// GetUserAttributes retrieves the attributes policies can refer to
+	GetUserAttributes(ctx context.Context, userID string) (map[string]string, error)
+	
+	// SetUserAttributes replaces a user's attributes
+	SetUserAttributes(ctx context.Context, userID string, attributes map[string]string) error
+}
+
+// APIKeyRepository defines the interface for API key operations
+type APIKeyRepository interface {
+	// CreateAPIKey stores a new API key
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	
+	// GetAPIKeyByID retrieves an API key by ID
+	GetAPIKeyByID(ctx context.Context, id string) (*models.APIKey, error)
+	
+	// GetAPIKeyByPrefix retrieves an API key by its public prefix
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	
+	// ListAPIKeysByOwner retrieves all API keys owned by a user
+	ListAPIKeysByOwner(ctx context.Context, ownerID string) ([]*models.APIKey, error)
+	
+	// RevokeAPIKey marks an API key as revoked
+	RevokeAPIKey(ctx context.Context, id string) error
+	
+	// ExpireAPIKey moves an API key's expiry, e.g. to give a rotated key a grace period
+	ExpireAPIKey(ctx context.Context, id string, expiresAt time.Time) error
+	
+	// UpdateAPIKeyLastUsed records when an API key was last used
+	UpdateAPIKeyLastUsed(ctx context.Context, id string, lastUsed time.Time) error
+}
+
+// MFARepository defines the interface for TOTP enrollment operations
+type MFARepository interface {
+	// SaveMFAEnrollment creates or replaces a user's enrollment
+	SaveMFAEnrollment(ctx context.Context, enrollment *models.MFAEnrollment) error
+	
+	// GetMFAEnrollment retrieves a user's enrollment
+	GetMFAEnrollment(ctx context.Context, userID string) (*models.MFAEnrollment, error)
+	
+	// ConfirmMFAEnrollment completes an enrollment, storing its recovery code hashes
+	ConfirmMFAEnrollment(ctx context.Context, userID string, recoveryCodes []string, confirmedAt time.Time) error
+	
+	// ClaimMFAStep records a code's time step as used, reporting false if it
+	// or a later step was already used
+	ClaimMFAStep(ctx context.Context, userID string, step int64) (bool, error)
+	
+	// ReplaceMFARecoveryCodes swaps the recovery code hashes, reporting false
+	// if they changed since they were read
+	ReplaceMFARecoveryCodes(ctx context.Context, userID string, old, new []string) (bool, error)
+	
+	// DeleteMFAEnrollment removes a user's enrollment
+	DeleteMFAEnrollment(ctx context.Context, userID string) error
+}
+
+// PasswordHistoryRepository defines the interface for the passwords users have had
+type PasswordHistoryRepository interface {
+	// AddPasswordHistory records a user's new password, keeping only their
+	// newest keep entries
+	AddPasswordHistory(ctx context.Context, entry *models.PasswordHistoryEntry, keep int) error
+	
+	// ListPasswordHistory retrieves a user's newest passwords, newest first
+	ListPasswordHistory(ctx context.Context, userID string, limit int) ([]*models.PasswordHistoryEntry, error)
+}
+
+// SigningKeyRepository defines the interface for token signing key operations
+type SigningKeyRepository interface {
+	// CreateSigningKey stores a new signing key
+	CreateSigningKey(ctx context.Context, key *models.SigningKey) error
+	
+	// ListSigningKeys retrieves the keys still published at a time, oldest first
+	ListSigningKeys(ctx context.Context, now time.Time) ([]*models.SigningKey, error)
+	
+	// RetireSigningKeys sets the expiry of keys activated before a time,
+	// unless they already expire sooner
+	RetireSigningKeys(ctx context.Context, activatedBefore, expiresAt time.Time) (int, error)
+	
+	// DeleteExpiredSigningKeys deletes keys that expired before a time
+	DeleteExpiredSigningKeys(ctx context.Context, before time.Time) (int, error)
+}
+
+// OAuthRepository defines the interface for OAuth client, consent and
+// authorization code operations
+type OAuthRepository interface {
+	// CreateOAuthClient registers a new client
+	CreateOAuthClient(ctx context.Context, client *models.OAuthClient) error
+	
+	// GetOAuthClient retrieves a client by its client ID
+	GetOAuthClient(ctx context.Context, id string) (*models.OAuthClient, error)
+	
+	// ListOAuthClients retrieves all clients, newest first
+	ListOAuthClients(ctx context.Context) ([]*models.OAuthClient, error)
+	
+	// RevokeOAuthClient marks a client as revoked
+	RevokeOAuthClient(ctx context.Context, id string) error
+	
+	// SaveOAuthConsent creates or replaces a user's consent to a client
+	SaveOAuthConsent(ctx context.Context, consent *models.OAuthConsent) error
+	
+	// GetOAuthConsent retrieves a user's consent to a client
+	GetOAuthConsent(ctx context.Context, userID, clientID string) (*models.OAuthConsent, error)
+	
+	// ListOAuthConsents retrieves all consents a user has granted
+	ListOAuthConsents(ctx context.Context, userID string) ([]*models.OAuthConsent, error)
+	
+	// DeleteOAuthConsent withdraws a user's consent to a client
+	DeleteOAuthConsent(ctx context.Context, userID, clientID string) error
+	
+	// CreateAuthorizationCode stores a new authorization code
+	CreateAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error
+	
+	// ConsumeAuthorizationCode marks a code as used and returns it as it was
+	// before, so a code that was already used can be recognized
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string, usedAt time.Time) (*models.AuthorizationCode, error)
+	
+	// SetAuthorizationCodeSession records the session a code was exchanged for
+	SetAuthorizationCodeSession(ctx context.Context, codeHash, sessionID string) error
+	
+	// DeleteExpiredAuthorizationCodes deletes codes that expired before a time
+	DeleteExpiredAuthorizationCodes(ctx context.Context, before time.Time) (int, error)
 }
 
 // AuthRepository is a composite interface that includes all auth-related repositories
@@ -110,4 +270,10 @@ type AuthRepository interface {
 	SessionRepository
 	PermissionRepository
 	RolePermissionRepository
+	RoleRepository
+	APIKeyRepository
+	MFARepository
+	PasswordHistoryRepository
+	SigningKeyRepository
+	OAuthRepository
 }
\ No newline at end of file
@@ -0,0 +1,239 @@
+// services/auth-service/internal/repository/mysql/api_key_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
+)
+
+const apiKeyColumns = `id, name, prefix, key_hash, owner_id, scopes,
+		       expires_at, last_used_at, revoked_at, rotated_from, created_at`
+
+type APIKeyRepository struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func NewAPIKeyRepository(db *sql.DB, logger log.Logger) repository.APIKeyRepository {
+	return &APIKeyRepository{
+		db:     db,
+		logger: logger.WithLayer("mysql-api-key-repository"),
+	}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (
+			id, name, prefix, key_hash, owner_id, scopes,
+			expires_at, rotated_from, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	scopesJSON, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return domain.NewInvalidAuthInputError("failed to marshal scopes", err)
+	}
+
+	var rotatedFrom sql.NullString
+	if key.RotatedFrom != "" {
+		rotatedFrom = sql.NullString{String: key.RotatedFrom, Valid: true}
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.OwnerID,
+		scopesJSON,
+		key.ExpiresAt,
+		rotatedFrom,
+		key.CreatedAt,
+	)
+
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to create API key in database"),
+			"create_api_key",
+		)
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByID(ctx context.Context, id string) (*models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE id = ?
+	`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewAPIKeyNotFoundError(id)
+		}
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get API key from database"),
+			"get_api_key_by_id",
+		)
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE prefix = ?
+	`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewAPIKeyNotFoundError(prefix)
+		}
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get API key by prefix"),
+			"get_api_key_by_prefix",
+		)
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeysByOwner(ctx context.Context, ownerID string) ([]*models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE owner_id = ?
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to list API keys"),
+			"list_api_keys_by_owner",
+		)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan API key"),
+				"list_api_keys_by_owner",
+			)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating API keys"),
+			"list_api_keys_by_owner",
+		)
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id string) error {
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	return r.update(ctx, "revoke_api_key", id, query, time.Now(), id)
+}
+
+func (r *APIKeyRepository) ExpireAPIKey(ctx context.Context, id string, expiresAt time.Time) error {
+	query := `UPDATE api_keys SET expires_at = ? WHERE id = ?`
+	return r.update(ctx, "expire_api_key", id, query, expiresAt, id)
+}
+
+func (r *APIKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id string, lastUsed time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = ? WHERE id = ?`
+	return r.update(ctx, "update_api_key_last_used", id, query, lastUsed, id)
+}
+
+// update runs a single-row update, reporting a missing key as not found
+func (r *APIKeyRepository) update(ctx context.Context, operation, id, query string, args ...any) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to update API key"),
+			operation,
+		)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			operation,
+		)
+	}
+
+	if affected == 0 {
+		return domain.NewAPIKeyNotFoundError(id)
+	}
+
+	return nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var scopesJSON []byte
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	var rotatedFrom sql.NullString
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.OwnerID,
+		&scopesJSON,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&rotatedFrom,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scopesJSON) > 0 {
+		if err := json.Unmarshal(scopesJSON, &key.Scopes); err != nil {
+			return nil, err
+		}
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	key.RotatedFrom = rotatedFrom.String
+
+	return key, nil
+}
@@ -14,6 +14,12 @@ type AuthRepository struct {
 	repository.SessionRepository
 	repository.PermissionRepository
 	repository.RolePermissionRepository
+	repository.RoleRepository
+	repository.APIKeyRepository
+	repository.MFARepository
+	repository.PasswordHistoryRepository
+	repository.SigningKeyRepository
+	repository.OAuthRepository
 	
 	db     *sql.DB
 	logger log.Logger
@@ -26,6 +32,12 @@ func NewAuthRepository(db *sql.DB, logger log.Logger) repository.AuthRepository
 		SessionRepository:    NewSessionRepository(db, logger),
 		PermissionRepository: NewPermissionRepository(db, logger),
 		RolePermissionRepository: NewRolePermissionRepository(db, logger),
+		RoleRepository: NewRoleRepository(db, logger),
+		APIKeyRepository: NewAPIKeyRepository(db, logger),
+		MFARepository: NewMFARepository(db, logger),
+		PasswordHistoryRepository: NewPasswordHistoryRepository(db, logger),
+		SigningKeyRepository: NewSigningKeyRepository(db, logger),
+		OAuthRepository: NewOAuthRepository(db, logger),
 		db:     db,
 		logger: logger.WithLayer("mysql-auth-repository"),
 	}
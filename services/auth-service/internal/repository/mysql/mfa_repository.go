@@ -0,0 +1,214 @@
+// services/auth-service/internal/repository/mysql/mfa_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
+)
+
+type MFARepository struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func NewMFARepository(db *sql.DB, logger log.Logger) repository.MFARepository {
+	return &MFARepository{
+		db:     db,
+		logger: logger.WithLayer("mysql-mfa-repository"),
+	}
+}
+
+func (r *MFARepository) SaveMFAEnrollment(ctx context.Context, enrollment *models.MFAEnrollment) error {
+	// Re-enrolling starts over with a new secret and no recovery codes
+	query := `
+		INSERT INTO mfa_enrollments (
+			user_id, secret_encrypted, recovery_codes, last_used_step,
+			confirmed_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			secret_encrypted = VALUES(secret_encrypted),
+			recovery_codes = VALUES(recovery_codes),
+			last_used_step = VALUES(last_used_step),
+			confirmed_at = VALUES(confirmed_at),
+			updated_at = VALUES(updated_at)
+	`
+
+	codesJSON, err := marshalRecoveryCodes(enrollment.RecoveryCodes)
+	if err != nil {
+		return domain.NewInvalidAuthInputError("failed to marshal recovery codes", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		enrollment.UserID,
+		enrollment.SecretEncrypted,
+		codesJSON,
+		enrollment.LastUsedStep,
+		enrollment.ConfirmedAt,
+		enrollment.CreatedAt,
+		enrollment.UpdatedAt,
+	)
+
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to save MFA enrollment in database"),
+			"save_mfa_enrollment",
+		)
+	}
+
+	return nil
+}
+
+func (r *MFARepository) GetMFAEnrollment(ctx context.Context, userID string) (*models.MFAEnrollment, error) {
+	query := `
+		SELECT user_id, secret_encrypted, recovery_codes, last_used_step,
+		       confirmed_at, created_at, updated_at
+		FROM mfa_enrollments
+		WHERE user_id = ?
+	`
+
+	enrollment := &models.MFAEnrollment{}
+	var codesJSON []byte
+	var confirmedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&enrollment.UserID,
+		&enrollment.SecretEncrypted,
+		&codesJSON,
+		&enrollment.LastUsedStep,
+		&confirmedAt,
+		&enrollment.CreatedAt,
+		&enrollment.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewMFANotEnrolledError(userID)
+		}
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get MFA enrollment from database"),
+			"get_mfa_enrollment",
+		)
+	}
+
+	if len(codesJSON) > 0 {
+		if err := json.Unmarshal(codesJSON, &enrollment.RecoveryCodes); err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to unmarshal recovery codes"),
+				"get_mfa_enrollment",
+			)
+		}
+	}
+	if confirmedAt.Valid {
+		enrollment.ConfirmedAt = &confirmedAt.Time
+	}
+
+	return enrollment, nil
+}
+
+func (r *MFARepository) ConfirmMFAEnrollment(ctx context.Context, userID string, recoveryCodes []string, confirmedAt time.Time) error {
+	query := `
+		UPDATE mfa_enrollments
+		SET confirmed_at = ?, recovery_codes = ?
+		WHERE user_id = ? AND confirmed_at IS NULL
+	`
+
+	codesJSON, err := marshalRecoveryCodes(recoveryCodes)
+	if err != nil {
+		return domain.NewInvalidAuthInputError("failed to marshal recovery codes", err)
+	}
+
+	affected, err := r.exec(ctx, "confirm_mfa_enrollment", query, confirmedAt, codesJSON, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewMFANotEnrolledError(userID)
+	}
+
+	return nil
+}
+
+func (r *MFARepository) ClaimMFAStep(ctx context.Context, userID string, step int64) (bool, error) {
+	// The comparison makes concurrent uses of one code race for a single row update
+	query := `UPDATE mfa_enrollments SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+
+	affected, err := r.exec(ctx, "claim_mfa_step", query, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *MFARepository) ReplaceMFARecoveryCodes(ctx context.Context, userID string, old, new []string) (bool, error) {
+	query := `
+		UPDATE mfa_enrollments
+		SET recovery_codes = ?
+		WHERE user_id = ? AND recovery_codes = CAST(? AS JSON)
+	`
+
+	oldJSON, err := marshalRecoveryCodes(old)
+	if err != nil {
+		return false, domain.NewInvalidAuthInputError("failed to marshal recovery codes", err)
+	}
+	newJSON, err := marshalRecoveryCodes(new)
+	if err != nil {
+		return false, domain.NewInvalidAuthInputError("failed to marshal recovery codes", err)
+	}
+
+	affected, err := r.exec(ctx, "replace_mfa_recovery_codes", query, newJSON, userID, oldJSON)
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *MFARepository) DeleteMFAEnrollment(ctx context.Context, userID string) error {
+	query := `DELETE FROM mfa_enrollments WHERE user_id = ?`
+
+	affected, err := r.exec(ctx, "delete_mfa_enrollment", query, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewMFANotEnrolledError(userID)
+	}
+
+	return nil
+}
+
+// exec runs a statement and returns the number of rows it affected
+func (r *MFARepository) exec(ctx context.Context, operation, query string, args ...any) (int64, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, domain.WithOperation(
+			domain.Wrap(err, "failed to update MFA enrollment"),
+			operation,
+		)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			operation,
+		)
+	}
+
+	return affected, nil
+}
+
+// marshalRecoveryCodes stores a missing list as an empty JSON array
+func marshalRecoveryCodes(codes []string) ([]byte, error) {
+	if codes == nil {
+		codes = []string{}
+	}
+	return json.Marshal(codes)
+}
@@ -0,0 +1,499 @@
+// services/auth-service/internal/repository/mysql/oauth_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
+)
+
+const oauthClientColumns = `id, name, type, secret_hash, redirect_uris, scopes,
+		       created_at, updated_at, revoked_at`
+
+const authorizationCodeColumns = `code_hash, client_id, user_id, redirect_uri, scopes,
+		       code_challenge, code_challenge_method, nonce, session_id,
+		       expires_at, used_at, created_at`
+
+type OAuthRepository struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func NewOAuthRepository(db *sql.DB, logger log.Logger) repository.OAuthRepository {
+	return &OAuthRepository{
+		db:     db,
+		logger: logger.WithLayer("mysql-oauth-repository"),
+	}
+}
+
+func (r *OAuthRepository) CreateOAuthClient(ctx context.Context, client *models.OAuthClient) error {
+	query := `
+		INSERT INTO oauth_clients (
+			id, name, type, secret_hash, redirect_uris, scopes, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	redirectURIsJSON, err := marshalStrings(client.RedirectURIs)
+	if err != nil {
+		return domain.NewInvalidAuthInputError("failed to marshal redirect URIs", err)
+	}
+	scopesJSON, err := marshalStrings(client.Scopes)
+	if err != nil {
+		return domain.NewInvalidAuthInputError("failed to marshal scopes", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		client.ID,
+		client.Name,
+		client.Type,
+		nullString(client.SecretHash),
+		redirectURIsJSON,
+		scopesJSON,
+		client.CreatedAt,
+		client.UpdatedAt,
+	)
+
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to create OAuth client in database"),
+			"create_oauth_client",
+		)
+	}
+
+	return nil
+}
+
+func (r *OAuthRepository) GetOAuthClient(ctx context.Context, id string) (*models.OAuthClient, error) {
+	query := `
+		SELECT ` + oauthClientColumns + `
+		FROM oauth_clients
+		WHERE id = ?
+	`
+
+	client, err := scanOAuthClient(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewOAuthClientNotFoundError(id)
+		}
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get OAuth client from database"),
+			"get_oauth_client",
+		)
+	}
+
+	return client, nil
+}
+
+func (r *OAuthRepository) ListOAuthClients(ctx context.Context) ([]*models.OAuthClient, error) {
+	query := `
+		SELECT ` + oauthClientColumns + `
+		FROM oauth_clients
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to list OAuth clients"),
+			"list_oauth_clients",
+		)
+	}
+	defer rows.Close()
+
+	var clients []*models.OAuthClient
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan OAuth client"),
+				"list_oauth_clients",
+			)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating OAuth clients"),
+			"list_oauth_clients",
+		)
+	}
+
+	return clients, nil
+}
+
+func (r *OAuthRepository) RevokeOAuthClient(ctx context.Context, id string) error {
+	query := `UPDATE oauth_clients SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	affected, err := r.exec(ctx, "revoke_oauth_client", query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewOAuthClientNotFoundError(id)
+	}
+
+	return nil
+}
+
+func (r *OAuthRepository) SaveOAuthConsent(ctx context.Context, consent *models.OAuthConsent) error {
+	query := `
+		INSERT INTO oauth_consents (
+			user_id, client_id, scopes, granted_at, updated_at
+		) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			scopes = VALUES(scopes),
+			updated_at = VALUES(updated_at)
+	`
+
+	scopesJSON, err := marshalStrings(consent.Scopes)
+	if err != nil {
+		return domain.NewInvalidAuthInputError("failed to marshal scopes", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		consent.UserID,
+		consent.ClientID,
+		scopesJSON,
+		consent.GrantedAt,
+		consent.UpdatedAt,
+	)
+
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to save OAuth consent in database"),
+			"save_oauth_consent",
+		)
+	}
+
+	return nil
+}
+
+func (r *OAuthRepository) GetOAuthConsent(ctx context.Context, userID, clientID string) (*models.OAuthConsent, error) {
+	query := `
+		SELECT user_id, client_id, scopes, granted_at, updated_at
+		FROM oauth_consents
+		WHERE user_id = ? AND client_id = ?
+	`
+
+	consent, err := scanOAuthConsent(r.db.QueryRowContext(ctx, query, userID, clientID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewOAuthConsentNotFoundError(userID, clientID)
+		}
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get OAuth consent from database"),
+			"get_oauth_consent",
+		)
+	}
+
+	return consent, nil
+}
+
+func (r *OAuthRepository) ListOAuthConsents(ctx context.Context, userID string) ([]*models.OAuthConsent, error) {
+	query := `
+		SELECT user_id, client_id, scopes, granted_at, updated_at
+		FROM oauth_consents
+		WHERE user_id = ?
+		ORDER BY granted_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to list OAuth consents"),
+			"list_oauth_consents",
+		)
+	}
+	defer rows.Close()
+
+	var consents []*models.OAuthConsent
+	for rows.Next() {
+		consent, err := scanOAuthConsent(rows)
+		if err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan OAuth consent"),
+				"list_oauth_consents",
+			)
+		}
+		consents = append(consents, consent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating OAuth consents"),
+			"list_oauth_consents",
+		)
+	}
+
+	return consents, nil
+}
+
+func (r *OAuthRepository) DeleteOAuthConsent(ctx context.Context, userID, clientID string) error {
+	query := `DELETE FROM oauth_consents WHERE user_id = ? AND client_id = ?`
+
+	affected, err := r.exec(ctx, "delete_oauth_consent", query, userID, clientID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewOAuthConsentNotFoundError(userID, clientID)
+	}
+
+	return nil
+}
+
+func (r *OAuthRepository) CreateAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	query := `
+		INSERT INTO oauth_authorization_codes (
+			code_hash, client_id, user_id, redirect_uri, scopes,
+			code_challenge, code_challenge_method, nonce, expires_at, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	scopesJSON, err := marshalStrings(code.Scopes)
+	if err != nil {
+		return domain.NewInvalidAuthInputError("failed to marshal scopes", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		scopesJSON,
+		code.CodeChallenge,
+		code.CodeChallengeMethod,
+		nullString(code.Nonce),
+		code.ExpiresAt,
+		code.CreatedAt,
+	)
+
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to create authorization code in database"),
+			"create_authorization_code",
+		)
+	}
+
+	return nil
+}
+
+// ConsumeAuthorizationCode locks the code's row while marking it used, so of
+// two exchanges racing with one code only the first sees it unused
+func (r *OAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string, usedAt time.Time) (*models.AuthorizationCode, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to begin transaction"),
+			"consume_authorization_code",
+		)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + authorizationCodeColumns + `
+		FROM oauth_authorization_codes
+		WHERE code_hash = ?
+		FOR UPDATE
+	`
+
+	code, err := scanAuthorizationCode(tx.QueryRowContext(ctx, query, codeHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewAuthorizationCodeNotFoundError()
+		}
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get authorization code from database"),
+			"consume_authorization_code",
+		)
+	}
+
+	if !code.IsUsed() {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE oauth_authorization_codes SET used_at = ? WHERE code_hash = ?`,
+			usedAt, codeHash,
+		)
+		if err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to mark authorization code used"),
+				"consume_authorization_code",
+			)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to commit authorization code use"),
+			"consume_authorization_code",
+		)
+	}
+
+	return code, nil
+}
+
+func (r *OAuthRepository) SetAuthorizationCodeSession(ctx context.Context, codeHash, sessionID string) error {
+	query := `UPDATE oauth_authorization_codes SET session_id = ? WHERE code_hash = ?`
+
+	affected, err := r.exec(ctx, "set_authorization_code_session", query, sessionID, codeHash)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewAuthorizationCodeNotFoundError()
+	}
+
+	return nil
+}
+
+func (r *OAuthRepository) DeleteExpiredAuthorizationCodes(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM oauth_authorization_codes WHERE expires_at < ?`
+
+	affected, err := r.exec(ctx, "delete_expired_authorization_codes", query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
+
+// exec runs a statement and returns the number of rows it affected
+func (r *OAuthRepository) exec(ctx context.Context, operation, query string, args ...any) (int64, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, domain.WithOperation(
+			domain.Wrap(err, "failed to execute OAuth statement"),
+			operation,
+		)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			operation,
+		)
+	}
+
+	return affected, nil
+}
+
+func scanOAuthClient(row rowScanner) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{}
+	var secretHash sql.NullString
+	var redirectURIsJSON, scopesJSON []byte
+	var revokedAt sql.NullTime
+
+	err := row.Scan(
+		&client.ID,
+		&client.Name,
+		&client.Type,
+		&secretHash,
+		&redirectURIsJSON,
+		&scopesJSON,
+		&client.CreatedAt,
+		&client.UpdatedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalStrings(redirectURIsJSON, &client.RedirectURIs); err != nil {
+		return nil, err
+	}
+	if err := unmarshalStrings(scopesJSON, &client.Scopes); err != nil {
+		return nil, err
+	}
+	client.SecretHash = secretHash.String
+	if revokedAt.Valid {
+		client.RevokedAt = &revokedAt.Time
+	}
+
+	return client, nil
+}
+
+func scanOAuthConsent(row rowScanner) (*models.OAuthConsent, error) {
+	consent := &models.OAuthConsent{}
+	var scopesJSON []byte
+
+	err := row.Scan(
+		&consent.UserID,
+		&consent.ClientID,
+		&scopesJSON,
+		&consent.GrantedAt,
+		&consent.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalStrings(scopesJSON, &consent.Scopes); err != nil {
+		return nil, err
+	}
+
+	return consent, nil
+}
+
+func scanAuthorizationCode(row rowScanner) (*models.AuthorizationCode, error) {
+	code := &models.AuthorizationCode{}
+	var scopesJSON []byte
+	var nonce, sessionID sql.NullString
+	var usedAt sql.NullTime
+
+	err := row.Scan(
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&scopesJSON,
+		&code.CodeChallenge,
+		&code.CodeChallengeMethod,
+		&nonce,
+		&sessionID,
+		&code.ExpiresAt,
+		&usedAt,
+		&code.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalStrings(scopesJSON, &code.Scopes); err != nil {
+		return nil, err
+	}
+	code.Nonce = nonce.String
+	code.SessionID = sessionID.String
+	if usedAt.Valid {
+		code.UsedAt = &usedAt.Time
+	}
+
+	return code, nil
+}
+
+// marshalStrings stores a missing list as an empty JSON array
+func marshalStrings(values []string) ([]byte, error) {
+	if values == nil {
+		values = []string{}
+	}
+	return json.Marshal(values)
+}
+
+func unmarshalStrings(data []byte, values *[]string) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, values)
+}
@@ -0,0 +1,102 @@
+// services/auth-service/internal/repository/mysql/password_history_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
+)
+
+type PasswordHistoryRepository struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func NewPasswordHistoryRepository(db *sql.DB, logger log.Logger) repository.PasswordHistoryRepository {
+	return &PasswordHistoryRepository{
+		db:     db,
+		logger: logger.WithLayer("mysql-password-history-repository"),
+	}
+}
+
+func (r *PasswordHistoryRepository) AddPasswordHistory(ctx context.Context, entry *models.PasswordHistoryEntry, keep int) error {
+	query := `
+		INSERT INTO password_history (id, user_id, password_hash, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, entry.ID, entry.UserID, entry.PasswordHash, entry.CreatedAt)
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to add password history in database"),
+			"add_password_history",
+		)
+	}
+
+	// Drop entries beyond the newest keep. MySQL can't LIMIT a subquery on
+	// the table being deleted from, hence the derived table.
+	prune := `
+		DELETE FROM password_history
+		WHERE user_id = ? AND id NOT IN (
+			SELECT id FROM (
+				SELECT id FROM password_history
+				WHERE user_id = ?
+				ORDER BY created_at DESC
+				LIMIT ?
+			) AS newest
+		)
+	`
+
+	if _, err := r.db.ExecContext(ctx, prune, entry.UserID, entry.UserID, max(keep, 1)); err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to prune password history"),
+			"add_password_history",
+		)
+	}
+
+	return nil
+}
+
+func (r *PasswordHistoryRepository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]*models.PasswordHistoryEntry, error) {
+	query := `
+		SELECT id, user_id, password_hash, created_at
+		FROM password_history
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to list password history"),
+			"list_password_history",
+		)
+	}
+	defer rows.Close()
+
+	var entries []*models.PasswordHistoryEntry
+	for rows.Next() {
+		entry := &models.PasswordHistoryEntry{}
+		if err := rows.Scan(&entry.ID, &entry.UserID, &entry.PasswordHash, &entry.CreatedAt); err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan password history"),
+				"list_password_history",
+			)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating password history"),
+			"list_password_history",
+		)
+	}
+
+	return entries, nil
+}
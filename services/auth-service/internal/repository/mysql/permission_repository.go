@@ -231,11 +231,13 @@ func (r *PermissionRepository) GetPermissionsByRole(ctx context.Context, roleID
 		SELECT p.id, p.name, p.description, p.resource, p.action, p.created_at, p.updated_at
 		FROM permissions p
 		INNER JOIN role_permissions rp ON p.id = rp.permission_id
-		WHERE rp.role_id = ?
+		INNER JOIN roles r ON r.id = rp.role_id
+		WHERE rp.role_id = ? OR r.name = ?
 		ORDER BY p.name
 	`
 	
-	rows, err := r.db.QueryContext(ctx, query, roleID)
+	// Users carry their role name, so accept either the role ID or its name
+	rows, err := r.db.QueryContext(ctx, query, roleID, roleID)
 	if err != nil {
 		return nil, domain.WithOperation(
 			domain.Wrap(err, "failed to get permissions by role from database"),
@@ -4,8 +4,11 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"strings"
 
 	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
 	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
 	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
 )
@@ -22,14 +25,25 @@ func NewRolePermissionRepository(db *sql.DB, logger log.Logger) repository.RoleP
 	}
 }
 
-func (r *RolePermissionRepository) AssignPermissionToRole(ctx context.Context, roleID string, permissionID string) error {
+func (r *RolePermissionRepository) AssignPermissionToRole(ctx context.Context, roleID string, permissionID string, conditions []models.PolicyCondition) error {
 	query := `
-		INSERT INTO role_permissions (role_id, permission_id, created_at)
-		VALUES (?, ?, NOW())
-		ON DUPLICATE KEY UPDATE created_at = created_at
+		INSERT INTO role_permissions (role_id, permission_id, conditions, created_at)
+		VALUES (?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE conditions = VALUES(conditions)
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, roleID, permissionID)
+	var conditionsJSON []byte
+	if len(conditions) > 0 {
+		var err error
+		if conditionsJSON, err = json.Marshal(conditions); err != nil {
+			return domain.WithOperation(
+				domain.Wrap(err, "failed to marshal grant conditions"),
+				"assign_permission_to_role",
+			)
+		}
+	}
+	
+	_, err := r.db.ExecContext(ctx, query, roleID, permissionID, conditionsJSON)
 	if err != nil {
 		return domain.WithOperation(
 			domain.Wrap(err, "failed to assign permission to role"),
@@ -98,4 +112,76 @@ func (r *RolePermissionRepository) GetRolesByPermission(ctx context.Context, per
 	}
 	
 	return roleIDs, nil
-}
\ No newline at end of file
+}
+
+func (r *RolePermissionRepository) GetPermissionGrants(ctx context.Context, roleIDs []string) ([]*models.PermissionGrant, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+	
+	query := `
+		SELECT p.id, p.name, p.description, p.resource, p.action, p.created_at, p.updated_at,
+			ro.id, ro.name, rp.conditions
+		FROM role_permissions rp
+		INNER JOIN permissions p ON p.id = rp.permission_id
+		INNER JOIN roles ro ON ro.id = rp.role_id
+		WHERE rp.role_id IN (?` + strings.Repeat(", ?", len(roleIDs)-1) + `)
+		ORDER BY p.resource, p.action, ro.name
+	`
+	
+	args := make([]any, len(roleIDs))
+	for i, roleID := range roleIDs {
+		args[i] = roleID
+	}
+	
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get permission grants"),
+			"get_permission_grants",
+		)
+	}
+	defer rows.Close()
+	
+	var grants []*models.PermissionGrant
+	for rows.Next() {
+		grant := &models.PermissionGrant{}
+		var conditionsJSON []byte
+		err := rows.Scan(
+			&grant.ID,
+			&grant.Name,
+			&grant.Description,
+			&grant.Resource,
+			&grant.Action,
+			&grant.CreatedAt,
+			&grant.UpdatedAt,
+			&grant.RoleID,
+			&grant.RoleName,
+			&conditionsJSON,
+		)
+		if err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan permission grant row"),
+				"get_permission_grants",
+			)
+		}
+		if len(conditionsJSON) > 0 {
+			if err := json.Unmarshal(conditionsJSON, &grant.Conditions); err != nil {
+				return nil, domain.WithOperation(
+					domain.Wrap(err, "failed to decode grant conditions"),
+					"get_permission_grants",
+				)
+			}
+		}
+		grants = append(grants, grant)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating permission grant rows"),
+			"get_permission_grants",
+		)
+	}
+	
+	return grants, nil
+}
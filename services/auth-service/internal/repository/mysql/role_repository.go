@@ -0,0 +1,281 @@
+// services/auth-service/internal/repository/mysql/role_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
+)
+
+type RoleRepository struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func NewRoleRepository(db *sql.DB, logger log.Logger) repository.RoleRepository {
+	return &RoleRepository{
+		db:     db,
+		logger: logger.WithLayer("mysql-role-repository"),
+	}
+}
+
+const roleColumns = `id, name, description, parent_id, created_at, updated_at`
+
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]*models.RoleDefinition, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to list roles"),
+			"list_roles",
+		)
+	}
+	defer rows.Close()
+
+	var roles []*models.RoleDefinition
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan role row"),
+				"list_roles",
+			)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating role rows"),
+			"list_roles",
+		)
+	}
+
+	return roles, nil
+}
+
+func (r *RoleRepository) GetRole(ctx context.Context, idOrName string) (*models.RoleDefinition, error) {
+	// Users carry their role name, so accept either the role ID or its name
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE id = ? OR name = ? LIMIT 1`
+
+	role, err := scanRole(r.db.QueryRowContext(ctx, query, idOrName, idOrName))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewRoleNotFoundError(idOrName)
+		}
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get role"),
+			"get_role",
+		)
+	}
+
+	return role, nil
+}
+
+func (r *RoleRepository) SetRoleParent(ctx context.Context, roleID string, parentID string) error {
+	query := `UPDATE roles SET parent_id = ?, updated_at = NOW() WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, nullString(parentID), roleID)
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to set role parent"),
+			"set_role_parent",
+		)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			"set_role_parent",
+		)
+	}
+
+	if affected == 0 {
+		return domain.NewRoleNotFoundError(roleID)
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) ListUserRoles(ctx context.Context, userID string) ([]*models.UserRole, error) {
+	query := `
+		SELECT user_id, role_id, assigned_at, assigned_by
+		FROM user_roles
+		WHERE user_id = ?
+		ORDER BY assigned_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to list user roles"),
+			"list_user_roles",
+		)
+	}
+	defer rows.Close()
+
+	var userRoles []*models.UserRole
+	for rows.Next() {
+		userRole := &models.UserRole{}
+		var assignedBy sql.NullString
+		if err := rows.Scan(&userRole.UserID, &userRole.RoleID, &userRole.AssignedAt, &assignedBy); err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan user role row"),
+				"list_user_roles",
+			)
+		}
+		userRole.AssignedBy = assignedBy.String
+		userRoles = append(userRoles, userRole)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating user role rows"),
+			"list_user_roles",
+		)
+	}
+
+	return userRoles, nil
+}
+
+func (r *RoleRepository) AssignUserRole(ctx context.Context, userRole *models.UserRole) error {
+	query := `
+		INSERT INTO user_roles (user_id, role_id, assigned_at, assigned_by)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE assigned_at = assigned_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userRole.UserID, userRole.RoleID, userRole.AssignedAt, nullString(userRole.AssignedBy))
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to assign role to user"),
+			"assign_user_role",
+		)
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) RevokeUserRole(ctx context.Context, userID string, roleID string) error {
+	query := `DELETE FROM user_roles WHERE user_id = ? AND role_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, userID, roleID)
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to revoke role from user"),
+			"revoke_user_role",
+		)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			"revoke_user_role",
+		)
+	}
+
+	if affected == 0 {
+		return domain.NewUserRoleNotFoundError(userID, roleID)
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) GetUserAttributes(ctx context.Context, userID string) (map[string]string, error) {
+	query := `SELECT name, value FROM user_attributes WHERE user_id = ?`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get user attributes"),
+			"get_user_attributes",
+		)
+	}
+	defer rows.Close()
+
+	attributes := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan user attribute row"),
+				"get_user_attributes",
+			)
+		}
+		attributes[name] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating user attribute rows"),
+			"get_user_attributes",
+		)
+	}
+
+	return attributes, nil
+}
+
+func (r *RoleRepository) SetUserAttributes(ctx context.Context, userID string, attributes map[string]string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to begin transaction"),
+			"set_user_attributes",
+		)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_attributes WHERE user_id = ?`, userID); err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to clear user attributes"),
+			"set_user_attributes",
+		)
+	}
+
+	for name, value := range attributes {
+		query := `INSERT INTO user_attributes (user_id, name, value) VALUES (?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, query, userID, name, value); err != nil {
+			return domain.WithOperation(
+				domain.Wrap(err, "failed to store user attribute"),
+				"set_user_attributes",
+			)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to commit user attributes"),
+			"set_user_attributes",
+		)
+	}
+
+	return nil
+}
+
+func scanRole(row rowScanner) (*models.RoleDefinition, error) {
+	role := &models.RoleDefinition{}
+	var description, parentID sql.NullString
+
+	err := row.Scan(
+		&role.ID,
+		&role.Name,
+		&description,
+		&parentID,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = description.String
+	role.ParentID = parentID.String
+	return role, nil
+}
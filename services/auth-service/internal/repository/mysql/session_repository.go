@@ -27,9 +27,9 @@ func NewSessionRepository(db *sql.DB, logger log.Logger) repository.SessionRepos
 func (r *SessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
 	query := `
 		INSERT INTO sessions (
-			id, user_id, refresh_token, user_agent, ip_address, 
+			id, user_id, refresh_token, generation, user_agent, ip_address, 
 			last_active, expires_at, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	
 	_, err := r.db.ExecContext(
@@ -38,6 +38,7 @@ func (r *SessionRepository) CreateSession(ctx context.Context, session *models.S
 		session.ID,
 		session.UserID,
 		session.RefreshToken,
+		session.Generation,
 		session.UserAgent,
 		session.IPAddress,
 		session.LastActive,
@@ -57,7 +58,7 @@ func (r *SessionRepository) CreateSession(ctx context.Context, session *models.S
 
 func (r *SessionRepository) GetSessionByID(ctx context.Context, id string) (*models.Session, error) {
 	query := `
-		SELECT id, user_id, refresh_token, user_agent, ip_address,
+		SELECT id, user_id, refresh_token, generation, user_agent, ip_address,
 		       last_active, expires_at, created_at
 		FROM sessions
 		WHERE id = ? AND expires_at > NOW()
@@ -68,6 +69,7 @@ func (r *SessionRepository) GetSessionByID(ctx context.Context, id string) (*mod
 		&session.ID,
 		&session.UserID,
 		&session.RefreshToken,
+		&session.Generation,
 		&session.UserAgent,
 		&session.IPAddress,
 		&session.LastActive,
@@ -90,7 +92,7 @@ func (r *SessionRepository) GetSessionByID(ctx context.Context, id string) (*mod
 
 func (r *SessionRepository) GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*models.Session, error) {
 	query := `
-		SELECT id, user_id, refresh_token, user_agent, ip_address,
+		SELECT id, user_id, refresh_token, generation, user_agent, ip_address,
 		       last_active, expires_at, created_at
 		FROM sessions
 		WHERE refresh_token = ? AND expires_at > NOW()
@@ -101,6 +103,7 @@ func (r *SessionRepository) GetSessionByRefreshToken(ctx context.Context, refres
 		&session.ID,
 		&session.UserID,
 		&session.RefreshToken,
+		&session.Generation,
 		&session.UserAgent,
 		&session.IPAddress,
 		&session.LastActive,
@@ -123,7 +126,7 @@ func (r *SessionRepository) GetSessionByRefreshToken(ctx context.Context, refres
 
 func (r *SessionRepository) GetSessionsByUserID(ctx context.Context, userID string) ([]*models.Session, error) {
 	query := `
-		SELECT id, user_id, refresh_token, user_agent, ip_address,
+		SELECT id, user_id, refresh_token, generation, user_agent, ip_address,
 		       last_active, expires_at, created_at
 		FROM sessions
 		WHERE user_id = ? AND expires_at > NOW()
@@ -146,6 +149,7 @@ func (r *SessionRepository) GetSessionsByUserID(ctx context.Context, userID stri
 			&session.ID,
 			&session.UserID,
 			&session.RefreshToken,
+			&session.Generation,
 			&session.UserAgent,
 			&session.IPAddress,
 			&session.LastActive,
@@ -174,13 +178,15 @@ func (r *SessionRepository) GetSessionsByUserID(ctx context.Context, userID stri
 func (r *SessionRepository) UpdateSession(ctx context.Context, session *models.Session) error {
 	query := `
 		UPDATE sessions 
-		SET user_agent = ?, ip_address = ?, last_active = ?, expires_at = ?
+		SET refresh_token = ?, generation = ?, user_agent = ?, ip_address = ?, last_active = ?, expires_at = ?
 		WHERE id = ?
 	`
 	
 	result, err := r.db.ExecContext(
 		ctx,
 		query,
+		session.RefreshToken,
+		session.Generation,
 		session.UserAgent,
 		session.IPAddress,
 		session.LastActive,
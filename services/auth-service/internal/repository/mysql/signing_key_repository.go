@@ -0,0 +1,145 @@
+// services/auth-service/internal/repository/mysql/signing_key_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
+)
+
+type SigningKeyRepository struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func NewSigningKeyRepository(db *sql.DB, logger log.Logger) repository.SigningKeyRepository {
+	return &SigningKeyRepository{
+		db:     db,
+		logger: logger.WithLayer("mysql-signing-key-repository"),
+	}
+}
+
+func (r *SigningKeyRepository) CreateSigningKey(ctx context.Context, key *models.SigningKey) error {
+	query := `
+		INSERT INTO signing_keys (
+			id, algorithm, private_key_encrypted, public_key,
+			activates_at, expires_at, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		key.ID,
+		key.Algorithm,
+		key.PrivateKeyEncrypted,
+		key.PublicKey,
+		key.ActivatesAt,
+		key.ExpiresAt,
+		key.CreatedAt,
+	)
+
+	if err != nil {
+		return domain.WithOperation(
+			domain.Wrap(err, "failed to create signing key in database"),
+			"create_signing_key",
+		)
+	}
+
+	return nil
+}
+
+func (r *SigningKeyRepository) ListSigningKeys(ctx context.Context, now time.Time) ([]*models.SigningKey, error) {
+	query := `
+		SELECT id, algorithm, private_key_encrypted, public_key,
+		       activates_at, expires_at, created_at
+		FROM signing_keys
+		WHERE expires_at IS NULL OR expires_at > ?
+		ORDER BY activates_at ASC, created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to list signing keys"),
+			"list_signing_keys",
+		)
+	}
+	defer rows.Close()
+
+	var keys []*models.SigningKey
+	for rows.Next() {
+		key := &models.SigningKey{}
+		var expiresAt sql.NullTime
+
+		err := rows.Scan(
+			&key.ID,
+			&key.Algorithm,
+			&key.PrivateKeyEncrypted,
+			&key.PublicKey,
+			&key.ActivatesAt,
+			&expiresAt,
+			&key.CreatedAt,
+		)
+		if err != nil {
+			return nil, domain.WithOperation(
+				domain.Wrap(err, "failed to scan signing key"),
+				"list_signing_keys",
+			)
+		}
+
+		if expiresAt.Valid {
+			key.ExpiresAt = &expiresAt.Time
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "error iterating signing keys"),
+			"list_signing_keys",
+		)
+	}
+
+	return keys, nil
+}
+
+func (r *SigningKeyRepository) RetireSigningKeys(ctx context.Context, activatedBefore, expiresAt time.Time) (int, error) {
+	query := `
+		UPDATE signing_keys
+		SET expires_at = ?
+		WHERE activates_at < ? AND (expires_at IS NULL OR expires_at > ?)
+	`
+	return r.exec(ctx, "retire_signing_keys", query, expiresAt, activatedBefore, expiresAt)
+}
+
+func (r *SigningKeyRepository) DeleteExpiredSigningKeys(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM signing_keys WHERE expires_at < ?`
+	return r.exec(ctx, "delete_expired_signing_keys", query, before)
+}
+
+// exec runs a statement and returns the number of rows it affected
+func (r *SigningKeyRepository) exec(ctx context.Context, operation, query string, args ...any) (int, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, domain.WithOperation(
+			domain.Wrap(err, "failed to update signing keys"),
+			operation,
+		)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			operation,
+		)
+	}
+
+	return int(affected), nil
+}
@@ -28,8 +28,8 @@ func NewTokenRepository(db *sql.DB, logger log.Logger) repository.TokenRepositor
 func (r *TokenRepository) CreateToken(ctx context.Context, token *models.Token) error {
 	query := `
 		INSERT INTO tokens (
-			id, user_id, type, value, expires_at, created_at, metadata
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
+			id, user_id, family_id, generation, type, value, expires_at, created_at, metadata
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	
 	var metadataJSON []byte
@@ -46,6 +46,8 @@ func (r *TokenRepository) CreateToken(ctx context.Context, token *models.Token)
 		query,
 		token.ID,
 		token.UserID,
+		nullString(token.FamilyID),
+		token.Generation,
 		token.Type,
 		token.Value,
 		token.ExpiresAt,
@@ -109,6 +111,159 @@ func (r *TokenRepository) GetTokenByValue(ctx context.Context, value string) (*m
 	return token, nil
 }
 
+// GetRefreshToken retrieves a refresh token by value, including revoked and
+// rotated tokens, so a replayed token can be traced to its family
+func (r *TokenRepository) GetRefreshToken(ctx context.Context, value string) (*models.Token, error) {
+	query := `
+		SELECT id, user_id, family_id, generation, type, value, expires_at,
+		       revoked_at, replaced_by, created_at, metadata
+		FROM tokens
+		WHERE value = ? AND type = ?
+	`
+	
+	token := &models.Token{}
+	var familyID, replacedBy sql.NullString
+	var metadataJSON []byte
+	var revokedAt sql.NullTime
+	
+	err := r.db.QueryRowContext(ctx, query, value, models.TokenTypeRefresh).Scan(
+		&token.ID,
+		&token.UserID,
+		&familyID,
+		&token.Generation,
+		&token.Type,
+		&token.Value,
+		&token.ExpiresAt,
+		&revokedAt,
+		&replacedBy,
+		&token.CreatedAt,
+		&metadataJSON,
+	)
+	
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewTokenNotFoundError("token_value")
+		}
+		return nil, domain.WithOperation(
+			domain.Wrap(err, "failed to get refresh token from database"),
+			"get_refresh_token",
+		)
+	}
+	
+	token.FamilyID = familyID.String
+	token.ReplacedBy = replacedBy.String
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &token.Metadata); err != nil {
+			return nil, domain.NewInvalidAuthInputError("failed to unmarshal metadata", err)
+		}
+	}
+	
+	return token, nil
+}
+
+// RotateRefreshToken replaces a refresh token with the next token of its
+// family. The old token is claimed with a compare-and-set, so of two
+// refreshes racing with the same token only one succeeds; the other gets
+// false and is treated as reuse.
+func (r *TokenRepository) RotateRefreshToken(ctx context.Context, tokenID string, next *models.Token) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, domain.WithOperation(
+			domain.Wrap(err, "failed to begin transaction"),
+			"rotate_refresh_token",
+		)
+	}
+	defer tx.Rollback()
+	
+	result, err := tx.ExecContext(ctx,
+		`UPDATE tokens SET revoked_at = ?, replaced_by = ? WHERE id = ? AND revoked_at IS NULL`,
+		time.Now(), next.ID, tokenID,
+	)
+	if err != nil {
+		return false, domain.WithOperation(
+			domain.Wrap(err, "failed to revoke rotated refresh token"),
+			"rotate_refresh_token",
+		)
+	}
+	
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			"rotate_refresh_token",
+		)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+	
+	var metadataJSON []byte
+	if next.Metadata != nil {
+		metadataJSON, err = json.Marshal(next.Metadata)
+		if err != nil {
+			return false, domain.NewInvalidAuthInputError("failed to marshal metadata", err)
+		}
+	}
+	
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tokens (
+			id, user_id, family_id, generation, type, value, expires_at, created_at, metadata
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		next.ID,
+		next.UserID,
+		nullString(next.FamilyID),
+		next.Generation,
+		next.Type,
+		next.Value,
+		next.ExpiresAt,
+		next.CreatedAt,
+		metadataJSON,
+	)
+	if err != nil {
+		return false, domain.WithOperation(
+			domain.Wrap(err, "failed to store rotated refresh token"),
+			"rotate_refresh_token",
+		)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return false, domain.WithOperation(
+			domain.Wrap(err, "failed to commit refresh token rotation"),
+			"rotate_refresh_token",
+		)
+	}
+	
+	return true, nil
+}
+
+// RevokeTokenFamily revokes every live token of a refresh token family
+func (r *TokenRepository) RevokeTokenFamily(ctx context.Context, familyID string) (int, error) {
+	query := `UPDATE tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`
+	
+	result, err := r.db.ExecContext(ctx, query, time.Now(), familyID)
+	if err != nil {
+		return 0, domain.WithOperation(
+			domain.Wrap(err, "failed to revoke token family"),
+			"revoke_token_family",
+		)
+	}
+	
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			"revoke_token_family",
+		)
+	}
+	
+	return int(affected), nil
+}
+
 func (r *TokenRepository) GetTokenByID(ctx context.Context, id string) (*models.Token, error) {
 	query := `
 		SELECT id, user_id, type, value, expires_at, revoked_at, created_at, metadata
@@ -260,6 +415,28 @@ func (r *TokenRepository) RevokeToken(ctx context.Context, tokenID string) error
 	return nil
 }
 
+func (r *TokenRepository) ConsumeToken(ctx context.Context, tokenID string) (bool, error) {
+	query := `UPDATE tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	
+	result, err := r.db.ExecContext(ctx, query, time.Now(), tokenID)
+	if err != nil {
+		return false, domain.WithOperation(
+			domain.Wrap(err, "failed to consume token in database"),
+			"consume_token",
+		)
+	}
+	
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, domain.WithOperation(
+			domain.Wrap(err, "failed to get affected rows"),
+			"consume_token",
+		)
+	}
+	
+	return affected == 1, nil
+}
+
 func (r *TokenRepository) RevokeAllTokensForUser(ctx context.Context, userID string, tokenType string) error {
 	var query string
 	var args []interface{}
@@ -372,4 +549,9 @@ func (r *TokenRepository) DeleteExpiredTokens(ctx context.Context) (int, error)
 	}
 	
 	return int(affected), nil
-}
\ No newline at end of file
+}
+
+// nullString stores an empty string as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,275 @@
+// services/auth-service/internal/service/api_key_service.go
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+	"github.com/google/uuid"
+)
+
+// API keys look like "fnk_<prefix>_<secret>". The prefix is stored in clear to
+// find the key; only a SHA-256 hash of the whole key is kept. Keys carry 256
+// bits of randomness, so a fast hash is enough, unlike passwords.
+const (
+	apiKeyScheme       = "fnk"
+	apiKeyPrefixBytes  = 6
+	apiKeySecretBytes  = 32
+	apiKeyLastUsedSkew = time.Minute
+)
+
+// CreateAPIKey issues a new API key for a user
+func (s *AuthServiceImpl) CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.APIKeyCreatedResponse, error) {
+	logCtx := s.logger.With("owner_id", req.OwnerID).With("operation", "create_api_key")
+	logCtx.Info("Processing create API key request")
+
+	if err := validateScopes(req.Scopes); err != nil {
+		logCtx.With("error", err.Error()).Warn("Invalid API key scopes")
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, domain.NewInvalidAuthInputError("Expiry must be in the future", nil)
+	}
+
+	owner, err := s.userClient.GetUser(ctx, req.OwnerID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("API key owner not found")
+		return nil, domain.NewUserNotFoundError(req.OwnerID)
+	}
+	if !owner.IsActive() {
+		logCtx.Warn("API key requested for inactive account")
+		return nil, domain.NewAccountInactiveError(owner.ID)
+	}
+
+	key, plaintext, err := s.issueAPIKey(ctx, req.OwnerID, req.Name, req.Scopes, req.ExpiresAt, "")
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to issue API key")
+		return nil, err
+	}
+
+	logCtx.With("key_id", key.ID).With("prefix", key.Prefix).Info("API key created")
+	return &dto.APIKeyCreatedResponse{APIKeyInfo: dto.FromAPIKey(key), Key: plaintext}, nil
+}
+
+// ListAPIKeys lists the API keys owned by a user
+func (s *AuthServiceImpl) ListAPIKeys(ctx context.Context, ownerID string) ([]dto.APIKeyInfo, error) {
+	logCtx := s.logger.With("owner_id", ownerID).With("operation", "list_api_keys")
+	logCtx.Debug("Listing API keys")
+
+	keys, err := s.authRepo.ListAPIKeysByOwner(ctx, ownerID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to list API keys")
+		return nil, domain.WithOperation(err, "list_api_keys_by_owner")
+	}
+
+	return dto.FromAPIKeys(keys), nil
+}
+
+// RotateAPIKey issues a replacement key with the same name and scopes. The old
+// key is revoked, or keeps working for the requested grace period so clients
+// can be redeployed without downtime.
+func (s *AuthServiceImpl) RotateAPIKey(ctx context.Context, req dto.RotateAPIKeyRequest) (*dto.APIKeyCreatedResponse, error) {
+	logCtx := s.logger.With("key_id", req.ID).With("owner_id", req.OwnerID).With("operation", "rotate_api_key")
+	logCtx.Info("Processing rotate API key request")
+
+	old, err := s.ownedAPIKey(ctx, req.ID, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !old.IsActive(time.Now()) {
+		logCtx.Warn("Attempt to rotate an inactive API key")
+		return nil, domain.NewInvalidAuthInputError("Only active API keys can be rotated", nil)
+	}
+
+	key, plaintext, err := s.issueAPIKey(ctx, old.OwnerID, old.Name, old.Scopes, old.ExpiresAt, old.ID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to issue replacement API key")
+		return nil, err
+	}
+
+	if req.GracePeriodSeconds > 0 {
+		graceEnd := time.Now().Add(time.Duration(req.GracePeriodSeconds) * time.Second)
+		if old.ExpiresAt == nil || graceEnd.Before(*old.ExpiresAt) {
+			err = s.authRepo.ExpireAPIKey(ctx, old.ID, graceEnd)
+		}
+	} else {
+		err = s.authRepo.RevokeAPIKey(ctx, old.ID)
+	}
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to retire rotated API key")
+		return nil, domain.WithOperation(err, "retire_api_key")
+	}
+
+	logCtx.With("new_key_id", key.ID).Info("API key rotated")
+	return &dto.APIKeyCreatedResponse{APIKeyInfo: dto.FromAPIKey(key), Key: plaintext}, nil
+}
+
+// RevokeAPIKey revokes an API key owned by the user
+func (s *AuthServiceImpl) RevokeAPIKey(ctx context.Context, req dto.RevokeAPIKeyRequest) error {
+	logCtx := s.logger.With("key_id", req.ID).With("owner_id", req.OwnerID).With("operation", "revoke_api_key")
+	logCtx.Info("Processing revoke API key request")
+
+	if _, err := s.ownedAPIKey(ctx, req.ID, req.OwnerID); err != nil {
+		return err
+	}
+
+	if err := s.authRepo.RevokeAPIKey(ctx, req.ID); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to revoke API key")
+		return domain.WithOperation(err, "revoke_api_key")
+	}
+
+	logCtx.Info("API key revoked")
+	return nil
+}
+
+// ValidateAPIKey checks a presented API key and returns it with its owner
+func (s *AuthServiceImpl) ValidateAPIKey(ctx context.Context, req dto.ValidateAPIKeyRequest) (*dto.ValidateAPIKeyResponse, error) {
+	logCtx := s.logger.With("operation", "validate_api_key")
+
+	prefix, ok := parseAPIKey(req.Key)
+	if !ok {
+		logCtx.Debug("Malformed API key")
+		return &dto.ValidateAPIKeyResponse{Valid: false}, nil
+	}
+	logCtx = logCtx.With("prefix", prefix)
+
+	key, err := s.authRepo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if domain.IsAPIKeyNotFound(err) {
+			logCtx.Debug("Unknown API key")
+			return &dto.ValidateAPIKeyResponse{Valid: false}, nil
+		}
+		logCtx.With("error", err.Error()).Error("Failed to look up API key")
+		return nil, domain.WithOperation(err, "get_api_key_by_prefix")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(req.Key)), []byte(key.KeyHash)) != 1 {
+		logCtx.Warn("API key hash mismatch")
+		return &dto.ValidateAPIKeyResponse{Valid: false}, nil
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		logCtx.With("key_id", key.ID).Debug("Revoked or expired API key presented")
+		return &dto.ValidateAPIKeyResponse{Valid: false}, nil
+	}
+
+	owner, err := s.userClient.GetUser(ctx, key.OwnerID)
+	if err != nil || !owner.IsActive() {
+		logCtx.With("key_id", key.ID).Warn("API key owner missing or inactive")
+		return &dto.ValidateAPIKeyResponse{Valid: false}, nil
+	}
+
+	// Recording every use would write on each request; minute resolution is plenty
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyLastUsedSkew {
+		if err := s.authRepo.UpdateAPIKeyLastUsed(ctx, key.ID, now); err != nil {
+			logCtx.With("error", err.Error()).Warn("Failed to record API key use")
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	info := dto.FromAPIKey(key)
+	return &dto.ValidateAPIKeyResponse{
+		Valid: true,
+		Key:   &info,
+		User:  dto.FromUser(owner),
+	}, nil
+}
+
+// ownedAPIKey loads a key, reporting keys owned by someone else as not found
+func (s *AuthServiceImpl) ownedAPIKey(ctx context.Context, id, ownerID string) (*models.APIKey, error) {
+	key, err := s.authRepo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.OwnerID != ownerID {
+		s.logger.With("key_id", id).With("owner_id", ownerID).Warn("API key accessed by non-owner")
+		return nil, domain.NewAPIKeyNotFoundError(id)
+	}
+	return key, nil
+}
+
+// issueAPIKey generates and stores a new key, returning it with its plaintext
+func (s *AuthServiceImpl) issueAPIKey(ctx context.Context, ownerID, name string, scopes []string, expiresAt *time.Time, rotatedFrom string) (*models.APIKey, string, error) {
+	prefix, err := randomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return nil, "", domain.NewInternalError("Failed to generate API key")
+	}
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return nil, "", domain.NewInternalError("Failed to generate API key")
+	}
+	plaintext := apiKeyScheme + "_" + prefix + "_" + secret
+
+	key := &models.APIKey{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     hashAPIKey(plaintext),
+		OwnerID:     ownerID,
+		Scopes:      scopes,
+		ExpiresAt:   expiresAt,
+		RotatedFrom: rotatedFrom,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.authRepo.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", domain.WithOperation(err, "create_api_key")
+	}
+	return key, plaintext, nil
+}
+
+// validateScopes checks scopes are "*", "resource:*" or "resource:action"
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return domain.NewInvalidAuthInputError("At least one scope is required", nil)
+	}
+	for _, scope := range scopes {
+		if scope != "*" && !isPermissionScope(scope) {
+			return domain.NewInvalidAuthInputWithValidation("Invalid API key scope", map[string]string{
+				"scopes": "scope " + scope + " must be resource:action, resource:* or *",
+			})
+		}
+	}
+	return nil
+}
+
+// isPermissionScope reports whether a scope is "resource:action" or "resource:*"
+func isPermissionScope(scope string) bool {
+	resource, action, ok := strings.Cut(scope, ":")
+	return ok && resource != "" && action != "" && !strings.Contains(action, ":")
+}
+
+// parseAPIKey returns the prefix of a well-formed key
+func parseAPIKey(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyScheme {
+		return "", false
+	}
+	if len(parts[1]) != apiKeyPrefixBytes*2 || len(parts[2]) != apiKeySecretBytes*2 {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
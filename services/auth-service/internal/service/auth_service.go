@@ -3,14 +3,18 @@ package service
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/0xsj/fn-go/pkg/common/log"
+	natsclient "github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/models"
 	"github.com/0xsj/fn-go/services/auth-service/internal/config"
 	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
 	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+	"github.com/0xsj/fn-go/services/auth-service/internal/lockout"
 	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
+	"github.com/0xsj/fn-go/services/auth-service/internal/validation"
 	"github.com/0xsj/fn-go/services/auth-service/pkg/jwt"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -21,6 +25,10 @@ type AuthServiceImpl struct {
 	authRepo        repository.AuthRepository
 	userClient      UserServiceClient
 	jwtManager      *jwt.JWTManager
+	events          EventPublisher
+	lockout         *lockout.Guard
+	notifier        NotificationClient
+	passwords       *validation.PasswordChecker
 	config          *config.Config
 	logger          log.Logger
 }
@@ -30,6 +38,10 @@ func NewAuthService(
 	authRepo repository.AuthRepository,
 	userClient UserServiceClient,
 	jwtManager *jwt.JWTManager,
+	events EventPublisher,
+	lockoutGuard *lockout.Guard,
+	notifier NotificationClient,
+	passwords *validation.PasswordChecker,
 	config *config.Config,
 	logger log.Logger,
 ) AuthService {
@@ -37,6 +49,10 @@ func NewAuthService(
 		authRepo:   authRepo,
 		userClient: userClient,
 		jwtManager: jwtManager,
+		events:     events,
+		lockout:    lockoutGuard,
+		notifier:   notifier,
+		passwords:  passwords,
 		config:     config,
 		logger:     logger.WithLayer("auth-service"),
 	}
@@ -58,12 +74,24 @@ func (s *AuthServiceImpl) Login(ctx context.Context, req dto.LoginRequest) (*dto
 	}
 	
 	if err != nil {
+		// Unknown usernames are throttled like accounts, so lockouts don't
+		// reveal which exist
+		account := strings.ToLower(strings.TrimSpace(req.Username))
+		if lockErr := s.checkLockout(ctx, account, req.IPAddress, logCtx); lockErr != nil {
+			return nil, lockErr
+		}
 		logCtx.With("error", err.Error()).Warn("User not found during login attempt")
+		s.loginFailed(ctx, nil, account, req.Username, req.IPAddress, logCtx)
 		return nil, domain.NewInvalidCredentialsError()
 	}
 
 	logCtx = logCtx.With("user_id", user.ID)
 
+	// Check for a lockout of the account or client address
+	if err := s.checkLockout(ctx, user.ID, req.IPAddress, logCtx); err != nil {
+		return nil, err
+	}
+
 	// Check if account is active
 	if !user.IsActive() {
 		logCtx.Warn("Login attempt on inactive account")
@@ -73,22 +101,41 @@ func (s *AuthServiceImpl) Login(ctx context.Context, req dto.LoginRequest) (*dto
 		return nil, domain.NewAccountInactiveError(user.ID)
 	}
 
-	// Check for account lockout
-	if user.FailedLogins >= s.config.Auth.MaxLoginAttempts {
-		logCtx.Warn("Login attempt on locked account")
-		return nil, domain.NewAccountLockedError(user.ID)
+	// Verify password. Lookups leave the hash out; only this fetch asks for it.
+	credentials, err := s.userClient.GetUserWithPassword(ctx, user.ID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to get user credentials")
+		return nil, domain.WithOperation(err, "get_user_credentials")
 	}
-
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	if err := bcrypt.CompareHashAndPassword([]byte(credentials.Password), []byte(req.Password)); err != nil {
 		logCtx.Warn("Invalid password provided")
 		if incErr := s.userClient.IncrementFailedLogins(ctx, user.ID); incErr != nil {
 			logCtx.With("error", incErr.Error()).Error("Failed to increment failed logins")
 		}
+		s.loginFailed(ctx, user, user.ID, req.Username, req.IPAddress, logCtx)
 		return nil, domain.NewInvalidCredentialsError()
 	}
 
+	// Users with a second factor, or whose role requires one, finish logging in with it
+	if challenge, err := s.mfaChallenge(ctx, user); err != nil || challenge != nil {
+		return challenge, err
+	}
+
+	return s.completeLogin(ctx, user, req.UserAgent, req.IPAddress)
+}
+
+// completeLogin starts a session for a fully authenticated user. Failed
+// logins are only reset here, so a known password doesn't clear the count
+// while second factor codes are being guessed.
+func (s *AuthServiceImpl) completeLogin(ctx context.Context, user *models.User, userAgent, ipAddress string) (*dto.LoginResponse, error) {
+	logCtx := s.logger.With("user_id", user.ID).With("ip_address", ipAddress)
+
 	// Reset failed login attempts on successful login
+	if s.lockout != nil {
+		if err := s.lockout.Succeed(ctx, user.ID); err != nil {
+			logCtx.With("error", err.Error()).Warn("Failed to reset login lockout")
+		}
+	}
 	if user.FailedLogins > 0 {
 		if err := s.userClient.ResetFailedLogins(ctx, user.ID); err != nil {
 			logCtx.With("error", err.Error()).Warn("Failed to reset failed logins")
@@ -112,8 +159,8 @@ func (s *AuthServiceImpl) Login(ctx context.Context, req dto.LoginRequest) (*dto
 		ID:           uuid.New().String(),
 		UserID:       user.ID,
 		RefreshToken: refreshToken,
-		UserAgent:    req.UserAgent,
-		IPAddress:    req.IPAddress,
+		UserAgent:    userAgent,
+		IPAddress:    ipAddress,
 		LastActive:   time.Now(),
 		ExpiresAt:    time.Now().Add(s.config.Auth.RefreshTokenExpiry),
 		CreatedAt:    time.Now(),
@@ -128,14 +175,15 @@ func (s *AuthServiceImpl) Login(ctx context.Context, req dto.LoginRequest) (*dto
 	refreshTokenModel := &models.Token{
 		ID:        uuid.New().String(),
 		UserID:    user.ID,
+		FamilyID:  session.ID,
 		Type:      models.TokenTypeRefresh,
 		Value:     refreshToken,
 		ExpiresAt: time.Now().Add(s.config.Auth.RefreshTokenExpiry),
 		CreatedAt: time.Now(),
 		Metadata: map[string]any{
 			"session_id": session.ID,
-			"user_agent": req.UserAgent,
-			"ip_address": req.IPAddress,
+			"user_agent": userAgent,
+			"ip_address": ipAddress,
 		},
 	}
 
@@ -154,6 +202,10 @@ func (s *AuthServiceImpl) Login(ctx context.Context, req dto.LoginRequest) (*dto
 		User:         dto.FromUser(user),
 		SessionID:    session.ID,
 	}
+	if s.passwordExpired(ctx, user, logCtx) {
+		logCtx.Info("Password has passed its maximum age")
+		response.PasswordExpired = true
+	}
 
 	logCtx.Info("Login successful")
 	return response, nil
@@ -175,6 +227,11 @@ func (s *AuthServiceImpl) Register(ctx context.Context, req dto.RegisterRequest)
 		return nil, domain.NewUserAlreadyExistsError(req.Username)
 	}
 
+	// Apply the password policy
+	if err := s.checkNewPassword(ctx, "password", req.Password, &models.User{Username: req.Username, Email: req.Email}, logCtx); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.config.Auth.PasswordHashCost)
 	if err != nil {
@@ -209,6 +266,7 @@ func (s *AuthServiceImpl) Register(ctx context.Context, req dto.RegisterRequest)
 	}
 
 	logCtx = logCtx.With("user_id", createdUser.ID)
+	s.recordPassword(ctx, createdUser.ID, string(hashedPassword), logCtx)
 
 	// Generate email verification token
 	verificationToken, err := s.generateVerificationToken(ctx, createdUser.ID)
@@ -257,6 +315,7 @@ func (s *AuthServiceImpl) Register(ctx context.Context, req dto.RegisterRequest)
 	refreshTokenModel := &models.Token{
 		ID:        uuid.New().String(),
 		UserID:    createdUser.ID,
+		FamilyID:  session.ID,
 		Type:      models.TokenTypeRefresh,
 		Value:     refreshToken,
 		ExpiresAt: time.Now().Add(s.config.Auth.RefreshTokenExpiry),
@@ -326,8 +385,17 @@ func (s *AuthServiceImpl) ValidateToken(ctx context.Context, req dto.ValidateTok
 	return response, nil
 }
 
-// RefreshToken generates new tokens using a refresh token
+// RefreshToken rotates a refresh token, returning new tokens. Each session is
+// a token family: a refresh replaces the family's token with the next
+// generation, and presenting a token that was already replaced means it was
+// copied, so the whole family and its session are revoked.
 func (s *AuthServiceImpl) RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error) {
+	return s.refreshToken(ctx, req, "")
+}
+
+// refreshToken rotates a refresh token for the OAuth client it was issued
+// to, or for a first-party session when clientID is empty
+func (s *AuthServiceImpl) refreshToken(ctx context.Context, req dto.RefreshTokenRequest, clientID string) (*dto.RefreshTokenResponse, error) {
 	logCtx := s.logger.With("operation", "refresh_token")
 	logCtx.Debug("Processing token refresh request")
 
@@ -341,18 +409,49 @@ func (s *AuthServiceImpl) RefreshToken(ctx context.Context, req dto.RefreshToken
 	logCtx = logCtx.With("user_id", userID).With("jwt_id", jwtID)
 
 	// Check if refresh token exists in database
-	storedToken, err := s.authRepo.GetTokenByValue(ctx, req.RefreshToken)
+	storedToken, err := s.authRepo.GetRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
 		logCtx.With("error", err.Error()).Warn("Refresh token not found in database")
 		return nil, domain.NewTokenNotFoundError(req.RefreshToken)
 	}
 
+	familyID := storedToken.FamilyID
+	logCtx = logCtx.With("session_id", familyID).With("generation", storedToken.Generation)
+
+	// A replaced token coming back means two parties hold the family
+	if storedToken.IsRotated() {
+		return nil, s.revokeTokenFamily(ctx, storedToken, req)
+	}
+
 	// Check if token is revoked
 	if storedToken.RevokedAt != nil {
 		logCtx.Warn("Attempted to use revoked refresh token")
 		return nil, domain.NewTokenRevokedError()
 	}
 
+	// Tokens issued before families were tracked have no session to rotate
+	if familyID == "" {
+		logCtx.Warn("Refresh token has no token family")
+		return nil, domain.NewTokenRevokedError()
+	}
+
+	// A client's tokens only refresh through that client, and never as a
+	// first-party session
+	if issuedTo, _ := storedToken.Metadata["client_id"].(string); issuedTo != clientID {
+		logCtx.With("client_id", clientID).Warn("Refresh token presented by the wrong client")
+		return nil, domain.NewInvalidTokenError()
+	}
+
+	// The family ends with its session
+	session, err := s.authRepo.GetSessionByID(ctx, familyID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Refresh token session no longer exists")
+		if _, revokeErr := s.authRepo.RevokeTokenFamily(ctx, familyID); revokeErr != nil {
+			logCtx.With("error", revokeErr.Error()).Error("Failed to revoke token family")
+		}
+		return nil, domain.NewTokenRevokedError()
+	}
+
 	// Get user
 	user, err := s.userClient.GetUser(ctx, userID)
 	if err != nil {
@@ -366,45 +465,62 @@ func (s *AuthServiceImpl) RefreshToken(ctx context.Context, req dto.RefreshToken
 		return nil, domain.NewAccountInactiveError(userID)
 	}
 
-	// Generate new tokens
-	newAccessToken, newRefreshToken, err := s.jwtManager.GenerateTokenPair(user)
+	// Client tokens keep their scopes for as long as the user's consent does
+	var scope string
+	var newAccessToken, newRefreshToken string
+	if clientID != "" {
+		scope, _ = storedToken.Metadata["scope"].(string)
+		scopes := strings.Fields(scope)
+		consent, consentErr := s.authRepo.GetOAuthConsent(ctx, userID, clientID)
+		if consentErr != nil || !containsAll(consent.Scopes, scopes) {
+			logCtx.With("client_id", clientID).Warn("Refresh token of a client the user no longer consents to")
+			if _, revokeErr := s.authRepo.RevokeTokenFamily(ctx, familyID); revokeErr != nil {
+				logCtx.With("error", revokeErr.Error()).Error("Failed to revoke token family")
+			}
+			return nil, domain.NewTokenRevokedError()
+		}
+		newAccessToken, newRefreshToken, err = s.jwtManager.GenerateClientTokenPair(user, clientID, scopes)
+	} else {
+		newAccessToken, newRefreshToken, err = s.jwtManager.GenerateTokenPair(user)
+	}
 	if err != nil {
 		logCtx.With("error", err.Error()).Error("Failed to generate new tokens")
 		return nil, domain.WithOperation(err, "generate_tokens")
 	}
 
-	// Revoke old refresh token
-	if err := s.authRepo.RevokeToken(ctx, storedToken.ID); err != nil {
-		logCtx.With("error", err.Error()).Error("Failed to revoke old refresh token")
-		return nil, domain.WithOperation(err, "revoke_old_token")
-	}
-
-	// Store new refresh token
+	// Replace the old refresh token with the next generation
 	newRefreshTokenModel := &models.Token{
-		ID:        uuid.New().String(),
-		UserID:    userID,
-		Type:      models.TokenTypeRefresh,
-		Value:     newRefreshToken,
-		ExpiresAt: time.Now().Add(s.config.Auth.RefreshTokenExpiry),
-		CreatedAt: time.Now(),
-		Metadata: storedToken.Metadata, // Carry over metadata
-	}
-
-	if err := s.authRepo.CreateToken(ctx, newRefreshTokenModel); err != nil {
-		logCtx.With("error", err.Error()).Error("Failed to store new refresh token")
-		return nil, domain.WithOperation(err, "store_new_token")
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		FamilyID:   familyID,
+		Generation: storedToken.Generation + 1,
+		Type:       models.TokenTypeRefresh,
+		Value:      newRefreshToken,
+		ExpiresAt:  time.Now().Add(s.config.Auth.RefreshTokenExpiry),
+		CreatedAt:  time.Now(),
+		Metadata:   storedToken.Metadata, // Carry over metadata
+	}
+
+	rotated, err := s.authRepo.RotateRefreshToken(ctx, storedToken.ID, newRefreshTokenModel)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to rotate refresh token")
+		return nil, domain.WithOperation(err, "rotate_refresh_token")
+	}
+	if !rotated {
+		// Another refresh claimed the token first, unless a logout revoked it
+		if current, err := s.authRepo.GetRefreshToken(ctx, req.RefreshToken); err == nil && !current.IsRotated() {
+			logCtx.Warn("Refresh token revoked during refresh")
+			return nil, domain.NewTokenRevokedError()
+		}
+		return nil, s.revokeTokenFamily(ctx, storedToken, req)
 	}
 
-	// Update session with new refresh token
-	if sessionID, ok := storedToken.Metadata["session_id"].(string); ok {
-		session, err := s.authRepo.GetSessionByID(ctx, sessionID)
-		if err == nil {
-			session.RefreshToken = newRefreshToken
-			session.LastActive = time.Now()
-			if updateErr := s.authRepo.UpdateSession(ctx, session); updateErr != nil {
-				logCtx.With("error", updateErr.Error()).Warn("Failed to update session")
-			}
-		}
+	// Move the session on to the new token
+	session.RefreshToken = newRefreshToken
+	session.Generation = newRefreshTokenModel.Generation
+	session.LastActive = time.Now()
+	if updateErr := s.authRepo.UpdateSession(ctx, session); updateErr != nil {
+		logCtx.With("error", updateErr.Error()).Warn("Failed to update session")
 	}
 
 	accessExpiry, _ := s.jwtManager.GetTokenExpiry()
@@ -414,12 +530,57 @@ func (s *AuthServiceImpl) RefreshToken(ctx context.Context, req dto.RefreshToken
 		RefreshToken: newRefreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    int64(accessExpiry.Seconds()),
+		Scope:        scope,
 	}
 
 	logCtx.Info("Token refresh successful")
 	return response, nil
 }
 
+// revokeTokenFamily ends a session whose refresh token was presented after
+// being rotated, revoking every token of the family, and reports the reuse
+func (s *AuthServiceImpl) revokeTokenFamily(ctx context.Context, token *models.Token, req dto.RefreshTokenRequest) error {
+	logCtx := s.logger.With("operation", "refresh_token").
+		With("user_id", token.UserID).
+		With("session_id", token.FamilyID).
+		With("generation", token.Generation).
+		With("ip_address", req.IPAddress)
+
+	revoked, err := s.authRepo.RevokeTokenFamily(ctx, token.FamilyID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to revoke token family")
+	}
+
+	currentGeneration := token.Generation
+	if session, err := s.authRepo.GetSessionByID(ctx, token.FamilyID); err == nil {
+		currentGeneration = session.Generation
+	}
+	if err := s.authRepo.DeleteSession(ctx, token.FamilyID); err != nil && !domain.IsSessionNotFound(err) {
+		logCtx.With("error", err.Error()).Error("Failed to delete session of reused refresh token")
+	}
+
+	logCtx.With("revoked_tokens", revoked).Warn("Refresh token reuse detected, session revoked")
+
+	if s.events != nil {
+		event := dto.RefreshTokenReusedEvent{
+			UserID:            token.UserID,
+			SessionID:         token.FamilyID,
+			TokenID:           token.ID,
+			Generation:        token.Generation,
+			CurrentGeneration: currentGeneration,
+			RevokedTokens:     revoked,
+			IPAddress:         req.IPAddress,
+			UserAgent:         req.UserAgent,
+			DetectedAt:        time.Now(),
+		}
+		if err := s.events.Publish(ctx, natsclient.SubjectAuthRefreshTokenReused, event); err != nil {
+			logCtx.With("error", err.Error()).Warn("Failed to publish refresh token reuse event")
+		}
+	}
+
+	return domain.NewTokenReusedError(token.FamilyID)
+}
+
 // Logout invalidates user tokens and sessions
 func (s *AuthServiceImpl) Logout(ctx context.Context, req dto.LogoutRequest) error {
 	logCtx := s.logger.With("user_id", req.UserID).With("operation", "logout")
@@ -440,6 +601,18 @@ func (s *AuthServiceImpl) Logout(ctx context.Context, req dto.LogoutRequest) err
 
 		logCtx.Info("All sessions and tokens revoked")
 	} else if req.SessionID != "" {
+		// Only the user's own session can be revoked; anyone else's is
+		// reported as not found, as a missing one is
+		session, err := s.authRepo.GetSessionByID(ctx, req.SessionID)
+		if err != nil && !domain.IsSessionNotFound(err) {
+			logCtx.With("error", err.Error()).With("session_id", req.SessionID).Error("Failed to get session")
+			return domain.WithOperation(err, "get_session")
+		}
+		if err != nil || session.UserID != req.UserID {
+			logCtx.With("session_id", req.SessionID).Warn("Logout of a session the user doesn't own")
+			return domain.NewSessionNotFoundError(req.SessionID)
+		}
+
 		// Revoke specific session
 		if err := s.authRepo.DeleteSession(ctx, req.SessionID); err != nil {
 			logCtx.With("error", err.Error).With("session_id", req.SessionID).Error("Failed to delete session")
@@ -506,7 +679,7 @@ func (s *AuthServiceImpl) ChangePassword(ctx context.Context, req dto.ChangePass
 	logCtx.Info("Processing password change request")
 
 	// Get user
-	user, err := s.userClient.GetUser(ctx, req.UserID)
+	user, err := s.userClient.GetUserWithPassword(ctx, req.UserID)
 	if err != nil {
 		logCtx.With("error", err.Error()).Error("Failed to get user for password change")
 		return domain.WithOperation(err, "get_user")
@@ -518,6 +691,11 @@ func (s *AuthServiceImpl) ChangePassword(ctx context.Context, req dto.ChangePass
 		return domain.NewInvalidCredentialsError()
 	}
 
+	// Apply the password policy, which also rules out the current password
+	if err := s.checkNewPassword(ctx, "newPassword", req.NewPassword, user, logCtx); err != nil {
+		return err
+	}
+
 	// Hash new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), s.config.Auth.PasswordHashCost)
 	if err != nil {
@@ -534,6 +712,7 @@ func (s *AuthServiceImpl) ChangePassword(ctx context.Context, req dto.ChangePass
 		logCtx.With("error", err.Error()).Error("Failed to update user password")
 		return domain.WithOperation(err, "update_password")
 	}
+	s.recordPassword(ctx, req.UserID, string(hashedPassword), logCtx)
 
 	// Revoke all existing sessions and refresh tokens for security
 	if err := s.authRepo.RevokeAllTokensForUser(ctx, req.UserID, string(models.TokenTypeRefresh)); err != nil {
@@ -618,6 +797,16 @@ func (s *AuthServiceImpl) ResetPassword(ctx context.Context, req dto.ResetPasswo
 		return domain.NewTokenRevokedError()
 	}
 
+	// Apply the password policy
+	user, err := s.userClient.GetUserWithPassword(ctx, token.UserID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to get user for password reset")
+		return domain.WithOperation(err, "get_user")
+	}
+	if err := s.checkNewPassword(ctx, "newPassword", req.NewPassword, user, logCtx); err != nil {
+		return err
+	}
+
 	// Hash new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), s.config.Auth.PasswordHashCost)
 	if err != nil {
@@ -634,6 +823,7 @@ func (s *AuthServiceImpl) ResetPassword(ctx context.Context, req dto.ResetPasswo
 		logCtx.With("error", err.Error()).Error("Failed to update user password")
 		return domain.WithOperation(err, "update_password")
 	}
+	s.recordPassword(ctx, token.UserID, string(hashedPassword), logCtx)
 
 	// Revoke the reset token
 	if err := s.authRepo.RevokeToken(ctx, token.ID); err != nil {
@@ -796,53 +986,65 @@ func (s *AuthServiceImpl) RevokeAllSessions(ctx context.Context, userID string)
 	return nil
 }
 
-// GetUserPermissions gets all permissions for a user based on their roles
+// GetUserPermissions gets all permissions for a user based on their roles,
+// including inherited ones. A permission granted unconditionally by any role
+// is listed once; otherwise each conditional grant is listed with its
+// conditions, any of which may allow the action.
 func (s *AuthServiceImpl) GetUserPermissions(ctx context.Context, userID string) ([]dto.PermissionResponse, error) {
 	logCtx := s.logger.With("user_id", userID).With("operation", "get_user_permissions")
 	logCtx.Debug("Getting user permissions")
 
-	// Get user to determine their roles
-	user, err := s.userClient.GetUser(ctx, userID)
+	subject, err := s.loadPolicySubject(ctx, userID)
 	if err != nil {
-		logCtx.With("error", err.Error()).Error("Failed to get user")
-		return nil, domain.WithOperation(err, "get_user")
+		logCtx.With("error", err.Error()).Error("Failed to resolve user roles")
+		return nil, domain.WithOperation(err, "load_policy_subject")
 	}
 
-	// Get permissions for the user's role
-	permissions, err := s.authRepo.GetPermissionsByRole(ctx, string(user.Role))
+	grants, err := s.authRepo.GetPermissionGrants(ctx, subject.roleIDs())
 	if err != nil {
-		logCtx.With("error", err.Error()).Error("Failed to get permissions for role")
-		return nil, domain.WithOperation(err, "get_permissions_by_role")
+		logCtx.With("error", err.Error()).Error("Failed to get permission grants")
+		return nil, domain.WithOperation(err, "get_permission_grants")
+	}
+
+	unconditional := make(map[string]bool)
+	for _, grant := range grants {
+		if len(grant.Conditions) == 0 {
+			unconditional[grant.ID] = true
+		}
+	}
+
+	permissionResponses := make([]dto.PermissionResponse, 0, len(grants))
+	listed := make(map[string]bool)
+	for _, grant := range grants {
+		if unconditional[grant.ID] {
+			if listed[grant.ID] || len(grant.Conditions) > 0 {
+				continue
+			}
+			listed[grant.ID] = true
+		}
+		permissionResponses = append(permissionResponses, dto.FromPermissionGrant(grant))
 	}
 
-	permissionResponses := dto.FromPermissions(permissions)
 	logCtx.With("permission_count", len(permissionResponses)).Debug("User permissions retrieved")
 	
 	return permissionResponses, nil
 }
 
-// CheckPermission checks if a user has a specific permission
+// CheckPermission checks if a user has a specific permission. Without the
+// resource's attributes, grants with conditions on them don't apply; use
+// EvaluatePolicy to check access to a particular resource.
 func (s *AuthServiceImpl) CheckPermission(ctx context.Context, userID string, resource string, action string) (bool, error) {
 	logCtx := s.logger.With("user_id", userID).With("resource", resource).With("action", action).With("operation", "check_permission")
 	logCtx.Debug("Checking user permission")
 
-	// Get user permissions
-	permissions, err := s.GetUserPermissions(ctx, userID)
+	decision, err := s.EvaluatePolicy(ctx, dto.EvaluatePolicyRequest{UserID: userID, Resource: resource, Action: action})
 	if err != nil {
-		logCtx.With("error", err.Error()).Error("Failed to get user permissions")
-		return false, domain.WithOperation(err, "get_user_permissions")
-	}
-
-	// Check if user has the required permission
-	for _, permission := range permissions {
-		if permission.Resource == resource && permission.Action == action {
-			logCtx.Debug("Permission granted")
-			return true, nil
-		}
+		logCtx.With("error", err.Error()).Error("Failed to evaluate policy")
+		return false, domain.WithOperation(err, "evaluate_policy")
 	}
 
-	logCtx.Debug("Permission denied")
-	return false, nil
+	logCtx.With("allowed", decision.Allowed).Debug(decision.Reason)
+	return decision.Allowed, nil
 }
 
 // AssignRolePermission assigns a permission to a role
@@ -850,12 +1052,20 @@ func (s *AuthServiceImpl) AssignRolePermission(ctx context.Context, req dto.Assi
 	logCtx := s.logger.With("role_id", req.RoleID).With("permission_id", req.PermissionID).With("operation", "assign_role_permission")
 	logCtx.Info("Processing assign role permission request")
 
-	if err := s.authRepo.AssignPermissionToRole(ctx, req.RoleID, req.PermissionID); err != nil {
+	for _, condition := range req.Conditions {
+		if err := validateCondition(condition); err != nil {
+			logCtx.With("error", err.Error()).Warn("Invalid grant condition")
+			return domain.NewInvalidAuthInputError(err.Error(), nil)
+		}
+	}
+
+	if err := s.authRepo.AssignPermissionToRole(ctx, req.RoleID, req.PermissionID, req.Conditions); err != nil {
 		logCtx.With("error", err.Error()).Error("Failed to assign permission to role")
 		return domain.WithOperation(err, "assign_permission_to_role")
 	}
 
-	logCtx.Info("Permission assigned to role successfully")
+	logCtx.With("condition_count", len(req.Conditions)).Info("Permission assigned to role successfully")
+	s.publishPermissionsChanged(ctx, dto.PermissionsChangedEvent{RoleID: req.RoleID, PermissionID: req.PermissionID, Change: "assigned"})
 	return nil
 }
 
@@ -870,9 +1080,26 @@ func (s *AuthServiceImpl) RevokeRolePermission(ctx context.Context, roleID strin
 	}
 
 	logCtx.Info("Permission revoked from role successfully")
+	s.publishPermissionsChanged(ctx, dto.PermissionsChangedEvent{RoleID: roleID, PermissionID: permissionID, Change: "revoked"})
 	return nil
 }
 
+// publishPermissionsChanged notifies consumers caching permissions that a
+// role, the role hierarchy or a user's roles changed
+func (s *AuthServiceImpl) publishPermissionsChanged(ctx context.Context, event dto.PermissionsChangedEvent) {
+	if s.events == nil {
+		return
+	}
+
+	event.ChangedAt = time.Now()
+
+	if err := s.events.Publish(ctx, natsclient.SubjectAuthPermissionsChanged, event); err != nil {
+		s.logger.With("role_id", event.RoleID).With("user_id", event.UserID).
+			With("error", err.Error()).
+			Warn("Failed to publish permissions changed event")
+	}
+}
+
 // GetAuthStats gets authentication statistics
 func (s *AuthServiceImpl) GetAuthStats(ctx context.Context) (*dto.AuthStatsResponse, error) {
 	logCtx := s.logger.With("operation", "get_auth_stats")
@@ -902,6 +1129,13 @@ func (s *AuthServiceImpl) CleanupExpiredTokens(ctx context.Context) (int, error)
 		return 0, domain.WithOperation(err, "delete_expired_tokens")
 	}
 
+	codes, err := s.authRepo.DeleteExpiredAuthorizationCodes(ctx, time.Now())
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to cleanup expired authorization codes")
+		return 0, domain.WithOperation(err, "delete_expired_authorization_codes")
+	}
+	deletedCount += codes
+
 	logCtx.With("deleted_count", deletedCount).Info("Expired tokens cleaned up")
 	return deletedCount, nil
 }
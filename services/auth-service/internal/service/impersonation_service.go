@@ -0,0 +1,84 @@
+// services/auth-service/internal/service/impersonation_service.go
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	natsclient "github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// Impersonate issues an administrator a token acting as a user, so support
+// staff see exactly what the user sees. The token names the administrator
+// in its act claim, lasts at most the configured maximum and can't be
+// refreshed. Administrators can't be impersonated, so impersonation never
+// grants more than the actor already holds.
+func (s *AuthServiceImpl) Impersonate(ctx context.Context, req dto.ImpersonateRequest) (*dto.ImpersonationResponse, error) {
+	logCtx := s.logger.With("user_id", req.UserID).With("actor_id", req.ActorID).With("operation", "impersonate")
+	logCtx.Info("Processing impersonation request")
+
+	if req.UserID == req.ActorID {
+		logCtx.Warn("Impersonation of self requested")
+		return nil, domain.NewImpersonationForbiddenError(req.UserID, "you can't impersonate yourself")
+	}
+
+	subject, err := s.loadPolicySubject(ctx, req.UserID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found for impersonation")
+		return nil, domain.NewUserNotFoundError(req.UserID)
+	}
+	user := subject.user
+
+	if !user.IsActive() {
+		logCtx.Warn("Impersonation of inactive account requested")
+		return nil, domain.NewAccountInactiveError(user.ID)
+	}
+	if slices.Contains(subject.roleNames(), string(models.RoleAdmin)) {
+		logCtx.Warn("Impersonation of administrator requested")
+		return nil, domain.NewImpersonationForbiddenError(user.ID, "administrators can't be impersonated")
+	}
+
+	duration := s.config.Impersonation.Duration
+	if req.DurationSeconds > 0 {
+		duration = time.Duration(req.DurationSeconds) * time.Second
+	}
+	duration = min(duration, s.config.Impersonation.MaxDuration)
+
+	accessToken, err := s.jwtManager.GenerateImpersonationToken(user, req.ActorID, duration)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to generate impersonation token")
+		return nil, domain.WithOperation(err, "generate_impersonation_token")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(duration)
+
+	if s.events != nil {
+		event := dto.ImpersonationStartedEvent{
+			UserID:    user.ID,
+			ActorID:   req.ActorID,
+			Reason:    req.Reason,
+			IPAddress: req.IPAddress,
+			UserAgent: req.UserAgent,
+			StartedAt: now,
+			ExpiresAt: expiresAt,
+		}
+		if err := s.events.Publish(ctx, natsclient.SubjectAuthImpersonationStarted, event); err != nil {
+			logCtx.With("error", err.Error()).Warn("Failed to publish impersonation started event")
+		}
+	}
+
+	logCtx.With("reason", req.Reason).With("expires_at", expiresAt).Warn("Impersonation started")
+	return &dto.ImpersonationResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(duration.Seconds()),
+		ExpiresAt:   expiresAt,
+		User:        dto.FromUser(user),
+		ActorID:     req.ActorID,
+	}, nil
+}
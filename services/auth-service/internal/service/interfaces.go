@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/0xsj/fn-go/pkg/common/jwks"
 	"github.com/0xsj/fn-go/pkg/models"
 	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
 )
@@ -24,6 +25,17 @@ type AuthService interface {
 	ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) error
 	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error
 	
+	// Lockout operations
+	RedeemUnlockToken(ctx context.Context, req dto.RedeemUnlockTokenRequest) error
+	UnlockAccount(ctx context.Context, req dto.UnlockAccountRequest) error
+	
+	// Impersonation
+	Impersonate(ctx context.Context, req dto.ImpersonateRequest) (*dto.ImpersonationResponse, error)
+	
+	// Magic link operations
+	RequestMagicLink(ctx context.Context, req dto.RequestMagicLinkRequest) error
+	ExchangeMagicLink(ctx context.Context, req dto.ExchangeMagicLinkRequest) (*dto.LoginResponse, error)
+	
 	// Email verification
 	VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error
 	ResendVerificationEmail(ctx context.Context, userID string) error
@@ -38,6 +50,41 @@ type AuthService interface {
 	CheckPermission(ctx context.Context, userID string, resource string, action string) (bool, error)
 	AssignRolePermission(ctx context.Context, req dto.AssignPermissionRequest) error
 	RevokeRolePermission(ctx context.Context, roleID string, permissionID string) error
+	EvaluatePolicy(ctx context.Context, req dto.EvaluatePolicyRequest) (*dto.PolicyDecision, error)
+	ListRoles(ctx context.Context) ([]dto.RoleResponse, error)
+	SetRoleParent(ctx context.Context, req dto.SetRoleParentRequest) error
+	GetUserRoles(ctx context.Context, userID string) (*dto.UserRolesResponse, error)
+	AssignUserRole(ctx context.Context, req dto.AssignUserRoleRequest) error
+	RevokeUserRole(ctx context.Context, req dto.RevokeUserRoleRequest) error
+	SetUserAttributes(ctx context.Context, req dto.SetUserAttributesRequest) error
+	
+	// API key operations
+	CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.APIKeyCreatedResponse, error)
+	ListAPIKeys(ctx context.Context, ownerID string) ([]dto.APIKeyInfo, error)
+	RotateAPIKey(ctx context.Context, req dto.RotateAPIKeyRequest) (*dto.APIKeyCreatedResponse, error)
+	RevokeAPIKey(ctx context.Context, req dto.RevokeAPIKeyRequest) error
+	ValidateAPIKey(ctx context.Context, req dto.ValidateAPIKeyRequest) (*dto.ValidateAPIKeyResponse, error)
+	
+	// Multi-factor authentication
+	VerifyMFA(ctx context.Context, req dto.VerifyMFARequest) (*dto.LoginResponse, error)
+	EnrollMFA(ctx context.Context, req dto.EnrollMFARequest) (*dto.MFAEnrollmentResponse, error)
+	ConfirmMFA(ctx context.Context, req dto.ConfirmMFARequest) (*dto.MFARecoveryCodesResponse, error)
+	DisableMFA(ctx context.Context, req dto.DisableMFARequest) error
+	ResetMFA(ctx context.Context, userID string) error
+	RegenerateRecoveryCodes(ctx context.Context, req dto.RegenerateRecoveryCodesRequest) (*dto.MFARecoveryCodesResponse, error)
+	GetMFAStatus(ctx context.Context, userID string) (*dto.MFAStatusResponse, error)
+	
+	// OAuth2 / OpenID Connect authorization server
+	RegisterOAuthClient(ctx context.Context, req dto.RegisterOAuthClientRequest) (*dto.OAuthClientCreatedResponse, error)
+	ListOAuthClients(ctx context.Context) ([]dto.OAuthClientInfo, error)
+	RevokeOAuthClient(ctx context.Context, clientID string) error
+	Authorize(ctx context.Context, req dto.AuthorizeRequest) (*dto.AuthorizeResponse, error)
+	ListOAuthConsents(ctx context.Context, userID string) ([]dto.OAuthConsentInfo, error)
+	RevokeOAuthConsent(ctx context.Context, req dto.RevokeOAuthConsentRequest) error
+	OAuthToken(ctx context.Context, req dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error)
+	UserInfo(ctx context.Context, req dto.UserInfoRequest) (map[string]any, error)
+	Introspect(ctx context.Context, req dto.IntrospectRequest) (*dto.IntrospectResponse, error)
+	Discovery(ctx context.Context) (*dto.DiscoveryDocument, error)
 	
 	// Administrative operations
 	GetAuthStats(ctx context.Context) (*dto.AuthStatsResponse, error)
@@ -51,6 +98,9 @@ type UserServiceClient interface {
 	GetUser(ctx context.Context, userID string) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	// GetUserWithPassword also returns the password hash, which user-service
+	// only sends to services holding the user.get.password scope
+	GetUserWithPassword(ctx context.Context, userID string) (*models.User, error)
 	
 	// User management operations
 	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
@@ -61,6 +111,23 @@ type UserServiceClient interface {
 	SetEmailVerified(ctx context.Context, userID string, verified bool) error
 }
 
+// NotificationClient sends messages to users through notification-service
+type NotificationClient interface {
+	SendEmail(ctx context.Context, to, subject, content string) error
+}
+
+// EventPublisher publishes auth domain events
+type EventPublisher interface {
+	Publish(ctx context.Context, subject string, data any) error
+}
+
+// SigningKeyService manages the keys tokens are signed with
+type SigningKeyService interface {
+	JWKS(ctx context.Context) (*jwks.Set, error)
+	ListSigningKeys(ctx context.Context) ([]dto.SigningKeyInfo, error)
+	RotateSigningKey(ctx context.Context) (*dto.SigningKeyInfo, error)
+}
+
 // HealthService defines health check operations
 type HealthService interface {
 	Check(ctx context.Context) (map[string]any, error)
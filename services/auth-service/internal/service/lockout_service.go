@@ -0,0 +1,199 @@
+// services/auth-service/internal/service/lockout_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	natsclient "github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+	"github.com/0xsj/fn-go/services/auth-service/internal/lockout"
+	"github.com/google/uuid"
+)
+
+// unlockTokenBytes is the size of an unlock token, which like an API key is
+// stored only as a SHA-256 hash
+const unlockTokenBytes = 32
+
+// unlockedBySelf marks an account unlocked with its emailed token
+const unlockedBySelf = "self"
+
+// checkLockout rejects a login barred by a lockout. Accounts are keyed by ID,
+// and logins naming no account by the username given, so both are locked out
+// alike and a lockout doesn't reveal whether an account exists.
+func (s *AuthServiceImpl) checkLockout(ctx context.Context, account, ipAddress string, logCtx log.Logger) error {
+	if s.lockout == nil {
+		return nil
+	}
+
+	lock, err := s.lockout.Check(ctx, account, ipAddress)
+	if err != nil {
+		// The stores fall back to memory, so this only fails open on a bug
+		logCtx.With("error", err.Error()).Error("Failed to check login lockout")
+		return nil
+	}
+	if lock == nil {
+		return nil
+	}
+
+	logCtx.With("scope", lock.Scope).With("retry_after", lock.Duration.String()).Warn("Login attempt while locked out")
+	if lock.Scope == lockout.ScopeIP {
+		return domain.NewLoginThrottledError(ipAddress, lock.Duration)
+	}
+	return domain.NewAccountLockedError(account, lock.Duration)
+}
+
+// loginFailed counts a failed login against the account and client address.
+// Lockouts it triggers are published for monitoring-service, and a locked
+// account's user is emailed a link to unlock it.
+func (s *AuthServiceImpl) loginFailed(ctx context.Context, user *models.User, account, username, ipAddress string, logCtx log.Logger) {
+	if s.lockout == nil {
+		return
+	}
+
+	lockouts, err := s.lockout.Fail(ctx, account, ipAddress)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to record failed login")
+	}
+
+	for _, lock := range lockouts {
+		event := dto.LoginLockoutEvent{
+			Scope:       lock.Scope,
+			Username:    username,
+			IPAddress:   ipAddress,
+			Failures:    lock.Failures,
+			LockedUntil: time.Now().Add(lock.Duration),
+			DetectedAt:  time.Now(),
+		}
+		if user != nil {
+			event.UserID = user.ID
+		}
+		if s.events != nil {
+			if err := s.events.Publish(ctx, natsclient.SubjectAuthLoginLockout, event); err != nil {
+				logCtx.With("error", err.Error()).Warn("Failed to publish login lockout event")
+			}
+		}
+
+		if lock.Scope == lockout.ScopeAccount && user != nil {
+			s.sendUnlockEmail(ctx, user, lock.Duration, logCtx)
+		}
+	}
+}
+
+// sendUnlockEmail emails a user a single-use link unlocking their account,
+// for when it was them failing to log in
+func (s *AuthServiceImpl) sendUnlockEmail(ctx context.Context, user *models.User, lockedFor time.Duration, logCtx log.Logger) {
+	if s.notifier == nil {
+		logCtx.Warn("No notification service to send the unlock email")
+		return
+	}
+
+	value, err := randomHex(unlockTokenBytes)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to generate unlock token")
+		return
+	}
+
+	now := time.Now()
+	token := &models.Token{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Type:      models.TokenTypeUnlock,
+		Value:     hashAPIKey(value),
+		ExpiresAt: now.Add(s.config.Lockout.UnlockTokenExpiry),
+		CreatedAt: now,
+	}
+	if err := s.authRepo.CreateToken(ctx, token); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to create unlock token")
+		return
+	}
+
+	link := s.config.Lockout.UnlockURL + "?" + url.Values{"token": {value}}.Encode()
+	content := fmt.Sprintf(
+		"Your account was locked for %s after too many failed login attempts.\n\n"+
+			"If this was you, unlock it now with the link below. It works once and expires in %s.\n\n%s\n\n"+
+			"If this wasn't you, someone may be guessing your password; consider changing it once you're back in.",
+		lockedFor.Round(time.Second), s.config.Lockout.UnlockTokenExpiry, link)
+
+	if err := s.notifier.SendEmail(ctx, user.Email, "Your account has been locked", content); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to send unlock email")
+		return
+	}
+	logCtx.With("token_id", token.ID).Info("Unlock email sent")
+}
+
+// RedeemUnlockToken unlocks the account an unlock token was emailed for. The
+// token works once.
+func (s *AuthServiceImpl) RedeemUnlockToken(ctx context.Context, req dto.RedeemUnlockTokenRequest) error {
+	logCtx := s.logger.With("operation", "redeem_unlock_token")
+	logCtx.Info("Processing unlock token")
+
+	token, err := s.authRepo.GetTokenByValue(ctx, hashAPIKey(req.Token))
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Unlock token not found")
+		return domain.NewInvalidTokenError()
+	}
+
+	logCtx = logCtx.With("user_id", token.UserID).With("token_id", token.ID)
+
+	if token.Type != models.TokenTypeUnlock {
+		logCtx.Warn("Invalid token type for unlock")
+		return domain.NewInvalidTokenError()
+	}
+	if time.Now().After(token.ExpiresAt) {
+		logCtx.Warn("Expired unlock token used")
+		return domain.NewTokenExpiredError()
+	}
+
+	return s.unlock(ctx, token.UserID, unlockedBySelf, logCtx)
+}
+
+// UnlockAccount unlocks an account for an administrator
+func (s *AuthServiceImpl) UnlockAccount(ctx context.Context, req dto.UnlockAccountRequest) error {
+	logCtx := s.logger.With("user_id", req.UserID).With("unlocked_by", req.UnlockedBy).With("operation", "unlock_account")
+	logCtx.Info("Processing unlock account request")
+
+	if _, err := s.userClient.GetUser(ctx, req.UserID); err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found for unlock")
+		return domain.NewUserNotFoundError(req.UserID)
+	}
+
+	return s.unlock(ctx, req.UserID, req.UnlockedBy, logCtx)
+}
+
+// unlock lifts an account's lockout, forgets its failed logins and spends
+// any unlock tokens still outstanding
+func (s *AuthServiceImpl) unlock(ctx context.Context, userID, unlockedBy string, logCtx log.Logger) error {
+	if s.lockout != nil {
+		if err := s.lockout.Unlock(ctx, userID); err != nil {
+			logCtx.With("error", err.Error()).Error("Failed to lift lockout")
+			return domain.NewInternalError("Failed to unlock account")
+		}
+	}
+
+	if err := s.authRepo.RevokeAllTokensForUser(ctx, userID, string(models.TokenTypeUnlock)); err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to revoke unlock tokens")
+	}
+	if err := s.userClient.ResetFailedLogins(ctx, userID); err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to reset failed logins")
+	}
+
+	if s.events != nil {
+		event := dto.AccountUnlockedEvent{
+			UserID:     userID,
+			UnlockedBy: unlockedBy,
+			UnlockedAt: time.Now(),
+		}
+		if err := s.events.Publish(ctx, natsclient.SubjectAuthAccountUnlocked, event); err != nil {
+			logCtx.With("error", err.Error()).Warn("Failed to publish account unlocked event")
+		}
+	}
+
+	logCtx.Info("Account unlocked")
+	return nil
+}
@@ -0,0 +1,192 @@
+// services/auth-service/internal/service/magic_link_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+	"github.com/google/uuid"
+)
+
+// magicLinkTokenBytes is the size of a magic link token, which like an API
+// key is stored only as a SHA-256 hash
+const magicLinkTokenBytes = 32
+
+// RequestMagicLink emails a user a single-use link that logs them in without
+// their password. Like ForgotPassword it succeeds whether or not the email
+// belongs to an account, so it can't be used to find out which do.
+func (s *AuthServiceImpl) RequestMagicLink(ctx context.Context, req dto.RequestMagicLinkRequest) error {
+	logCtx := s.logger.With("email", req.Email).With("ip_address", req.IPAddress).With("operation", "request_magic_link")
+	logCtx.Info("Processing magic link request")
+
+	// A throttled address is refused outright, as it would be at login
+	if err := s.checkLockout(ctx, "", req.IPAddress, logCtx); err != nil {
+		return err
+	}
+
+	user, err := s.userClient.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logCtx.With("error", err.Error()).Debug("User not found for magic link")
+		return nil
+	}
+
+	logCtx = logCtx.With("user_id", user.ID)
+
+	if !user.IsActive() {
+		logCtx.Warn("Magic link requested for inactive account")
+		return nil
+	}
+
+	// A locked account gets no link, but isn't told so
+	if s.checkLockout(ctx, user.ID, "", logCtx) != nil {
+		return nil
+	}
+
+	if s.notifier == nil {
+		logCtx.Warn("No notification service to send the magic link")
+		return nil
+	}
+
+	if s.lockout != nil {
+		allowed, err := s.lockout.Cooldown(ctx, "magic-link:"+user.ID, s.config.MagicLink.Interval)
+		if err != nil {
+			logCtx.With("error", err.Error()).Warn("Failed to check magic link interval")
+		} else if !allowed {
+			logCtx.Info("Magic link requested again too soon")
+			return nil
+		}
+	}
+
+	// Only the newest link works
+	if err := s.authRepo.RevokeAllTokensForUser(ctx, user.ID, string(models.TokenTypeMagicLink)); err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to revoke previous magic links")
+	}
+
+	value, err := randomHex(magicLinkTokenBytes)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to generate magic link token")
+		return domain.NewInternalError("Failed to create magic link")
+	}
+
+	now := time.Now()
+	token := &models.Token{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Type:      models.TokenTypeMagicLink,
+		Value:     hashAPIKey(value),
+		ExpiresAt: now.Add(s.config.MagicLink.Expiry),
+		CreatedAt: now,
+		Metadata: map[string]any{
+			"device_id":  req.DeviceID,
+			"user_agent": req.UserAgent,
+			"ip_address": req.IPAddress,
+		},
+	}
+	if err := s.authRepo.CreateToken(ctx, token); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to create magic link token")
+		return domain.WithOperation(err, "create_magic_link_token")
+	}
+
+	link := s.config.MagicLink.URL + "?" + url.Values{"token": {value}}.Encode()
+	content := fmt.Sprintf(
+		"Use the link below to log in. It works once and expires in %s.\n\n%s\n\n"+
+			"If you didn't ask to log in, you can ignore this email.",
+		s.config.MagicLink.Expiry, link)
+
+	if err := s.notifier.SendEmail(ctx, user.Email, "Your login link", content); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to send magic link email")
+		return domain.NewInternalError("Failed to send magic link")
+	}
+
+	logCtx.With("token_id", token.ID).Info("Magic link sent")
+	return nil
+}
+
+// ExchangeMagicLink logs in with a magic link's token. Failures count towards
+// the client address's lockout as failed passwords do, and links bound to a
+// device or address only work from it.
+func (s *AuthServiceImpl) ExchangeMagicLink(ctx context.Context, req dto.ExchangeMagicLinkRequest) (*dto.LoginResponse, error) {
+	logCtx := s.logger.With("ip_address", req.IPAddress).With("operation", "exchange_magic_link")
+	logCtx.Info("Processing magic link login")
+
+	if err := s.checkLockout(ctx, "", req.IPAddress, logCtx); err != nil {
+		return nil, err
+	}
+
+	token, err := s.authRepo.GetTokenByValue(ctx, hashAPIKey(req.Token))
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Magic link token not found")
+		s.loginFailed(ctx, nil, "", "", req.IPAddress, logCtx)
+		return nil, domain.NewInvalidTokenError()
+	}
+
+	logCtx = logCtx.With("user_id", token.UserID).With("token_id", token.ID)
+
+	if token.Type != models.TokenTypeMagicLink {
+		logCtx.Warn("Invalid token type for magic link")
+		s.loginFailed(ctx, nil, "", "", req.IPAddress, logCtx)
+		return nil, domain.NewInvalidTokenError()
+	}
+	if time.Now().After(token.ExpiresAt) {
+		logCtx.Warn("Expired magic link used")
+		return nil, domain.NewTokenExpiredError()
+	}
+
+	// A link used from elsewhere stays usable, so whoever asked for it can
+	// still log in with it
+	if !s.magicLinkBound(token, req) {
+		logCtx.Warn("Magic link used from a different device or address")
+		s.loginFailed(ctx, nil, "", "", req.IPAddress, logCtx)
+		return nil, domain.NewInvalidTokenError()
+	}
+
+	consumed, err := s.authRepo.ConsumeToken(ctx, token.ID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to consume magic link token")
+		return nil, domain.WithOperation(err, "consume_magic_link_token")
+	}
+	if !consumed {
+		logCtx.Warn("Magic link already used")
+		return nil, domain.NewInvalidTokenError()
+	}
+
+	user, err := s.userClient.GetUser(ctx, token.UserID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to get user for magic link")
+		return nil, domain.WithOperation(err, "get_user")
+	}
+	if !user.IsActive() {
+		logCtx.Warn("Magic link login on inactive account")
+		return nil, domain.NewAccountInactiveError(user.ID)
+	}
+
+	if err := s.checkLockout(ctx, user.ID, req.IPAddress, logCtx); err != nil {
+		return nil, err
+	}
+
+	// The link stands in for the password only; a second factor is still asked for
+	if challenge, err := s.mfaChallenge(ctx, user); err != nil || challenge != nil {
+		return challenge, err
+	}
+
+	return s.completeLogin(ctx, user, req.UserAgent, req.IPAddress)
+}
+
+// magicLinkBound reports whether a magic link is being used from the device,
+// and if configured the address, that asked for it
+func (s *AuthServiceImpl) magicLinkBound(token *models.Token, req dto.ExchangeMagicLinkRequest) bool {
+	if deviceID, _ := token.Metadata["device_id"].(string); deviceID != "" && deviceID != req.DeviceID {
+		return false
+	}
+	if s.config.MagicLink.BindIP {
+		if ipAddress, _ := token.Metadata["ip_address"].(string); ipAddress != "" && ipAddress != req.IPAddress {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,505 @@
+// services/auth-service/internal/service/mfa_service.go
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+	"github.com/0xsj/fn-go/services/auth-service/pkg/secretbox"
+	"github.com/0xsj/fn-go/services/auth-service/pkg/totp"
+)
+
+// Recovery codes look like "a1b2c-3d4e5". They carry 40 bits of randomness
+// and each failed attempt counts towards the account lockout, so a fast hash
+// is enough.
+const recoveryCodeBytes = 5
+
+// VerifyMFA completes a login that passed the password check by checking its
+// second factor. A login that had to enroll first confirms the enrollment
+// here and gets its recovery codes with the tokens.
+func (s *AuthServiceImpl) VerifyMFA(ctx context.Context, req dto.VerifyMFARequest) (*dto.LoginResponse, error) {
+	logCtx := s.logger.With("ip_address", req.IPAddress).With("operation", "verify_mfa")
+	logCtx.Info("Processing MFA verification request")
+
+	userID, enroll, err := s.jwtManager.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Invalid MFA token")
+		return nil, err
+	}
+	logCtx = logCtx.With("user_id", userID)
+
+	user, err := s.userClient.GetUser(ctx, userID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found for MFA token")
+		return nil, domain.NewInvalidTokenError()
+	}
+	if !user.IsActive() {
+		logCtx.Warn("MFA verification on inactive account")
+		return nil, domain.NewAccountInactiveError(user.ID)
+	}
+	if err := s.checkLockout(ctx, user.ID, req.IPAddress, logCtx); err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.authRepo.GetMFAEnrollment(ctx, user.ID)
+	if err != nil {
+		if domain.IsMFANotEnrolled(err) {
+			logCtx.Warn("MFA verification without an enrollment")
+			if enroll {
+				return nil, err
+			}
+			return nil, domain.NewInvalidTokenError()
+		}
+		logCtx.With("error", err.Error()).Error("Failed to get MFA enrollment")
+		return nil, domain.WithOperation(err, "get_mfa_enrollment")
+	}
+
+	var recoveryCodes []string
+	if enrollment.IsConfirmed() {
+		err = s.verifySecondFactor(ctx, enrollment, req.Code, req.RecoveryCode)
+	} else if enroll {
+		// The first code proves the authenticator was set up; recovery codes don't exist yet
+		if err = s.verifyCode(ctx, enrollment, req.Code); err == nil {
+			recoveryCodes, err = s.confirmEnrollment(ctx, user.ID)
+		}
+	} else {
+		logCtx.Warn("MFA verification against an unconfirmed enrollment")
+		return nil, domain.NewInvalidTokenError()
+	}
+	if err != nil {
+		if domain.IsInvalidMFACode(err) {
+			logCtx.Warn("Invalid MFA code provided")
+			if incErr := s.userClient.IncrementFailedLogins(ctx, user.ID); incErr != nil {
+				logCtx.With("error", incErr.Error()).Error("Failed to increment failed logins")
+			}
+			s.loginFailed(ctx, user, user.ID, user.Username, req.IPAddress, logCtx)
+		} else {
+			logCtx.With("error", err.Error()).Error("Failed to verify MFA code")
+		}
+		return nil, err
+	}
+
+	response, err := s.completeLogin(ctx, user, req.UserAgent, req.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// EnrollMFA generates a new TOTP secret for a user. The enrollment only
+// counts once confirmed with a first code, by ConfirmMFA or, for a login
+// required to enroll, by VerifyMFA.
+func (s *AuthServiceImpl) EnrollMFA(ctx context.Context, req dto.EnrollMFARequest) (*dto.MFAEnrollmentResponse, error) {
+	logCtx := s.logger.With("operation", "enroll_mfa")
+	logCtx.Info("Processing MFA enrollment request")
+
+	userID := req.UserID
+	if req.MFAToken != "" {
+		tokenUserID, enroll, err := s.jwtManager.ValidateMFAToken(req.MFAToken)
+		if err != nil {
+			logCtx.With("error", err.Error()).Warn("Invalid MFA token")
+			return nil, err
+		}
+		if !enroll {
+			logCtx.With("user_id", tokenUserID).Warn("MFA token does not permit enrollment")
+			return nil, domain.NewInvalidTokenError()
+		}
+		userID = tokenUserID
+	}
+	if userID == "" {
+		return nil, domain.NewInvalidAuthInputError("User ID or MFA token is required", nil)
+	}
+	logCtx = logCtx.With("user_id", userID)
+
+	user, err := s.userClient.GetUser(ctx, userID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found for MFA enrollment")
+		return nil, domain.NewUserNotFoundError(userID)
+	}
+	if !user.IsActive() {
+		logCtx.Warn("MFA enrollment for inactive account")
+		return nil, domain.NewAccountInactiveError(user.ID)
+	}
+
+	existing, err := s.authRepo.GetMFAEnrollment(ctx, user.ID)
+	if err != nil && !domain.IsMFANotEnrolled(err) {
+		logCtx.With("error", err.Error()).Error("Failed to get MFA enrollment")
+		return nil, domain.WithOperation(err, "get_mfa_enrollment")
+	}
+	if existing != nil && existing.IsConfirmed() {
+		logCtx.Warn("MFA enrollment while already enrolled")
+		return nil, domain.NewMFAAlreadyEnrolledError(user.ID)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, domain.NewInternalError("Failed to generate MFA secret")
+	}
+	sealed, err := s.sealMFASecret(user.ID, secret)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to encrypt MFA secret")
+		return nil, domain.NewInternalError("Failed to store MFA secret")
+	}
+
+	now := time.Now()
+	enrollment := &models.MFAEnrollment{
+		UserID:          user.ID,
+		SecretEncrypted: sealed,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.authRepo.SaveMFAEnrollment(ctx, enrollment); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to save MFA enrollment")
+		return nil, domain.WithOperation(err, "save_mfa_enrollment")
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+
+	logCtx.Info("MFA enrollment started")
+	return &dto.MFAEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.config.MFA.Issuer, account, secret),
+		Issuer:          s.config.MFA.Issuer,
+		Account:         account,
+	}, nil
+}
+
+// ConfirmMFA completes an enrollment with a first code and returns the
+// user's recovery codes
+func (s *AuthServiceImpl) ConfirmMFA(ctx context.Context, req dto.ConfirmMFARequest) (*dto.MFARecoveryCodesResponse, error) {
+	logCtx := s.logger.With("user_id", req.UserID).With("operation", "confirm_mfa")
+	logCtx.Info("Processing MFA confirmation request")
+
+	enrollment, err := s.authRepo.GetMFAEnrollment(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.IsConfirmed() {
+		logCtx.Warn("MFA confirmation while already enrolled")
+		return nil, domain.NewMFAAlreadyEnrolledError(req.UserID)
+	}
+
+	if err := s.verifyCode(ctx, enrollment, req.Code); err != nil {
+		logCtx.Warn("Invalid MFA code provided")
+		return nil, err
+	}
+
+	codes, err := s.confirmEnrollment(ctx, req.UserID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to confirm MFA enrollment")
+		return nil, err
+	}
+
+	logCtx.Info("MFA enrollment confirmed")
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA removes a user's second factor. Roles that require one can't
+// remove it, only enroll a new one after an administrator resets it.
+func (s *AuthServiceImpl) DisableMFA(ctx context.Context, req dto.DisableMFARequest) error {
+	logCtx := s.logger.With("user_id", req.UserID).With("operation", "disable_mfa")
+	logCtx.Info("Processing disable MFA request")
+
+	user, err := s.userClient.GetUser(ctx, req.UserID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found for disable MFA")
+		return domain.NewUserNotFoundError(req.UserID)
+	}
+	if s.mfaRequired(user.Role) {
+		logCtx.With("role", user.Role).Warn("Attempt to disable required MFA")
+		return domain.NewMFARequiredError(user.ID, string(user.Role))
+	}
+
+	if err := s.checkLockout(ctx, user.ID, "", logCtx); err != nil {
+		return err
+	}
+
+	enrollment, err := s.authRepo.GetMFAEnrollment(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// An unconfirmed enrollment never protected anything, so no code is needed
+	if enrollment.IsConfirmed() {
+		if err := s.verifySecondFactor(ctx, enrollment, req.Code, req.RecoveryCode); err != nil {
+			if domain.IsInvalidMFACode(err) {
+				logCtx.Warn("Invalid MFA code provided")
+				if incErr := s.userClient.IncrementFailedLogins(ctx, user.ID); incErr != nil {
+					logCtx.With("error", incErr.Error()).Error("Failed to increment failed logins")
+				}
+				s.loginFailed(ctx, user, user.ID, user.Username, "", logCtx)
+			}
+			return err
+		}
+	}
+
+	if err := s.authRepo.DeleteMFAEnrollment(ctx, user.ID); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to delete MFA enrollment")
+		return domain.WithOperation(err, "delete_mfa_enrollment")
+	}
+
+	logCtx.Info("MFA disabled")
+	return nil
+}
+
+// ResetMFA removes a user's second factor without a code, for administrators
+// helping users who lost both their authenticator and recovery codes. Users
+// whose role requires a factor enroll again at their next login.
+func (s *AuthServiceImpl) ResetMFA(ctx context.Context, userID string) error {
+	logCtx := s.logger.With("user_id", userID).With("operation", "reset_mfa")
+	logCtx.Info("Processing reset MFA request")
+
+	if err := s.authRepo.DeleteMFAEnrollment(ctx, userID); err != nil {
+		if !domain.IsMFANotEnrolled(err) {
+			logCtx.With("error", err.Error()).Error("Failed to delete MFA enrollment")
+			return domain.WithOperation(err, "delete_mfa_enrollment")
+		}
+		return err
+	}
+
+	logCtx.Info("MFA reset")
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes, invalidating the old ones
+func (s *AuthServiceImpl) RegenerateRecoveryCodes(ctx context.Context, req dto.RegenerateRecoveryCodesRequest) (*dto.MFARecoveryCodesResponse, error) {
+	logCtx := s.logger.With("user_id", req.UserID).With("operation", "regenerate_recovery_codes")
+	logCtx.Info("Processing regenerate recovery codes request")
+
+	enrollment, err := s.authRepo.GetMFAEnrollment(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.IsConfirmed() {
+		return nil, domain.NewMFANotEnrolledError(req.UserID)
+	}
+
+	if err := s.verifyCode(ctx, enrollment, req.Code); err != nil {
+		logCtx.Warn("Invalid MFA code provided")
+		return nil, err
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, domain.NewInternalError("Failed to generate recovery codes")
+	}
+	replaced, err := s.authRepo.ReplaceMFARecoveryCodes(ctx, req.UserID, enrollment.RecoveryCodes, hashes)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to replace recovery codes")
+		return nil, domain.WithOperation(err, "replace_mfa_recovery_codes")
+	}
+	if !replaced {
+		return nil, domain.NewInvalidAuthInputError("Recovery codes changed during the request, please try again", nil)
+	}
+
+	logCtx.Info("Recovery codes regenerated")
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// GetMFAStatus describes a user's second factor
+func (s *AuthServiceImpl) GetMFAStatus(ctx context.Context, userID string) (*dto.MFAStatusResponse, error) {
+	logCtx := s.logger.With("user_id", userID).With("operation", "get_mfa_status")
+	logCtx.Debug("Getting MFA status")
+
+	user, err := s.userClient.GetUser(ctx, userID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found for MFA status")
+		return nil, domain.NewUserNotFoundError(userID)
+	}
+
+	status := &dto.MFAStatusResponse{Required: s.mfaRequired(user.Role)}
+
+	enrollment, err := s.authRepo.GetMFAEnrollment(ctx, userID)
+	if err != nil {
+		if domain.IsMFANotEnrolled(err) {
+			return status, nil
+		}
+		logCtx.With("error", err.Error()).Error("Failed to get MFA enrollment")
+		return nil, domain.WithOperation(err, "get_mfa_enrollment")
+	}
+
+	status.Enabled = enrollment.IsConfirmed()
+	status.Pending = !enrollment.IsConfirmed()
+	status.RecoveryCodesRemaining = len(enrollment.RecoveryCodes)
+	status.ConfirmedAt = enrollment.ConfirmedAt
+	return status, nil
+}
+
+// mfaChallenge returns the response that ends the first login step for users
+// who owe a second factor, or nil when the password is enough
+func (s *AuthServiceImpl) mfaChallenge(ctx context.Context, user *models.User) (*dto.LoginResponse, error) {
+	logCtx := s.logger.With("user_id", user.ID).With("operation", "mfa_challenge")
+
+	enrolled := false
+	enrollment, err := s.authRepo.GetMFAEnrollment(ctx, user.ID)
+	if err == nil {
+		enrolled = enrollment.IsConfirmed()
+	} else if !domain.IsMFANotEnrolled(err) {
+		logCtx.With("error", err.Error()).Error("Failed to get MFA enrollment")
+		return nil, domain.WithOperation(err, "get_mfa_enrollment")
+	}
+
+	if !enrolled && !s.mfaRequired(user.Role) {
+		return nil, nil
+	}
+
+	token, err := s.jwtManager.GenerateMFAToken(user.ID, !enrolled, s.config.MFA.ChallengeExpiry)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to generate MFA token")
+		return nil, domain.WithOperation(err, "generate_mfa_token")
+	}
+
+	logCtx.With("enrollment_required", !enrolled).Info("Login awaiting second factor")
+	return &dto.LoginResponse{
+		User:                  dto.FromUser(user),
+		MFARequired:           true,
+		MFAEnrollmentRequired: !enrolled,
+		MFAToken:              token,
+	}, nil
+}
+
+// mfaRequired reports whether a role may only log in with a second factor
+func (s *AuthServiceImpl) mfaRequired(role models.Role) bool {
+	for _, required := range s.config.MFA.RequiredRoles {
+		if strings.EqualFold(required, string(role)) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySecondFactor accepts either a current code or an unused recovery code
+func (s *AuthServiceImpl) verifySecondFactor(ctx context.Context, enrollment *models.MFAEnrollment, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		return s.useRecoveryCode(ctx, enrollment, recoveryCode)
+	}
+	return s.verifyCode(ctx, enrollment, code)
+}
+
+// verifyCode checks a TOTP code and marks its time step used, so the same
+// code can't be presented twice
+func (s *AuthServiceImpl) verifyCode(ctx context.Context, enrollment *models.MFAEnrollment, code string) error {
+	if code == "" {
+		return domain.NewInvalidMFACodeError()
+	}
+
+	secret, err := s.openMFASecret(enrollment.UserID, enrollment.SecretEncrypted)
+	if err != nil {
+		s.logger.With("user_id", enrollment.UserID).With("error", err.Error()).Error("Failed to decrypt MFA secret")
+		return domain.NewInternalError("Failed to read MFA secret")
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), enrollment.LastUsedStep)
+	if !ok {
+		return domain.NewInvalidMFACodeError()
+	}
+
+	claimed, err := s.authRepo.ClaimMFAStep(ctx, enrollment.UserID, step)
+	if err != nil {
+		return domain.WithOperation(err, "claim_mfa_step")
+	}
+	if !claimed {
+		return domain.NewInvalidMFACodeError()
+	}
+	return nil
+}
+
+// useRecoveryCode consumes a recovery code. The codes are swapped only if
+// unchanged since read, so each code works exactly once.
+func (s *AuthServiceImpl) useRecoveryCode(ctx context.Context, enrollment *models.MFAEnrollment, code string) error {
+	hash := hashRecoveryCode(code)
+
+	remaining := make([]string, 0, len(enrollment.RecoveryCodes))
+	found := false
+	for _, stored := range enrollment.RecoveryCodes {
+		if !found && subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			found = true
+			continue
+		}
+		remaining = append(remaining, stored)
+	}
+	if !found {
+		return domain.NewInvalidMFACodeError()
+	}
+
+	replaced, err := s.authRepo.ReplaceMFARecoveryCodes(ctx, enrollment.UserID, enrollment.RecoveryCodes, remaining)
+	if err != nil {
+		return domain.WithOperation(err, "replace_mfa_recovery_codes")
+	}
+	if !replaced {
+		return domain.NewInvalidMFACodeError()
+	}
+
+	s.logger.With("user_id", enrollment.UserID).
+		With("remaining", len(remaining)).
+		Info("Recovery code used")
+	return nil
+}
+
+// confirmEnrollment marks an enrollment confirmed, returning its new recovery codes
+func (s *AuthServiceImpl) confirmEnrollment(ctx context.Context, userID string) ([]string, error) {
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, domain.NewInternalError("Failed to generate recovery codes")
+	}
+	if err := s.authRepo.ConfirmMFAEnrollment(ctx, userID, hashes, time.Now()); err != nil {
+		return nil, domain.WithOperation(err, "confirm_mfa_enrollment")
+	}
+	return codes, nil
+}
+
+// generateRecoveryCodes returns new recovery codes with the hashes to store
+func (s *AuthServiceImpl) generateRecoveryCodes() (codes, hashes []string, err error) {
+	n := max(s.config.MFA.RecoveryCodes, 1)
+	codes = make([]string, n)
+	hashes = make([]string, n)
+	for i := range codes {
+		raw, err := randomHex(recoveryCodeBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = raw[:len(raw)/2] + "-" + raw[len(raw)/2:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// sealMFASecret encrypts a TOTP secret, bound to the user it belongs to
+func (s *AuthServiceImpl) sealMFASecret(userID, secret string) ([]byte, error) {
+	box, err := secretbox.New(s.config.MFA.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return box.Seal([]byte(secret), []byte(userID))
+}
+
+// openMFASecret decrypts a TOTP secret sealed by sealMFASecret
+func (s *AuthServiceImpl) openMFASecret(userID string, sealed []byte) (string, error) {
+	box, err := secretbox.New(s.config.MFA.EncryptionKey)
+	if err != nil {
+		return "", err
+	}
+	secret, err := box.Open(sealed, []byte(userID))
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
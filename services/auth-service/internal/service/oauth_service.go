@@ -0,0 +1,805 @@
+// services/auth-service/internal/service/oauth_service.go
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/serviceauth"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+	"github.com/google/uuid"
+)
+
+// Client IDs and secrets, like authorization codes, are random and stored only
+// as SHA-256 hashes, the same as API keys
+const (
+	oauthClientIDBytes     = 16
+	oauthClientSecretBytes = 32
+	authorizationCodeBytes = 32
+)
+
+// PKCE code verifiers are 43 to 128 characters (RFC 7636 section 4.1)
+const (
+	codeVerifierMinLength = 43
+	codeVerifierMaxLength = 128
+)
+
+// oidcScopes are the OpenID Connect scopes. Clients may also hold API scopes
+// ("resource:action"), which limit what their access tokens can reach.
+var oidcScopes = []string{"openid", "profile", "email", "phone"}
+
+// RegisterOAuthClient registers a partner application. A confidential
+// client's secret is returned only here.
+func (s *AuthServiceImpl) RegisterOAuthClient(ctx context.Context, req dto.RegisterOAuthClientRequest) (*dto.OAuthClientCreatedResponse, error) {
+	logCtx := s.logger.With("name", req.Name).With("operation", "register_oauth_client")
+	logCtx.Info("Processing register OAuth client request")
+
+	clientType := models.OAuthClientType(req.Type)
+	switch clientType {
+	case models.OAuthClientConfidential, models.OAuthClientPublic:
+		if len(req.RedirectURIs) == 0 {
+			return nil, domain.NewInvalidAuthInputError("At least one redirect URI is required", nil)
+		}
+		for _, uri := range req.RedirectURIs {
+			if err := validateRedirectURI(uri); err != nil {
+				logCtx.With("redirect_uri", uri).Warn("Invalid OAuth redirect URI")
+				return nil, err
+			}
+		}
+		if err := validateClientScopes(req.Scopes); err != nil {
+			logCtx.With("error", err.Error()).Warn("Invalid OAuth client scopes")
+			return nil, err
+		}
+	case models.OAuthClientService:
+		// Service accounts never redirect a user anywhere
+		if len(req.RedirectURIs) > 0 {
+			return nil, domain.NewInvalidAuthInputError("Service accounts have no redirect URIs", nil)
+		}
+		if err := validateServiceScopes(req.Scopes); err != nil {
+			logCtx.With("error", err.Error()).Warn("Invalid service account scopes")
+			return nil, err
+		}
+	default:
+		return nil, domain.NewInvalidAuthInputWithValidation("Invalid OAuth client", map[string]string{
+			"type": "type must be confidential, public or service",
+		})
+	}
+
+	id, err := randomHex(oauthClientIDBytes)
+	if err != nil {
+		return nil, domain.NewInternalError("Failed to generate client ID")
+	}
+
+	now := time.Now()
+	client := &models.OAuthClient{
+		ID:           id,
+		Name:         req.Name,
+		Type:         clientType,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	var secret string
+	if client.IsConfidential() {
+		if secret, err = randomHex(oauthClientSecretBytes); err != nil {
+			return nil, domain.NewInternalError("Failed to generate client secret")
+		}
+		client.SecretHash = hashAPIKey(secret)
+	}
+
+	if err := s.authRepo.CreateOAuthClient(ctx, client); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to register OAuth client")
+		return nil, domain.WithOperation(err, "create_oauth_client")
+	}
+
+	logCtx.With("client_id", client.ID).With("type", client.Type).Info("OAuth client registered")
+	return &dto.OAuthClientCreatedResponse{OAuthClientInfo: dto.FromOAuthClient(client), ClientSecret: secret}, nil
+}
+
+// ListOAuthClients lists the registered OAuth clients
+func (s *AuthServiceImpl) ListOAuthClients(ctx context.Context) ([]dto.OAuthClientInfo, error) {
+	clients, err := s.authRepo.ListOAuthClients(ctx)
+	if err != nil {
+		s.logger.With("error", err.Error()).Error("Failed to list OAuth clients")
+		return nil, domain.WithOperation(err, "list_oauth_clients")
+	}
+
+	return dto.FromOAuthClients(clients), nil
+}
+
+// RevokeOAuthClient revokes a client. Its refresh tokens stop working at
+// once, since the client can no longer authenticate to use them.
+func (s *AuthServiceImpl) RevokeOAuthClient(ctx context.Context, clientID string) error {
+	logCtx := s.logger.With("client_id", clientID).With("operation", "revoke_oauth_client")
+	logCtx.Info("Processing revoke OAuth client request")
+
+	if err := s.authRepo.RevokeOAuthClient(ctx, clientID); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to revoke OAuth client")
+		return domain.WithOperation(err, "revoke_oauth_client")
+	}
+
+	logCtx.Info("OAuth client revoked")
+	return nil
+}
+
+// Authorize handles an authorization code request for a signed-in user.
+// Requests that can't be trusted to reach the client, because the client or
+// its redirect URI is unknown, fail with an error for the user; every other
+// outcome, including the code, is sent to the client's redirect URI.
+func (s *AuthServiceImpl) Authorize(ctx context.Context, req dto.AuthorizeRequest) (*dto.AuthorizeResponse, error) {
+	logCtx := s.logger.With("user_id", req.UserID).With("client_id", req.ClientID).With("operation", "authorize")
+	logCtx.Info("Processing authorization request")
+
+	// A code approved under impersonation would exchange for tokens without
+	// the act claim, outliving the impersonation and escaping its audit trail
+	if req.ActorID != "" {
+		logCtx.With("actor_id", req.ActorID).Warn("Authorization request under impersonation")
+		return nil, domain.NewImpersonationForbiddenError(req.UserID, "clients can't be authorized while impersonating")
+	}
+
+	client, err := s.authRepo.GetOAuthClient(ctx, req.ClientID)
+	if err != nil {
+		if domain.IsOAuthClientNotFound(err) {
+			logCtx.Warn("Authorization request for unknown client")
+			return nil, domain.NewInvalidAuthInputError("Unknown OAuth client", nil)
+		}
+		return nil, domain.WithOperation(err, "get_oauth_client")
+	}
+	if !client.IsActive() {
+		logCtx.Warn("Authorization request for revoked client")
+		return nil, domain.NewInvalidAuthInputError("OAuth client has been revoked", nil)
+	}
+	if client.IsService() {
+		logCtx.Warn("Authorization request for service account")
+		return nil, domain.NewInvalidAuthInputError("Service accounts can't sign users in", nil)
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(redirectURI) {
+		logCtx.With("redirect_uri", req.RedirectURI).Warn("Authorization request with unregistered redirect URI")
+		return nil, domain.NewInvalidAuthInputError("Redirect URI is not registered for the client", nil)
+	}
+
+	reject := func(code, description string) (*dto.AuthorizeResponse, error) {
+		logCtx.With("error", code).Info("Authorization request rejected")
+		return &dto.AuthorizeResponse{RedirectTo: redirectWith(redirectURI, req.State, url.Values{
+			"error":             {code},
+			"error_description": {description},
+		})}, nil
+	}
+
+	if req.ResponseType != "code" {
+		return reject(domain.OAuthUnsupportedResponseType, "Only the authorization code flow is supported")
+	}
+	// PKCE protects the codes of confidential clients too
+	if req.CodeChallengeMethod != "S256" || !validCodeChallenge(req.CodeChallenge) {
+		return reject(domain.OAuthInvalidRequest, "A code_challenge with code_challenge_method S256 is required")
+	}
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !containsAll(client.Scopes, scopes) {
+		return reject(domain.OAuthInvalidScope, "The client may not request these scopes")
+	}
+
+	switch req.Decision {
+	case "", "approve":
+	case "deny":
+		return reject(domain.OAuthAccessDenied, "The user denied the request")
+	default:
+		return nil, domain.NewInvalidAuthInputError("Decision must be approve or deny", nil)
+	}
+
+	user, err := s.userClient.GetUser(ctx, req.UserID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found for authorization request")
+		return nil, domain.NewUserNotFoundError(req.UserID)
+	}
+	if !user.IsActive() {
+		logCtx.Warn("Authorization request from inactive account")
+		return nil, domain.NewAccountInactiveError(user.ID)
+	}
+
+	// Ask only for scopes the user hasn't already granted the client
+	consent, err := s.authRepo.GetOAuthConsent(ctx, user.ID, client.ID)
+	if err != nil && !domain.IsOAuthConsentNotFound(err) {
+		logCtx.With("error", err.Error()).Error("Failed to get OAuth consent")
+		return nil, domain.WithOperation(err, "get_oauth_consent")
+	}
+	if consent == nil || !containsAll(consent.Scopes, scopes) {
+		if req.Decision != "approve" {
+			info := dto.FromOAuthClient(client)
+			return &dto.AuthorizeResponse{ConsentRequired: true, Client: &info, Scopes: scopes}, nil
+		}
+
+		now := time.Now()
+		granted := &models.OAuthConsent{UserID: user.ID, ClientID: client.ID, Scopes: scopes, GrantedAt: now, UpdatedAt: now}
+		if consent != nil {
+			granted.Scopes = union(consent.Scopes, scopes)
+			granted.GrantedAt = consent.GrantedAt
+		}
+		if err := s.authRepo.SaveOAuthConsent(ctx, granted); err != nil {
+			logCtx.With("error", err.Error()).Error("Failed to save OAuth consent")
+			return nil, domain.WithOperation(err, "save_oauth_consent")
+		}
+		logCtx.With("scopes", granted.Scopes).Info("OAuth consent granted")
+	}
+
+	code, err := randomHex(authorizationCodeBytes)
+	if err != nil {
+		return nil, domain.NewInternalError("Failed to generate authorization code")
+	}
+	now := time.Now()
+	authCode := &models.AuthorizationCode{
+		CodeHash:            hashAPIKey(code),
+		ClientID:            client.ID,
+		UserID:              user.ID,
+		RedirectURI:         redirectURI,
+		Scopes:              scopes,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
+		ExpiresAt:           now.Add(s.config.OAuth.CodeExpiry),
+		CreatedAt:           now,
+	}
+	if err := s.authRepo.CreateAuthorizationCode(ctx, authCode); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to store authorization code")
+		return nil, domain.WithOperation(err, "create_authorization_code")
+	}
+
+	logCtx.Info("Authorization code issued")
+	return &dto.AuthorizeResponse{RedirectTo: redirectWith(redirectURI, req.State, url.Values{"code": {code}})}, nil
+}
+
+// ListOAuthConsents lists the clients a user has granted access to
+func (s *AuthServiceImpl) ListOAuthConsents(ctx context.Context, userID string) ([]dto.OAuthConsentInfo, error) {
+	logCtx := s.logger.With("user_id", userID).With("operation", "list_oauth_consents")
+
+	consents, err := s.authRepo.ListOAuthConsents(ctx, userID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to list OAuth consents")
+		return nil, domain.WithOperation(err, "list_oauth_consents")
+	}
+
+	infos := make([]dto.OAuthConsentInfo, 0, len(consents))
+	for _, consent := range consents {
+		info := dto.OAuthConsentInfo{
+			ClientID:  consent.ClientID,
+			Scopes:    consent.Scopes,
+			GrantedAt: consent.GrantedAt,
+			UpdatedAt: consent.UpdatedAt,
+		}
+		if client, err := s.authRepo.GetOAuthClient(ctx, consent.ClientID); err == nil {
+			info.ClientName = client.Name
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// RevokeOAuthConsent withdraws a user's consent to a client. The client's
+// refresh tokens for the user stop working at their next use.
+func (s *AuthServiceImpl) RevokeOAuthConsent(ctx context.Context, req dto.RevokeOAuthConsentRequest) error {
+	logCtx := s.logger.With("user_id", req.UserID).With("client_id", req.ClientID).With("operation", "revoke_oauth_consent")
+	logCtx.Info("Processing revoke OAuth consent request")
+
+	if err := s.authRepo.DeleteOAuthConsent(ctx, req.UserID, req.ClientID); err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to revoke OAuth consent")
+		return domain.WithOperation(err, "delete_oauth_consent")
+	}
+
+	logCtx.Info("OAuth consent revoked")
+	return nil
+}
+
+// OAuthToken handles a token endpoint request from an authenticated client
+func (s *AuthServiceImpl) OAuthToken(ctx context.Context, req dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Service accounts act only as themselves, and only they do
+	if client.IsService() != (req.GrantType == "client_credentials") && req.GrantType != "" {
+		s.logger.With("client_id", client.ID).With("grant_type", req.GrantType).Warn("Grant type not allowed for OAuth client")
+		return nil, domain.NewOAuthError(domain.OAuthUnauthorizedClient, "The client may not use this grant type")
+	}
+
+	switch req.GrantType {
+	case "authorization_code":
+		return s.exchangeAuthorizationCode(ctx, client, req)
+	case "refresh_token":
+		return s.refreshClientToken(ctx, client, req)
+	case "client_credentials":
+		return s.issueServiceToken(ctx, client, req)
+	case "":
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "grant_type is required")
+	default:
+		return nil, domain.NewOAuthError(domain.OAuthUnsupportedGrantType, "Supported grant types are authorization_code, refresh_token and client_credentials")
+	}
+}
+
+// issueServiceToken issues a service account a token for the NATS subjects
+// it may call. There is no refresh token; the account asks for a new one.
+func (s *AuthServiceImpl) issueServiceToken(ctx context.Context, client *models.OAuthClient, req dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	logCtx := s.logger.With("client_id", client.ID).With("operation", "issue_service_token")
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !containsAll(client.Scopes, scopes) {
+		logCtx.With("scope", req.Scope).Warn("Service account requested scopes it doesn't hold")
+		return nil, domain.NewOAuthError(domain.OAuthInvalidScope, "The client may not request these scopes")
+	}
+
+	expiry := s.config.OAuth.ServiceTokenExpiry
+	token, err := s.jwtManager.GenerateServiceToken(client.ID, scopes, expiry)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to generate service token")
+		return nil, domain.NewInternalError("Failed to generate token")
+	}
+
+	logCtx.With("scope", strings.Join(scopes, " ")).Info("Service token issued")
+	return &dto.OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(expiry.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// exchangeAuthorizationCode redeems a code for tokens. Like a login, the
+// exchange starts a session, which is the family of the refresh tokens.
+func (s *AuthServiceImpl) exchangeAuthorizationCode(ctx context.Context, client *models.OAuthClient, req dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	logCtx := s.logger.With("client_id", client.ID).With("operation", "exchange_authorization_code")
+
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "code and code_verifier are required")
+	}
+
+	now := time.Now()
+	code, err := s.authRepo.ConsumeAuthorizationCode(ctx, hashAPIKey(req.Code), now)
+	if err != nil {
+		if domain.IsAuthorizationCodeNotFound(err) {
+			logCtx.Warn("Unknown authorization code presented")
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "Invalid authorization code")
+		}
+		logCtx.With("error", err.Error()).Error("Failed to consume authorization code")
+		return nil, domain.WithOperation(err, "consume_authorization_code")
+	}
+	logCtx = logCtx.With("user_id", code.UserID)
+
+	// A code presented twice has leaked, so the tokens it was exchanged for
+	// are revoked as well (RFC 6749 section 4.1.2)
+	if code.IsUsed() {
+		logCtx.With("session_id", code.SessionID).Warn("Authorization code reused, revoking its session")
+		if code.SessionID != "" {
+			s.endSession(ctx, code.SessionID)
+		}
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "Authorization code has already been used")
+	}
+	if code.ClientID != client.ID {
+		logCtx.Warn("Authorization code presented by another client")
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "Invalid authorization code")
+	}
+	if now.After(code.ExpiresAt) {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "Authorization code has expired")
+	}
+	if req.RedirectURI != "" && req.RedirectURI != code.RedirectURI {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "redirect_uri does not match the authorization request")
+	}
+	if !verifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		logCtx.Warn("PKCE verification failed")
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "code_verifier does not match the code challenge")
+	}
+
+	user, err := s.userClient.GetUser(ctx, code.UserID)
+	if err != nil || !user.IsActive() {
+		logCtx.Warn("Authorization code of a missing or inactive user")
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "The user can no longer sign in")
+	}
+
+	accessToken, refreshToken, err := s.jwtManager.GenerateClientTokenPair(user, client.ID, code.Scopes)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to generate tokens")
+		return nil, domain.WithOperation(err, "generate_tokens")
+	}
+
+	session := &models.Session{
+		ID:           uuid.New().String(),
+		UserID:       user.ID,
+		RefreshToken: refreshToken,
+		UserAgent:    req.UserAgent,
+		IPAddress:    req.IPAddress,
+		LastActive:   now,
+		ExpiresAt:    now.Add(s.config.Auth.RefreshTokenExpiry),
+		CreatedAt:    now,
+	}
+	if err := s.authRepo.CreateSession(ctx, session); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to create session")
+		return nil, domain.WithOperation(err, "create_session")
+	}
+
+	scope := strings.Join(code.Scopes, " ")
+	refreshTokenModel := &models.Token{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		FamilyID:  session.ID,
+		Type:      models.TokenTypeRefresh,
+		Value:     refreshToken,
+		ExpiresAt: now.Add(s.config.Auth.RefreshTokenExpiry),
+		CreatedAt: now,
+		Metadata: map[string]any{
+			"session_id": session.ID,
+			"user_agent": req.UserAgent,
+			"ip_address": req.IPAddress,
+			"client_id":  client.ID,
+			"scope":      scope,
+		},
+	}
+	if err := s.authRepo.CreateToken(ctx, refreshTokenModel); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to store refresh token")
+		return nil, domain.WithOperation(err, "store_refresh_token")
+	}
+
+	if err := s.authRepo.SetAuthorizationCodeSession(ctx, code.CodeHash, session.ID); err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to record the session of an authorization code")
+	}
+
+	accessExpiry, _ := s.jwtManager.GetTokenExpiry()
+	response := &dto.OAuthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessExpiry.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}
+
+	if slices.Contains(code.Scopes, "openid") {
+		claims := oidcClaims(user, code.Scopes)
+		claims["auth_time"] = code.CreatedAt.Unix()
+		claims["sid"] = session.ID
+		if code.Nonce != "" {
+			claims["nonce"] = code.Nonce
+		}
+		response.IDToken, err = s.jwtManager.GenerateIDToken(s.config.OAuth.Issuer, client.ID, s.config.OAuth.IDTokenExpiry, claims)
+		if err != nil {
+			logCtx.With("error", err.Error()).Error("Failed to generate ID token")
+			return nil, domain.WithOperation(err, "generate_id_token")
+		}
+	}
+
+	logCtx.With("session_id", session.ID).Info("Authorization code exchanged")
+	return response, nil
+}
+
+// refreshClientToken rotates a refresh token issued to the client. Scopes
+// can't be narrowed on refresh; the tokens keep the scopes first granted.
+func (s *AuthServiceImpl) refreshClientToken(ctx context.Context, client *models.OAuthClient, req dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "refresh_token is required")
+	}
+
+	refreshed, err := s.refreshToken(ctx, dto.RefreshTokenRequest{
+		RefreshToken: req.RefreshToken,
+		UserAgent:    req.UserAgent,
+		IPAddress:    req.IPAddress,
+	}, client.ID)
+	if err != nil {
+		if domain.IsUnauthorized(err) || domain.IsNotFound(err) || domain.IsForbidden(err) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "Refresh token is invalid, expired or revoked")
+		}
+		return nil, err
+	}
+
+	return &dto.OAuthTokenResponse{
+		AccessToken:  refreshed.AccessToken,
+		TokenType:    refreshed.TokenType,
+		ExpiresIn:    refreshed.ExpiresIn,
+		RefreshToken: refreshed.RefreshToken,
+		Scope:        refreshed.Scope,
+	}, nil
+}
+
+// UserInfo returns the claims about the user that an access token's scopes
+// grant (OpenID Connect Core section 5.3)
+func (s *AuthServiceImpl) UserInfo(ctx context.Context, req dto.UserInfoRequest) (map[string]any, error) {
+	claims, err := s.jwtManager.ValidateAccessToken(req.AccessToken)
+	if err != nil {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidToken, "The access token is invalid or expired")
+	}
+	if !slices.Contains(claims.Scopes, "openid") {
+		return nil, domain.NewOAuthError(domain.OAuthInsufficientScope, "The access token was not granted the openid scope")
+	}
+
+	user, err := s.userClient.GetUser(ctx, claims.UserID)
+	if err != nil || !user.IsActive() {
+		s.logger.With("user_id", claims.UserID).Warn("Userinfo requested for a missing or inactive user")
+		return nil, domain.NewOAuthError(domain.OAuthInvalidToken, "The access token is invalid or expired")
+	}
+
+	return oidcClaims(user, claims.Scopes), nil
+}
+
+// Introspect reports whether a token is active (RFC 7662). Clients may only
+// introspect tokens issued to them; any other token is reported inactive.
+func (s *AuthServiceImpl) Introspect(ctx context.Context, req dto.IntrospectRequest) (*dto.IntrospectResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsConfidential() {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "Only confidential clients may introspect tokens")
+	}
+	if req.Token == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "token is required")
+	}
+
+	inactive := &dto.IntrospectResponse{Active: false}
+
+	// The token type hint only saves a lookup, so both types are tried
+	if claims, err := s.jwtManager.ValidateAccessToken(req.Token); err == nil {
+		if claims.Audience != client.ID {
+			return inactive, nil
+		}
+		if user, err := s.userClient.GetUser(ctx, claims.UserID); err != nil || !user.IsActive() {
+			return inactive, nil
+		}
+		return &dto.IntrospectResponse{
+			Active:    true,
+			Scope:     strings.Join(claims.Scopes, " "),
+			ClientID:  claims.Audience,
+			Username:  claims.Username,
+			TokenType: "Bearer",
+			ExpiresAt: claims.ExpiresAt,
+			IssuedAt:  claims.IssuedAt,
+			Subject:   claims.UserID,
+			Audience:  claims.Audience,
+			Issuer:    claims.Issuer,
+		}, nil
+	}
+
+	if _, _, err := s.jwtManager.ValidateRefreshToken(req.Token); err == nil {
+		token, err := s.authRepo.GetRefreshToken(ctx, req.Token)
+		if err != nil || token.RevokedAt != nil {
+			return inactive, nil
+		}
+		if issuedTo, _ := token.Metadata["client_id"].(string); issuedTo != client.ID {
+			return inactive, nil
+		}
+		scope, _ := token.Metadata["scope"].(string)
+		return &dto.IntrospectResponse{
+			Active:    true,
+			Scope:     scope,
+			ClientID:  client.ID,
+			TokenType: "refresh_token",
+			ExpiresAt: token.ExpiresAt.Unix(),
+			IssuedAt:  token.CreatedAt.Unix(),
+			Subject:   token.UserID,
+			Audience:  client.ID,
+		}, nil
+	}
+
+	return inactive, nil
+}
+
+// Discovery returns the OpenID Provider metadata. Endpoints are the gateway's,
+// under the configured issuer.
+func (s *AuthServiceImpl) Discovery(ctx context.Context) (*dto.DiscoveryDocument, error) {
+	issuer := s.config.OAuth.Issuer
+	return &dto.DiscoveryDocument{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             s.config.OAuth.AuthorizePage,
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserInfoEndpoint:                  issuer + "/oauth/userinfo",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		IntrospectionEndpoint:             issuer + "/oauth/introspect",
+		ScopesSupported:                   oidcScopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{s.config.Signing.Algorithm},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "sid",
+			"name", "given_name", "family_name", "preferred_username", "updated_at",
+			"email", "email_verified", "phone_number",
+		},
+	}, nil
+}
+
+// authenticateClient checks a client's credentials. Public clients have no
+// secret; PKCE stands in for it.
+func (s *AuthServiceImpl) authenticateClient(ctx context.Context, clientID, secret string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "Client authentication is required")
+	}
+
+	client, err := s.authRepo.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if domain.IsOAuthClientNotFound(err) {
+			s.logger.With("client_id", clientID).Warn("Unknown OAuth client")
+			return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "Client authentication failed")
+		}
+		return nil, domain.WithOperation(err, "get_oauth_client")
+	}
+	if !client.IsActive() {
+		s.logger.With("client_id", clientID).Warn("Revoked OAuth client attempted to authenticate")
+		return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "Client authentication failed")
+	}
+
+	if client.IsConfidential() {
+		if secret == "" || subtle.ConstantTimeCompare([]byte(hashAPIKey(secret)), []byte(client.SecretHash)) != 1 {
+			s.logger.With("client_id", clientID).Warn("OAuth client secret mismatch")
+			return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "Client authentication failed")
+		}
+	} else if secret != "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "Public clients have no secret")
+	}
+
+	return client, nil
+}
+
+// endSession revokes a session's refresh tokens and deletes it
+func (s *AuthServiceImpl) endSession(ctx context.Context, sessionID string) {
+	logCtx := s.logger.With("session_id", sessionID)
+	if _, err := s.authRepo.RevokeTokenFamily(ctx, sessionID); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to revoke token family")
+	}
+	if err := s.authRepo.DeleteSession(ctx, sessionID); err != nil && !domain.IsSessionNotFound(err) {
+		logCtx.With("error", err.Error()).Error("Failed to delete session")
+	}
+}
+
+// oidcClaims returns the standard claims about a user that the scopes
+// grant (OpenID Connect Core section 5.4)
+func oidcClaims(user *models.User, scopes []string) map[string]any {
+	claims := map[string]any{"sub": user.ID}
+	if slices.Contains(scopes, "profile") {
+		claims["name"] = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		claims["given_name"] = user.FirstName
+		claims["family_name"] = user.LastName
+		claims["preferred_username"] = user.Username
+		claims["updated_at"] = user.UpdatedAt.Unix()
+	}
+	if slices.Contains(scopes, "email") {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.EmailVerified
+	}
+	if slices.Contains(scopes, "phone") && user.Phone != "" {
+		claims["phone_number"] = user.Phone
+	}
+	return claims
+}
+
+// verifyPKCE checks a code verifier against its S256 challenge (RFC 7636
+// section 4.6)
+func verifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < codeVerifierMinLength || len(verifier) > codeVerifierMaxLength {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// validCodeChallenge reports whether a challenge is an encoded SHA-256 hash
+func validCodeChallenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// validateRedirectURI accepts HTTPS URIs, HTTP on the loopback interface and
+// the reverse-domain schemes of native apps (RFC 8252 section 7). Fragments
+// are never allowed (RFC 6749 section 3.1.2).
+func validateRedirectURI(uri string) error {
+	invalid := domain.NewInvalidAuthInputWithValidation("Invalid redirect URI", map[string]string{
+		"redirectUris": "redirect URI " + uri + " must be an absolute https URI without a fragment",
+	})
+
+	u, err := url.Parse(uri)
+	if err != nil || u.Fragment != "" {
+		return invalid
+	}
+	switch u.Scheme {
+	case "https":
+		if u.Host == "" {
+			return invalid
+		}
+	case "http":
+		if host := u.Hostname(); host != "localhost" && host != "127.0.0.1" && host != "::1" {
+			return invalid
+		}
+	default:
+		if !strings.Contains(u.Scheme, ".") {
+			return invalid
+		}
+	}
+	return nil
+}
+
+// validateClientScopes checks a client's scopes are OpenID scopes or API
+// scopes. Unlike API keys, clients can't be granted every permission with "*".
+func validateClientScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return domain.NewInvalidAuthInputError("At least one scope is required", nil)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(oidcScopes, scope) && !isPermissionScope(scope) {
+			return domain.NewInvalidAuthInputWithValidation("Invalid OAuth client scope", map[string]string{
+				"scopes": "scope " + scope + " must be an OpenID scope, resource:action or resource:*",
+			})
+		}
+	}
+	return nil
+}
+
+// validateServiceScopes checks a service account's scopes are NATS subject
+// patterns, such as "user.get" or "user.>"
+func validateServiceScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return domain.NewInvalidAuthInputError("At least one scope is required", nil)
+	}
+	for _, scope := range scopes {
+		if !serviceauth.ValidScope(scope) {
+			return domain.NewInvalidAuthInputWithValidation("Invalid service account scope", map[string]string{
+				"scopes": "scope " + scope + " must be a NATS subject, optionally with * or a trailing > wildcard",
+			})
+		}
+	}
+	return nil
+}
+
+// redirectWith adds parameters and the client's state to a redirect URI
+func redirectWith(redirectURI, state string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	query := u.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// containsAll reports whether every scope in want is in have
+func containsAll(have, want []string) bool {
+	for _, scope := range want {
+		if !slices.Contains(have, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// union returns the scopes in either list, in order of first appearance
+func union(a, b []string) []string {
+	merged := slices.Clone(a)
+	for _, scope := range b {
+		if !slices.Contains(merged, scope) {
+			merged = append(merged, scope)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,105 @@
+// services/auth-service/internal/service/password_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// checkNewPassword applies the password policy to a password being set for
+// user, reporting violations under the request field it came in. Users that
+// exist also may not reuse one of their recent passwords; user.Password is
+// their current hash, if known.
+func (s *AuthServiceImpl) checkNewPassword(ctx context.Context, field, password string, user *models.User, logCtx log.Logger) error {
+	var violations []string
+	if s.passwords != nil {
+		violations = s.passwords.Check(password, user.Username, user.Email)
+	}
+
+	if user.ID != "" && s.config.Password.HistorySize > 0 {
+		reused, err := s.passwordReused(ctx, user, password)
+		if err != nil {
+			logCtx.With("error", err.Error()).Warn("Failed to check password history")
+		} else if reused {
+			violations = append(violations,
+				fmt.Sprintf("Password must not match any of your last %d passwords", s.config.Password.HistorySize))
+		}
+	}
+
+	if len(violations) > 0 {
+		logCtx.With("violations", len(violations)).Warn("Password rejected by password policy")
+		return domain.NewPasswordPolicyError(field, violations)
+	}
+	return nil
+}
+
+// passwordReused reports whether a password matches the user's current one
+// or one in their history
+func (s *AuthServiceImpl) passwordReused(ctx context.Context, user *models.User, password string) (bool, error) {
+	history, err := s.authRepo.ListPasswordHistory(ctx, user.ID, s.config.Password.HistorySize)
+	if err != nil {
+		return false, err
+	}
+
+	// The current password is usually the newest entry too; bcrypt is slow
+	// enough not to compare it twice
+	hashes := make(map[string]struct{}, len(history)+1)
+	if user.Password != "" {
+		hashes[user.Password] = struct{}{}
+	}
+	for _, entry := range history {
+		hashes[entry.PasswordHash] = struct{}{}
+	}
+
+	for hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordPassword adds a password just set to the user's history, which
+// also dates it for the maximum age
+func (s *AuthServiceImpl) recordPassword(ctx context.Context, userID, hash string, logCtx log.Logger) {
+	if s.config.Password.HistorySize <= 0 && s.config.Password.MaxAge <= 0 {
+		return
+	}
+
+	entry := &models.PasswordHistoryEntry{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.authRepo.AddPasswordHistory(ctx, entry, s.config.Password.HistorySize); err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to record password history")
+	}
+}
+
+// passwordExpired reports whether a user's password is older than the
+// maximum age. Passwords set before history was kept date from the account.
+func (s *AuthServiceImpl) passwordExpired(ctx context.Context, user *models.User, logCtx log.Logger) bool {
+	if s.config.Password.MaxAge <= 0 {
+		return false
+	}
+
+	history, err := s.authRepo.ListPasswordHistory(ctx, user.ID, 1)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to check password age")
+		return false
+	}
+
+	setAt := user.CreatedAt
+	if len(history) > 0 {
+		setAt = history[0].CreatedAt
+	}
+	return time.Since(setAt) > s.config.Password.MaxAge
+}
@@ -0,0 +1,570 @@
+// services/auth-service/internal/service/policy_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+)
+
+// Grant conditions refer to the caller's attributes as "subject.<name>" and
+// to the resource's as "resource.<name>"
+const (
+	subjectAttributePrefix  = "subject."
+	resourceAttributePrefix = "resource."
+)
+
+// Limits on the attributes stored for a user
+const (
+	maxUserAttributes        = 32
+	maxUserAttributeNameLen  = 64
+	maxUserAttributeValueLen = 255
+)
+
+// builtinSubjectAttributes are known for every user and can't be replaced by
+// stored attributes
+var builtinSubjectAttributes = map[string]bool{
+	"id":       true,
+	"username": true,
+	"email":    true,
+	"role":     true,
+	"roles":    true,
+}
+
+// EvaluatePolicy decides whether a user may perform an action on a resource
+// with the given attributes. Any grant of the permission, held directly or
+// inherited, allows it when all of its conditions hold. The decision lists
+// every grant considered, so it doubles as the explanation of why access was
+// granted or denied.
+func (s *AuthServiceImpl) EvaluatePolicy(ctx context.Context, req dto.EvaluatePolicyRequest) (*dto.PolicyDecision, error) {
+	permission := req.Resource + ":" + req.Action
+	logCtx := s.logger.With("user_id", req.UserID).With("permission", permission).With("operation", "evaluate_policy")
+	logCtx.Debug("Evaluating policy")
+
+	subject, err := s.loadPolicySubject(ctx, req.UserID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to resolve user roles")
+		return nil, domain.WithOperation(err, "load_policy_subject")
+	}
+
+	grants, err := s.authRepo.GetPermissionGrants(ctx, subject.roleIDs())
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to get permission grants")
+		return nil, domain.WithOperation(err, "get_permission_grants")
+	}
+
+	env := policyEnvironment{subject: subject.environment(), resource: req.Attributes}
+	decision := &dto.PolicyDecision{
+		Permission: permission,
+		Roles:      subject.roleNames(),
+		Subject:    env.subject,
+		Grants:     []dto.GrantEvaluation{},
+	}
+
+	for _, grant := range grants {
+		if grant.Resource != req.Resource || grant.Action != req.Action {
+			continue
+		}
+
+		evaluation := dto.GrantEvaluation{
+			Role:         grant.RoleName,
+			InheritedVia: subject.via[grant.RoleID],
+			Applies:      true,
+		}
+		for _, condition := range grant.Conditions {
+			result := env.evaluate(condition)
+			evaluation.Applies = evaluation.Applies && result.Satisfied
+			evaluation.Conditions = append(evaluation.Conditions, result)
+		}
+		decision.Grants = append(decision.Grants, evaluation)
+
+		if evaluation.Applies && !decision.Allowed {
+			decision.Allowed = true
+			decision.Reason = grantReason(evaluation)
+		}
+	}
+
+	if !decision.Allowed {
+		switch {
+		case len(decision.Roles) == 0:
+			decision.Reason = "The user holds no roles"
+		case len(decision.Grants) == 0:
+			decision.Reason = fmt.Sprintf("None of the roles %s grant %s", strings.Join(decision.Roles, ", "), permission)
+		default:
+			decision.Reason = fmt.Sprintf("The conditions of every grant of %s failed", permission)
+		}
+	}
+
+	logCtx.With("allowed", decision.Allowed).Debug("Policy evaluated")
+	return decision, nil
+}
+
+// ListRoles lists the roles with the roles each inherits from
+func (s *AuthServiceImpl) ListRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	logCtx := s.logger.With("operation", "list_roles")
+
+	graph, err := s.loadRoleGraph(ctx)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to list roles")
+		return nil, domain.WithOperation(err, "list_roles")
+	}
+
+	responses := make([]dto.RoleResponse, 0, len(graph.roles))
+	for _, role := range graph.roles {
+		var inherits []string
+		for _, ancestor := range graph.ancestors(role) {
+			inherits = append(inherits, ancestor.Name)
+		}
+		responses = append(responses, dto.FromRole(role, inherits))
+	}
+
+	return responses, nil
+}
+
+// SetRoleParent makes a role inherit another role's permissions, or stop
+// inheriting when no parent is given. A role can't end up inheriting from
+// itself.
+func (s *AuthServiceImpl) SetRoleParent(ctx context.Context, req dto.SetRoleParentRequest) error {
+	logCtx := s.logger.With("role_id", req.RoleID).With("parent_id", req.ParentID).With("operation", "set_role_parent")
+	logCtx.Info("Processing set role parent request")
+
+	graph, err := s.loadRoleGraph(ctx)
+	if err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to load roles")
+		return domain.WithOperation(err, "list_roles")
+	}
+
+	role := graph.find(req.RoleID)
+	if role == nil {
+		return domain.NewRoleNotFoundError(req.RoleID)
+	}
+
+	var parentID string
+	if req.ParentID != "" {
+		parent := graph.find(req.ParentID)
+		if parent == nil {
+			return domain.NewRoleNotFoundError(req.ParentID)
+		}
+		if parent.ID == role.ID {
+			return domain.NewInvalidAuthInputError("A role can't inherit from itself", nil)
+		}
+		for _, ancestor := range graph.ancestors(parent) {
+			if ancestor.ID == role.ID {
+				logCtx.Warn("Role parent would create an inheritance cycle")
+				return domain.NewInvalidAuthInputError(
+					fmt.Sprintf("Role %s already inherits from %s", parent.Name, role.Name), nil)
+			}
+		}
+		parentID = parent.ID
+	}
+
+	if err := s.authRepo.SetRoleParent(ctx, role.ID, parentID); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to set role parent")
+		return domain.WithOperation(err, "set_role_parent")
+	}
+
+	logCtx.Info("Role parent set")
+	s.publishPermissionsChanged(ctx, dto.PermissionsChangedEvent{RoleID: role.ID, Change: "parent_changed"})
+	return nil
+}
+
+// GetUserRoles lists the roles a user holds, directly and by inheritance,
+// with the attributes policies know them by
+func (s *AuthServiceImpl) GetUserRoles(ctx context.Context, userID string) (*dto.UserRolesResponse, error) {
+	logCtx := s.logger.With("user_id", userID).With("operation", "get_user_roles")
+
+	subject, err := s.loadPolicySubject(ctx, userID)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to resolve user roles")
+		return nil, domain.WithOperation(err, "load_policy_subject")
+	}
+
+	response := &dto.UserRolesResponse{
+		UserID:      userID,
+		ProfileRole: string(subject.user.Role),
+		Assigned:    make([]dto.UserRoleInfo, 0, len(subject.assigned)),
+		Effective:   subject.roleNames(),
+		Attributes:  subject.attributes,
+	}
+	for _, userRole := range subject.assigned {
+		info := dto.UserRoleInfo{
+			RoleID:     userRole.RoleID,
+			AssignedAt: userRole.AssignedAt,
+			AssignedBy: userRole.AssignedBy,
+		}
+		if role := subject.graph.byID[userRole.RoleID]; role != nil {
+			info.RoleName = role.Name
+		}
+		response.Assigned = append(response.Assigned, info)
+	}
+
+	return response, nil
+}
+
+// AssignUserRole gives a user a role on top of the role on their profile
+func (s *AuthServiceImpl) AssignUserRole(ctx context.Context, req dto.AssignUserRoleRequest) error {
+	logCtx := s.logger.With("user_id", req.UserID).With("role", req.Role).With("operation", "assign_user_role")
+	logCtx.Info("Processing assign user role request")
+
+	if _, err := s.userClient.GetUser(ctx, req.UserID); err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found")
+		return domain.NewUserNotFoundError(req.UserID)
+	}
+
+	role, err := s.authRepo.GetRole(ctx, req.Role)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Role not found")
+		return domain.WithOperation(err, "get_role")
+	}
+
+	userRole := &models.UserRole{
+		UserID:     req.UserID,
+		RoleID:     role.ID,
+		AssignedAt: time.Now(),
+		AssignedBy: req.AssignedBy,
+	}
+	if err := s.authRepo.AssignUserRole(ctx, userRole); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to assign role")
+		return domain.WithOperation(err, "assign_user_role")
+	}
+
+	logCtx.Info("Role assigned to user")
+	s.publishPermissionsChanged(ctx, dto.PermissionsChangedEvent{RoleID: role.ID, UserID: req.UserID, Change: "role_assigned"})
+	return nil
+}
+
+// RevokeUserRole removes a role assigned to a user. The role on the user's
+// profile is managed by user-service and can't be revoked here.
+func (s *AuthServiceImpl) RevokeUserRole(ctx context.Context, req dto.RevokeUserRoleRequest) error {
+	logCtx := s.logger.With("user_id", req.UserID).With("role", req.Role).With("operation", "revoke_user_role")
+	logCtx.Info("Processing revoke user role request")
+
+	role, err := s.authRepo.GetRole(ctx, req.Role)
+	if err != nil {
+		logCtx.With("error", err.Error()).Warn("Role not found")
+		return domain.WithOperation(err, "get_role")
+	}
+
+	if err := s.authRepo.RevokeUserRole(ctx, req.UserID, role.ID); err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to revoke role")
+		return domain.WithOperation(err, "revoke_user_role")
+	}
+
+	logCtx.Info("Role revoked from user")
+	s.publishPermissionsChanged(ctx, dto.PermissionsChangedEvent{RoleID: role.ID, UserID: req.UserID, Change: "role_revoked"})
+	return nil
+}
+
+// SetUserAttributes replaces the attributes grant conditions can refer to as
+// subject.<name>, e.g. the entity a dispatcher works for
+func (s *AuthServiceImpl) SetUserAttributes(ctx context.Context, req dto.SetUserAttributesRequest) error {
+	logCtx := s.logger.With("user_id", req.UserID).With("operation", "set_user_attributes")
+	logCtx.Info("Processing set user attributes request")
+
+	if len(req.Attributes) > maxUserAttributes {
+		return domain.NewInvalidAuthInputError(fmt.Sprintf("A user can have at most %d attributes", maxUserAttributes), nil)
+	}
+	for name, value := range req.Attributes {
+		switch {
+		case name == "" || len(name) > maxUserAttributeNameLen || strings.ContainsAny(name, ". "):
+			return domain.NewInvalidAuthInputError(fmt.Sprintf("Invalid attribute name %q", name), nil)
+		case builtinSubjectAttributes[name]:
+			return domain.NewInvalidAuthInputError(fmt.Sprintf("Attribute %q is reserved", name), nil)
+		case len(value) > maxUserAttributeValueLen:
+			return domain.NewInvalidAuthInputError(fmt.Sprintf("Attribute %q is too long", name), nil)
+		}
+	}
+
+	if _, err := s.userClient.GetUser(ctx, req.UserID); err != nil {
+		logCtx.With("error", err.Error()).Warn("User not found")
+		return domain.NewUserNotFoundError(req.UserID)
+	}
+
+	if err := s.authRepo.SetUserAttributes(ctx, req.UserID, req.Attributes); err != nil {
+		logCtx.With("error", err.Error()).Error("Failed to set user attributes")
+		return domain.WithOperation(err, "set_user_attributes")
+	}
+
+	logCtx.With("attribute_count", len(req.Attributes)).Info("User attributes set")
+	return nil
+}
+
+// roleGraph indexes the roles by ID and name to walk their inheritance
+type roleGraph struct {
+	roles  []*models.RoleDefinition
+	byID   map[string]*models.RoleDefinition
+	byName map[string]*models.RoleDefinition
+}
+
+func (s *AuthServiceImpl) loadRoleGraph(ctx context.Context) (*roleGraph, error) {
+	roles, err := s.authRepo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &roleGraph{
+		roles:  roles,
+		byID:   make(map[string]*models.RoleDefinition, len(roles)),
+		byName: make(map[string]*models.RoleDefinition, len(roles)),
+	}
+	for _, role := range roles {
+		graph.byID[role.ID] = role
+		graph.byName[role.Name] = role
+	}
+	return graph, nil
+}
+
+// find looks a role up by ID or name
+func (g *roleGraph) find(idOrName string) *models.RoleDefinition {
+	if role, ok := g.byID[idOrName]; ok {
+		return role
+	}
+	return g.byName[idOrName]
+}
+
+// ancestors returns the roles a role inherits from, nearest first. The walk
+// stops at a cycle, which SetRoleParent refuses to create.
+func (g *roleGraph) ancestors(role *models.RoleDefinition) []*models.RoleDefinition {
+	var chain []*models.RoleDefinition
+	seen := map[string]bool{role.ID: true}
+	for parent := g.byID[role.ParentID]; parent != nil && !seen[parent.ID]; parent = g.byID[parent.ParentID] {
+		seen[parent.ID] = true
+		chain = append(chain, parent)
+	}
+	return chain
+}
+
+// policySubject is what the policy engine knows about a user
+type policySubject struct {
+	user       *models.User
+	graph      *roleGraph
+	assigned   []*models.UserRole
+	attributes map[string]string
+	// roles holds every role the user has, held roles first, each once
+	roles []*models.RoleDefinition
+	// via maps an inherited role's ID to the held role it came through
+	via map[string]string
+}
+
+// loadPolicySubject resolves a user's roles: the role on their profile, the
+// roles assigned to them, and every role these inherit from
+func (s *AuthServiceImpl) loadPolicySubject(ctx context.Context, userID string) (*policySubject, error) {
+	user, err := s.userClient.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := s.loadRoleGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned, err := s.authRepo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := s.authRepo.GetUserAttributes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := &policySubject{
+		user:       user,
+		graph:      graph,
+		assigned:   assigned,
+		attributes: attributes,
+		via:        make(map[string]string),
+	}
+
+	var held []*models.RoleDefinition
+	if role := graph.find(string(user.Role)); role != nil {
+		held = append(held, role)
+	}
+	for _, userRole := range assigned {
+		if role := graph.byID[userRole.RoleID]; role != nil {
+			held = append(held, role)
+		}
+	}
+
+	// Add the held roles first, so a role held directly isn't reported as
+	// inherited through another
+	seen := make(map[string]bool)
+	for _, role := range held {
+		if !seen[role.ID] {
+			seen[role.ID] = true
+			subject.roles = append(subject.roles, role)
+		}
+	}
+	for _, role := range held {
+		for _, ancestor := range graph.ancestors(role) {
+			if seen[ancestor.ID] {
+				continue
+			}
+			seen[ancestor.ID] = true
+			subject.roles = append(subject.roles, ancestor)
+			subject.via[ancestor.ID] = role.Name
+		}
+	}
+
+	return subject, nil
+}
+
+func (p *policySubject) roleIDs() []string {
+	ids := make([]string, len(p.roles))
+	for i, role := range p.roles {
+		ids[i] = role.ID
+	}
+	return ids
+}
+
+func (p *policySubject) roleNames() []string {
+	names := make([]string, len(p.roles))
+	for i, role := range p.roles {
+		names[i] = role.Name
+	}
+	return names
+}
+
+// environment returns the attributes conditions refer to as subject.<name>
+func (p *policySubject) environment() map[string]any {
+	env := make(map[string]any, len(p.attributes)+len(builtinSubjectAttributes))
+	for name, value := range p.attributes {
+		env[name] = value
+	}
+	env["id"] = p.user.ID
+	env["username"] = p.user.Username
+	env["email"] = p.user.Email
+	env["role"] = string(p.user.Role)
+	env["roles"] = p.roleNames()
+	return env
+}
+
+// policyEnvironment holds the attributes grant conditions are evaluated
+// against: the caller's and those of the resource acted on
+type policyEnvironment struct {
+	subject  map[string]any
+	resource map[string]any
+}
+
+// evaluate evaluates a grant condition. Missing attributes never satisfy a
+// condition, so grants conditioned on a resource don't apply without it.
+func (e policyEnvironment) evaluate(condition models.PolicyCondition) dto.ConditionResult {
+	result := dto.ConditionResult{PolicyCondition: condition}
+
+	left, ok := e.resolve(condition.Attribute)
+	if !ok {
+		result.Detail = condition.Attribute + " is not set"
+		return result
+	}
+
+	var right any = condition.Value
+	if isAttributeReference(condition.Value) {
+		if right, ok = e.resolve(condition.Value); !ok {
+			result.Detail = condition.Value + " is not set"
+			return result
+		}
+	} else if condition.Operator == models.PolicyOpIn {
+		right = strings.Split(condition.Value, ",")
+	}
+
+	switch condition.Operator {
+	case models.PolicyOpEquals:
+		result.Satisfied = attributeEqual(left, right)
+	case models.PolicyOpNotEquals:
+		result.Satisfied = !attributeEqual(left, right)
+	case models.PolicyOpIn:
+		result.Satisfied = attributeContains(right, left)
+	case models.PolicyOpContains:
+		result.Satisfied = attributeContains(left, right)
+	}
+
+	result.Detail = fmt.Sprintf("%s (%v) %s %s (%v)", condition.Attribute, left, condition.Operator, condition.Value, right)
+	return result
+}
+
+// resolve looks up a subject or resource attribute
+func (e policyEnvironment) resolve(name string) (any, bool) {
+	var attributes map[string]any
+	switch {
+	case strings.HasPrefix(name, subjectAttributePrefix):
+		attributes, name = e.subject, strings.TrimPrefix(name, subjectAttributePrefix)
+	case strings.HasPrefix(name, resourceAttributePrefix):
+		attributes, name = e.resource, strings.TrimPrefix(name, resourceAttributePrefix)
+	default:
+		return nil, false
+	}
+
+	value, ok := attributes[name]
+	if !ok || value == nil || value == "" {
+		return nil, false
+	}
+	return value, true
+}
+
+// validateCondition checks that a grant condition is well formed
+func validateCondition(condition models.PolicyCondition) error {
+	if !isAttributeReference(condition.Attribute) {
+		return fmt.Errorf("condition attribute %q must start with %q or %q",
+			condition.Attribute, subjectAttributePrefix, resourceAttributePrefix)
+	}
+	switch condition.Operator {
+	case models.PolicyOpEquals, models.PolicyOpNotEquals, models.PolicyOpIn, models.PolicyOpContains:
+	default:
+		return fmt.Errorf("unsupported condition operator %q", condition.Operator)
+	}
+	if condition.Value == "" {
+		return fmt.Errorf("condition on %q has no value", condition.Attribute)
+	}
+	return nil
+}
+
+func isAttributeReference(name string) bool {
+	return strings.HasPrefix(name, subjectAttributePrefix) || strings.HasPrefix(name, resourceAttributePrefix)
+}
+
+// attributeEqual compares attribute values by their string form, so IDs
+// match whether they were decoded as strings or numbers
+func attributeEqual(a, b any) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// attributeContains reports whether a list attribute holds a value. A scalar
+// is treated as a list of one.
+func attributeContains(list, value any) bool {
+	switch items := list.(type) {
+	case []any:
+		for _, item := range items {
+			if attributeEqual(item, value) {
+				return true
+			}
+		}
+		return false
+	case []string:
+		for _, item := range items {
+			if attributeEqual(strings.TrimSpace(item), value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return attributeEqual(list, value)
+	}
+}
+
+// grantReason explains why an applicable grant allows the action
+func grantReason(evaluation dto.GrantEvaluation) string {
+	reason := "Granted by role " + evaluation.Role
+	if evaluation.InheritedVia != "" {
+		reason += " (inherited through " + evaluation.InheritedVia + ")"
+	}
+	if len(evaluation.Conditions) > 0 {
+		reason += " with all conditions met"
+	}
+	return reason
+}
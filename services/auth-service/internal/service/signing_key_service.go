@@ -0,0 +1,292 @@
+// services/auth-service/internal/service/signing_key_service.go
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/jwks"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	natsclient "github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/auth-service/internal/config"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/0xsj/fn-go/services/auth-service/internal/dto"
+	"github.com/0xsj/fn-go/services/auth-service/internal/repository"
+	"github.com/0xsj/fn-go/services/auth-service/pkg/jwt"
+	"github.com/0xsj/fn-go/services/auth-service/pkg/secretbox"
+	"github.com/google/uuid"
+)
+
+// SigningKeyManager keeps the JWT manager's key set in step with the keys
+// stored in the database and rotates them on schedule.
+//
+// A rotation creates a key that is published PublishLead before it signs, so
+// verifiers caching the JWKS document learn it first, and retires the keys it
+// replaces after Overlap, once the tokens they signed have expired. Every
+// instance reloads the stored keys each RefreshInterval, so a key created by
+// one is used by all.
+type SigningKeyManager struct {
+	repo     repository.SigningKeyRepository
+	keys     *jwt.KeySet
+	box      *secretbox.Box
+	events   EventPublisher
+	config   config.SigningConfig
+	logger   log.Logger
+	rotating sync.Mutex
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewSigningKeyManager creates a signing key manager
+func NewSigningKeyManager(
+	repo repository.SigningKeyRepository,
+	keys *jwt.KeySet,
+	events EventPublisher,
+	cfg config.SigningConfig,
+	logger log.Logger,
+) (*SigningKeyManager, error) {
+	if err := jwt.ValidateAlgorithm(cfg.Algorithm); err != nil {
+		return nil, domain.Wrap(err, "invalid signing configuration")
+	}
+	box, err := secretbox.New(cfg.EncryptionKey)
+	if err != nil {
+		return nil, domain.Wrap(err, "failed to create signing key cipher")
+	}
+
+	return &SigningKeyManager{
+		repo:   repo,
+		keys:   keys,
+		box:    box,
+		events: events,
+		config: cfg,
+		logger: logger.WithLayer("signing-key-manager"),
+	}, nil
+}
+
+// Start loads the stored keys, creating the first one if there are none,
+// then reloads and rotates them in the background until Close
+func (m *SigningKeyManager) Start(ctx context.Context) error {
+	stored, err := m.reload(ctx)
+	if err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		m.logger.Info("No signing keys found, creating the first one")
+		if _, err := m.createKey(ctx, time.Now()); err != nil {
+			return err
+		}
+		if _, err := m.reload(ctx); err != nil {
+			return err
+		}
+	}
+
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.run()
+	return nil
+}
+
+// Close stops the background reloads and rotations
+func (m *SigningKeyManager) Close(ctx context.Context) error {
+	if m.stop == nil {
+		return nil
+	}
+	close(m.stop)
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *SigningKeyManager) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx := context.Background()
+			stored, err := m.reload(ctx)
+			if err != nil {
+				m.logger.With("error", err.Error()).Error("Failed to reload signing keys")
+				continue
+			}
+			if m.rotationDue(stored, time.Now()) {
+				if _, err := m.RotateSigningKey(ctx); err != nil {
+					m.logger.With("error", err.Error()).Error("Scheduled signing key rotation failed")
+				}
+			}
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// rotationDue reports whether the newest key, pending or not, has reached
+// the rotation interval
+func (m *SigningKeyManager) rotationDue(stored []*models.SigningKey, now time.Time) bool {
+	if m.config.RotationInterval <= 0 || len(stored) == 0 {
+		return false
+	}
+	newest := stored[len(stored)-1]
+	return !now.Before(newest.ActivatesAt.Add(m.config.RotationInterval))
+}
+
+// JWKS returns the published public keys
+func (m *SigningKeyManager) JWKS(ctx context.Context) (*jwks.Set, error) {
+	return m.keys.JWKS(time.Now())
+}
+
+// ListSigningKeys lists the published keys, oldest first
+func (m *SigningKeyManager) ListSigningKeys(ctx context.Context) ([]dto.SigningKeyInfo, error) {
+	now := time.Now()
+	stored, err := m.repo.ListSigningKeys(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var signingID string
+	if key, err := m.keys.SigningKey(now); err == nil {
+		signingID = key.ID
+	}
+
+	infos := make([]dto.SigningKeyInfo, 0, len(stored))
+	for _, key := range stored {
+		infos = append(infos, toSigningKeyInfo(key, key.ID == signingID))
+	}
+	return infos, nil
+}
+
+// RotateSigningKey creates a new signing key and schedules the current keys
+// for retirement
+func (m *SigningKeyManager) RotateSigningKey(ctx context.Context) (*dto.SigningKeyInfo, error) {
+	m.rotating.Lock()
+	defer m.rotating.Unlock()
+
+	now := time.Now()
+	activatesAt := now.Add(m.config.PublishLead)
+	key, err := m.createKey(ctx, activatesAt)
+	if err != nil {
+		return nil, err
+	}
+	logCtx := m.logger.With("kid", key.ID)
+
+	retired, err := m.repo.RetireSigningKeys(ctx, activatesAt, activatesAt.Add(m.config.Overlap))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.reload(ctx); err != nil {
+		return nil, err
+	}
+	m.publishRotation(ctx)
+
+	if deleted, err := m.repo.DeleteExpiredSigningKeys(ctx, now); err != nil {
+		logCtx.With("error", err.Error()).Warn("Failed to delete expired signing keys")
+	} else if deleted > 0 {
+		logCtx.With("count", deleted).Info("Deleted expired signing keys")
+	}
+
+	logCtx.With("activates_at", activatesAt).With("retired", retired).Info("Signing key rotated")
+	info := toSigningKeyInfo(key, false)
+	return &info, nil
+}
+
+// createKey generates a key and stores it with its private half sealed
+func (m *SigningKeyManager) createKey(ctx context.Context, activatesAt time.Time) (*models.SigningKey, error) {
+	signer, err := jwt.GenerateKey(m.config.Algorithm)
+	if err != nil {
+		return nil, domain.WithOperation(domain.Wrap(err, "failed to generate signing key"), "create_signing_key")
+	}
+	private, err := jwt.MarshalPrivateKey(signer)
+	if err != nil {
+		return nil, domain.WithOperation(domain.Wrap(err, "failed to encode signing key"), "create_signing_key")
+	}
+	public, err := jwt.MarshalPublicKey(signer.Public())
+	if err != nil {
+		return nil, domain.WithOperation(domain.Wrap(err, "failed to encode signing key"), "create_signing_key")
+	}
+
+	key := &models.SigningKey{
+		ID:          uuid.New().String(),
+		Algorithm:   m.config.Algorithm,
+		PublicKey:   public,
+		ActivatesAt: activatesAt,
+		CreatedAt:   time.Now(),
+	}
+	key.PrivateKeyEncrypted, err = m.box.Seal(private, []byte(key.ID))
+	if err != nil {
+		return nil, domain.WithOperation(domain.Wrap(err, "failed to encrypt signing key"), "create_signing_key")
+	}
+
+	if err := m.repo.CreateSigningKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// reload replaces the key set with the stored keys and returns them. A key
+// whose private half can't be opened still verifies but never signs.
+func (m *SigningKeyManager) reload(ctx context.Context) ([]*models.SigningKey, error) {
+	stored, err := m.repo.ListSigningKeys(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*jwt.Key, 0, len(stored))
+	for _, s := range stored {
+		logCtx := m.logger.With("kid", s.ID)
+
+		public, err := jwt.ParsePublicKey(s.PublicKey)
+		if err != nil {
+			logCtx.With("error", err.Error()).Error("Skipping signing key with unreadable public key")
+			continue
+		}
+		key := &jwt.Key{
+			ID:          s.ID,
+			Algorithm:   s.Algorithm,
+			Public:      public,
+			ActivatesAt: s.ActivatesAt,
+			ExpiresAt:   s.ExpiresAt,
+		}
+
+		if der, err := m.box.Open(s.PrivateKeyEncrypted, []byte(s.ID)); err != nil {
+			logCtx.With("error", err.Error()).Error("Failed to decrypt signing key, using it for verification only")
+		} else if key.Private, err = jwt.ParsePrivateKey(der); err != nil {
+			logCtx.With("error", err.Error()).Error("Failed to parse signing key, using it for verification only")
+		}
+		keys = append(keys, key)
+	}
+
+	m.keys.Replace(keys)
+	return stored, nil
+}
+
+// publishRotation announces the new key set so verifiers don't wait for
+// their next refresh
+func (m *SigningKeyManager) publishRotation(ctx context.Context) {
+	set, err := m.keys.JWKS(time.Now())
+	if err != nil {
+		m.logger.With("error", err.Error()).Error("Failed to build JWKS document")
+		return
+	}
+	if err := m.events.Publish(ctx, natsclient.SubjectAuthKeysRotated, set); err != nil {
+		m.logger.With("error", err.Error()).Warn("Failed to publish key rotation event")
+	}
+}
+
+func toSigningKeyInfo(key *models.SigningKey, signing bool) dto.SigningKeyInfo {
+	return dto.SigningKeyInfo{
+		ID:          key.ID,
+		Algorithm:   key.Algorithm,
+		Signing:     signing,
+		ActivatesAt: key.ActivatesAt,
+		ExpiresAt:   key.ExpiresAt,
+		CreatedAt:   key.CreatedAt,
+	}
+}
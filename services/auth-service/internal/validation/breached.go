@@ -0,0 +1,83 @@
+// services/auth-service/internal/validation/breached.go
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+)
+
+// breachedPrefixLength is the length of the SHA-1 prefix passwords are
+// bucketed by, as in the k-anonymity range queries of breach corpora
+const breachedPrefixLength = 5
+
+// BreachedList screens passwords against breached password SHA-1 hashes kept
+// on disk in k-anonymity buckets: one file per hash prefix, e.g. 21BD1.txt,
+// listing the rest of each hash in the bucket as SUFFIX or SUFFIX:COUNT per
+// line, the layout breach corpora's range downloads come in. Only the bucket
+// of the password being checked is read, so the corpus is never held in
+// memory, and passwords are only ever hashed, never compared in the clear.
+type BreachedList struct {
+	dir    string
+	logger log.Logger
+}
+
+// OpenBreachedList opens a directory of breached password buckets
+func OpenBreachedList(dir string, logger log.Logger) (*BreachedList, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s: breached password list must be a directory of prefix buckets", dir)
+	}
+
+	return &BreachedList{
+		dir:    dir,
+		logger: logger.WithLayer("breached-list"),
+	}, nil
+}
+
+// Contains reports whether a password is on the list. A bucket that can't be
+// read is logged and treated as not containing the password, so a damaged
+// corpus doesn't stop users from setting passwords.
+func (l *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+
+	found, err := l.searchBucket(prefix, suffix)
+	if err != nil {
+		l.logger.With("prefix", prefix).With("error", err.Error()).Error("Failed to read breached password bucket")
+		return false
+	}
+	return found
+}
+
+// searchBucket scans the bucket of a hash prefix for a suffix. A missing
+// bucket holds no hashes.
+func (l *BreachedList) searchBucket(prefix, suffix string) (bool, error) {
+	file, err := os.Open(filepath.Join(l.dir, prefix+".txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(entry, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
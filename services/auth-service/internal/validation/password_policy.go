@@ -0,0 +1,126 @@
+// services/auth-service/internal/validation/password_policy.go
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minSimilarLength is the shortest username or email part checked for in
+// passwords; shorter ones would match too much by chance
+const minSimilarLength = 3
+
+// PasswordPolicy is the content a password must have
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectSimilar bool // reject passwords containing the username or email
+}
+
+// PasswordChecker checks passwords against the policy and, when a breached
+// list is configured, rejects passwords known from breaches
+type PasswordChecker struct {
+	policy   PasswordPolicy
+	breached *BreachedList
+}
+
+// NewPasswordChecker creates a new password checker; breached may be nil
+func NewPasswordChecker(policy PasswordPolicy, breached *BreachedList) *PasswordChecker {
+	return &PasswordChecker{
+		policy:   policy,
+		breached: breached,
+	}
+}
+
+// Check returns every way a password breaks the policy, or nothing if it
+// complies. Identities are the username and email of the account the
+// password is for.
+func (c *PasswordChecker) Check(password string, identities ...string) []string {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if c.policy.MinLength > 0 && length < c.policy.MinLength {
+		violations = append(violations, fmt.Sprintf("Password must be at least %d characters long", c.policy.MinLength))
+	}
+	if c.policy.MaxLength > 0 && length > c.policy.MaxLength {
+		violations = append(violations, fmt.Sprintf("Password must not exceed %d characters", c.policy.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if c.policy.RequireUpper && !hasUpper {
+		violations = append(violations, "Password must contain at least one uppercase letter")
+	}
+	if c.policy.RequireLower && !hasLower {
+		violations = append(violations, "Password must contain at least one lowercase letter")
+	}
+	if c.policy.RequireDigit && !hasDigit {
+		violations = append(violations, "Password must contain at least one digit")
+	}
+	if c.policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, "Password must contain at least one symbol")
+	}
+
+	if c.policy.RejectSimilar && similarToIdentity(password, identities) {
+		violations = append(violations, "Password must not contain your username or email")
+	}
+
+	if c.breached != nil && c.breached.Contains(password) {
+		violations = append(violations, "Password has appeared in a data breach; choose a different one")
+	}
+
+	return violations
+}
+
+// Policy returns the policy passwords are checked against
+func (c *PasswordChecker) Policy() PasswordPolicy {
+	return c.policy
+}
+
+// similarToIdentity reports whether a password contains a username, an email
+// or the local part of one, ignoring case and punctuation
+func similarToIdentity(password string, identities []string) bool {
+	normalized := normalizeForSimilarity(password)
+	for _, identity := range identities {
+		candidates := []string{identity}
+		if local, _, ok := strings.Cut(identity, "@"); ok {
+			candidates = append(candidates, local)
+		}
+		for _, candidate := range candidates {
+			candidate = normalizeForSimilarity(candidate)
+			if len(candidate) >= minSimilarLength && strings.Contains(normalized, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeForSimilarity lowercases s and keeps only its letters and digits,
+// so "John.Smith" and "johnsmith" compare equal
+func normalizeForSimilarity(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
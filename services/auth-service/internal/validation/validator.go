@@ -60,13 +60,16 @@ type Validator interface {
 
 // AuthValidator handles auth-specific validation
 type AuthValidator struct {
-	logger log.Logger
+	passwords *PasswordChecker
+	logger    log.Logger
 }
 
-// NewAuthValidator creates a new auth validator
-func NewAuthValidator(logger log.Logger) *AuthValidator {
+// NewAuthValidator creates a new auth validator checking passwords against
+// the password policy
+func NewAuthValidator(passwords *PasswordChecker, logger log.Logger) *AuthValidator {
 	return &AuthValidator{
-		logger: logger.WithLayer("auth-validator"),
+		passwords: passwords,
+		logger:    logger.WithLayer("auth-validator"),
 	}
 }
 
@@ -160,10 +163,8 @@ func (v *AuthValidator) validateRegisterRequest(req dto.RegisterRequest) error {
 	// Password validation
 	if strings.TrimSpace(req.Password) == "" {
 		ve.Add("password", "Password is required")
-	} else {
-		if err := validatePassword(req.Password); err != nil {
-			ve.Add("password", err.Error())
-		}
+	} else if violations := v.passwords.Check(req.Password, req.Username, req.Email); len(violations) > 0 {
+		ve.Add("password", strings.Join(violations, "; "))
 	}
 
 	// First name validation
@@ -293,10 +294,8 @@ func (v *AuthValidator) validateChangePasswordRequest(req dto.ChangePasswordRequ
 
 	if strings.TrimSpace(req.NewPassword) == "" {
 		ve.Add("newPassword", "New password is required")
-	} else {
-		if err := validatePassword(req.NewPassword); err != nil {
-			ve.Add("newPassword", err.Error())
-		}
+	} else if violations := v.passwords.Check(req.NewPassword); len(violations) > 0 {
+		ve.Add("newPassword", strings.Join(violations, "; "))
 	}
 
 	// Check passwords are different
@@ -321,10 +320,8 @@ func (v *AuthValidator) validateResetPasswordRequest(req dto.ResetPasswordReques
 
 	if strings.TrimSpace(req.NewPassword) == "" {
 		ve.Add("newPassword", "New password is required")
-	} else {
-		if err := validatePassword(req.NewPassword); err != nil {
-			ve.Add("newPassword", err.Error())
-		}
+	} else if violations := v.passwords.Check(req.NewPassword); len(violations) > 0 {
+		ve.Add("newPassword", strings.Join(violations, "; "))
 	}
 
 	if ve.HasErrors() {
@@ -389,29 +386,6 @@ func (v *AuthValidator) validateAssignPermissionRequest(req dto.AssignPermission
 
 // Helper validation functions
 
-// validatePassword validates password strength
-func validatePassword(password string) error {
-	if len(password) < 8 {
-		return fmt.Errorf("password must be at least 8 characters long")
-	}
-
-	if len(password) > 128 {
-		return fmt.Errorf("password must not exceed 128 characters")
-	}
-
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	if !hasUpper {
-		return fmt.Errorf("password must contain at least one uppercase letter")
-	}
-
-	hasDigit := regexp.MustCompile(`[0-9]`).MatchString(password)
-	if !hasDigit {
-		return fmt.Errorf("password must contain at least one digit")
-	}
-
-	return nil
-}
-
 // isValidEmail validates email format
 func isValidEmail(email string) bool {
 	pattern := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
@@ -0,0 +1,4 @@
+-- services/auth-service/migrations/000002_api_keys.down.sql
+-- Rollback API keys
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,19 @@
+-- services/auth-service/migrations/000002_api_keys.up.sql
+-- API keys for machine clients
+
+CREATE TABLE api_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL UNIQUE,
+    key_hash CHAR(64) NOT NULL,
+    owner_id VARCHAR(36) NOT NULL,
+    scopes JSON NOT NULL,
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    rotated_from VARCHAR(36) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    INDEX idx_api_keys_owner_id (owner_id),
+    INDEX idx_api_keys_expires_at (expires_at)
+);
@@ -0,0 +1,4 @@
+-- services/auth-service/migrations/000003_mfa.down.sql
+-- Rollback TOTP second factors
+
+DROP TABLE IF EXISTS mfa_enrollments;
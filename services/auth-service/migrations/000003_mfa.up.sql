@@ -0,0 +1,12 @@
+-- services/auth-service/migrations/000003_mfa.up.sql
+-- TOTP second factors
+
+CREATE TABLE mfa_enrollments (
+    user_id VARCHAR(36) PRIMARY KEY,
+    secret_encrypted VARBINARY(255) NOT NULL,
+    recovery_codes JSON NOT NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
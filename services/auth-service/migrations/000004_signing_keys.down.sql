@@ -0,0 +1,10 @@
+-- services/auth-service/migrations/000004_signing_keys.down.sql
+-- Rollback asymmetric token signing keys
+
+ALTER TABLE sessions
+    DROP INDEX idx_sessions_refresh_token,
+    MODIFY refresh_token VARCHAR(255) NOT NULL,
+    ADD UNIQUE INDEX refresh_token (refresh_token),
+    ADD INDEX idx_sessions_refresh_token (refresh_token);
+
+DROP TABLE IF EXISTS signing_keys;
@@ -0,0 +1,21 @@
+-- services/auth-service/migrations/000004_signing_keys.up.sql
+-- Asymmetric token signing keys
+
+CREATE TABLE signing_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    private_key_encrypted VARBINARY(4096) NOT NULL,
+    public_key VARBINARY(1024) NOT NULL,
+    activates_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    INDEX idx_signing_keys_expires_at (expires_at)
+);
+
+-- Asymmetrically signed tokens don't fit in VARCHAR(255)
+ALTER TABLE sessions
+    DROP INDEX refresh_token,
+    DROP INDEX idx_sessions_refresh_token,
+    MODIFY refresh_token TEXT NOT NULL,
+    ADD INDEX idx_sessions_refresh_token (refresh_token(255));
@@ -0,0 +1,11 @@
+-- services/auth-service/migrations/000005_token_families.down.sql
+-- Rollback refresh token families
+
+ALTER TABLE sessions
+    DROP COLUMN generation;
+
+ALTER TABLE tokens
+    DROP INDEX idx_tokens_family_id,
+    DROP COLUMN replaced_by,
+    DROP COLUMN generation,
+    DROP COLUMN family_id;
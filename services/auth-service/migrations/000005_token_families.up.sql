@@ -0,0 +1,17 @@
+-- services/auth-service/migrations/000005_token_families.up.sql
+-- Refresh token families. Every session is a family: each refresh replaces
+-- the family's token with the next generation, and presenting a replaced
+-- token revokes the whole family.
+
+ALTER TABLE tokens
+    ADD COLUMN family_id VARCHAR(36) NULL AFTER user_id,
+    ADD COLUMN generation INT NOT NULL DEFAULT 0 AFTER family_id,
+    ADD COLUMN replaced_by VARCHAR(36) NULL AFTER revoked_at,
+    ADD INDEX idx_tokens_family_id (family_id);
+
+UPDATE tokens
+SET family_id = JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.session_id'))
+WHERE type = 'refresh' AND JSON_EXTRACT(metadata, '$.session_id') IS NOT NULL;
+
+ALTER TABLE sessions
+    ADD COLUMN generation INT NOT NULL DEFAULT 0 AFTER refresh_token;
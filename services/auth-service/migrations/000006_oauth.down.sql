@@ -0,0 +1,6 @@
+-- services/auth-service/migrations/000006_oauth.down.sql
+-- Rollback OAuth2 / OpenID Connect tables
+
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TABLE IF EXISTS oauth_consents;
+DROP TABLE IF EXISTS oauth_clients;
@@ -0,0 +1,42 @@
+-- services/auth-service/migrations/000006_oauth.up.sql
+-- OAuth2 / OpenID Connect clients, consents and authorization codes
+
+CREATE TABLE oauth_clients (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    secret_hash CHAR(64) NULL,
+    redirect_uris JSON NOT NULL,
+    scopes JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL
+);
+
+CREATE TABLE oauth_consents (
+    user_id VARCHAR(36) NOT NULL,
+    client_id VARCHAR(64) NOT NULL,
+    scopes JSON NOT NULL,
+    granted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    
+    PRIMARY KEY (user_id, client_id),
+    INDEX idx_oauth_consents_client_id (client_id)
+);
+
+CREATE TABLE oauth_authorization_codes (
+    code_hash CHAR(64) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    scopes JSON NOT NULL,
+    code_challenge VARCHAR(128) NOT NULL,
+    code_challenge_method VARCHAR(16) NOT NULL,
+    nonce VARCHAR(255) NULL,
+    session_id VARCHAR(36) NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    INDEX idx_oauth_authorization_codes_expires_at (expires_at)
+);
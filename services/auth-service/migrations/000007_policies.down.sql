@@ -0,0 +1,11 @@
+-- services/auth-service/migrations/000007_policies.down.sql
+-- Rollback role inheritance, conditional grants and user attributes
+
+DROP TABLE IF EXISTS user_attributes;
+
+ALTER TABLE role_permissions
+    DROP COLUMN conditions;
+
+ALTER TABLE roles
+    DROP FOREIGN KEY fk_roles_parent,
+    DROP COLUMN parent_id;
@@ -0,0 +1,37 @@
+-- services/auth-service/migrations/000007_policies.up.sql
+-- Role inheritance, conditional permission grants and user attributes for
+-- the policy engine. A role holds its own permissions plus its parent's; a
+-- grant with conditions only applies when the caller's and the resource's
+-- attributes satisfy all of them.
+
+ALTER TABLE roles
+    ADD COLUMN parent_id VARCHAR(36) NULL AFTER description,
+    ADD CONSTRAINT fk_roles_parent FOREIGN KEY (parent_id) REFERENCES roles(id) ON DELETE SET NULL;
+
+ALTER TABLE role_permissions
+    ADD COLUMN conditions JSON NULL AFTER permission_id;
+
+-- Attributes of users that conditions can refer to as subject.<name>,
+-- e.g. the entity a dispatcher works for
+CREATE TABLE user_attributes (
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, name)
+);
+
+-- admin builds on dispatcher, which builds on customer
+UPDATE roles SET parent_id = 'role-customer' WHERE id = 'role-dispatcher';
+UPDATE roles SET parent_id = 'role-dispatcher' WHERE id = 'role-admin';
+
+-- Customers read the incidents they reported
+UPDATE role_permissions
+SET conditions = '[{"attribute": "resource.reported_by", "operator": "eq", "value": "subject.id"}]'
+WHERE role_id = 'role-customer' AND permission_id = 'perm-incident-read';
+
+-- Dispatchers update the incidents of the entity they work for
+UPDATE role_permissions
+SET conditions = '[{"attribute": "resource.entity_id", "operator": "eq", "value": "subject.entity_id"}]'
+WHERE role_id = 'role-dispatcher' AND permission_id = 'perm-incident-update';
@@ -0,0 +1,7 @@
+-- services/auth-service/migrations/000008_account_unlock.down.sql
+-- Rollback unlock tokens
+
+DELETE FROM tokens WHERE type = 'unlock';
+
+ALTER TABLE tokens
+    MODIFY type ENUM('access', 'refresh', 'reset', 'verify') NOT NULL;
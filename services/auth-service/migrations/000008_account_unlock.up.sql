@@ -0,0 +1,6 @@
+-- services/auth-service/migrations/000008_account_unlock.up.sql
+-- Unlock tokens, emailed to users whose account was locked out by failed
+-- logins. Only a SHA-256 hash of the token is stored.
+
+ALTER TABLE tokens
+    MODIFY type ENUM('access', 'refresh', 'reset', 'verify', 'unlock') NOT NULL;
@@ -0,0 +1,4 @@
+-- services/auth-service/migrations/000009_password_history.down.sql
+-- Rollback password history
+
+DROP TABLE IF EXISTS password_history;
@@ -0,0 +1,13 @@
+-- services/auth-service/migrations/000009_password_history.up.sql
+-- Hashes of the passwords users have had, so the password policy can
+-- refuse reuse and tell when the current one is too old. The newest row is
+-- the current password.
+
+CREATE TABLE password_history (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_password_history_user (user_id, created_at)
+);
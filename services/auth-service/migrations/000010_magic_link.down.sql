@@ -0,0 +1,7 @@
+-- services/auth-service/migrations/000010_magic_link.down.sql
+-- Rollback magic link tokens
+
+DELETE FROM tokens WHERE type = 'magic_link';
+
+ALTER TABLE tokens
+    MODIFY type ENUM('access', 'refresh', 'reset', 'verify', 'unlock') NOT NULL;
@@ -0,0 +1,6 @@
+-- services/auth-service/migrations/000010_magic_link.up.sql
+-- Magic link tokens, emailed to log in without a password. Only a SHA-256
+-- hash of the token is stored.
+
+ALTER TABLE tokens
+    MODIFY type ENUM('access', 'refresh', 'reset', 'verify', 'unlock', 'magic_link') NOT NULL;
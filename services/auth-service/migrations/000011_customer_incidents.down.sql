@@ -0,0 +1,6 @@
+-- services/auth-service/migrations/000011_customer_incidents.down.sql
+-- Rollback customer incident reporting and comments
+
+DELETE FROM role_permissions WHERE role_id = 'role-customer' AND permission_id = 'perm-incident-create';
+DELETE FROM role_permissions WHERE permission_id = 'perm-incident-comment';
+DELETE FROM permissions WHERE id = 'perm-incident-comment';
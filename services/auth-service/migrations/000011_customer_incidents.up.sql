@@ -0,0 +1,13 @@
+-- services/auth-service/migrations/000011_customer_incidents.up.sql
+-- Customers report incidents and comment on those they reported. Commenting
+-- is its own permission, so it doesn't also let customers edit or move an
+-- incident through its statuses.
+
+INSERT INTO permissions (id, name, description, resource, action) VALUES
+('perm-incident-comment', 'Comment on Incident', 'Add comments to incidents', 'incident', 'comment');
+
+INSERT INTO role_permissions (role_id, permission_id, conditions) VALUES
+('role-admin', 'perm-incident-comment', NULL),
+('role-dispatcher', 'perm-incident-comment', '[{"attribute": "resource.entity_id", "operator": "eq", "value": "subject.entity_id"}]'),
+('role-customer', 'perm-incident-comment', '[{"attribute": "resource.reported_by", "operator": "eq", "value": "subject.id"}]'),
+('role-customer', 'perm-incident-create', NULL);
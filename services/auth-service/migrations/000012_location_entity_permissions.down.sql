@@ -0,0 +1,10 @@
+-- Rollback location and entity permissions
+
+DELETE FROM role_permissions WHERE permission_id IN (
+    'perm-location-create', 'perm-location-read', 'perm-location-update', 'perm-location-delete',
+    'perm-entity-create', 'perm-entity-read', 'perm-entity-update', 'perm-entity-delete'
+);
+DELETE FROM permissions WHERE id IN (
+    'perm-location-create', 'perm-location-read', 'perm-location-update', 'perm-location-delete',
+    'perm-entity-create', 'perm-entity-read', 'perm-entity-update', 'perm-entity-delete'
+);
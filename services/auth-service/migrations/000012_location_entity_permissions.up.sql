@@ -0,0 +1,29 @@
+-- Permissions for locations and entities, which the gateway previously let
+-- any authenticated caller create. Everyone reads them, as incidents refer
+-- to both; dispatchers record locations, and only admins manage entities.
+
+INSERT INTO permissions (id, name, description, resource, action) VALUES
+('perm-location-create', 'Create Location', 'Create new locations', 'location', 'create'),
+('perm-location-read', 'Read Location', 'View location information', 'location', 'read'),
+('perm-location-update', 'Update Location', 'Update location information', 'location', 'update'),
+('perm-location-delete', 'Delete Location', 'Delete locations', 'location', 'delete'),
+('perm-entity-create', 'Create Entity', 'Create new entities', 'entity', 'create'),
+('perm-entity-read', 'Read Entity', 'View entity information', 'entity', 'read'),
+('perm-entity-update', 'Update Entity', 'Update entity information', 'entity', 'update'),
+('perm-entity-delete', 'Delete Entity', 'Delete entities', 'entity', 'delete');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('role-admin', 'perm-location-create'),
+('role-admin', 'perm-location-read'),
+('role-admin', 'perm-location-update'),
+('role-admin', 'perm-location-delete'),
+('role-admin', 'perm-entity-create'),
+('role-admin', 'perm-entity-read'),
+('role-admin', 'perm-entity-update'),
+('role-admin', 'perm-entity-delete'),
+('role-dispatcher', 'perm-location-create'),
+('role-dispatcher', 'perm-location-read'),
+('role-dispatcher', 'perm-location-update'),
+('role-dispatcher', 'perm-entity-read'),
+('role-customer', 'perm-location-read'),
+('role-customer', 'perm-entity-read');
@@ -2,26 +2,40 @@
 package jwt
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/0xsj/fn-go/pkg/common/jwks"
+	"github.com/0xsj/fn-go/pkg/common/serviceauth"
 	"github.com/0xsj/fn-go/pkg/models"
 	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-// JWTManager handles JWT operations
+// JWTManager handles JWT operations. Tokens are signed with the key set's
+// current key and name it in their kid header, so they can be verified with
+// the published JWKS document alone.
 type JWTManager struct {
-	secretKey            []byte
+	keys                 *KeySet
+	legacySecret         []byte
 	accessTokenExpiry    time.Duration
 	refreshTokenExpiry   time.Duration
 	issuer               string
 }
 
-// NewJWTManager creates a new JWT manager
-func NewJWTManager(secretKey string, accessTokenExpiry, refreshTokenExpiry time.Duration) *JWTManager {
+// NewJWTManager creates a new JWT manager. A non-empty legacy secret keeps
+// HS256 tokens issued before asymmetric signing valid until they expire.
+func NewJWTManager(keys *KeySet, legacySecret string, accessTokenExpiry, refreshTokenExpiry time.Duration) *JWTManager {
+	var secret []byte
+	if legacySecret != "" {
+		secret = []byte(legacySecret)
+	}
 	return &JWTManager{
-		secretKey:            []byte(secretKey),
+		keys:                 keys,
+		legacySecret:         secret,
 		accessTokenExpiry:    accessTokenExpiry,
 		refreshTokenExpiry:   refreshTokenExpiry,
 		issuer:               "fn-go-auth-service",
@@ -45,10 +59,57 @@ func (j *JWTManager) GenerateTokenPair(user *models.User) (accessToken, refreshT
 	return accessToken, refreshToken, nil
 }
 
+// GenerateClientTokenPair generates tokens issued to an OAuth client acting
+// for a user. The access token names the client as its audience and carries
+// the scopes the user granted, so it only reaches what those scopes allow.
+func (j *JWTManager) GenerateClientTokenPair(user *models.User, clientID string, scopes []string) (accessToken, refreshToken string, err error) {
+	claims := j.accessClaims(user)
+	claims["aud"] = clientID
+	claims["client_id"] = clientID
+	claims["scope"] = strings.Join(scopes, " ")
+	accessToken, err = j.sign(claims)
+	if err != nil {
+		return "", "", domain.WithOperation(err, "generate_access_token")
+	}
+
+	claims = j.refreshClaims(user)
+	claims["aud"] = clientID
+	refreshToken, err = j.sign(claims)
+	if err != nil {
+		return "", "", domain.WithOperation(err, "generate_refresh_token")
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// GenerateIDToken creates an OpenID Connect ID token for a client. It has no
+// type claim, so it can never pass as an access or refresh token.
+func (j *JWTManager) GenerateIDToken(issuer, audience string, expiry time.Duration, userClaims map[string]any) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{}
+	for name, value := range userClaims {
+		claims[name] = value
+	}
+	claims["iss"] = issuer
+	claims["aud"] = audience
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(expiry).Unix()
+	return j.sign(claims)
+}
+
 // generateAccessToken creates a short-lived access token
 func (j *JWTManager) generateAccessToken(user *models.User) (string, error) {
+	return j.sign(j.accessClaims(user))
+}
+
+// generateRefreshToken creates a long-lived refresh token
+func (j *JWTManager) generateRefreshToken(user *models.User) (string, error) {
+	return j.sign(j.refreshClaims(user))
+}
+
+func (j *JWTManager) accessClaims(user *models.User) jwt.MapClaims {
 	now := time.Now()
-	claims := jwt.MapClaims{
+	return jwt.MapClaims{
 		"sub":      user.ID,
 		"username": user.Username,
 		"email":    user.Email,
@@ -59,20 +120,11 @@ func (j *JWTManager) generateAccessToken(user *models.User) (string, error) {
 		"jti":      uuid.New().String(),
 		"type":     "access",
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.secretKey)
-	if err != nil {
-		return "", domain.NewInvalidTokenError()
-	}
-
-	return tokenString, nil
 }
 
-// generateRefreshToken creates a long-lived refresh token
-func (j *JWTManager) generateRefreshToken(user *models.User) (string, error) {
+func (j *JWTManager) refreshClaims(user *models.User) jwt.MapClaims {
 	now := time.Now()
-	claims := jwt.MapClaims{
+	return jwt.MapClaims{
 		"sub":  user.ID,
 		"iat":  now.Unix(),
 		"exp":  now.Add(j.refreshTokenExpiry).Unix(),
@@ -80,48 +132,91 @@ func (j *JWTManager) generateRefreshToken(user *models.User) (string, error) {
 		"jti":  uuid.New().String(),
 		"type": "refresh",
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.secretKey)
-	if err != nil {
-		return "", domain.NewInvalidTokenError()
-	}
+// GenerateImpersonationToken creates an access token for user naming the
+// administrator acting as them in an act claim (RFC 8693 section 4.1). It
+// lives for expiry rather than the usual access token lifetime, and no
+// refresh token goes with it, so the impersonation ends when it expires.
+func (j *JWTManager) GenerateImpersonationToken(user *models.User, actorID string, expiry time.Duration) (string, error) {
+	claims := j.accessClaims(user)
+	claims["exp"] = time.Now().Add(expiry).Unix()
+	claims["act"] = map[string]any{"sub": actorID}
+	return j.sign(claims)
+}
 
-	return tokenString, nil
+// GenerateMFAToken creates a short-lived token standing for a login that has
+// passed the password check and still owes a second factor. Enroll marks a
+// user who must enroll a factor before finishing the login.
+func (j *JWTManager) GenerateMFAToken(userID string, enroll bool, expiry time.Duration) (string, error) {
+	now := time.Now()
+	return j.sign(jwt.MapClaims{
+		"sub":    userID,
+		"iat":    now.Unix(),
+		"exp":    now.Add(expiry).Unix(),
+		"iss":    j.issuer,
+		"jti":    uuid.New().String(),
+		"type":   "mfa",
+		"enroll": enroll,
+	})
 }
 
-// ValidateAccessToken validates and parses an access token
-func (j *JWTManager) ValidateAccessToken(tokenString string) (*models.TokenClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, domain.NewInvalidTokenError()
-		}
-		return j.secretKey, nil
+// GenerateServiceToken creates a token for a service account calling other
+// services. Its scopes are the NATS subjects it may call, and its type keeps
+// it from ever passing as a user's token.
+func (j *JWTManager) GenerateServiceToken(clientID string, scopes []string, expiry time.Duration) (string, error) {
+	now := time.Now()
+	return j.sign(jwt.MapClaims{
+		"sub":       clientID,
+		"client_id": clientID,
+		"scope":     strings.Join(scopes, " "),
+		"iat":       now.Unix(),
+		"exp":       now.Add(expiry).Unix(),
+		"iss":       j.issuer,
+		"jti":       uuid.New().String(),
+		"type":      serviceauth.TokenType,
 	})
+}
 
-	if err != nil {
-		// Check if it's an expiration error by looking at the error message
-		// This avoids importing the standard errors package
-		if isTokenExpiredError(err) {
-			return nil, domain.NewTokenExpiredError()
+// ServiceTokenFetcher issues auth-service its own service tokens, so its
+// calls to other services are authenticated without asking itself for a token
+func (j *JWTManager) ServiceTokenFetcher(clientID string, scopes []string, expiry time.Duration) serviceauth.Fetcher {
+	return func(ctx context.Context) (*serviceauth.Token, error) {
+		issuedAt := time.Now()
+		token, err := j.GenerateServiceToken(clientID, scopes, expiry)
+		if err != nil {
+			return nil, domain.WithOperation(err, "generate_service_token")
 		}
-		return nil, domain.NewInvalidTokenError()
+		return &serviceauth.Token{Value: token, IssuedAt: issuedAt, ExpiresAt: issuedAt.Add(expiry)}, nil
 	}
+}
 
-	if !token.Valid {
-		return nil, domain.NewInvalidTokenError()
+// sign signs claims with the current signing key
+func (j *JWTManager) sign(claims jwt.MapClaims) (string, error) {
+	key, err := j.keys.SigningKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+	method, err := signingMethod(key.Algorithm)
+	if err != nil {
+		return "", domain.NewInvalidTokenError()
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, domain.NewInvalidTokenError()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", domain.NewInvalidTokenError()
 	}
 
-	// Verify token type
-	tokenType, ok := claims["type"].(string)
-	if !ok || tokenType != "access" {
-		return nil, domain.NewInvalidTokenError()
+	return tokenString, nil
+}
+
+// ValidateAccessToken validates and parses an access token
+func (j *JWTManager) ValidateAccessToken(tokenString string) (*models.TokenClaims, error) {
+	claims, err := j.parse(tokenString, "access")
+	if err != nil {
+		return nil, err
 	}
 
 	// Extract and validate required claims
@@ -150,15 +245,28 @@ func (j *JWTManager) ValidateAccessToken(tokenString string) (*models.TokenClaim
 	exp, _ := claims["exp"].(float64)
 	issuer, _ := claims["iss"].(string)
 
+	// Tokens issued to OAuth clients are limited to the granted scopes
+	audience, _ := claims["aud"].(string)
+	scope, _ := claims["scope"].(string)
+
+	// Impersonation tokens name the administrator acting as the user
+	var actor string
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		actor, _ = act["sub"].(string)
+	}
+
 	tokenClaims := &models.TokenClaims{
 		UserID:    userID,
 		Username:  username,
 		Email:     email,
 		Roles:     roles,
+		Scopes:    strings.Fields(scope),
 		IssuedAt:  int64(iat),
 		ExpiresAt: int64(exp),
 		Issuer:    issuer,
+		Audience:  audience,
 		JWTID:     jwtID,
+		Actor:     actor,
 	}
 
 	return tokenClaims, nil
@@ -166,48 +274,96 @@ func (j *JWTManager) ValidateAccessToken(tokenString string) (*models.TokenClaim
 
 // ValidateRefreshToken validates and parses a refresh token
 func (j *JWTManager) ValidateRefreshToken(tokenString string) (userID string, jwtID string, err error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, domain.NewInvalidTokenError()
-		}
-		return j.secretKey, nil
-	})
+	claims, err := j.parse(tokenString, "refresh")
+	if err != nil {
+		return "", "", err
+	}
+
+	// Extract required claims
+	userID, ok := claims["sub"].(string)
+	if !ok || userID == "" {
+		return "", "", domain.NewInvalidTokenError()
+	}
+
+	jwtID, ok = claims["jti"].(string)
+	if !ok || jwtID == "" {
+		return "", "", domain.NewInvalidTokenError()
+	}
+
+	return userID, jwtID, nil
+}
+
+// ValidateMFAToken validates and parses an MFA challenge token
+func (j *JWTManager) ValidateMFAToken(tokenString string) (userID string, enroll bool, err error) {
+	claims, err := j.parse(tokenString, "mfa")
+	if err != nil {
+		return "", false, err
+	}
+
+	userID, ok := claims["sub"].(string)
+	if !ok || userID == "" {
+		return "", false, domain.NewInvalidTokenError()
+	}
+	enroll, _ = claims["enroll"].(bool)
+
+	return userID, enroll, nil
+}
 
+// parse verifies a token's signature, expiry and type and returns its claims
+func (j *JWTManager) parse(tokenString, tokenType string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, j.keyFunc)
 	if err != nil {
-		if isTokenExpiredError(err) {
-			return "", "", domain.NewTokenExpiredError()
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, domain.NewTokenExpiredError()
 		}
-		return "", "", domain.NewInvalidTokenError()
+		return nil, domain.NewInvalidTokenError()
 	}
 
 	if !token.Valid {
-		return "", "", domain.NewInvalidTokenError()
+		return nil, domain.NewInvalidTokenError()
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", "", domain.NewInvalidTokenError()
+		return nil, domain.NewInvalidTokenError()
 	}
 
 	// Verify token type
-	tokenType, ok := claims["type"].(string)
-	if !ok || tokenType != "refresh" {
-		return "", "", domain.NewInvalidTokenError()
+	if t, ok := claims["type"].(string); !ok || t != tokenType {
+		return nil, domain.NewInvalidTokenError()
 	}
 
-	// Extract required claims
-	userID, ok = claims["sub"].(string)
-	if !ok || userID == "" {
-		return "", "", domain.NewInvalidTokenError()
+	// Only impersonation access tokens name an actor. Refresh and MFA tokens
+	// start or extend sessions, which an impersonation must never do.
+	if _, ok := claims["act"]; ok && tokenType != "access" {
+		return nil, domain.NewInvalidTokenError()
 	}
 
-	jwtID, ok = claims["jti"].(string)
-	if !ok || jwtID == "" {
-		return "", "", domain.NewInvalidTokenError()
+	return claims, nil
+}
+
+// keyFunc resolves the key a token was signed with from its kid header.
+// Tokens without one predate asymmetric signing and verify with the legacy
+// secret, if one is still configured.
+func (j *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || j.legacySecret == nil {
+			return nil, domain.NewInvalidTokenError()
+		}
+		return j.legacySecret, nil
 	}
 
-	return userID, jwtID, nil
+	key, ok := j.keys.VerificationKey(kid, time.Now())
+	if !ok || token.Method.Alg() != key.Algorithm {
+		return nil, domain.NewInvalidTokenError()
+	}
+	return key.Public, nil
+}
+
+// JWKS returns the JWKS document verifiers check tokens against
+func (j *JWTManager) JWKS() (*jwks.Set, error) {
+	return j.keys.JWKS(time.Now())
 }
 
 // ExtractTokenWithoutValidation extracts claims without validating the token
@@ -228,11 +384,3 @@ func (j *JWTManager) ExtractTokenWithoutValidation(tokenString string) (jwt.MapC
 func (j *JWTManager) GetTokenExpiry() (accessExpiry, refreshExpiry time.Duration) {
 	return j.accessTokenExpiry, j.refreshTokenExpiry
 }
-
-func isTokenExpiredError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errMsg := err.Error()
-	return errMsg == "token is expired" || errMsg == "Token is expired"
-}
\ No newline at end of file
@@ -0,0 +1,167 @@
+// services/auth-service/pkg/jwt/keyset.go
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/jwks"
+	"github.com/0xsj/fn-go/services/auth-service/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// rsaKeyBits is the size of generated RS256 keys
+const rsaKeyBits = 2048
+
+// Key is a parsed signing key
+type Key struct {
+	ID          string
+	Algorithm   string
+	Private     crypto.Signer
+	Public      crypto.PublicKey
+	ActivatesAt time.Time
+	ExpiresAt   *time.Time
+}
+
+// published reports whether the key can verify tokens at a time
+func (k *Key) published(now time.Time) bool {
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// KeySet holds the keys tokens are signed and verified with. The most
+// recently activated key signs; every published key verifies.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []*Key
+}
+
+// NewKeySet creates an empty key set
+func NewKeySet() *KeySet {
+	return &KeySet{}
+}
+
+// Replace swaps in a new list of keys
+func (s *KeySet) Replace(keys []*Key) {
+	sorted := make([]*Key, len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActivatesAt.Before(sorted[j].ActivatesAt)
+	})
+
+	s.mu.Lock()
+	s.keys = sorted
+	s.mu.Unlock()
+}
+
+// SigningKey returns the key to sign new tokens with
+func (s *KeySet) SigningKey(now time.Time) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		key := s.keys[i]
+		if key.Private != nil && !key.ActivatesAt.After(now) && key.published(now) {
+			return key, nil
+		}
+	}
+	return nil, domain.NewInternalError("No token signing key is available")
+}
+
+// VerificationKey returns the published key with the given ID
+func (s *KeySet) VerificationKey(kid string, now time.Time) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.ID == kid && key.published(now) {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// JWKS returns the JWKS document for the published keys, including keys not
+// yet activated so verifiers learn them ahead of use
+func (s *KeySet) JWKS(now time.Time) (*jwks.Set, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := &jwks.Set{Keys: []jwks.Key{}}
+	for _, key := range s.keys {
+		if !key.published(now) {
+			continue
+		}
+		jwk, err := jwks.NewKey(key.ID, key.Algorithm, key.Public)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+// GenerateKey creates a new private key for an algorithm
+func GenerateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case jwks.AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case jwks.AlgorithmEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
+
+// MarshalPrivateKey encodes a private key as PKCS #8 DER
+func MarshalPrivateKey(key crypto.Signer) ([]byte, error) {
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// ParsePrivateKey decodes a PKCS #8 DER private key
+func ParsePrivateKey(der []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// MarshalPublicKey encodes a public key as PKIX DER
+func MarshalPublicKey(key crypto.PublicKey) ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(key)
+}
+
+// ParsePublicKey decodes a PKIX DER public key
+func ParsePublicKey(der []byte) (crypto.PublicKey, error) {
+	return x509.ParsePKIXPublicKey(der)
+}
+
+// ValidateAlgorithm reports whether keys can be generated for an algorithm
+func ValidateAlgorithm(algorithm string) error {
+	_, err := signingMethod(algorithm)
+	return err
+}
+
+// signingMethod returns the JWT signing method for an algorithm
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case jwks.AlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case jwks.AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
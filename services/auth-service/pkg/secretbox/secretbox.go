@@ -0,0 +1,52 @@
+// services/auth-service/pkg/secretbox/secretbox.go
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrMalformed is returned for sealed data too short to hold a nonce
+var ErrMalformed = errors.New("sealed data is malformed")
+
+// Box encrypts secrets stored by auth-service with AES-256-GCM. Each value
+// is sealed with additional data, usually the ID of the row it belongs to,
+// so a ciphertext copied to another row won't open.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New creates a box whose key is derived from a configured passphrase
+func New(passphrase string) (*Box, error) {
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext bound to additional data, prefixing a random nonce
+func (b *Box) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts data sealed with the same additional data
+func (b *Box) Open(sealed, additionalData []byte) ([]byte, error) {
+	size := b.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, ErrMalformed
+	}
+	return b.aead.Open(nil, sealed[:size], sealed[size:], additionalData)
+}
@@ -0,0 +1,111 @@
+// services/auth-service/pkg/secretbox/secretbox_test.go
+package secretbox
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func newBox(t *testing.T, passphrase string) *Box {
+	t.Helper()
+
+	box, err := New(passphrase)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return box
+}
+
+func TestSealOpen(t *testing.T) {
+	box := newBox(t, "passphrase")
+	plaintext := []byte("JBSWY3DPEHPK3PXP")
+	ad := []byte("user-1")
+
+	sealed, err := box.Seal(plaintext, ad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Error("sealed data contains the plaintext")
+	}
+
+	opened, err := box.Open(sealed, ad)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("got %q, want %q", opened, plaintext)
+	}
+
+	// A box from the same passphrase opens it too
+	opened, err = newBox(t, "passphrase").Open(sealed, ad)
+	if err != nil || !bytes.Equal(opened, plaintext) {
+		t.Errorf("second box got %q, %v; want %q", opened, err, plaintext)
+	}
+}
+
+func TestSealUsesFreshNonces(t *testing.T) {
+	box := newBox(t, "passphrase")
+
+	first, err := box.Seal([]byte("secret"), nil)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	second, err := box.Seal([]byte("secret"), nil)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if bytes.Equal(first, second) {
+		t.Error("sealing the same plaintext twice gave the same ciphertext")
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	box := newBox(t, "passphrase")
+	ad := []byte("user-1")
+
+	sealed, err := box.Seal([]byte("secret"), ad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	tampered := func(i int) []byte {
+		data := append([]byte{}, sealed...)
+		data[i] ^= 0x01
+		return data
+	}
+
+	tests := []struct {
+		name   string
+		box    *Box
+		sealed []byte
+		ad     []byte
+	}{
+		{"tampered nonce", box, tampered(0), ad},
+		{"tampered ciphertext", box, tampered(len(sealed) / 2), ad},
+		{"tampered tag", box, tampered(len(sealed) - 1), ad},
+		{"truncated", box, sealed[:len(sealed)-1], ad},
+		{"other additional data", box, sealed, []byte("user-2")},
+		{"no additional data", box, sealed, nil},
+		{"other passphrase", newBox(t, "other"), sealed, ad},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if opened, err := tt.box.Open(tt.sealed, tt.ad); err == nil {
+				t.Errorf("Open succeeded with %q, want an error", opened)
+			}
+		})
+	}
+}
+
+func TestOpenMalformed(t *testing.T) {
+	box := newBox(t, "passphrase")
+
+	for _, sealed := range [][]byte{nil, []byte("short")} {
+		if _, err := box.Open(sealed, nil); !errors.Is(err, ErrMalformed) {
+			t.Errorf("Open(%q) = %v, want ErrMalformed", sealed, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// services/auth-service/pkg/totp/totp_test.go
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors, "12345678901234567890", base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestCodeRFC6238 checks codes against the RFC 6238 appendix B SHA-1
+// vectors, which are given as 8 digits; a 6 digit code is the last 6 of them
+func TestCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if want := tt.want[len(tt.want)-Digits:]; code != want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, code, want)
+		}
+	}
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+	upper, err := Code(rfcSecret, 1)
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	lower, err := Code(strings.ToLower(rfcSecret), 1)
+	if err != nil {
+		t.Fatalf("Code with lowercase secret: %v", err)
+	}
+	if upper != lower {
+		t.Errorf("got %s for the lowercase secret, want %s", lower, upper)
+	}
+}
+
+func TestCodeRejectsInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code accepted a secret that isn't base32")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		want     int64
+		ok       bool
+	}{
+		{"current step", codeAt(step), 0, step, true},
+		{"previous step within skew", codeAt(step - 1), 0, step - 1, true},
+		{"next step within skew", codeAt(step + 1), 0, step + 1, true},
+		{"outside skew", codeAt(step - 2), 0, 0, false},
+		{"spaces ignored", " " + codeAt(step)[:3] + " " + codeAt(step)[3:] + " ", 0, step, true},
+		{"wrong length", codeAt(step)[:Digits-1], 0, 0, false},
+		{"wrong code", "000000", 0, 0, false},
+		// Once a step is used its code, and any earlier one, is refused
+		{"replayed step", codeAt(step), step, 0, false},
+		{"earlier than last used step", codeAt(step - 1), step, 0, false},
+		{"later than last used step", codeAt(step + 1), step, step + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, ok := Validate(rfcSecret, tt.code, now, tt.lastStep)
+			if ok != tt.ok || matched != tt.want {
+				t.Errorf("Validate(%q) = %d, %v; want %d, %v", tt.code, matched, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q isn't base32: %v", secret, err)
+	}
+	if len(key) != SecretBytes {
+		t.Errorf("got a %d byte secret, want %d", len(key), SecretBytes)
+	}
+
+	other, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	if secret == other {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("fn go", "ada@example.com", rfcSecret)
+
+	for _, want := range []string{
+		"otpauth://totp/fn%20go:ada@example.com?",
+		"secret=" + rfcSecret,
+		"issuer=fn+go",
+		"algorithm=SHA1",
+		"digits=6",
+		"period=30",
+	} {
+		if !strings.Contains(uri, want) {
+			t.Errorf("URI %q doesn't contain %q", uri, want)
+		}
+	}
+}
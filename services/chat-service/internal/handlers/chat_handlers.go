@@ -9,7 +9,15 @@ import (
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/response"
 	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/pkg/repository"
+)
+
+// Message lists are paged newest first, keyed by send time and ID
+const (
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 200
 )
 
 // ChatHandler handles chat-related requests
@@ -235,15 +243,14 @@ func (h *ChatHandler) SendMessage(data []byte) (any, error) {
 	return message, nil
 }
 
-// ListMessages handles requests to list messages in a chat
+// ListMessages handles requests to list messages in a chat, one page at a time
 func (h *ChatHandler) ListMessages(data []byte) (any, error) {
 	handlerLogger := h.logger.With("subject", "chat.message.list")
 	handlerLogger.Info("Received chat.message.list request")
 	
 	var req struct {
+		repository.PageRequest
 		ChatID string `json:"chat_id"`
-		Limit  int    `json:"limit"`
-		Before string `json:"before"`
 	}
 
 	if err := json.Unmarshal(data, &req); err != nil {
@@ -251,9 +258,15 @@ func (h *ChatHandler) ListMessages(data []byte) (any, error) {
 		return nil, errors.NewBadRequestError("Invalid request format", err)
 	}
 
+	cursor, limit, err := req.Parse(2, defaultMessagePageSize, maxMessagePageSize)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Invalid pagination parameters")
+		return nil, err
+	}
+
 	handlerLogger = handlerLogger.With("chat_id", req.ChatID).
-		With("limit", req.Limit).
-		With("before", req.Before)
+		With("limit", limit).
+		With("cursor", req.Cursor != "")
 	handlerLogger.Info("Listing messages for chat")
 
 	// Create mock messages
@@ -295,6 +308,16 @@ func (h *ChatHandler) ListMessages(data []byte) (any, error) {
 		},
 	}
 
+	messages, cursors := repository.PageSlice(messages, cursor, limit, true, messageKey)
+
 	handlerLogger.With("count", len(messages)).Info("Returning message list")
-	return messages, nil
+	return &response.CursorPage{
+		Items:   messages,
+		Cursors: response.CursorMeta{Next: cursors.Next, Prev: cursors.Prev, Limit: limit},
+	}, nil
+}
+
+// messageKey is the keyset position of a message in message lists
+func messageKey(message *models.ChatMessage) []any {
+	return []any{message.SentAt, message.ID}
 }
\ No newline at end of file
@@ -3,9 +3,8 @@ package main
 
 import (
 	"os"
-	"os/signal"
-	"syscall"
 
+	"github.com/0xsj/fn-go/pkg/common/lifecycle"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/services/entity-service/internal/config"
@@ -47,7 +46,6 @@ func main() {
 	if err != nil {
 		logger.With("error", err.Error()).Fatal("Failed to connect to NATS")
 	}
-	defer client.Close()
 	logger.Info("Successfully connected to NATS server")
 
 	// Initialize handlers
@@ -60,11 +58,12 @@ func main() {
 	entityHandler.RegisterHandlers(client.Conn())
 	logger.Info("Handlers registered, service is ready")
 
-	// Wait for termination signal
-	logger.Info("Waiting for termination signal")
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
-	<-signalCh
-
-	logger.Info("Shutting down")
+	// Drain NATS so handlers in flight can finish and publish their events
+	shutdown := lifecycle.NewManager(cfg.Server, logger)
+	shutdown.OnShutdown("nats", client.Drain)
+	if err := shutdown.Run(); err != nil {
+		logger.With("error", err.Error()).Error("Graceful shutdown incomplete")
+		os.Exit(1)
+	}
+	logger.Info("Shutdown complete")
 }
\ No newline at end of file
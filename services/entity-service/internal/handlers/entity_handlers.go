@@ -15,6 +15,7 @@ import (
 // EntityHandler handles entity-related requests
 type EntityHandler struct {
 	logger log.Logger
+	publisher *patterns.Publisher
 	// entityService would normally be here
 }
 
@@ -27,6 +28,9 @@ func NewEntityHandlerWithMocks(logger log.Logger) *EntityHandler {
 
 // RegisterHandlers registers entity-related handlers with NATS
 func (h *EntityHandler) RegisterHandlers(conn *nats.Conn) {
+	// Domain events let consumers such as the gateway cache react to changes
+	h.publisher = patterns.NewPublisher(conn, "entity-service", h.logger)
+
 	// Get entity by ID
 	patterns.HandleRequest(conn, "entity.get", h.GetEntity, h.logger)
 	
@@ -35,6 +39,10 @@ func (h *EntityHandler) RegisterHandlers(conn *nats.Conn) {
 	
 	// Create entity
 	patterns.HandleRequest(conn, "entity.create", h.CreateEntity, h.logger)
+
+	// Update and delete entities
+	patterns.HandleRequest(conn, "entity.update", h.UpdateEntity, h.logger)
+	patterns.HandleRequest(conn, "entity.delete", h.DeleteEntity, h.logger)
 }
 
 // GetEntity handles requests to get an entity by ID
@@ -121,5 +129,54 @@ func (h *EntityHandler) CreateEntity(data []byte) (any, error) {
 	entity.UpdatedAt = time.Now()
 
 	handlerLogger.Info("Entity created successfully")
+	h.publisher.PublishEvent(handlerLogger, "entity.event.created", &entity)
+	return &entity, nil
+}
+
+// UpdateEntity handles requests to update an entity
+func (h *EntityHandler) UpdateEntity(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "entity.update")
+	handlerLogger.Info("Received entity.update request")
+
+	var entity models.Entity
+	if err := json.Unmarshal(data, &entity); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal entity data")
+		return nil, errors.NewBadRequestError("Invalid entity data", err)
+	}
+
+	handlerLogger = handlerLogger.With("entity_id", entity.ID)
+	if entity.ID == "" {
+		handlerLogger.Warn("Empty entity ID provided")
+		return nil, errors.NewBadRequestError("Entity ID is required", nil)
+	}
+
+	entity.UpdatedAt = time.Now()
+
+	handlerLogger.Info("Entity updated successfully")
+	h.publisher.PublishEvent(handlerLogger, "entity.event.updated", &entity)
 	return &entity, nil
-}
\ No newline at end of file
+}
+
+// DeleteEntity handles requests to delete an entity
+func (h *EntityHandler) DeleteEntity(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "entity.delete")
+	handlerLogger.Info("Received entity.delete request")
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+		return nil, errors.NewBadRequestError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("entity_id", req.ID)
+	if req.ID == "" {
+		handlerLogger.Warn("Empty entity ID provided")
+		return nil, errors.NewBadRequestError("Entity ID is required", nil)
+	}
+
+	handlerLogger.Info("Entity deleted successfully")
+	h.publisher.PublishEvent(handlerLogger, "entity.event.deleted", map[string]string{"id": req.ID})
+	return map[string]any{"id": req.ID, "deleted": true}, nil
+}
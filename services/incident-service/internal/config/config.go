@@ -48,11 +48,13 @@ func Load(logger log.Logger) (*Config, error) {
 			Version: provider.GetDefault("VERSION", "1.0.0"),
 		},
 		Server: config.ServerConfig{
-			Port:         provider.GetIntDefault("PORT", 8080),
-			Host:         provider.GetDefault("HOST", ""),
-			ReadTimeout:  provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
-			WriteTimeout: provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
-			IdleTimeout:  provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+			Port:            provider.GetIntDefault("PORT", 8080),
+			Host:            provider.GetDefault("HOST", ""),
+			ReadTimeout:     provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:    provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:     provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+			ShutdownTimeout: provider.GetDurationDefault("SHUTDOWN_TIMEOUT", 30*time.Second),
+			ShutdownDelay:   provider.GetDurationDefault("SHUTDOWN_DELAY", 0),
 		},
 		Database: config.DatabaseConfig{
 			Host:            provider.GetDefault("DB_HOST", "localhost"),
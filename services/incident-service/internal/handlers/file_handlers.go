@@ -0,0 +1,103 @@
+// services/incident-service/internal/handlers/file_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/incident-service/internal/domain"
+)
+
+// attachmentIndex holds the metadata of files attached to incidents. The
+// files themselves are stored by the gateway, which streams them directly
+// to storage and only sends the metadata here.
+type attachmentIndex struct {
+	mu         sync.RWMutex
+	byIncident map[string][]*models.IncidentAttachment
+}
+
+func newAttachmentIndex() *attachmentIndex {
+	return &attachmentIndex{byIncident: make(map[string][]*models.IncidentAttachment)}
+}
+
+// AttachFile handles incident.files.attach, recording an uploaded file
+func (h *IncidentHandler) AttachFile(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "incident.files.attach")
+	handlerLogger.Info("Received incident.files.attach request")
+
+	var attachment models.IncidentAttachment
+	if err := json.Unmarshal(data, &attachment); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal attachment")
+		return nil, errors.NewBadRequestError("Invalid attachment data", err)
+	}
+
+	if attachment.ID == "" || attachment.IncidentID == "" || attachment.StoragePath == "" {
+		handlerLogger.Warn("Incomplete attachment metadata provided")
+		return nil, errors.NewBadRequestError("Attachment ID, incident ID and storage path are required", nil)
+	}
+
+	handlerLogger = handlerLogger.With("incident_id", attachment.IncidentID).
+		With("attachment_id", attachment.ID).
+		With("size", attachment.FileSize)
+
+	h.attachments.mu.Lock()
+	h.attachments.byIncident[attachment.IncidentID] = append(h.attachments.byIncident[attachment.IncidentID], &attachment)
+	h.attachments.mu.Unlock()
+
+	handlerLogger.Info("Attachment recorded")
+	h.publisher.PublishEvent(handlerLogger, "incident.event.file_attached", map[string]any{
+		"id":         attachment.IncidentID,
+		"attachment": &attachment,
+	})
+	return &attachment, nil
+}
+
+// ListFiles handles incident.files.list
+func (h *IncidentHandler) ListFiles(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "incident.files.list")
+	handlerLogger.Info("Received incident.files.list request")
+
+	var req struct {
+		IncidentID string `json:"incident_id"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+		return nil, errors.NewBadRequestError("Invalid request format", err)
+	}
+
+	h.attachments.mu.RLock()
+	attachments := append([]*models.IncidentAttachment{}, h.attachments.byIncident[req.IncidentID]...)
+	h.attachments.mu.RUnlock()
+
+	handlerLogger.With("incident_id", req.IncidentID).With("count", len(attachments)).Info("Returning attachment list")
+	return attachments, nil
+}
+
+// GetFile handles incident.files.get, returning one attachment's metadata
+func (h *IncidentHandler) GetFile(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "incident.files.get")
+	handlerLogger.Info("Received incident.files.get request")
+
+	var req struct {
+		IncidentID string `json:"incident_id"`
+		ID         string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+		return nil, errors.NewBadRequestError("Invalid request format", err)
+	}
+
+	h.attachments.mu.RLock()
+	defer h.attachments.mu.RUnlock()
+
+	for _, attachment := range h.attachments.byIncident[req.IncidentID] {
+		if attachment.ID == req.ID {
+			return attachment, nil
+		}
+	}
+
+	handlerLogger.With("incident_id", req.IncidentID).With("attachment_id", req.ID).Warn("Attachment not found")
+	return nil, domain.NewAttachmentNotFoundError(req.ID, req.IncidentID)
+}
@@ -9,12 +9,22 @@ import (
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/response"
 	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/pkg/repository"
+)
+
+// Incident lists are paged newest first, keyed by creation time and ID
+const (
+	defaultIncidentPageSize = 20
+	maxIncidentPageSize     = 100
 )
 
 // IncidentHandler handles incident-related requests
 type IncidentHandler struct {
 	logger log.Logger
+	publisher *patterns.Publisher
+	attachments *attachmentIndex
 	// incidentService would normally be here
 }
 
@@ -22,11 +32,15 @@ type IncidentHandler struct {
 func NewIncidentHandlerWithMocks(logger log.Logger) *IncidentHandler {
 	return &IncidentHandler{
 		logger: logger.WithLayer("incident-handler"),
+		attachments: newAttachmentIndex(),
 	}
 }
 
 // RegisterHandlers registers incident-related handlers with NATS
 func (h *IncidentHandler) RegisterHandlers(conn *nats.Conn) {
+	// Domain events let consumers such as the gateway cache react to changes
+	h.publisher = patterns.NewPublisher(conn, "incident-service", h.logger)
+
 	// Get incident by ID
 	patterns.HandleRequest(conn, "incident.get", h.GetIncident, h.logger)
 	
@@ -35,6 +49,17 @@ func (h *IncidentHandler) RegisterHandlers(conn *nats.Conn) {
 	
 	// Create incident
 	patterns.HandleRequest(conn, "incident.create", h.CreateIncident, h.logger)
+
+	// Update, delete, change the status of and assign incidents
+	patterns.HandleRequest(conn, "incident.update", h.UpdateIncident, h.logger)
+	patterns.HandleRequest(conn, "incident.delete", h.DeleteIncident, h.logger)
+	patterns.HandleRequest(conn, "incident.status.update", h.UpdateIncidentStatus, h.logger)
+	patterns.HandleRequest(conn, "incident.assign", h.AssignIncident, h.logger)
+
+	// File metadata; the contents are stored by the gateway
+	patterns.HandleRequest(conn, "incident.files.attach", h.AttachFile, h.logger)
+	patterns.HandleRequest(conn, "incident.files.list", h.ListFiles, h.logger)
+	patterns.HandleRequest(conn, "incident.files.get", h.GetFile, h.logger)
 }
 
 // GetIncident handles requests to get an incident by ID
@@ -78,7 +103,12 @@ func (h *IncidentHandler) GetIncident(data []byte) (any, error) {
 	return incident, nil
 }
 
-// ListIncidents handles requests to list all incidents
+// incidentKey is the keyset position of an incident in incident lists
+func incidentKey(incident *models.Incident) []any {
+	return []any{incident.CreatedAt, incident.ID}
+}
+
+// ListIncidents handles requests to list incidents, one page at a time
 func (h *IncidentHandler) ListIncidents(data []byte) (any, error) {
 	handlerLogger := h.logger.With("subject", "incident.list")
 	handlerLogger.Info("Received incident.list request")
@@ -114,8 +144,41 @@ func (h *IncidentHandler) ListIncidents(data []byte) (any, error) {
 		},
 	}
 
+	// Apply the reporter filter, which the gateway forces for customers
+	var req struct {
+		repository.PageRequest
+		ReportedBy string `json:"reported_by"`
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &req); err != nil {
+			handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+			return nil, errors.NewBadRequestError("Invalid request format", err)
+		}
+	}
+
+	cursor, limit, err := req.Parse(2, defaultIncidentPageSize, maxIncidentPageSize)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Warn("Invalid pagination parameters")
+		return nil, err
+	}
+
+	if req.ReportedBy != "" {
+		filtered := make([]*models.Incident, 0, len(incidents))
+		for _, incident := range incidents {
+			if incident.ReportedBy == req.ReportedBy {
+				filtered = append(filtered, incident)
+			}
+		}
+		incidents = filtered
+	}
+
+	incidents, cursors := repository.PageSlice(incidents, cursor, limit, true, incidentKey)
+
 	handlerLogger.With("count", len(incidents)).Info("Returning incident list")
-	return incidents, nil
+	return &response.CursorPage{
+		Items:   incidents,
+		Cursors: response.CursorMeta{Next: cursors.Next, Prev: cursors.Prev, Limit: limit},
+	}, nil
 }
 
 // CreateIncident handles requests to create a new incident
@@ -137,5 +200,113 @@ func (h *IncidentHandler) CreateIncident(data []byte) (any, error) {
 	incident.UpdatedAt = time.Now()
 
 	handlerLogger.Info("Incident created successfully")
+	h.publisher.PublishEvent(handlerLogger, "incident.event.created", &incident)
 	return &incident, nil
-}
\ No newline at end of file
+}
+
+// UpdateIncident handles requests to update an incident
+func (h *IncidentHandler) UpdateIncident(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "incident.update")
+	handlerLogger.Info("Received incident.update request")
+
+	var incident models.Incident
+	if err := json.Unmarshal(data, &incident); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal incident data")
+		return nil, errors.NewBadRequestError("Invalid incident data", err)
+	}
+
+	handlerLogger = handlerLogger.With("incident_id", incident.ID)
+	if incident.ID == "" {
+		handlerLogger.Warn("Empty incident ID provided")
+		return nil, errors.NewBadRequestError("Incident ID is required", nil)
+	}
+
+	incident.UpdatedAt = time.Now()
+
+	handlerLogger.Info("Incident updated successfully")
+	h.publisher.PublishEvent(handlerLogger, "incident.event.updated", &incident)
+	return &incident, nil
+}
+
+// DeleteIncident handles requests to delete an incident
+func (h *IncidentHandler) DeleteIncident(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "incident.delete")
+	handlerLogger.Info("Received incident.delete request")
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+		return nil, errors.NewBadRequestError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("incident_id", req.ID)
+	if req.ID == "" {
+		handlerLogger.Warn("Empty incident ID provided")
+		return nil, errors.NewBadRequestError("Incident ID is required", nil)
+	}
+
+	handlerLogger.Info("Incident deleted successfully")
+	h.publisher.PublishEvent(handlerLogger, "incident.event.deleted", map[string]string{"id": req.ID})
+	return map[string]any{"id": req.ID, "deleted": true}, nil
+}
+
+// UpdateIncidentStatus handles requests to move an incident to another status
+func (h *IncidentHandler) UpdateIncidentStatus(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "incident.status.update")
+	handlerLogger.Info("Received incident.status.update request")
+
+	var req struct {
+		ID     string                `json:"id"`
+		Status models.IncidentStatus `json:"status"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+		return nil, errors.NewBadRequestError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("incident_id", req.ID).With("status", req.Status)
+	if req.ID == "" || req.Status == "" {
+		handlerLogger.Warn("Incident ID or status missing")
+		return nil, errors.NewBadRequestError("Incident ID and status are required", nil)
+	}
+
+	event := map[string]any{"id": req.ID, "status": req.Status, "updated_at": time.Now()}
+
+	handlerLogger.Info("Incident status updated successfully")
+	h.publisher.PublishEvent(handlerLogger, "incident.event.status_changed", event)
+	return event, nil
+}
+
+// AssignIncident handles requests to assign an incident to a user
+func (h *IncidentHandler) AssignIncident(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "incident.assign")
+	handlerLogger.Info("Received incident.assign request")
+
+	var req struct {
+		ID         string `json:"id"`
+		AssignedTo string `json:"assigned_to"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+		return nil, errors.NewBadRequestError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("incident_id", req.ID).With("assigned_to", req.AssignedTo)
+	if req.ID == "" || req.AssignedTo == "" {
+		handlerLogger.Warn("Incident ID or assignee missing")
+		return nil, errors.NewBadRequestError("Incident ID and assignee are required", nil)
+	}
+
+	event := map[string]any{
+		"id":          req.ID,
+		"assigned_to": req.AssignedTo,
+		"status":      models.IncidentStatusAssigned,
+		"updated_at":  time.Now(),
+	}
+
+	handlerLogger.Info("Incident assigned successfully")
+	h.publisher.PublishEvent(handlerLogger, "incident.event.assigned", event)
+	return event, nil
+}
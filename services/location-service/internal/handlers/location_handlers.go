@@ -15,6 +15,7 @@ import (
 // LocationHandler handles location-related requests
 type LocationHandler struct {
 	logger log.Logger
+	publisher *patterns.Publisher
 	// locationService would normally be here
 }
 
@@ -27,6 +28,9 @@ func NewLocationHandlerWithMocks(logger log.Logger) *LocationHandler {
 
 // RegisterHandlers registers location-related handlers with NATS
 func (h *LocationHandler) RegisterHandlers(conn *nats.Conn) {
+	// Domain events let consumers such as the gateway cache react to changes
+	h.publisher = patterns.NewPublisher(conn, "location-service", h.logger)
+
 	// Get location by ID
 	patterns.HandleRequest(conn, "location.get", h.GetLocation, h.logger)
 	
@@ -35,6 +39,10 @@ func (h *LocationHandler) RegisterHandlers(conn *nats.Conn) {
 	
 	// Create location
 	patterns.HandleRequest(conn, "location.create", h.CreateLocation, h.logger)
+
+	// Update and delete locations
+	patterns.HandleRequest(conn, "location.update", h.UpdateLocation, h.logger)
+	patterns.HandleRequest(conn, "location.delete", h.DeleteLocation, h.logger)
 }
 
 // GetLocation handles requests to get a location by ID
@@ -149,5 +157,54 @@ func (h *LocationHandler) CreateLocation(data []byte) (any, error) {
 	location.UpdatedAt = time.Now()
 
 	handlerLogger.Info("Location created successfully")
+	h.publisher.PublishEvent(handlerLogger, "location.event.created", &location)
+	return &location, nil
+}
+
+// UpdateLocation handles requests to update a location
+func (h *LocationHandler) UpdateLocation(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "location.update")
+	handlerLogger.Info("Received location.update request")
+
+	var location models.Location
+	if err := json.Unmarshal(data, &location); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal location data")
+		return nil, errors.NewBadRequestError("Invalid location data", err)
+	}
+
+	handlerLogger = handlerLogger.With("location_id", location.ID)
+	if location.ID == "" {
+		handlerLogger.Warn("Empty location ID provided")
+		return nil, errors.NewBadRequestError("Location ID is required", nil)
+	}
+
+	location.UpdatedAt = time.Now()
+
+	handlerLogger.Info("Location updated successfully")
+	h.publisher.PublishEvent(handlerLogger, "location.event.updated", &location)
 	return &location, nil
-}
\ No newline at end of file
+}
+
+// DeleteLocation handles requests to delete a location
+func (h *LocationHandler) DeleteLocation(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", "location.delete")
+	handlerLogger.Info("Received location.delete request")
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+		return nil, errors.NewBadRequestError("Invalid request format", err)
+	}
+
+	handlerLogger = handlerLogger.With("location_id", req.ID)
+	if req.ID == "" {
+		handlerLogger.Warn("Empty location ID provided")
+		return nil, errors.NewBadRequestError("Location ID is required", nil)
+	}
+
+	handlerLogger.Info("Location deleted successfully")
+	h.publisher.PublishEvent(handlerLogger, "location.event.deleted", map[string]string{"id": req.ID})
+	return map[string]any{"id": req.ID, "deleted": true}, nil
+}
@@ -3,13 +3,16 @@ package main
 
 import (
 	"os"
-	"os/signal"
-	"syscall"
 
+	"github.com/0xsj/fn-go/pkg/common/db"
+	"github.com/0xsj/fn-go/pkg/common/lifecycle"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
 	"github.com/0xsj/fn-go/services/monitoring-service/internal/config"
 	"github.com/0xsj/fn-go/services/monitoring-service/internal/handlers"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/repository/mysql"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/service"
 )
 
 func main() {
@@ -35,6 +38,31 @@ func main() {
 		With("prometheus_enabled", cfg.Monitoring.PrometheusEnabled).
 		Info("Configuration loaded")
 
+	// Initialize database connection
+	logger.Info("Connecting to database")
+	dbConfig := db.MySQLConfig{
+		DatabaseConfig: db.DatabaseConfig{
+			Host:            cfg.Database.Host,
+			Port:            cfg.Database.Port,
+			Username:        cfg.Database.Username,
+			Password:        cfg.Database.Password,
+			Database:        cfg.Database.Database,
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+			ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+			Timeout:         cfg.Database.Timeout,
+		},
+		ParseTime: true,
+		Charset:   "utf8mb4",
+	}
+
+	dbConn, err := db.NewMySQLDB(logger, dbConfig)
+	if err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to connect to database")
+	}
+	logger.Info("Successfully connected to database")
+
 	// Initialize NATS client
 	logger.Info("Connecting to NATS server")
 	natsConfig := nats.Config{
@@ -48,24 +76,45 @@ func main() {
 	if err != nil {
 		logger.With("error", err.Error()).Fatal("Failed to connect to NATS")
 	}
-	defer client.Close()
 	logger.Info("Successfully connected to NATS server")
 
+	// Initialize repositories and services
+	auditRepo := mysql.NewAuditRepository(dbConn, logger)
+	auditService := service.NewAuditService(auditRepo, logger)
+	securityService := service.NewSecurityService(cfg.Security, logger)
+
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler(logger)
 	monitoringHandler := handlers.NewMonitoringHandlerWithMocks(logger)
+	auditHandler := handlers.NewAuditHandler(auditService, logger)
+	securityHandler := handlers.NewSecurityHandler(securityService, logger)
 
 	// Register handlers
 	logger.Info("Setting up request handlers")
 	healthHandler.RegisterHandlers(client.Conn())
 	monitoringHandler.RegisterHandlers(client.Conn())
-	logger.Info("Handlers registered, service is ready")
+	auditHandler.RegisterHandlers(client.Conn())
+
+	// Store the audit records the gateway publishes for mutating requests
+	subscriber := patterns.NewSubscriber(client.Conn(), "monitoring-service", logger)
+	if err := auditHandler.Subscribe(subscriber); err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to subscribe to audit records")
+	}
 
-	// Wait for termination signal
-	logger.Info("Waiting for termination signal")
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
-	<-signalCh
+	// Watch auth-service lockouts for credential stuffing
+	if err := securityHandler.Subscribe(subscriber); err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to subscribe to security events")
+	}
+	logger.Info("Handlers registered, service is ready")
 
-	logger.Info("Shutting down")
+	// Drain NATS before closing the database, so handlers in flight can
+	// finish storing their records
+	shutdown := lifecycle.NewManager(cfg.Server, logger)
+	shutdown.OnShutdown("nats", client.Drain)
+	shutdown.OnShutdown("database", lifecycle.CloseFunc(dbConn.Close))
+	if err := shutdown.Run(); err != nil {
+		logger.With("error", err.Error()).Error("Graceful shutdown incomplete")
+		os.Exit(1)
+	}
+	logger.Info("Shutdown complete")
 }
\ No newline at end of file
@@ -15,6 +15,7 @@ type Config struct {
 	NATS       config.NATSConfig
 	Logging    config.LogConfig
 	Monitoring MonitoringConfig
+	Security   SecurityConfig
 }
 
 type ServiceConfig struct {
@@ -29,6 +30,12 @@ type AlertingConfig struct {
 	DefaultRecipients []string
 }
 
+// SecurityConfig configures credential stuffing detection over login lockouts
+type SecurityConfig struct {
+	StuffingThreshold int           // distinct addresses or accounts locked out; 0 disables
+	StuffingWindow    time.Duration // how far back lockouts are counted
+}
+
 type MonitoringConfig struct {
 	PrometheusEnabled bool
 	PrometheusPort    int
@@ -55,11 +62,13 @@ func Load(logger log.Logger) (*Config, error) {
 			Version: provider.GetDefault("VERSION", "1.0.0"),
 		},
 		Server: config.ServerConfig{
-			Port:         provider.GetIntDefault("PORT", 8080),
-			Host:         provider.GetDefault("HOST", ""),
-			ReadTimeout:  provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
-			WriteTimeout: provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
-			IdleTimeout:  provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+			Port:            provider.GetIntDefault("PORT", 8080),
+			Host:            provider.GetDefault("HOST", ""),
+			ReadTimeout:     provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:    provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:     provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+			ShutdownTimeout: provider.GetDurationDefault("SHUTDOWN_TIMEOUT", 30*time.Second),
+			ShutdownDelay:   provider.GetDurationDefault("SHUTDOWN_DELAY", 0),
 		},
 		Database: config.DatabaseConfig{
 			Host:            provider.GetDefault("DB_HOST", "localhost"),
@@ -99,6 +108,10 @@ func Load(logger log.Logger) (*Config, error) {
 				DefaultRecipients: provider.GetSlice("ALERTING_DEFAULT_RECIPIENTS", ","),
 			},
 		},
+		Security: SecurityConfig{
+			StuffingThreshold: provider.GetIntDefault("SECURITY_STUFFING_THRESHOLD", 10),
+			StuffingWindow:    provider.GetDurationDefault("SECURITY_STUFFING_WINDOW", 5*time.Minute),
+		},
 	}
 	
 	return cfg, nil
@@ -1 +1,40 @@
-package dto
\ No newline at end of file
+// services/monitoring-service/internal/dto/request.go
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/repository"
+)
+
+// ListAuditRecordsRequest filters and pages the audit log, newest first.
+// Fields arrive as strings when forwarded from gateway query parameters.
+type ListAuditRecordsRequest struct {
+	repository.PageRequest
+	UserID        string      `json:"user_id,omitempty"`
+	Method        string      `json:"method,omitempty"`
+	Route         string      `json:"route,omitempty"`
+	Status        json.Number `json:"status,omitempty"`
+	CorrelationID string      `json:"correlation_id,omitempty"`
+	ActorID       string      `json:"actor_id,omitempty"` // records made while this administrator impersonated
+	// From and To bound the record time in RFC 3339; To is exclusive
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// SecurityEvent is an auth-service security event: a login lockout, an
+// account unlocked after one, or an administrator starting to impersonate a
+// user. Fields not used by the event are empty.
+type SecurityEvent struct {
+	Scope       string    `json:"scope,omitempty"` // "account" or "ip" for lockouts
+	UserID      string    `json:"userId,omitempty"`
+	Username    string    `json:"username,omitempty"`
+	IPAddress   string    `json:"ipAddress,omitempty"`
+	Failures    int       `json:"failures,omitempty"`
+	LockedUntil time.Time `json:"lockedUntil,omitempty"`
+	UnlockedBy  string    `json:"unlockedBy,omitempty"`
+	ActorID     string    `json:"actorId,omitempty"` // the administrator impersonating
+	Reason      string    `json:"reason,omitempty"`
+	ExpiresAt   time.Time `json:"expiresAt,omitempty"`
+}
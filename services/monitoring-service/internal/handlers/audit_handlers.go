@@ -0,0 +1,83 @@
+// services/monitoring-service/internal/handlers/audit_handlers.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/dto"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/service"
+)
+
+// auditQueue load balances audit records across monitoring-service replicas,
+// so each record is stored once
+const auditQueue = "monitoring-service"
+
+// AuditHandler stores the audit records published by the gateway and serves
+// queries over them
+type AuditHandler struct {
+	auditService service.AuditService
+	logger       log.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService service.AuditService, logger log.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger.WithLayer("audit-handler"),
+	}
+}
+
+// RegisterHandlers registers the audit log query handler with NATS
+func (h *AuditHandler) RegisterHandlers(conn *nats.Conn) {
+	patterns.HandleRequest(conn, nats.SubjectAuditList, h.ListRecords, h.logger)
+}
+
+// Subscribe stores the audit records published by the gateway
+func (h *AuditHandler) Subscribe(subscriber *patterns.Subscriber) error {
+	_, err := subscriber.QueueSubscribe(nats.SubjectAuditRequest, auditQueue, h.RecordRequest)
+	return err
+}
+
+// RecordRequest stores one audit record
+func (h *AuditHandler) RecordRequest(ctx context.Context, msg *patterns.MessageEnvelope) error {
+	var record models.AuditRecord
+	if err := msg.Unmarshal(&record); err != nil {
+		h.logger.With("error", err.Error()).Error("Failed to unmarshal audit record")
+		return err
+	}
+
+	if err := h.auditService.RecordRequest(ctx, &record); err != nil {
+		h.logger.With("error", err.Error()).
+			With("correlation_id", record.CorrelationID).
+			Error("Failed to store audit record")
+		return err
+	}
+	return nil
+}
+
+// ListRecords handles requests to query the audit log
+func (h *AuditHandler) ListRecords(data []byte) (any, error) {
+	handlerLogger := h.logger.With("subject", nats.SubjectAuditList)
+	handlerLogger.Info("Received audit list request")
+
+	var req dto.ListAuditRecordsRequest
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &req); err != nil {
+			handlerLogger.With("error", err.Error()).Error("Failed to unmarshal request")
+			return nil, errors.NewBadRequestError("Invalid request format", err)
+		}
+	}
+
+	page, err := h.auditService.ListRecords(context.Background(), req)
+	if err != nil {
+		handlerLogger.With("error", err.Error()).Error("Failed to list audit records")
+		return nil, err
+	}
+	return page, nil
+}
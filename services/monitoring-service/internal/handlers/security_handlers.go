@@ -0,0 +1,47 @@
+// services/monitoring-service/internal/handlers/security_handlers.go
+package handlers
+
+import (
+	"context"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/dto"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/service"
+)
+
+// securityQueue load balances security events across monitoring-service
+// replicas. Each replica counts the lockouts it sees, so the stuffing
+// threshold applies per replica.
+const securityQueue = "monitoring-service"
+
+// SecurityHandler watches the security events auth-service publishes
+type SecurityHandler struct {
+	securityService service.SecurityService
+	logger          log.Logger
+}
+
+// NewSecurityHandler creates a new security handler
+func NewSecurityHandler(securityService service.SecurityService, logger log.Logger) *SecurityHandler {
+	return &SecurityHandler{
+		securityService: securityService,
+		logger:          logger.WithLayer("security-handler"),
+	}
+}
+
+// Subscribe watches login lockouts, unlocks and impersonations
+func (h *SecurityHandler) Subscribe(subscriber *patterns.Subscriber) error {
+	_, err := subscriber.QueueSubscribe(nats.SubjectAuthSecurityEvents, securityQueue, h.RecordEvent)
+	return err
+}
+
+// RecordEvent records one security event
+func (h *SecurityHandler) RecordEvent(ctx context.Context, msg *patterns.MessageEnvelope) error {
+	var event dto.SecurityEvent
+	if err := msg.Unmarshal(&event); err != nil {
+		h.logger.With("error", err.Error()).With("subject", msg.Subject).Error("Failed to unmarshal security event")
+		return err
+	}
+	return h.securityService.RecordSecurityEvent(ctx, msg.Subject, event)
+}
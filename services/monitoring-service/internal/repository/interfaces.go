@@ -0,0 +1,30 @@
+// services/monitoring-service/internal/repository/interfaces.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/pkg/repository"
+)
+
+// AuditFilter selects audit records; zero fields are not filtered on
+type AuditFilter struct {
+	UserID        string
+	Method        string
+	Route         string
+	Status        int
+	CorrelationID string
+	ActorID       string
+	From          time.Time
+	To            time.Time
+}
+
+// AuditRepository defines the contract for audit record storage
+type AuditRepository interface {
+	// Create stores a record, ignoring one already stored under its ID
+	Create(ctx context.Context, record *models.AuditRecord) error
+	// List returns up to limit+1 records past the cursor, newest first
+	List(ctx context.Context, filter AuditFilter, cursor *repository.Cursor, limit int) ([]*models.AuditRecord, error)
+}
@@ -0,0 +1,200 @@
+// services/monitoring-service/internal/repository/mysql/audit_repository.go
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/0xsj/fn-go/pkg/common/db"
+	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/models"
+	pagination "github.com/0xsj/fn-go/pkg/repository"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/repository"
+)
+
+// auditSorts orders the audit log newest first, by ID within the same instant
+var auditSorts = []db.SortOption{
+	{Field: "recorded_at", Direction: db.SortDescending},
+	{Field: "id", Direction: db.SortDescending},
+}
+
+// AuditRepository implements repository.AuditRepository using MySQL
+type AuditRepository struct {
+	db     db.DB
+	logger log.Logger
+}
+
+func NewAuditRepository(db db.DB, logger log.Logger) repository.AuditRepository {
+	return &AuditRepository{
+		db:     db,
+		logger: logger.WithLayer("mysql-audit-repository"),
+	}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, record *models.AuditRecord) error {
+	// Redelivered records keep their ID, so they are stored once
+	query := `
+		INSERT IGNORE INTO audit_records (
+			id, recorded_at, user_id, principal_id, principal_type, actor_id, roles, method, path,
+			route, subject, params, status, latency_ms, correlation_id, client_ip, user_agent
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	rolesJSON, err := json.Marshal(record.Roles)
+	if err != nil {
+		return errors.NewBadRequestError("Failed to process audit record roles", err)
+	}
+	paramsJSON, err := json.Marshal(record.Params)
+	if err != nil {
+		return errors.NewBadRequestError("Failed to process audit record params", err)
+	}
+
+	_, err = r.db.Execute(
+		ctx,
+		query,
+		record.ID,
+		record.Time.UTC(),
+		nullString(record.UserID),
+		nullString(record.PrincipalID),
+		nullString(record.PrincipalType),
+		nullString(record.ActorID),
+		rolesJSON,
+		record.Method,
+		record.Path,
+		nullString(record.Route),
+		nullString(record.Subject),
+		paramsJSON,
+		record.Status,
+		record.LatencyMS,
+		record.CorrelationID,
+		record.ClientIP,
+		nullString(record.UserAgent),
+	)
+	if err != nil {
+		return errors.NewDatabaseError("Failed to store audit record", err)
+	}
+	return nil
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter repository.AuditFilter, cursor *pagination.Cursor, limit int) ([]*models.AuditRecord, error) {
+	var values []any
+	backward := false
+	if cursor != nil {
+		values = cursor.Values
+		backward = cursor.Backward
+	}
+	clauses, args := db.BuildCursorQueryClauses(auditFilters(filter), auditSorts, values, backward, limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, recorded_at, user_id, principal_id, principal_type, actor_id, roles, method, path,
+		       route, subject, params, status, latency_ms, correlation_id, client_ip, user_agent
+		FROM audit_records
+		%s
+	`, clauses)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewDatabaseError("Failed to list audit records", err)
+	}
+	defer rows.Close()
+
+	var records []*models.AuditRecord
+	for rows.Next() {
+		record := &models.AuditRecord{}
+		var userID, principalID, principalType, actorID, route, subject, userAgent sql.NullString
+		var rolesJSON, paramsJSON []byte
+
+		err := rows.Scan(
+			&record.ID,
+			&record.Time,
+			&userID,
+			&principalID,
+			&principalType,
+			&actorID,
+			&rolesJSON,
+			&record.Method,
+			&record.Path,
+			&route,
+			&subject,
+			&paramsJSON,
+			&record.Status,
+			&record.LatencyMS,
+			&record.CorrelationID,
+			&record.ClientIP,
+			&userAgent,
+		)
+		if err != nil {
+			return nil, errors.NewDatabaseError("Failed to scan audit record row", err)
+		}
+
+		record.UserID = userID.String
+		record.PrincipalID = principalID.String
+		record.PrincipalType = principalType.String
+		record.ActorID = actorID.String
+		record.Route = route.String
+		record.Subject = subject.String
+		record.UserAgent = userAgent.String
+
+		if len(rolesJSON) > 0 {
+			if err := json.Unmarshal(rolesJSON, &record.Roles); err != nil {
+				return nil, errors.NewDatabaseError("Failed to process audit record roles", err)
+			}
+		}
+		if len(paramsJSON) > 0 {
+			if err := json.Unmarshal(paramsJSON, &record.Params); err != nil {
+				return nil, errors.NewDatabaseError("Failed to process audit record params", err)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("Error iterating audit record rows", err)
+	}
+
+	return records, nil
+}
+
+// auditFilters maps a filter to the columns it selects on
+func auditFilters(filter repository.AuditFilter) map[string]any {
+	filters := make(map[string]any)
+	if filter.UserID != "" {
+		filters["user_id"] = filter.UserID
+	}
+	if filter.Method != "" {
+		filters["method"] = filter.Method
+	}
+	if filter.Route != "" {
+		filters["route"] = filter.Route
+	}
+	if filter.Status != 0 {
+		filters["status"] = filter.Status
+	}
+	if filter.CorrelationID != "" {
+		filters["correlation_id"] = filter.CorrelationID
+	}
+	if filter.ActorID != "" {
+		filters["actor_id"] = filter.ActorID
+	}
+
+	var timeRange db.Range
+	if !filter.From.IsZero() {
+		timeRange.From = filter.From.UTC()
+	}
+	if !filter.To.IsZero() {
+		timeRange.To = filter.To.UTC()
+	}
+	if timeRange.From != nil || timeRange.To != nil {
+		filters["recorded_at"] = timeRange
+	}
+	return filters
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,100 @@
+// services/monitoring-service/internal/service/audit_service.go
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/errors"
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/response"
+	"github.com/0xsj/fn-go/pkg/models"
+	pagination "github.com/0xsj/fn-go/pkg/repository"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/dto"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/repository"
+)
+
+// Audit log page sizes
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// AuditServiceImpl implements the AuditService interface
+type AuditServiceImpl struct {
+	repo   repository.AuditRepository
+	logger log.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repository.AuditRepository, logger log.Logger) AuditService {
+	return &AuditServiceImpl{
+		repo:   repo,
+		logger: logger.WithLayer("audit-service"),
+	}
+}
+
+// RecordRequest stores an audit record emitted by the gateway
+func (s *AuditServiceImpl) RecordRequest(ctx context.Context, record *models.AuditRecord) error {
+	if record.ID == "" || record.Method == "" || record.Time.IsZero() {
+		return errors.NewValidationError("Audit record is missing its ID, method or time", nil)
+	}
+	return s.repo.Create(ctx, record)
+}
+
+// ListRecords returns a page of the audit log, newest first
+func (s *AuditServiceImpl) ListRecords(ctx context.Context, req dto.ListAuditRecordsRequest) (*response.CursorPage, error) {
+	cursor, limit, err := req.Parse(len(auditKey(&models.AuditRecord{})), defaultAuditPageSize, maxAuditPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.AuditFilter{
+		UserID:        req.UserID,
+		Method:        strings.ToUpper(req.Method),
+		Route:         req.Route,
+		CorrelationID: req.CorrelationID,
+		ActorID:       req.ActorID,
+	}
+	if req.Status != "" {
+		if filter.Status, err = strconv.Atoi(req.Status.String()); err != nil {
+			return nil, errors.NewBadRequestError("Status must be an HTTP status code", err)
+		}
+	}
+	if filter.From, err = parseAuditTime("from", req.From); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseAuditTime("to", req.To); err != nil {
+		return nil, err
+	}
+
+	records, err := s.repo.List(ctx, filter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	records, cursors := pagination.Page(records, limit, cursor, auditKey)
+
+	return &response.CursorPage{
+		Items:   records,
+		Cursors: response.CursorMeta{Next: cursors.Next, Prev: cursors.Prev, Limit: limit},
+	}, nil
+}
+
+// auditKey returns the sort key of an audit record
+func auditKey(record *models.AuditRecord) []any {
+	return []any{record.Time, record.ID}
+}
+
+// parseAuditTime parses an optional RFC 3339 time filter
+func parseAuditTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.NewBadRequestError(name+" must be an RFC 3339 time", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,21 @@
+// services/monitoring-service/internal/service/interfaces.go
+package service
+
+import (
+	"context"
+
+	"github.com/0xsj/fn-go/pkg/common/response"
+	"github.com/0xsj/fn-go/pkg/models"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/dto"
+)
+
+// AuditService defines the contract for the audit log
+type AuditService interface {
+	RecordRequest(ctx context.Context, record *models.AuditRecord) error
+	ListRecords(ctx context.Context, req dto.ListAuditRecordsRequest) (*response.CursorPage, error)
+}
+
+// SecurityService defines the contract for watching auth security events
+type SecurityService interface {
+	RecordSecurityEvent(ctx context.Context, subject string, event dto.SecurityEvent) error
+}
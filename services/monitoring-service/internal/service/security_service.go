@@ -0,0 +1,116 @@
+// services/monitoring-service/internal/service/security_service.go
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/fn-go/pkg/common/log"
+	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/config"
+	"github.com/0xsj/fn-go/services/monitoring-service/internal/dto"
+)
+
+// SecurityServiceImpl implements the SecurityService interface. Every event
+// is logged; lockouts are also counted over a sliding window, and many
+// distinct addresses or accounts locking out within it raise a credential
+// stuffing alert.
+type SecurityServiceImpl struct {
+	cfg    config.SecurityConfig
+	logger log.Logger
+
+	mu        sync.Mutex
+	lockouts  map[string]time.Time // "scope:key" to its latest lockout
+	lastAlert time.Time
+}
+
+// NewSecurityService creates a new security service
+func NewSecurityService(cfg config.SecurityConfig, logger log.Logger) SecurityService {
+	return &SecurityServiceImpl{
+		cfg:      cfg,
+		logger:   logger.WithLayer("security-service"),
+		lockouts: make(map[string]time.Time),
+	}
+}
+
+// RecordSecurityEvent logs a security event and checks lockouts for
+// credential stuffing
+func (s *SecurityServiceImpl) RecordSecurityEvent(ctx context.Context, subject string, event dto.SecurityEvent) error {
+	logger := s.logger.With("subject", subject)
+	if event.UserID != "" {
+		logger = logger.With("user_id", event.UserID)
+	}
+
+	switch subject {
+	case nats.SubjectAuthLoginLockout:
+		logger.With("scope", event.Scope).
+			With("username", event.Username).
+			With("ip_address", event.IPAddress).
+			With("failures", event.Failures).
+			With("locked_until", event.LockedUntil).
+			Warn("Login locked out")
+		s.recordLockout(event, time.Now())
+	case nats.SubjectAuthAccountUnlocked:
+		logger.With("unlocked_by", event.UnlockedBy).Info("Account unlocked")
+	case nats.SubjectAuthImpersonationStarted:
+		logger.With("actor_id", event.ActorID).
+			With("reason", event.Reason).
+			With("ip_address", event.IPAddress).
+			With("expires_at", event.ExpiresAt).
+			Warn("Impersonation started")
+	default:
+		logger.Info("Security event received")
+	}
+	return nil
+}
+
+// recordLockout counts a lockout in the window and alerts once the distinct
+// addresses or accounts locked out reach the threshold. Alerts are spaced a
+// window apart so an ongoing attack raises one per window.
+func (s *SecurityServiceImpl) recordLockout(event dto.SecurityEvent, now time.Time) {
+	if s.cfg.StuffingThreshold <= 0 {
+		return
+	}
+
+	key := event.IPAddress
+	if event.Scope != "ip" {
+		key = event.UserID
+		if key == "" {
+			key = event.Username
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lockouts[event.Scope+":"+key] = now
+
+	var ips, accounts int
+	for k, at := range s.lockouts {
+		if now.Sub(at) > s.cfg.StuffingWindow {
+			delete(s.lockouts, k)
+			continue
+		}
+		if strings.HasPrefix(k, "ip:") {
+			ips++
+		} else {
+			accounts++
+		}
+	}
+
+	if ips < s.cfg.StuffingThreshold && accounts < s.cfg.StuffingThreshold {
+		return
+	}
+	if now.Sub(s.lastAlert) < s.cfg.StuffingWindow {
+		return
+	}
+	s.lastAlert = now
+
+	s.logger.With("alert", "credential_stuffing").
+		With("locked_ips", ips).
+		With("locked_accounts", accounts).
+		With("window", s.cfg.StuffingWindow.String()).
+		Error("Possible credential stuffing: many logins locked out")
+}
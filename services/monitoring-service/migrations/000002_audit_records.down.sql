@@ -0,0 +1,4 @@
+-- services/monitoring-service/migrations/000002_audit_records.down.sql
+-- Rollback audit records
+
+DROP TABLE IF EXISTS audit_records;
@@ -0,0 +1,26 @@
+-- services/monitoring-service/migrations/000002_audit_records.up.sql
+-- Audit records of mutating API requests, emitted by the gateway
+
+CREATE TABLE audit_records (
+    id VARCHAR(36) PRIMARY KEY,
+    recorded_at DATETIME(6) NOT NULL,
+    user_id VARCHAR(36) NULL,
+    principal_id VARCHAR(36) NULL,
+    principal_type VARCHAR(20) NULL,
+    roles JSON NULL,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    route VARCHAR(255) NULL,
+    subject VARCHAR(255) NULL,
+    params JSON NULL,
+    status SMALLINT NOT NULL,
+    latency_ms INT NOT NULL,
+    correlation_id VARCHAR(128) NOT NULL,
+    client_ip VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(512) NULL,
+    
+    INDEX idx_audit_records_recorded_at (recorded_at, id),
+    INDEX idx_audit_records_user_id (user_id, recorded_at),
+    INDEX idx_audit_records_route (route, recorded_at),
+    INDEX idx_audit_records_correlation_id (correlation_id)
+);
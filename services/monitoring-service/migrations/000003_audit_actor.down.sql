@@ -0,0 +1,6 @@
+-- services/monitoring-service/migrations/000003_audit_actor.down.sql
+-- Rollback audit record actors
+
+ALTER TABLE audit_records
+    DROP INDEX idx_audit_records_actor_id,
+    DROP COLUMN actor_id;
@@ -0,0 +1,6 @@
+-- services/monitoring-service/migrations/000003_audit_actor.up.sql
+-- The administrator behind requests made while impersonating a user
+
+ALTER TABLE audit_records
+    ADD COLUMN actor_id VARCHAR(36) NULL AFTER principal_type,
+    ADD INDEX idx_audit_records_actor_id (actor_id, recorded_at);
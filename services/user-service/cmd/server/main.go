@@ -3,12 +3,15 @@ package main
 
 import (
 	"os"
-	"os/signal"
-	"syscall"
+	"time"
 
 	"github.com/0xsj/fn-go/pkg/common/db"
+	"github.com/0xsj/fn-go/pkg/common/jwks"
+	"github.com/0xsj/fn-go/pkg/common/lifecycle"
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
+	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/serviceauth"
 	"github.com/0xsj/fn-go/services/user-service/internal/config"
 	"github.com/0xsj/fn-go/services/user-service/internal/handlers"
 	"github.com/0xsj/fn-go/services/user-service/internal/repository/mysql"
@@ -51,7 +54,6 @@ func main() {
 	if err != nil {
 		logger.With("error", err.Error()).Fatal("Failed to connect to database")
 	}
-	defer dbConn.Close()
 	logger.Info("Successfully connected to database")
 
 	// Initialize NATS client
@@ -67,7 +69,6 @@ func main() {
 	if err != nil {
 		logger.With("error", err.Error()).Fatal("Failed to connect to NATS")
 	}
-	defer client.Close()
 	logger.Info("Successfully connected to NATS server")
 
 	// Initialize repositories
@@ -76,9 +77,20 @@ func main() {
 	// Initialize services
 	userService := service.NewUserService(userRepo, logger)
 
+	// Service tokens are verified against the keys auth-service publishes
+	verifier := jwks.NewVerifier(jwks.NATSFetcher(client.Conn(), 5*time.Second, logger), cfg.JWKS.MinRefreshInterval, logger)
+	if err := verifier.Start(cfg.JWKS.RefreshInterval); err != nil {
+		logger.With("error", err.Error()).Warn("Failed to fetch signing keys, retrying in the background")
+	}
+	subscriber := patterns.NewSubscriber(client.Conn(), cfg.Service.Name, logger)
+	if _, err := verifier.SubscribeRotations(subscriber); err != nil {
+		logger.With("error", err.Error()).Fatal("Failed to subscribe to signing key rotations")
+	}
+	authn := serviceauth.NewAuthenticator(verifier, logger)
+
 	// Create handlers
 	healthHandler := handlers.NewHealthHandler(logger)
-	userHandler := handlers.NewUserHandler(userService, logger)
+	userHandler := handlers.NewUserHandler(userService, authn, logger)
 
 	// Register handlers
 	logger.Info("Setting up request handlers")
@@ -86,11 +98,14 @@ func main() {
 	userHandler.RegisterHandlers(client.Conn())
 	logger.Info("Handlers registered, service is ready")
 
-	// Wait for termination signal
-	logger.Info("Waiting for termination signal")
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
-	<-signalCh
-
-	logger.Info("Shutting down")
+	// Drain NATS before closing the database, so handlers in flight can
+	// finish their queries
+	shutdown := lifecycle.NewManager(cfg.Server, logger)
+	shutdown.OnShutdown("nats", client.Drain)
+	shutdown.OnShutdown("database", lifecycle.CloseFunc(dbConn.Close))
+	if err := shutdown.Run(); err != nil {
+		logger.With("error", err.Error()).Error("Graceful shutdown incomplete")
+		os.Exit(1)
+	}
+	logger.Info("Shutdown complete")
 }
\ No newline at end of file
@@ -14,6 +14,7 @@ type Config struct {
 	Database config.DatabaseConfig
 	NATS     config.NATSConfig
 	Logging  config.LogConfig
+	JWKS     JWKSConfig
 }
 
 type ServiceConfig struct {
@@ -21,6 +22,13 @@ type ServiceConfig struct {
 	Version string
 }
 
+// JWKSConfig configures verification of service tokens against the keys
+// auth-service publishes
+type JWKSConfig struct {
+	RefreshInterval    time.Duration // how often the keys are refetched
+	MinRefreshInterval time.Duration // least time between refetches for unknown key IDs
+}
+
 func Load(logger log.Logger) (*Config, error) {
     // Create environment provider with prefix "USER_SERVICE"
     provider := config.NewEnvProvider("USER_SERVICE")
@@ -39,11 +47,13 @@ func Load(logger log.Logger) (*Config, error) {
             Version: provider.GetDefault("VERSION", "1.0.0"),
         },
         Server: config.ServerConfig{
-            Port:         provider.GetIntDefault("PORT", 8080),
-            Host:         provider.GetDefault("HOST", ""),
-            ReadTimeout:  provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
-            WriteTimeout: provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
-            IdleTimeout:  provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+            Port:            provider.GetIntDefault("PORT", 8080),
+            Host:            provider.GetDefault("HOST", ""),
+            ReadTimeout:     provider.GetDurationDefault("READ_TIMEOUT", 10*time.Second),
+            WriteTimeout:    provider.GetDurationDefault("WRITE_TIMEOUT", 10*time.Second),
+            IdleTimeout:     provider.GetDurationDefault("IDLE_TIMEOUT", 30*time.Second),
+            ShutdownTimeout: provider.GetDurationDefault("SHUTDOWN_TIMEOUT", 30*time.Second),
+            ShutdownDelay:   provider.GetDurationDefault("SHUTDOWN_DELAY", 0),
         },
         Database: config.DatabaseConfig{
             Host:            provider.GetDefault("DB_HOST", "localhost"),
@@ -69,6 +79,10 @@ func Load(logger log.Logger) (*Config, error) {
             Output:     provider.GetDefault("LOG_OUTPUT", "stdout"),
             TimeFormat: provider.GetDefault("LOG_TIME_FORMAT", "2006-01-02 15:04:05"),
         },
+        JWKS: JWKSConfig{
+            RefreshInterval:    provider.GetDurationDefault("JWKS_REFRESH_INTERVAL", 5*time.Minute),
+            MinRefreshInterval: provider.GetDurationDefault("JWKS_MIN_REFRESH_INTERVAL", 10*time.Second),
+        },
     }
     
     return cfg, nil
@@ -26,6 +26,13 @@ type UserResponse struct {
     UpdatedAt     time.Time               `json:"updatedAt"`
 }
 
+// UserWithPassword is a user with their password hash, which the user model
+// never serializes. It is only sent to services authorized to see it.
+type UserWithPassword struct {
+    *models.User
+    PasswordHash string `json:"passwordHash"`
+}
+
 // ListUsersResponse represents a paginated list of users
 type ListUsersResponse struct {
     Users      []UserResponse `json:"users"`
@@ -9,6 +9,7 @@ import (
 	"github.com/0xsj/fn-go/pkg/common/log"
 	"github.com/0xsj/fn-go/pkg/common/nats"
 	"github.com/0xsj/fn-go/pkg/common/nats/patterns"
+	"github.com/0xsj/fn-go/pkg/common/serviceauth"
 	"github.com/0xsj/fn-go/services/user-service/internal/domain"
 	"github.com/0xsj/fn-go/services/user-service/internal/dto"
 	"github.com/0xsj/fn-go/services/user-service/internal/service"
@@ -19,21 +20,23 @@ import (
 // UserHandler handles user-related requests
 type UserHandler struct {
 	userService service.UserService
+	authn       *serviceauth.Authenticator
 	logger      log.Logger
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService service.UserService, logger log.Logger) *UserHandler {
+func NewUserHandler(userService service.UserService, authn *serviceauth.Authenticator, logger log.Logger) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		authn:       authn,
 		logger:      logger.WithLayer("user-handler"),
 	}
 }
 
 // RegisterHandlers registers user-related handlers with NATS
 func (h *UserHandler) RegisterHandlers(conn *nats.Conn) {
-	// Get user by ID
-	patterns.HandleRequest(conn, "user.get", h.GetUser, h.logger)
+	// Get user by ID; the password hash only goes to authorized services
+	h.authn.HandleRequest(conn, "user.get", h.GetUser, h.logger)
 	
 	// List users
 	// patterns.HandleRequest(conn, "user.list", h.ListUsers, h.logger)
@@ -48,8 +51,9 @@ func (h *UserHandler) RegisterHandlers(conn *nats.Conn) {
 	// patterns.HandleRequest(conn, "user.delete", h.DeleteUser, h.logger)
 }
 
-// GetUser handles requests to get a user by ID
-func (h *UserHandler) GetUser(data []byte) (any, error) {
+// GetUser handles requests to get a user by ID. Services holding the
+// user.get.password scope may ask for the password hash too.
+func (h *UserHandler) GetUser(caller *serviceauth.Caller, data []byte) (any, error) {
 	handlerLogger := h.logger.With("subject", "user.get")
 	handlerLogger.Info("Received user.get request")
 	
@@ -62,7 +66,8 @@ func (h *UserHandler) GetUser(data []byte) (any, error) {
 	}()
 
 	var req struct {
-		ID string `json:"id"`
+		ID              string `json:"id"`
+		IncludePassword bool   `json:"includePassword,omitempty"`
 	}
 
 	handlerLogger.Debug("Unmarshaling request data")
@@ -81,6 +86,14 @@ func (h *UserHandler) GetUser(data []byte) (any, error) {
 		return nil, domain.NewInvalidUserInputError("User ID is required", nil)
 	}
 
+	if req.IncludePassword {
+		if err := h.authn.Authorize(caller, nats.ScopeUserPassword); err != nil {
+			metrics.RequestDurationHistogram.WithLabelValues("GetUser", "error").Observe(time.Since(startTime).Seconds())
+			return nil, err
+		}
+		handlerLogger = handlerLogger.With("client_id", caller.ClientID)
+	}
+
 	// Use real service to get user
 	user, err := h.userService.GetUser(context.Background(), req.ID)
 	if err != nil {
@@ -89,6 +102,11 @@ func (h *UserHandler) GetUser(data []byte) (any, error) {
 		return nil, err
 	}
 
+	if req.IncludePassword {
+		handlerLogger.Info("User found, returning response with password hash")
+		return dto.UserWithPassword{User: user, PasswordHash: user.Password}, nil
+	}
+
 	handlerLogger.Info("User found, returning response")
 	return user, nil
 }